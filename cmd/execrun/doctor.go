@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gur-shatz/go-run/internal/doctor"
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/pkg/execrun"
+)
+
+// runDoctor checks the environment execrun runs in and prints a fix for
+// each problem (`execrun doctor`). It fails if any check fails.
+func runDoctor(configPath string) error {
+	log.Init(false)
+
+	results := []doctor.Result{doctor.GoToolchain()}
+	cfg, _, err := execrun.LoadConfig(configPath, configOpts...)
+	if err != nil {
+		results = append(results, doctor.Result{Check: "config", Status: doctor.Fail, Detail: err.Error(),
+			Fix: "fix the config, or run `execrun init` to generate one"})
+	} else {
+		results = append(results, doctor.Result{Check: "config", Detail: configPath})
+		configAbs, err := filepath.Abs(configPath)
+		if err != nil {
+			return fmt.Errorf("resolve config path: %w", err)
+		}
+		rootDir := filepath.Dir(configAbs)
+		watch, files := doctor.WatchPatterns("config", cfg, rootDir)
+		results = append(results, watch,
+			doctor.WatchLimit(doctor.Dirs(files)),
+			doctor.Writable("cache_dir", cfg.CachePath(rootDir)))
+	}
+
+	if failed := doctor.Print(results); failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/pkg/execrun"
+)
+
+// explainSample is how many watched files `execrun explain` lists without
+// -all.
+const explainSample = 20
+
+// runExplain prints what a run would watch and run, without running
+// anything (`execrun explain [file...]`). Given files, it says whether each
+// is watched.
+func runExplain(configPath string, args []string) error {
+	efs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	jsonOut := efs.Bool("json", false, "print the explanation as JSON")
+	all := efs.Bool("all", false, fmt.Sprintf("list every watched file, not the first %d", explainSample))
+	if err := efs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	log.Init(false)
+
+	cfg, _, err := execrun.LoadConfig(configPath, configOpts...)
+	if err != nil {
+		return err
+	}
+	configAbs, err := filepath.Abs(configPath)
+	if err != nil {
+		return fmt.Errorf("resolve config path: %w", err)
+	}
+	e, err := cfg.Explain(filepath.Dir(configAbs))
+	if err != nil {
+		return err
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(e)
+	}
+
+	fmt.Printf("Config:   %s\n", configPath)
+	fmt.Printf("Root dir: %s (commands run here, patterns are relative to it)\n", e.RootDir)
+	fmt.Println("Watch patterns:")
+	printList(e.Patterns)
+	files := e.Files
+	if !*all && len(files) > explainSample {
+		files = files[:explainSample]
+	}
+	fmt.Printf("Watched files: %d\n", len(e.Files))
+	printList(files)
+	if len(files) < len(e.Files) {
+		fmt.Printf("  ... and %d more (-all lists them)\n", len(e.Files)-len(files))
+	}
+	for _, steps := range []struct {
+		name string
+		cmds []string
+	}{{"Build", e.Build}, {"Test", e.Test}, {"Exec", e.Exec}} {
+		if len(steps.cmds) > 0 {
+			fmt.Printf("%s:\n", steps.name)
+			printList(steps.cmds)
+		}
+	}
+
+	for _, arg := range efs.Args() {
+		rel := arg
+		if abs, err := filepath.Abs(arg); err == nil {
+			if r, err := filepath.Rel(e.RootDir, abs); err == nil {
+				rel = filepath.ToSlash(r)
+			}
+		}
+		if slices.Contains(e.Files, rel) {
+			fmt.Printf("%s: watched\n", arg)
+		} else {
+			fmt.Printf("%s: not watched; no pattern matches it, or an exclusion, ignore file, or .gitignore leaves it out\n", arg)
+		}
+	}
+	return nil
+}
+
+func printList(items []string) {
+	for _, s := range items {
+		fmt.Printf("  %s\n", s)
+	}
+}
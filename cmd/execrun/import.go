@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gur-shatz/go-run/internal/color"
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/pkg/execrun"
+)
+
+// runImport converts another hot reloader's config into an execrun config
+// (`execrun import air .air.toml`).
+func runImport(configPath string, args []string) error {
+	ifs := flag.NewFlagSet("import", flag.ContinueOnError)
+	write := ifs.Bool("w", false, "write the config file instead of printing it")
+	if err := ifs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	rest := ifs.Args()
+	if len(rest) < 1 || rest[0] != "air" {
+		return fmt.Errorf("usage: execrun import [-w] air [.air.toml]")
+	}
+	airPath := ".air.toml"
+	if len(rest) > 1 {
+		airPath = rest[1]
+	}
+	if *write {
+		if _, err := os.Stat(configPath); err == nil {
+			return fmt.Errorf("%s already exists (remove it first, or drop -w and paste the config)", configPath)
+		}
+	}
+
+	result, err := execrun.ImportAir(airPath)
+	if err != nil {
+		return err
+	}
+	// Warnings go to stderr so the printed config can be redirected.
+	for _, w := range result.Warnings {
+		fmt.Fprintln(os.Stderr, color.Stderr().Yellow("warning: "+w))
+	}
+
+	out := os.Stdout
+	if *write {
+		f, err := os.Create(configPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	if err := enc.Encode(result.Config); err != nil {
+		return fmt.Errorf("write %s: %w", configPath, err)
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	if *write {
+		log.Init(false)
+		log.Success("Created %s from %s", configPath, airPath)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// readKeys sends on trigger each time "r" is typed on a line of its own,
+// until in is closed.
+func readKeys(in io.Reader, trigger chan<- struct{}) {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) != "r" {
+			continue
+		}
+		select {
+		case trigger <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/pkg/config"
+	"github.com/gur-shatz/go-run/pkg/execrun"
+)
+
+// runLint loads the config and lists the deprecated keys it uses
+// (`execrun lint`). It fails if there are any.
+func runLint(configPath string, args []string) error {
+	lfs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	jsonOut := lfs.Bool("json", false, "print the warnings as a JSON list")
+	if err := lfs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	log.Init(false)
+
+	warnings := []config.Warning{}
+	config.SetDeprecationHandler(func(w config.Warning) { warnings = append(warnings, w) })
+	if _, _, err := execrun.LoadConfig(configPath, configOpts...); err != nil {
+		return err
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(warnings); err != nil {
+			return err
+		}
+	} else {
+		for _, w := range warnings {
+			fmt.Println(w)
+		}
+	}
+	if len(warnings) > 0 {
+		return fmt.Errorf("%s: %d deprecated keys", configPath, len(warnings))
+	}
+	if !*jsonOut {
+		log.Success("%s: no deprecated keys", configPath)
+	}
+	return nil
+}
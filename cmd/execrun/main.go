@@ -11,15 +11,23 @@ import (
 	"syscall"
 	"time"
 
+	"golang.org/x/term"
+
 	"github.com/gur-shatz/go-run/internal/buildinfo"
 	"github.com/gur-shatz/go-run/internal/color"
 	"github.com/gur-shatz/go-run/internal/configutil"
+	"github.com/gur-shatz/go-run/internal/desktop"
+	"github.com/gur-shatz/go-run/internal/hasher"
 	"github.com/gur-shatz/go-run/internal/log"
 	"github.com/gur-shatz/go-run/internal/sumfile"
+	"github.com/gur-shatz/go-run/internal/termstatus"
 	"github.com/gur-shatz/go-run/pkg/config"
 	"github.com/gur-shatz/go-run/pkg/execrun"
 )
 
+// configOpts holds the config.Options set by flags, for every LoadConfig.
+var configOpts []config.Option
+
 func main() {
 	color.Init()
 	if err := run(); err != nil {
@@ -31,15 +39,34 @@ func main() {
 func run() error {
 	fs := flag.NewFlagSet("execrun", flag.ContinueOnError)
 
-	configPath := fs.String("config", "execrun.yaml", "path to config file")
+	configPath := fs.String("config", "execrun.yaml", "path to config file, or - to read it from stdin")
 	fs.StringVar(configPath, "c", "execrun.yaml", "path to config file (shorthand)")
 	envFile := fs.String("e", "", "load environment variables from YAML file")
 	poll := fs.Duration("poll", 500*time.Millisecond, "poll interval")
-	debounce := fs.Duration("debounce", 300*time.Millisecond, "debounce duration")
+	debounce := fs.Duration("debounce", 0, "debounce duration (default: the config's debounce, else 300ms)")
 	verbose := fs.Bool("v", false, "verbose output")
+	version := fs.Bool("version", false, "print the version, commit, and build date and exit")
+	plain := fs.Bool("plain", false, "no colors; spell out status as [OK]/[FAIL] text")
+	colorMode := fs.String("color", string(color.Auto), "colorize output: auto (terminals, unless NO_COLOR is set), always, or never")
+	noColor := fs.Bool("no-color", false, "never colorize output (like -color never)")
+	porcelain := fs.Bool("porcelain", false, "print log messages as stable tab-separated records (time, target, event, detail) for scripts")
+	logFormat := fs.String("log-format", string(log.FormatText), "log message format: text, porcelain (like -porcelain), or json lines with level, time, prefix, target, and msg")
+	logLevel := fs.String("log-level", log.LevelInfo.String(), "hide log messages below debug, info, warn, or error (debug shows verbose messages, like -v)")
+	logTime := fs.String("log-time", "", "start log lines with a timestamp: time, ms, rfc3339, or a Go time layout")
+	quiet := fs.Bool("quiet", false, "hide heartbeats and status lines; errors, warnings, and build results still print")
+	prefixOutput := fs.Bool("prefix-output", false, "start each line of child output with its target and stage tag, e.g. [api:build], unless output: raw is set")
+	bell := fs.Bool("bell", false, "ring the terminal bell when a build finishes")
+	termTitle := fs.Bool("term-title", false, "show the last build result in the terminal title")
+	notify := fs.Bool("notify", false, "show a desktop notification when a build fails or is fixed")
+	strict := fs.Bool("strict", false, "reject unknown config fields and loosely typed values (like strict: true)")
+	overlay := fs.String("overlay", "", "config file deep-merged over -c before templates are processed")
+	allowShell := fs.Bool("allow-shell", false, "enable the shell template function, which runs commands from the config")
+	hashAlg := fs.String("hash", string(hasher.CRC32C), "hash algorithm for change detection: crc32c, or sha256 for sum files from older versions")
 	stdoutFile := fs.String("stdout", "", "redirect child stdout to file")
 	stderrFile := fs.String("stderr", "", "redirect child stderr to file")
 	combinedFile := fs.String("combined", "", "redirect both stdout and stderr to one file")
+	noKeys := fs.Bool("no-keys", false, "leave stdin to the process instead of reading r + Enter as a rebuild request")
+	force := fs.Bool("force", false, "take over from another instance watching the same config instead of refusing to start")
 
 	fs.Usage = func() {
 		fmt.Fprintf(os.Stderr, "execrun %s\n\n", buildinfo.String())
@@ -47,7 +74,14 @@ func run() error {
 		fmt.Fprintf(os.Stderr, "Commands:\n")
 		fmt.Fprintf(os.Stderr, "  init    Generate a starter config file\n")
 		fmt.Fprintf(os.Stderr, "  test    Run configured test steps and exit\n")
-		fmt.Fprintf(os.Stderr, "  sum     Snapshot watched file hashes to execrun.sum\n\n")
+		fmt.Fprintf(os.Stderr, "  sum     Snapshot watched file hashes to .gorun/execrun.sum\n")
+		fmt.Fprintf(os.Stderr, "  verify  Compare watched files to the sum file and list the differences (verify [sumfile])\n")
+		fmt.Fprintf(os.Stderr, "  import  Convert an air config (import [-w] air [.air.toml])\n")
+		fmt.Fprintf(os.Stderr, "  lint    List deprecated config keys (-json)\n")
+		fmt.Fprintf(os.Stderr, "  doctor  Check the Go toolchain, watch patterns and limits, and cache dir\n")
+		fmt.Fprintf(os.Stderr, "  validate  Load and resolve the config and list its errors\n")
+		fmt.Fprintf(os.Stderr, "  explain Print the resolved patterns, watched files, and commands (explain [-all] [-json] [file...])\n")
+		fmt.Fprintf(os.Stderr, "  version Print the version, commit, and build date\n\n")
 		fmt.Fprintf(os.Stderr, "Examples:\n")
 		fmt.Fprintf(os.Stderr, "  execrun                          Run with default config (execrun.yaml)\n")
 		fmt.Fprintf(os.Stderr, "  execrun -c myapp.yaml            Run with custom config\n")
@@ -56,7 +90,11 @@ func run() error {
 		fmt.Fprintf(os.Stderr, "  execrun -e vars.yaml             Load env vars from YAML file\n")
 		fmt.Fprintf(os.Stderr, "  execrun -c myapp.yaml init       Generate myapp.yaml\n")
 		fmt.Fprintf(os.Stderr, "  execrun sum                      Snapshot file hashes\n")
-		fmt.Fprintf(os.Stderr, "  execrun -c myapp.yaml sum        Snapshot using custom config\n\n")
+		fmt.Fprintf(os.Stderr, "  execrun -c myapp.yaml sum        Snapshot using custom config\n")
+		fmt.Fprintf(os.Stderr, "  execrun import -w air            Write execrun.yaml from .air.toml\n")
+		fmt.Fprintf(os.Stderr, "  execrun lint -json               List deprecated keys as JSON\n")
+		fmt.Fprintf(os.Stderr, "  execrun doctor                   Check the environment\n")
+		fmt.Fprintf(os.Stderr, "  execrun explain api/handler.go   Show whether a file is watched\n\n")
 		fmt.Fprintf(os.Stderr, "Flags:\n")
 		fs.PrintDefaults()
 	}
@@ -67,6 +105,44 @@ func run() error {
 		}
 		return err
 	}
+	if *version || fs.Arg(0) == "version" {
+		fmt.Printf("%s %s\n", fs.Name(), buildinfo.String())
+		return nil
+	}
+	format, err := log.ParseFormat(*logFormat)
+	if err != nil {
+		return err
+	}
+	if *porcelain {
+		format = log.FormatPorcelain
+	}
+	mode, err := color.ParseMode(*colorMode)
+	if err != nil {
+		return err
+	}
+	if *noColor {
+		mode = color.Never
+	}
+	color.SetMode(mode)
+	color.SetPlain(*plain || format != log.FormatText)
+	log.SetFormat(format)
+	level, err := log.ParseLevel(*logLevel)
+	if err != nil {
+		return err
+	}
+	log.SetLevel(level)
+	log.SetTimeFormat(*logTime)
+	log.SetQuiet(*quiet)
+	config.SetStrict(*strict)
+	config.SetShell(*allowShell)
+	alg, err := hasher.ParseAlgorithm(*hashAlg)
+	if err != nil {
+		return err
+	}
+	hasher.SetAlgorithm(alg)
+	if *overlay != "" {
+		configOpts = append(configOpts, config.WithOverlay(*overlay))
+	}
 
 	// Load env file if specified (before config loading so vars are available)
 	if *envFile != "" {
@@ -76,7 +152,7 @@ func run() error {
 	}
 
 	// Resolve .yml/.yaml fallback
-	*configPath = configutil.ResolveYAMLPath(*configPath)
+	*configPath = configutil.ResolveConfigPath(*configPath)
 
 	// Check for subcommands
 	args := fs.Args()
@@ -85,16 +161,28 @@ func run() error {
 		case "init":
 			return runInit(*configPath)
 		case "test":
-			return runTest(*configPath, *verbose)
+			return runTest(*configPath, *verbose, *prefixOutput)
 		case "sum":
 			return runSum(*configPath)
+		case "import":
+			return runImport(*configPath, args[1:])
+		case "lint":
+			return runLint(*configPath, args[1:])
+		case "verify":
+			return runVerify(*configPath, args[1:])
+		case "doctor":
+			return runDoctor(*configPath)
+		case "validate":
+			return runValidate(*configPath)
+		case "explain":
+			return runExplain(*configPath, args[1:])
 		}
 	}
 
 	log.Init(*verbose)
 
 	// Load config
-	cfg, _, err := execrun.LoadConfig(*configPath)
+	cfg, _, err := execrun.LoadConfig(*configPath, configOpts...)
 	if err != nil {
 		return err
 	}
@@ -109,7 +197,11 @@ func run() error {
 		return fmt.Errorf("resolve config path: %w", err)
 	}
 	rootDir := filepath.Dir(configAbs)
-	sumFile := strings.TrimSuffix(filepath.Base(*configPath), filepath.Ext(*configPath)) + ".sum"
+	cacheDir := cfg.CachePath(rootDir)
+	if err := execrun.PrepareCacheDir(cacheDir, rootDir); err != nil {
+		return err
+	}
+	sumFile := filepath.Join(cacheDir, sumFileName(*configPath))
 
 	// Set up stdout/stderr writers
 	opts := execrun.Options{
@@ -120,6 +212,33 @@ func run() error {
 		Stderr:       os.Stderr,
 		SumFile:      sumFile,
 		RootDir:      rootDir,
+		Force:        *force,
+		Quiet:        *quiet,
+		PrefixOutput: *prefixOutput,
+	}
+
+	// On a terminal, typing r + Enter forces a rebuild.
+	if !*noKeys && term.IsTerminal(int(os.Stdin.Fd())) {
+		trigger := make(chan struct{}, 1)
+		opts.BuildTrigger = trigger
+		go readKeys(os.Stdin, trigger)
+		log.Verbose("Type r + Enter to rebuild, or touch %s", execrun.ReloadFile)
+	}
+
+	status := termstatus.New(os.Stderr, *bell, *termTitle)
+	defer status.Close()
+	var notifier *desktop.Notifier
+	if *notify {
+		if notifier, err = desktop.New("execrun"); err != nil {
+			log.Warn("%v", err)
+		}
+	}
+	if status != nil || notifier != nil {
+		name := filepath.Base(rootDir)
+		opts.OnBuildDone = func(_ time.Duration, err error) {
+			status.Report(name, err == nil)
+			notifier.Report(name, err == nil)
+		}
 	}
 
 	if *combinedFile != "" {
@@ -177,7 +296,7 @@ func run() error {
 func runSum(configPath string) error {
 	log.Init(false)
 
-	cfg, _, err := execrun.LoadConfig(configPath)
+	cfg, _, err := execrun.LoadConfig(configPath, configOpts...)
 	if err != nil {
 		return err
 	}
@@ -195,7 +314,11 @@ func runSum(configPath string) error {
 		return fmt.Errorf("scan files: %w", err)
 	}
 
-	sumFile := filepath.Join(rootDir, strings.TrimSuffix(filepath.Base(configPath), filepath.Ext(configPath))+".sum")
+	cacheDir := cfg.CachePath(rootDir)
+	if err := execrun.PrepareCacheDir(cacheDir, rootDir); err != nil {
+		return err
+	}
+	sumFile := filepath.Join(cacheDir, sumFileName(configPath))
 	if err := sumfile.Write(sumFile, sums); err != nil {
 		return fmt.Errorf("write %s: %w", sumFile, err)
 	}
@@ -204,10 +327,10 @@ func runSum(configPath string) error {
 	return nil
 }
 
-func runTest(configPath string, verbose bool) error {
+func runTest(configPath string, verbose, prefixOutput bool) error {
 	log.Init(verbose)
 
-	cfg, _, err := execrun.LoadConfig(configPath)
+	cfg, _, err := execrun.LoadConfig(configPath, configOpts...)
 	if err != nil {
 		return err
 	}
@@ -219,17 +342,32 @@ func runTest(configPath string, verbose bool) error {
 	rootDir := filepath.Dir(configAbs)
 
 	opts := execrun.Options{
-		RootDir:   rootDir,
-		LogPrefix: "[execrun]",
-		Verbose:   verbose,
-		Stdout:    os.Stdout,
-		Stderr:    os.Stderr,
+		RootDir:      rootDir,
+		LogPrefix:    "[execrun]",
+		Verbose:      verbose,
+		Stdout:       os.Stdout,
+		Stderr:       os.Stderr,
+		PrefixOutput: prefixOutput,
 	}
 
 	return execrun.RunTests(context.Background(), *cfg, opts)
 }
 
+// sumFileName returns the name of the sum file of a config: execrun.sum
+// for execrun.yaml, and for a config read from stdin.
+func sumFileName(configPath string) string {
+	if configPath == config.Stdin {
+		return "execrun.sum"
+	}
+	return strings.TrimSuffix(filepath.Base(configPath), filepath.Ext(configPath)) + ".sum"
+}
+
 func runInit(configPath string) error {
+	if configPath == config.Stdin {
+		// With -c -, print the starter config for a generator to edit.
+		_, err := os.Stdout.WriteString(execrun.DefaultConfigYAML)
+		return err
+	}
 	if _, err := os.Stat(configPath); err == nil {
 		return fmt.Errorf("%s already exists (remove it first to regenerate)", configPath)
 	}
@@ -237,6 +375,9 @@ func runInit(configPath string) error {
 	if err := os.WriteFile(configPath, []byte(execrun.DefaultConfigYAML), 0644); err != nil {
 		return fmt.Errorf("write %s: %w", configPath, err)
 	}
+	if err := execrun.PrepareCacheDir(filepath.Join(filepath.Dir(configPath), execrun.DefaultCacheDir), filepath.Dir(configPath)); err != nil {
+		return err
+	}
 
 	log.Init(false)
 	log.Success("Created %s", configPath)
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/pkg/execrun"
+)
+
+// runValidate loads and resolves the config, templates included, and
+// prints every error in it (`execrun validate`), e.g. for pre-commit hooks.
+func runValidate(configPath string) error {
+	log.Init(false)
+
+	if _, _, err := execrun.LoadConfig(configPath, configOpts...); err != nil {
+		fmt.Println(err)
+		return fmt.Errorf("%s is invalid", configPath)
+	}
+	log.Success("%s is valid", configPath)
+	return nil
+}
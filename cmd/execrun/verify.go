@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/pkg/execrun"
+)
+
+// runVerify compares the watched files to the sum file and lists the
+// differences (`execrun verify [sumfile]`), e.g. to check in CI that
+// generated code is up to date. It fails if there are any.
+func runVerify(configPath string, args []string) error {
+	log.Init(false)
+
+	cfg, _, err := execrun.LoadConfig(configPath, configOpts...)
+	if err != nil {
+		return err
+	}
+	configAbs, err := filepath.Abs(configPath)
+	if err != nil {
+		return fmt.Errorf("resolve config path: %w", err)
+	}
+	rootDir := filepath.Dir(configAbs)
+	sumFile := filepath.Join(cfg.CachePath(rootDir), sumFileName(configPath))
+	if len(args) > 0 {
+		sumFile = args[0]
+	}
+
+	changes, err := execrun.Verify(cfg, rootDir, sumFile)
+	if err != nil {
+		return err
+	}
+	if changes.IsEmpty() {
+		log.Success("Files match %s", sumFile)
+		return nil
+	}
+	for _, f := range changes.Added {
+		fmt.Printf("added:    %s\n", f)
+	}
+	for _, f := range changes.Modified {
+		fmt.Printf("modified: %s\n", f)
+	}
+	for _, f := range changes.Removed {
+		fmt.Printf("removed:  %s\n", f)
+	}
+	return fmt.Errorf("%d files differ from %s", len(changes.Added)+len(changes.Modified)+len(changes.Removed), sumFile)
+}
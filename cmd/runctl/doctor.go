@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/gur-shatz/go-run/internal/doctor"
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+// runDoctor checks the environment runctl runs in and prints a fix for
+// each problem (`runctl doctor`). It fails if any check fails.
+func runDoctor(configPath string) error {
+	log.SetPrefix("[runctl]")
+	log.Init(false)
+
+	results := []doctor.Result{doctor.GoToolchain()}
+	cfg, err := runctl.LoadConfig(configPath, configOpts...)
+	if err != nil {
+		results = append(results, doctor.Result{Check: "runctl.yaml", Status: doctor.Fail, Detail: err.Error(),
+			Fix: "fix the config, or run `runctl init` to generate one"})
+		doctor.Print(results)
+		return fmt.Errorf("%s: invalid config", configPath)
+	}
+	results = append(results, doctor.Result{Check: "runctl.yaml", Detail: configPath})
+	baseDir, err := filepath.Abs(filepath.Dir(configPath))
+	if err != nil {
+		return err
+	}
+
+	dirs := 0
+	for _, name := range slices.Sorted(maps.Keys(cfg.Targets)) {
+		tcfg := cfg.Targets[name]
+		if tcfg.IsRemote() {
+			continue
+		}
+		ecfg, _, err := tcfg.LoadExecConfig(name, baseDir, cfg.ParentVars(name))
+		if err != nil {
+			results = append(results, doctor.Result{Check: name, Status: doctor.Fail, Detail: err.Error(),
+				Fix: fmt.Sprintf("fix %s's config", name)})
+			continue
+		}
+		watch, files := doctor.WatchPatterns(name, ecfg, tcfg.Dir(baseDir))
+		results = append(results, watch)
+		dirs += doctor.Dirs(files)
+	}
+	results = append(results, doctor.WatchLimit(dirs))
+
+	results = append(results, doctor.PortFree("api", cfg.API.Port))
+	if cfg.API.GRPCPort != 0 {
+		results = append(results, doctor.PortFree("grpc", cfg.API.GRPCPort))
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Targets)) {
+		lazy := cfg.Targets[name].Lazy
+		if lazy == nil {
+			continue
+		}
+		if _, p, err := net.SplitHostPort(lazy.Listen); err == nil {
+			if port, err := strconv.Atoi(p); err == nil {
+				results = append(results, doctor.PortFree(name+" lazy listen", port))
+			}
+		}
+	}
+
+	if cfg.LogsDir != "" {
+		results = append(results, doctor.Writable("logs_dir", cfg.LogsDir))
+	}
+	if cfg.CacheDir != "" {
+		cacheDir := cfg.CacheDir
+		if !filepath.IsAbs(cacheDir) {
+			cacheDir = filepath.Join(baseDir, cacheDir)
+		}
+		results = append(results, doctor.Writable("cache_dir", cacheDir))
+	}
+
+	if failed := doctor.Print(results); failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+// runExec runs a command in a target's context on the running runctl
+// (`runctl exec <target> -- <cmd>`) and exits with the command's exit code.
+func runExec(configPath string, args []string) error {
+	efs := flag.NewFlagSet("exec", flag.ContinueOnError)
+	timeout := efs.String("timeout", "", "kill the command after this long (default: 5m)")
+	if err := efs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	rest := efs.Args()
+	if len(rest) > 1 && rest[1] == "--" {
+		rest = append(rest[:1], rest[2:]...)
+	}
+	if len(rest) < 2 {
+		return fmt.Errorf("usage: runctl exec [-timeout 10m] <target> -- <cmd> [args...]")
+	}
+	name, argv := rest[0], rest[1:]
+
+	cfg, err := runctl.LoadConfig(configPath, configOpts...)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(runctl.ExecRequest{Cmd: argv, Timeout: *timeout})
+	if err != nil {
+		return err
+	}
+	u := fmt.Sprintf("http://localhost:%d%s/targets/%s/exec", cfg.API.Port, cfg.API.APIPrefix(), url.PathEscape(name))
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+cfg.API.Token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("is runctl running? %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		return fmt.Errorf("exec %s: %s", name, e.Error)
+	}
+	var res runctl.ExecResult
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("decode exec result: %w", err)
+	}
+
+	fmt.Print(res.Output)
+	if res.TimedOut {
+		return fmt.Errorf("exec %s: timed out", name)
+	}
+	if res.ExitCode != 0 {
+		os.Exit(res.ExitCode)
+	}
+	return nil
+}
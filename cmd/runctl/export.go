@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gur-shatz/go-run/internal/color"
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+// runExport renders the config in another tool's format
+// (`runctl export procfile`).
+func runExport(configPath string, args []string) error {
+	efs := flag.NewFlagSet("export", flag.ContinueOnError)
+	write := efs.Bool("w", false, "write Procfile and .env next to runctl.yaml instead of printing the Procfile")
+	force := efs.Bool("f", false, "with -w, overwrite an existing Procfile and .env")
+	if err := efs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if efs.NArg() != 1 || efs.Arg(0) != "procfile" {
+		return fmt.Errorf("usage: runctl export [-w [-f]] procfile")
+	}
+
+	cfg, err := runctl.LoadConfig(configPath, configOpts...)
+	if err != nil {
+		return err
+	}
+	baseDir := filepath.Dir(configPath)
+	export, err := cfg.ExportProcfile(baseDir)
+	if err != nil {
+		return err
+	}
+	// Notes go to stderr so the printed Procfile can be redirected.
+	for _, s := range export.Skipped {
+		fmt.Fprintln(os.Stderr, color.Stderr().Yellow("skipped "+s))
+	}
+
+	if !*write {
+		_, err := os.Stdout.Write(export.Procfile)
+		return err
+	}
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"Procfile", export.Procfile},
+		{".env", export.Env},
+	}
+	if !*force {
+		for _, f := range files {
+			path := filepath.Join(baseDir, f.name)
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("%s already exists (use -f to overwrite)", path)
+			}
+		}
+	}
+	log.Init(false)
+	for _, f := range files {
+		path := filepath.Join(baseDir, f.name)
+		if err := os.WriteFile(path, f.data, 0644); err != nil {
+			return fmt.Errorf("write %s: %w", path, err)
+		}
+		log.Success("Wrote %s", path)
+	}
+	return nil
+}
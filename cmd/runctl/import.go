@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gur-shatz/go-run/internal/color"
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+// runImport converts another tool's config into runctl targets
+// (`runctl import compose docker-compose.yml`).
+func runImport(configPath string, args []string) error {
+	ifs := flag.NewFlagSet("import", flag.ContinueOnError)
+	write := ifs.Bool("w", false, "write a new runctl.yaml instead of printing the targets")
+	if err := ifs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	rest := ifs.Args()
+	if len(rest) < 1 || rest[0] != "compose" {
+		return fmt.Errorf("usage: runctl import [-w] compose [docker-compose.yml]")
+	}
+	composePath := "docker-compose.yml"
+	if len(rest) > 1 {
+		composePath = rest[1]
+	}
+	if *write {
+		if _, err := os.Stat(configPath); err == nil {
+			return fmt.Errorf("%s already exists (remove it first, or drop -w and paste the targets)", configPath)
+		}
+	}
+
+	result, err := runctl.ImportCompose(composePath, filepath.Dir(configPath))
+	if err != nil {
+		return err
+	}
+	// Warnings go to stderr so the printed targets can be piped.
+	for _, w := range result.Warnings {
+		fmt.Fprintln(os.Stderr, color.Stderr().Yellow("warning: "+w))
+	}
+
+	if !*write {
+		return encodeYAML(os.Stdout, map[string]any{"targets": result.Targets})
+	}
+	f, err := os.Create(configPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	cfg := runctl.Config{
+		API:     runctl.APIConfig{Port: runctl.DefaultAPIPort},
+		Targets: result.Targets,
+	}
+	if err := encodeYAML(f, cfg); err != nil {
+		return fmt.Errorf("write %s: %w", configPath, err)
+	}
+	log.Init(false)
+	log.Success("Created %s with %d targets from %s", configPath, len(result.Targets), composePath)
+	return nil
+}
+
+// encodeYAML writes v with the two-space indent runctl.yaml files use.
+func encodeYAML(w io.Writer, v any) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/pkg/config"
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+// runLint loads runctl.yaml and every target's config and lists the
+// deprecated keys they use (`runctl lint`). It fails if there are any.
+func runLint(configPath string, args []string) error {
+	lfs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	jsonOut := lfs.Bool("json", false, "print the warnings as a JSON list")
+	if err := lfs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	log.SetPrefix("[runctl]")
+	log.Init(false)
+
+	warnings := []config.Warning{}
+	config.SetDeprecationHandler(func(w config.Warning) { warnings = append(warnings, w) })
+	cfg, err := runctl.LoadConfig(configPath, configOpts...)
+	if err != nil {
+		return err
+	}
+	baseDir, err := filepath.Abs(filepath.Dir(configPath))
+	if err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Targets)) {
+		tcfg := cfg.Targets[name]
+		if tcfg.IsRemote() {
+			continue
+		}
+		if _, _, err := tcfg.LoadExecConfig(name, baseDir, cfg.ParentVars(name)); err != nil {
+			return fmt.Errorf("target %q: %w", name, err)
+		}
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(warnings); err != nil {
+			return err
+		}
+	} else {
+		for _, w := range warnings {
+			fmt.Println(w)
+		}
+	}
+	if len(warnings) > 0 {
+		return fmt.Errorf("%d deprecated keys", len(warnings))
+	}
+	if !*jsonOut {
+		log.Success("No deprecated keys in %s or its targets' configs", configPath)
+	}
+	return nil
+}
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
-	"maps"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -19,8 +21,12 @@ import (
 	"github.com/gur-shatz/go-run/internal/buildinfo"
 	"github.com/gur-shatz/go-run/internal/color"
 	"github.com/gur-shatz/go-run/internal/configutil"
+	"github.com/gur-shatz/go-run/internal/desktop"
+	"github.com/gur-shatz/go-run/internal/hasher"
 	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/internal/mask"
 	"github.com/gur-shatz/go-run/internal/sumfile"
+	"github.com/gur-shatz/go-run/internal/termstatus"
 
 	"github.com/gur-shatz/go-run/pkg/config"
 	"github.com/gur-shatz/go-run/pkg/execrun"
@@ -37,6 +43,12 @@ func (this *stringSlice) Set(val string) error {
 	return nil
 }
 
+// configOpts holds the config.Options set by flags, for every LoadConfig.
+var configOpts []config.Option
+
+// defaultOutput is the targets' default output: set by -prefix-output.
+var defaultOutput string
+
 func main() {
 	color.Init()
 	if err := run(); err != nil {
@@ -48,10 +60,27 @@ func main() {
 func run() error {
 	fs := flag.NewFlagSet("runctl", flag.ContinueOnError)
 
-	configPath := fs.String("config", "runctl.yaml", "path to config file")
+	configPath := fs.String("config", "runctl.yaml", "path to config file, or - to read it from stdin")
 	fs.StringVar(configPath, "c", "runctl.yaml", "path to config file (shorthand)")
 	envFile := fs.String("e", "", "load environment variables from YAML file")
 	verbose := fs.Bool("v", false, "verbose output")
+	version := fs.Bool("version", false, "print the version, commit, and build date and exit")
+	plain := fs.Bool("plain", false, "no colors; spell out status as [OK]/[FAIL] text")
+	colorMode := fs.String("color", string(color.Auto), "colorize output: auto (terminals, unless NO_COLOR is set), always, or never")
+	noColor := fs.Bool("no-color", false, "never colorize output (like -color never)")
+	porcelain := fs.Bool("porcelain", false, "print log messages as stable tab-separated records (time, target, event, detail) for scripts")
+	logFormat := fs.String("log-format", string(log.FormatText), "log message format: text, porcelain (like -porcelain), or json lines with level, time, prefix, target, and msg")
+	logLevel := fs.String("log-level", log.LevelInfo.String(), "hide log messages below debug, info, warn, or error (debug shows verbose messages, like -v)")
+	logTime := fs.String("log-time", "", "start log lines with a timestamp: time, ms, rfc3339, or a Go time layout")
+	quiet := fs.Bool("quiet", false, "hide heartbeats and status lines; errors, warnings, and build results still print")
+	prefixOutput := fs.Bool("prefix-output", false, "start each line of child output with its target and stage tag, e.g. [api:build], unless output: raw is set")
+	bell := fs.Bool("bell", false, "ring the terminal bell when a build finishes")
+	termTitle := fs.Bool("term-title", false, "show each target's last build result in the terminal title")
+	notify := fs.Bool("notify", false, "show a desktop notification when a target's build fails or is fixed")
+	strict := fs.Bool("strict", false, "reject unknown config fields and loosely typed values (like strict: true)")
+	overlay := fs.String("overlay", "", "config file deep-merged over -c before templates are processed")
+	allowShell := fs.Bool("allow-shell", false, "enable the shell template function, which runs commands from the config")
+	hashAlg := fs.String("hash", string(hasher.CRC32C), "hash algorithm for change detection: crc32c, or sha256 for sum files from older versions")
 	ui := fs.Bool("ui", false, "serve embedded web dashboard")
 	title := fs.String("title", "", "override UI title")
 	fs.StringVar(title, "T", "", "override UI title (shorthand)")
@@ -67,7 +96,20 @@ func run() error {
 		fmt.Fprintf(os.Stderr, "  build   Run build steps for all (or selected) targets and exit\n")
 		fmt.Fprintf(os.Stderr, "  test    Run test steps for all (or selected) targets and exit\n")
 		fmt.Fprintf(os.Stderr, "  sum     Write .sum files for all (or selected) targets and exit\n")
-		fmt.Fprintf(os.Stderr, "  vars    Dump resolved variables for all (or selected) targets\n\n")
+		fmt.Fprintf(os.Stderr, "  verify  Compare all (or selected) targets' watched files to their .sum files (verify [sumfile|dir])\n")
+		fmt.Fprintf(os.Stderr, "  vars    Dump resolved variables for all (or selected) targets\n")
+		fmt.Fprintf(os.Stderr, "  agent   Run targets for a remote runctl (no UI; see host: in runctl.yaml)\n")
+		fmt.Fprintf(os.Stderr, "  exec    Run a command in a target's dir and vars on the running runctl\n")
+		fmt.Fprintf(os.Stderr, "  report  Summarize local build/test/crash stats (-days N, --json)\n")
+		fmt.Fprintf(os.Stderr, "  export  Write the targets as a Procfile and .env for foreman/overmind\n")
+		fmt.Fprintf(os.Stderr, "  import  Convert docker-compose services into runctl targets\n")
+		fmt.Fprintf(os.Stderr, "  top     Live CPU, memory, and uptime of the running runctl's targets\n")
+		fmt.Fprintf(os.Stderr, "  wait    Block until the running runctl's targets are ready (or in -state)\n")
+		fmt.Fprintf(os.Stderr, "  lint    List deprecated keys in runctl.yaml and the targets' configs (-json)\n")
+		fmt.Fprintf(os.Stderr, "  doctor  Check the Go toolchain, watch patterns and limits, ports, and logs_dir\n")
+		fmt.Fprintf(os.Stderr, "  validate  Load and resolve runctl.yaml and the targets' configs and list their errors\n")
+		fmt.Fprintf(os.Stderr, "  version Print the version, commit, and build date\n")
+		fmt.Fprintf(os.Stderr, "  self-update  Replace this binary with the latest release\n\n")
 		fmt.Fprintf(os.Stderr, "Examples:\n")
 		fmt.Fprintf(os.Stderr, "  runctl                          Run with default config (runctl.yaml)\n")
 		fmt.Fprintf(os.Stderr, "  runctl -ui                      Run with web dashboard\n")
@@ -82,6 +124,16 @@ func run() error {
 		fmt.Fprintf(os.Stderr, "  runctl sum                      Write sum files for all targets\n")
 		fmt.Fprintf(os.Stderr, "  runctl vars                     Show resolved variables\n")
 		fmt.Fprintf(os.Stderr, "  runctl -t api vars              Show variables for 'api' target\n")
+		fmt.Fprintf(os.Stderr, "  runctl vars --json              Show variables as JSON\n")
+		fmt.Fprintf(os.Stderr, "  runctl agent                    Serve targets to a remote runctl\n")
+		fmt.Fprintf(os.Stderr, "  runctl exec api -- go run ./cmd/migrate   Run a one-off command as 'api'\n")
+		fmt.Fprintf(os.Stderr, "  runctl report -days 30          Show a month of dev-loop stats\n")
+		fmt.Fprintf(os.Stderr, "  runctl export -w procfile        Write Procfile and .env next to runctl.yaml\n")
+		fmt.Fprintf(os.Stderr, "  runctl import -w compose        Create runctl.yaml from docker-compose.yml\n")
+		fmt.Fprintf(os.Stderr, "  runctl top -n 1s                Watch target CPU and memory every second\n")
+		fmt.Fprintf(os.Stderr, "  runctl wait -target api -timeout 2m   Wait until 'api' is built and running\n")
+		fmt.Fprintf(os.Stderr, "  runctl lint -json               List deprecated config keys as JSON\n")
+		fmt.Fprintf(os.Stderr, "  runctl self-update              Upgrade to the latest release\n")
 		fmt.Fprintf(os.Stderr, "  runctl init                     Generate runctl.yaml\n\n")
 		fmt.Fprintf(os.Stderr, "Flags:\n")
 		fs.PrintDefaults()
@@ -93,6 +145,47 @@ func run() error {
 		}
 		return err
 	}
+	if *version || fs.Arg(0) == "version" {
+		fmt.Printf("%s %s\n", fs.Name(), buildinfo.String())
+		return nil
+	}
+	format, err := log.ParseFormat(*logFormat)
+	if err != nil {
+		return err
+	}
+	if *porcelain {
+		format = log.FormatPorcelain
+	}
+	mode, err := color.ParseMode(*colorMode)
+	if err != nil {
+		return err
+	}
+	if *noColor {
+		mode = color.Never
+	}
+	color.SetMode(mode)
+	color.SetPlain(*plain || format != log.FormatText)
+	log.SetFormat(format)
+	level, err := log.ParseLevel(*logLevel)
+	if err != nil {
+		return err
+	}
+	log.SetLevel(level)
+	log.SetTimeFormat(*logTime)
+	log.SetQuiet(*quiet)
+	if *prefixOutput {
+		defaultOutput = execrun.OutputPrefixed
+	}
+	config.SetStrict(*strict)
+	config.SetShell(*allowShell)
+	alg, err := hasher.ParseAlgorithm(*hashAlg)
+	if err != nil {
+		return err
+	}
+	hasher.SetAlgorithm(alg)
+	if *overlay != "" {
+		configOpts = append(configOpts, config.WithOverlay(*overlay))
+	}
 
 	// Load env file if specified (before config loading so vars are available)
 	if *envFile != "" {
@@ -102,8 +195,9 @@ func run() error {
 	}
 
 	// Resolve .yml/.yaml fallback
-	*configPath = configutil.ResolveYAMLPath(*configPath)
+	*configPath = configutil.ResolveConfigPath(*configPath)
 
+	agent := false
 	args := fs.Args()
 	if len(args) > 0 {
 		switch args[0] {
@@ -116,21 +210,57 @@ func run() error {
 		case "sum":
 			return runSum(*configPath, *verbose, targets)
 		case "vars":
-			return runVars(*configPath, targets)
+			return runVars(*configPath, targets, args[1:])
+		case "exec":
+			return runExec(*configPath, args[1:])
+		case "report":
+			return runReport(*configPath, args[1:])
+		case "export":
+			return runExport(*configPath, args[1:])
+		case "import":
+			return runImport(*configPath, args[1:])
+		case "top":
+			return runTop(*configPath, args[1:])
+		case "wait":
+			return runWait(*configPath, args[1:])
+		case "lint":
+			return runLint(*configPath, args[1:])
+		case "verify":
+			return runVerify(*configPath, targets, args[1:])
+		case "doctor":
+			return runDoctor(*configPath)
+		case "validate":
+			return runValidate(*configPath)
+		case "self-update":
+			return runSelfUpdate()
+		case "agent":
+			// An agent is a headless runctl: a remote controller drives
+			// its targets through the API.
+			agent = true
+			*ui = false
 		}
 	}
 
-	if *ui {
+	if agent {
+		log.SetPrefix("[agent]")
+	} else if *ui {
 		log.SetPrefix("[runui]")
 	} else {
 		log.SetPrefix("[runctl]")
 	}
 	log.Init(*verbose)
+	if !agent {
+		go checkForUpdate()
+	}
 
-	cfg, err := runctl.LoadConfig(*configPath)
+	cfg, err := runctl.LoadConfig(*configPath, configOpts...)
 	if err != nil {
 		return err
 	}
+	if agent && cfg.API.Token == "" {
+		return fmt.Errorf("runctl agent needs api.token; the controlling runctl sends it as the target's host_token")
+	}
+	cfg.SetDefaultOutput(defaultOutput)
 	if *title != "" {
 		cfg.Title = *title
 	}
@@ -141,6 +271,7 @@ func run() error {
 	if err != nil {
 		return err
 	}
+	log.SetRedactor(ctrl.Redact)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -164,30 +295,73 @@ func run() error {
 		}
 	}
 
+	// on_stop runs even if on_start failed partway, to undo what it started.
+	// Deferred first, it runs after the targets are killed.
+	defer func() {
+		if err := ctrl.RunStopHooks(context.Background()); err != nil {
+			log.Error("%v", err)
+		}
+	}()
+	if err := ctrl.RunStartHooks(ctx); err != nil {
+		return err
+	}
+
+	if !agent {
+		status := termstatus.New(os.Stderr, *bell, *termTitle)
+		defer status.Close()
+		var notifier *desktop.Notifier
+		if *notify {
+			if notifier, err = desktop.New("runctl"); err != nil {
+				log.Warn("%v", err)
+			}
+		}
+		if status != nil || notifier != nil {
+			events, unsubscribe := ctrl.Subscribe() // before any target builds
+			defer unsubscribe()
+			go reportBuilds(ctx, events, status, notifier)
+		}
+	}
+
 	// Start targets (filtered or all enabled)
 	ctrl.StartTargetsFiltered(targets)
 	defer ctrl.KillTargets()
 
-	go runHeartbeat(ctx, ctrl, targets)
+	if interval := cfg.Heartbeat.Interval(); interval > 0 && !log.Quiet() {
+		go runHeartbeat(ctx, ctrl, targets, interval)
+	}
+	go ctrl.RunJanitor(ctx)
 
 	// Create chi router and mount API routes
 	r := chi.NewRouter()
-	r.Mount("/api", ctrl.Routes())
+	var api http.Handler = ctrl.Routes()
+	if agent {
+		api = runctl.RequireToken(cfg.API.Token)(api)
+	}
+	r.Mount(cfg.API.APIPrefix(), api)
 	if *ui {
-		r.Mount("/", runui.Routes())
+		r.Mount(cmp.Or(cfg.API.BasePath, "/"), runui.Routes(cfg.API.BasePath))
 	}
 
+	addr := cfg.API.ListenAddr(cfg.API.Port)
+	if agent && cfg.API.Host == "" {
+		// Agents are reached from the controlling runctl's machine.
+		addr = fmt.Sprintf(":%d", cfg.API.Port)
+	}
 	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", cfg.API.Port),
+		Addr:    addr,
 		Handler: r,
 	}
 
 	errCh := make(chan error, 1)
 	go func() {
-		if *ui {
-			fmt.Fprintf(os.Stdout, "[runui] Dashboard: http://localhost:%d/\n", cfg.API.Port)
+		if log.Structured() {
+			log.Status("Listening on %s", addr)
+		} else if agent {
+			fmt.Fprintf(os.Stdout, "[agent] Listening on %s\n", addr)
+		} else if *ui {
+			fmt.Fprintf(os.Stdout, "[runui] Dashboard: http://localhost:%d%s/\n", cfg.API.Port, cfg.API.BasePath)
 		} else {
-			fmt.Fprintf(os.Stdout, "[runctl] API server listening on :%d, no UI\n", cfg.API.Port)
+			fmt.Fprintf(os.Stdout, "[runctl] API server listening on %s, no UI\n", addr)
 		}
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			errCh <- err
@@ -195,22 +369,41 @@ func run() error {
 		close(errCh)
 	}()
 
+	grpcErrCh := make(chan error, 1)
+	if cfg.API.GRPCPort != 0 {
+		grpcAddr := cfg.API.ListenAddr(cfg.API.GRPCPort)
+		lis, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			return fmt.Errorf("grpc server: %w", err)
+		}
+		grpcServer := ctrl.GRPCServer()
+		defer grpcServer.Stop()
+		go func() {
+			log.Status("gRPC API listening on %s", grpcAddr)
+			if err := grpcServer.Serve(lis); err != nil {
+				grpcErrCh <- err
+			}
+		}()
+	}
+
 	select {
 	case <-ctx.Done():
 		server.Close()
 		return nil
 	case err := <-errCh:
 		return fmt.Errorf("api server: %w", err)
+	case err := <-grpcErrCh:
+		return fmt.Errorf("grpc server: %w", err)
 	}
 }
 
-func runHeartbeat(ctx context.Context, ctrl *runctl.Controller, targets []string) {
+func runHeartbeat(ctx context.Context, ctrl *runctl.Controller, targets []string, interval time.Duration) {
 	selected := make(map[string]bool, len(targets))
 	for _, name := range targets {
 		selected[name] = true
 	}
 
-	ticker := time.NewTicker(10 * time.Second)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -219,6 +412,14 @@ func runHeartbeat(ctx context.Context, ctrl *runctl.Controller, targets []string
 			return
 		case <-ticker.C:
 			summary := runctl.SummarizeHeartbeat(ctrl.Status(), selected)
+			if log.Structured() {
+				log.Record("runctl", log.EventHeartbeat, porcelainHeartbeat(summary))
+				continue
+			}
+			if color.Plain() {
+				log.Status("%s", summary.PlainMarker())
+				continue
+			}
 			if summary.AllHealthy {
 				fmt.Print(color.Green("."))
 				continue
@@ -232,9 +433,43 @@ func runHeartbeat(ctx context.Context, ctrl *runctl.Controller, targets []string
 	}
 }
 
+// porcelainHeartbeat is the detail of a porcelain heartbeat record:
+// ok, pending, or fail build=N run=N test=N.
+func porcelainHeartbeat(summary runctl.HeartbeatSummary) string {
+	switch {
+	case summary.AllHealthy:
+		return "ok"
+	case summary.HasFailures():
+		return fmt.Sprintf("fail build=%d run=%d test=%d", summary.BuildFailures, summary.RunFailures, summary.TestFailures)
+	default:
+		return "pending"
+	}
+}
+
+// reportBuilds passes build results to the terminal bell and title and to
+// desktop notifications.
+func reportBuilds(ctx context.Context, events <-chan runctl.Event, status *termstatus.Reporter, notifier *desktop.Notifier) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-events:
+			switch e.Event {
+			case runctl.EventBuildSucceeded:
+				status.Report(e.Target, true)
+				notifier.Report(e.Target, true)
+			case runctl.EventBuildFailed:
+				status.Report(e.Target, false)
+				notifier.Report(e.Target, false)
+			}
+		}
+	}
+}
+
 // resolveTargets returns the (name, TargetConfig) pairs to operate on.
-// If filterNames is empty, all enabled targets are returned.
-// Returns an error if a filter name doesn't exist in the config.
+// If filterNames is empty, all enabled local targets are returned.
+// Returns an error if a filter name doesn't exist in the config or names a
+// remote target, which can only be built by its agent.
 func resolveTargets(cfg *runctl.Config, filterNames []string) ([]targetEntry, error) {
 	if len(filterNames) > 0 {
 		entries := make([]targetEntry, 0, len(filterNames))
@@ -243,6 +478,9 @@ func resolveTargets(cfg *runctl.Config, filterNames []string) ([]targetEntry, er
 			if !ok {
 				return nil, fmt.Errorf("unknown target %q", name)
 			}
+			if tcfg.IsRemote() {
+				return nil, fmt.Errorf("target %q runs on remote agent %s", name, tcfg.Host)
+			}
 			entries = append(entries, targetEntry{Name: name, Config: tcfg})
 		}
 		return entries, nil
@@ -250,7 +488,7 @@ func resolveTargets(cfg *runctl.Config, filterNames []string) ([]targetEntry, er
 
 	entries := make([]targetEntry, 0, len(cfg.Targets))
 	for name, tcfg := range cfg.Targets {
-		if tcfg.IsEnabled() {
+		if tcfg.IsEnabled() && !tcfg.IsRemote() {
 			entries = append(entries, targetEntry{Name: name, Config: tcfg})
 		}
 	}
@@ -265,40 +503,22 @@ type targetEntry struct {
 // loadExecrunConfig loads an execrun config for a target, merging parent vars.
 // Returns the config, root directory, and resolved vars from the execrun config's vars: section.
 func loadExecrunConfig(entry targetEntry, cfg *runctl.Config, baseDir string) (*execrun.Config, string, map[string]string, error) {
-	dir := filepath.Dir(entry.Config.Config)
-	if !filepath.IsAbs(dir) {
-		dir = filepath.Join(baseDir, dir)
-	}
-	configFile := filepath.Base(entry.Config.Config)
-	configPath := configutil.ResolveYAMLPath(filepath.Join(dir, configFile))
-
-	parentVars := cfg.ResolvedVars
-	if len(entry.Config.Vars) > 0 {
-		parentVars = make(map[string]string, len(cfg.ResolvedVars)+len(entry.Config.Vars))
-		maps.Copy(parentVars, cfg.ResolvedVars)
-		maps.Copy(parentVars, entry.Config.Vars)
-	}
-
-	var configOpts []config.Option
-	if len(parentVars) > 0 {
-		configOpts = append(configOpts, config.WithVars(parentVars))
-	}
-
-	ecfg, execrunVars, err := execrun.LoadConfig(configPath, configOpts...)
+	ecfg, execrunVars, err := entry.Config.LoadExecConfig(entry.Name, baseDir, cfg.ParentVars(entry.Name))
 	if err != nil {
 		return nil, "", nil, fmt.Errorf("target %q: load config: %w", entry.Name, err)
 	}
-	return ecfg, dir, execrunVars, nil
+	return ecfg, entry.Config.Dir(baseDir), execrunVars, nil
 }
 
 func runBuild(configPath string, verbose bool, filterNames []string) error {
 	log.SetPrefix("[runctl]")
 	log.Init(verbose)
 
-	cfg, err := runctl.LoadConfig(configPath)
+	cfg, err := runctl.LoadConfig(configPath, configOpts...)
 	if err != nil {
 		return err
 	}
+	cfg.SetDefaultOutput(defaultOutput)
 
 	baseDir := filepath.Dir(configPath)
 	absBase, err := filepath.Abs(baseDir)
@@ -347,7 +567,7 @@ func runSum(configPath string, verbose bool, filterNames []string) error {
 	log.SetPrefix("[runctl]")
 	log.Init(verbose)
 
-	cfg, err := runctl.LoadConfig(configPath)
+	cfg, err := runctl.LoadConfig(configPath, configOpts...)
 	if err != nil {
 		return err
 	}
@@ -376,9 +596,7 @@ func runSum(configPath string, verbose bool, filterNames []string) error {
 			continue
 		}
 
-		configFile := filepath.Base(entry.Config.Config)
-		sumFile := strings.TrimSuffix(configFile, filepath.Ext(configFile)) + ".sum"
-		sumPath := filepath.Join(dir, sumFile)
+		sumPath := entry.Config.SumFilePath(entry.Name, absBase)
 
 		if err := sumfile.Write(sumPath, sums); err != nil {
 			log.Error("%s: write sum: %v", entry.Name, err)
@@ -395,10 +613,11 @@ func runTest(configPath string, verbose bool, filterNames []string) error {
 	log.SetPrefix("[runctl]")
 	log.Init(verbose)
 
-	cfg, err := runctl.LoadConfig(configPath)
+	cfg, err := runctl.LoadConfig(configPath, configOpts...)
 	if err != nil {
 		return err
 	}
+	cfg.SetDefaultOutput(defaultOutput)
 
 	baseDir := filepath.Dir(configPath)
 	absBase, err := filepath.Abs(baseDir)
@@ -443,8 +662,17 @@ func runTest(configPath string, verbose bool, filterNames []string) error {
 	return nil
 }
 
-func runVars(configPath string, filterNames []string) error {
-	cfg, err := runctl.LoadConfig(configPath)
+func runVars(configPath string, filterNames []string, args []string) error {
+	vfs := flag.NewFlagSet("vars", flag.ContinueOnError)
+	jsonOut := vfs.Bool("json", false, "print vars as JSON")
+	if err := vfs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	cfg, err := runctl.LoadConfig(configPath, configOpts...)
 	if err != nil {
 		return err
 	}
@@ -453,73 +681,35 @@ func runVars(configPath string, filterNames []string) error {
 	if err != nil {
 		return err
 	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	sort.Strings(names)
 
 	absBase, err := filepath.Abs(filepath.Dir(configPath))
 	if err != nil {
 		return err
 	}
 
-	// Print global vars
-	fmt.Println("Global vars:")
-	if len(cfg.ResolvedVars) == 0 {
-		fmt.Println("  (none)")
-	} else {
-		keys := make([]string, 0, len(cfg.ResolvedVars))
-		for k := range cfg.ResolvedVars {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
-		for _, k := range keys {
-			fmt.Printf("  %s=%s\n", k, cfg.ResolvedVars[k])
-		}
-	}
-
-	// Print per-target vars (merged: global + target overrides + execrun vars)
-	for _, entry := range entries {
-		fmt.Printf("\nTarget %q vars:\n", entry.Name)
+	report := cfg.VarsReport(names, absBase, nil)
 
-		merged := make(map[string]string, len(cfg.ResolvedVars)+len(entry.Config.Vars))
-		maps.Copy(merged, cfg.ResolvedVars)
-		maps.Copy(merged, entry.Config.Vars)
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
 
-		if len(merged) == 0 {
-			fmt.Println("  (none)")
-		} else {
-			keys := make([]string, 0, len(merged))
-			for k := range merged {
-				keys = append(keys, k)
-			}
-			sort.Strings(keys)
-			for _, k := range keys {
-				v := merged[k]
-				// Mark overridden vars
-				if _, isOverride := entry.Config.Vars[k]; isOverride {
-					if _, isGlobal := cfg.ResolvedVars[k]; isGlobal {
-						fmt.Printf("  %s=%s  (overridden)\n", k, v)
-						continue
-					}
-					fmt.Printf("  %s=%s  (target-only)\n", k, v)
-					continue
-				}
-				fmt.Printf("  %s=%s\n", k, v)
-			}
-		}
+	fmt.Println("Global vars:")
+	printVars(report.Global)
 
-		// Load execrun config to show its vars: section
-		_, _, execrunVars, loadErr := loadExecrunConfig(entry, cfg, absBase)
-		if loadErr != nil {
-			fmt.Printf("  execrun vars: (error: %v)\n", loadErr)
-		} else if len(execrunVars) > 0 {
-			fmt.Printf("  execrun vars:\n")
-			keys := make([]string, 0, len(execrunVars))
-			for k := range execrunVars {
-				keys = append(keys, k)
-			}
-			sort.Strings(keys)
-			for _, k := range keys {
-				fmt.Printf("    %s=%s\n", k, execrunVars[k])
-			}
+	// Merged view of what each child config sees, with provenance
+	for _, tv := range report.Targets {
+		fmt.Printf("\nTarget %q vars:\n", tv.Name)
+		if tv.Error != "" {
+			fmt.Printf("  (error loading child config: %s)\n", tv.Error)
 		}
+		printVars(tv.Vars)
 	}
 
 	// Print environment variables
@@ -527,13 +717,39 @@ func runVars(configPath string, filterNames []string) error {
 	envVars := os.Environ()
 	sort.Strings(envVars)
 	for _, e := range envVars {
-		fmt.Printf("  %s\n", e)
+		if k, _, _ := strings.Cut(e, "="); cfg.Secrets[k] != "" {
+			e = k + "=" + mask.Placeholder
+		}
+		fmt.Printf("  %s\n", cfg.Redact(e))
 	}
 
 	return nil
 }
 
+func printVars(vars []runctl.Var) {
+	if len(vars) == 0 {
+		fmt.Println("  (none)")
+		return
+	}
+	for _, v := range vars {
+		source := string(v.Source)
+		if len(v.Shadows) > 0 {
+			shadows := make([]string, len(v.Shadows))
+			for i, s := range v.Shadows {
+				shadows[i] = string(s)
+			}
+			source += ", overrides " + strings.Join(shadows, ", ")
+		}
+		fmt.Printf("  %s=%s  (%s)\n", v.Name, v.Value, source)
+	}
+}
+
 func runInit(configPath string) error {
+	if configPath == config.Stdin {
+		// With -c -, print the starter config for a generator to edit.
+		_, err := os.Stdout.WriteString(runctl.DefaultConfigYAML)
+		return err
+	}
 	if _, err := os.Stat(configPath); err == nil {
 		return fmt.Errorf("%s already exists (remove it first to regenerate)", configPath)
 	}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+// runReport prints the local usage stats of this checkout.
+func runReport(configPath string, args []string) error {
+	rfs := flag.NewFlagSet("report", flag.ContinueOnError)
+	days := rfs.Int("days", 7, "number of days to include, ending today")
+	jsonOut := rfs.Bool("json", false, "print the report as JSON")
+	if err := rfs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if *days < 1 {
+		return fmt.Errorf("-days must be at least 1")
+	}
+
+	cfg, err := runctl.LoadConfig(configPath, configOpts...)
+	if err != nil {
+		return err
+	}
+	path := cfg.StatsPath()
+	if path == "" {
+		return fmt.Errorf("usage stats are off (stats: false in %s)", configPath)
+	}
+	stats, err := runctl.LoadUsageStats(path)
+	if err != nil {
+		return err
+	}
+	report := stats.Report(time.Now(), *days)
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	fmt.Printf("Usage since %s (%d days)\n\n", report.Since, *days)
+	if len(report.Days) == 0 {
+		fmt.Println("  (no builds, tests, or crashes recorded)")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "DAY\tBUILDS\tFAILED\tAVG BUILD\tTESTS\tFAILED\tCRASHES\t")
+	for _, d := range report.Days {
+		printUsageRow(tw, d.Day, d.Usage)
+	}
+	printUsageRow(tw, "total", report.Total)
+	tw.Flush()
+
+	fmt.Println()
+	tw = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "TARGET\tBUILDS\tFAILED\tAVG BUILD\tTESTS\tFAILED\tCRASHES\t")
+	for _, t := range report.Targets {
+		printUsageRow(tw, t.Name, t.Usage)
+	}
+	tw.Flush()
+
+	if report.MostFailing != "" {
+		fmt.Printf("\nMost failing: %s\n", report.MostFailing)
+	}
+	return nil
+}
+
+func printUsageRow(tw *tabwriter.Writer, label string, u runctl.Usage) {
+	avg := "-"
+	if u.Builds > 0 {
+		avg = u.AvgBuild().Round(100 * time.Millisecond).String()
+	}
+	fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%d\t%d\t%d\t\n",
+		label, u.Builds, u.BuildFailures, avg, u.Tests, u.TestFailures, u.Crashes)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gur-shatz/go-run/internal/buildinfo"
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/internal/selfupdate"
+)
+
+const releaseRepo = "gur-shatz/go-run"
+
+// envNoUpdateCheck turns off the daily "new version available" check.
+const envNoUpdateCheck = "RUNCTL_NO_UPDATE_CHECK"
+
+// runSelfUpdate replaces the running runctl with the latest release.
+func runSelfUpdate() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate executable: %w", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return fmt.Errorf("locate executable: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	u := selfupdate.New(releaseRepo, "runctl")
+	rel, err := u.Latest(ctx)
+	if err != nil {
+		return err
+	}
+	if buildinfo.Version != "dev" && !selfupdate.Newer(rel.Tag, buildinfo.Version) {
+		log.Status("Already up to date (%s)", buildinfo.Version)
+		return nil
+	}
+
+	log.Status("Updating %s: %s -> %s", exe, buildinfo.Version, rel.Tag)
+	if err := u.Apply(ctx, rel, exe); err != nil {
+		return fmt.Errorf("self-update: %w", err)
+	}
+	log.Success("Updated to %s", rel.Tag)
+	return nil
+}
+
+// checkForUpdate prints a one-line notice when a newer release exists. It
+// runs at most once a day, never for dev builds, and stays silent on errors.
+func checkForUpdate() {
+	if buildinfo.Version == "dev" || os.Getenv(envNoUpdateCheck) != "" {
+		return
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil || !selfupdate.Due(filepath.Join(cache, "runctl", "update-check")) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	rel, err := selfupdate.New(releaseRepo, "runctl").Latest(ctx)
+	if err != nil || !selfupdate.Newer(rel.Tag, buildinfo.Version) {
+		return
+	}
+	log.Status("New version available: %s (current %s). Run `runctl self-update` to upgrade.", rel.Tag, buildinfo.Version)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/gur-shatz/go-run/internal/diskspace"
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+// cpuSample is the last cumulative CPU reading of a target, kept between
+// frames to turn cpu_secs into a percentage.
+type cpuSample struct {
+	pid  int
+	secs float64
+	at   time.Time
+}
+
+// runTop polls the running runctl and renders a refreshing table of its
+// targets' resource usage (`runctl top`).
+func runTop(configPath string, args []string) error {
+	tfs := flag.NewFlagSet("top", flag.ContinueOnError)
+	interval := tfs.Duration("n", 2*time.Second, "refresh interval")
+	once := tfs.Bool("once", false, "print one frame and exit")
+	if err := tfs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if *interval < 100*time.Millisecond {
+		return fmt.Errorf("-n must be at least 100ms")
+	}
+
+	cfg, err := runctl.LoadConfig(configPath, configOpts...)
+	if err != nil {
+		return err
+	}
+	base := fmt.Sprintf("http://localhost:%d%s", cfg.API.Port, cfg.API.APIPrefix())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	prev := map[string]cpuSample{}
+	if *once {
+		// CPU% needs two samples; take a short one before the real frame.
+		if _, err := topFrame(base, prev); err != nil {
+			return err
+		}
+		time.Sleep(time.Second)
+		frame, err := topFrame(base, prev)
+		if err != nil {
+			return err
+		}
+		fmt.Print(frame)
+		return nil
+	}
+
+	clear := term.IsTerminal(int(os.Stdout.Fd()))
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		frame, err := topFrame(base, prev)
+		if err != nil {
+			return err
+		}
+		if clear {
+			fmt.Print("\033[H\033[2J")
+		}
+		fmt.Printf("runctl top - %s (every %s, Ctrl-C to quit)\n\n", time.Now().Format(time.TimeOnly), *interval)
+		fmt.Print(frame)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// topFrame fetches targets and resources once and renders the table,
+// updating prev with this frame's CPU readings.
+func topFrame(base string, prev map[string]cpuSample) (string, error) {
+	var statuses []runctl.TargetStatus
+	if err := getJSON(base+"/targets", &statuses); err != nil {
+		return "", err
+	}
+	var usage []runctl.ResourceUsage
+	if err := getJSON(base+"/resources", &usage); err != nil {
+		return "", err
+	}
+	byName := make(map[string]runctl.ResourceUsage, len(usage))
+	for _, u := range usage {
+		byName[u.Name] = u
+	}
+
+	now := time.Now()
+	var buf strings.Builder
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TARGET\tSTATE\tPID\tPROCS\tCPU%\tRSS\tUPTIME\tRESTARTS")
+	for _, s := range statuses {
+		u := byName[s.Name]
+		pid, procs, cpu, rss, uptime := "-", "-", "-", "-", "-"
+		if s.PID > 0 {
+			pid = fmt.Sprint(s.PID)
+		}
+		if u.Processes > 0 {
+			procs = fmt.Sprint(u.Processes)
+			rss = diskspace.FormatSize(u.RSSBytes)
+			if p, ok := prev[s.Name]; ok && p.pid == u.PID && now.After(p.at) {
+				pct := (u.CPUSeconds - p.secs) / now.Sub(p.at).Seconds() * 100
+				cpu = fmt.Sprintf("%.1f", max(pct, 0))
+			}
+			prev[s.Name] = cpuSample{pid: u.PID, secs: u.CPUSeconds, at: now}
+		} else {
+			delete(prev, s.Name)
+		}
+		if s.State == runctl.StateRunning && s.LastStartTime != nil {
+			uptime = now.Sub(*s.LastStartTime).Round(time.Second).String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			s.Name, s.State, pid, procs, cpu, rss, uptime, s.RestartCount)
+	}
+	tw.Flush()
+	return buf.String(), nil
+}
+
+// getJSON decodes the response of a GET against the running runctl.
+func getJSON(url string, v any) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return fmt.Errorf("is runctl running? %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+// runValidate loads and resolves runctl.yaml and every local target's
+// config, templates included, and prints every error in them
+// (`runctl validate`), e.g. for pre-commit hooks.
+func runValidate(configPath string) error {
+	log.SetPrefix("[runctl]")
+	log.Init(false)
+
+	cfg, err := runctl.LoadConfig(configPath, configOpts...)
+	if err != nil {
+		fmt.Println(err)
+		return fmt.Errorf("%s is invalid", configPath)
+	}
+	baseDir, err := filepath.Abs(filepath.Dir(configPath))
+	if err != nil {
+		return err
+	}
+	if err := cfg.ValidateTargets(baseDir); err != nil {
+		errs := []error{err}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			errs = joined.Unwrap()
+		}
+		for _, err := range errs {
+			fmt.Println(err)
+		}
+		return fmt.Errorf("%d of %d targets have invalid configs", len(errs), len(cfg.Targets))
+	}
+	log.Success("%s and its targets' configs are valid", configPath)
+	return nil
+}
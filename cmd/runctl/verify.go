@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/pkg/execrun"
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+// runVerify compares each selected target's watched files to its sum file
+// and lists the differences (`runctl verify [sumfile|dir]`), e.g. to check
+// in CI that generated code is up to date. It fails if any target differs.
+// A dir argument holds a <target>.sum copy of each target's sum file; a
+// file argument needs a single selected target.
+func runVerify(configPath string, filterNames []string, args []string) error {
+	log.SetPrefix("[runctl]")
+	log.Init(false)
+
+	cfg, err := runctl.LoadConfig(configPath, configOpts...)
+	if err != nil {
+		return err
+	}
+	absBase, err := filepath.Abs(filepath.Dir(configPath))
+	if err != nil {
+		return fmt.Errorf("resolve base dir: %w", err)
+	}
+	entries, err := resolveTargets(cfg, filterNames)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("verify takes at most one sum file or dir, got %d", len(args))
+	}
+	sumDir, sumFile := "", ""
+	if len(args) == 1 {
+		if info, err := os.Stat(args[0]); err == nil && info.IsDir() {
+			sumDir = args[0]
+		} else if len(entries) != 1 {
+			return fmt.Errorf("%s is not a dir; select a single target (-t) to verify against a sum file", args[0])
+		} else {
+			sumFile = args[0]
+		}
+	}
+
+	failed := 0
+	for _, entry := range entries {
+		ecfg, dir, _, err := loadExecrunConfig(entry, cfg, absBase)
+		if err != nil {
+			log.Error("%s: %v", entry.Name, err)
+			failed++
+			continue
+		}
+		sumPath := entry.Config.SumFilePath(entry.Name, absBase)
+		switch {
+		case sumFile != "":
+			sumPath = sumFile
+		case sumDir != "":
+			sumPath = filepath.Join(sumDir, entry.Name+".sum")
+		}
+		changes, err := execrun.Verify(ecfg, dir, sumPath)
+		if err != nil {
+			log.Error("%s: %v", entry.Name, err)
+			failed++
+			continue
+		}
+		if changes.IsEmpty() {
+			log.Success("%s: files match %s", entry.Name, sumPath)
+			continue
+		}
+		failed++
+		for _, f := range changes.Added {
+			fmt.Printf("%s: added:    %s\n", entry.Name, f)
+		}
+		for _, f := range changes.Modified {
+			fmt.Printf("%s: modified: %s\n", entry.Name, f)
+		}
+		for _, f := range changes.Removed {
+			fmt.Printf("%s: removed:  %s\n", entry.Name, f)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d targets failed verification", failed, len(entries))
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+// waitPoll is how often runctl wait asks the running runctl.
+const waitPoll = 500 * time.Millisecond
+
+// waitStates are the values -state accepts besides "ready".
+var waitStates = []runctl.TargetState{
+	runctl.StateIdle, runctl.StateStarting, runctl.StateRunning, runctl.StateStopped,
+	runctl.StateError, runctl.StateExited, runctl.StateCrashLoop, runctl.StateSuspended,
+}
+
+// runWait blocks until targets on the running runctl reach a state, or are
+// ready as GET /api/ready defines it (`runctl wait`). It keeps retrying while
+// runctl itself is still coming up.
+func runWait(configPath string, args []string) error {
+	wfs := flag.NewFlagSet("wait", flag.ContinueOnError)
+	var targets stringSlice
+	wfs.Var(&targets, "target", "target to wait for (repeatable; default: all, or ready: for -state ready)")
+	state := wfs.String("state", "ready", "state to wait for: ready (built, running, tests passed) or a target state such as running")
+	timeout := wfs.Duration("timeout", 60*time.Second, "give up after this long")
+	if err := wfs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if wfs.NArg() > 0 {
+		return fmt.Errorf("usage: runctl wait [-target name]... [-state ready|running|...] [-timeout 60s]")
+	}
+	if *state != "ready" && !isWaitState(*state) {
+		return fmt.Errorf("-state must be ready or one of %s, got %q", joinStates(waitStates), *state)
+	}
+
+	cfg, err := runctl.LoadConfig(configPath, configOpts...)
+	if err != nil {
+		return err
+	}
+	base := fmt.Sprintf("http://localhost:%d%s", cfg.API.Port, cfg.API.APIPrefix())
+
+	check := func() (bool, string, error) { return waitReady(base, targets) }
+	if *state != "ready" {
+		check = func() (bool, string, error) { return waitState(base, targets, runctl.TargetState(*state)) }
+	}
+	deadline := time.Now().Add(*timeout)
+	for {
+		ok, pending, err := check()
+		var down *url.Error // runctl isn't up yet: keep trying
+		if err != nil && !errors.As(err, &down) {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return fmt.Errorf("timed out after %s: is runctl running? %w", *timeout, err)
+			}
+			return fmt.Errorf("timed out after %s: %s", *timeout, pending)
+		}
+		time.Sleep(waitPoll)
+	}
+}
+
+// waitReady checks GET /api/ready and describes the targets not ready yet.
+func waitReady(base string, targets []string) (bool, string, error) {
+	u := base + "/ready"
+	if len(targets) > 0 {
+		u += "?targets=" + url.QueryEscape(strings.Join(targets, ","))
+	}
+	var res runctl.Readiness
+	if err := getWaitJSON(u, &res, http.StatusOK, http.StatusServiceUnavailable); err != nil {
+		return false, "", err
+	}
+	var pending []string
+	for name, phase := range res.Targets {
+		if phase != "ready" {
+			pending = append(pending, name+" is "+phase)
+		}
+	}
+	slices.Sort(pending)
+	return res.Ready, strings.Join(pending, ", "), nil
+}
+
+// waitState checks whether every target is in state and describes the ones
+// that aren't.
+func waitState(base string, targets []string, state runctl.TargetState) (bool, string, error) {
+	var statuses []runctl.TargetStatus
+	if err := getWaitJSON(base+"/targets", &statuses, http.StatusOK); err != nil {
+		return false, "", err
+	}
+	byName := make(map[string]runctl.TargetStatus, len(statuses))
+	for _, st := range statuses {
+		byName[st.Name] = st
+	}
+	names := slices.Clone(targets)
+	if len(names) == 0 {
+		for name := range byName {
+			names = append(names, name)
+		}
+	}
+	var pending []string
+	slices.Sort(names)
+	for _, name := range names {
+		st, ok := byName[name]
+		if !ok {
+			return false, "", fmt.Errorf("target %q not found", name)
+		}
+		if st.State != state {
+			pending = append(pending, fmt.Sprintf("%s is %s", name, st.State))
+		}
+	}
+	return len(pending) == 0, strings.Join(pending, ", "), nil
+}
+
+// getWaitJSON decodes the response to GET u, which must have one of codes.
+func getWaitJSON(u string, v any, codes ...int) error {
+	resp, err := http.Get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	for _, code := range codes {
+		if resp.StatusCode == code {
+			return json.NewDecoder(resp.Body).Decode(v)
+		}
+	}
+	var e struct {
+		Error string `json:"error"`
+	}
+	json.NewDecoder(resp.Body).Decode(&e)
+	return fmt.Errorf("wait: %s", e.Error)
+}
+
+func isWaitState(s string) bool {
+	return slices.Contains(waitStates, runctl.TargetState(s))
+}
+
+func joinStates(states []runctl.TargetState) string {
+	names := make([]string, len(states))
+	for i, st := range states {
+		names[i] = string(st)
+	}
+	return strings.Join(names, ", ")
+}
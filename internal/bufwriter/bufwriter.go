@@ -0,0 +1,105 @@
+// Package bufwriter decouples a fast producer, such as a child process
+// writing to a pipe, from a destination that may block, such as a log file
+// on a full disk or slow NFS mount.
+package bufwriter
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// closeTimeout bounds how long Close waits for a blocked destination.
+const closeTimeout = 5 * time.Second
+
+// Writer buffers up to a fixed number of bytes for a background goroutine
+// to write to the destination. Write never blocks: when the buffer is full
+// the oldest whole lines are dropped.
+type Writer struct {
+	w      io.Writer
+	max    int
+	onDrop func(n int64)
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	buf     []byte
+	dropped int64 // since the last onDrop
+	closed  bool
+	done    chan struct{}
+
+	total atomic.Int64
+}
+
+// New returns a Writer that buffers up to size bytes for w. onDrop, if
+// not nil, is called from the background goroutine with the bytes dropped
+// since its last call, once w accepts writes again.
+func New(w io.Writer, size int, onDrop func(n int64)) *Writer {
+	this := &Writer{w: w, max: size, onDrop: onDrop, done: make(chan struct{})}
+	this.cond = sync.NewCond(&this.mu)
+	go this.drain()
+	return this
+}
+
+func (this *Writer) Write(p []byte) (int, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if this.closed {
+		return 0, os.ErrClosed
+	}
+	this.buf = append(this.buf, p...)
+	if over := len(this.buf) - this.max; over > 0 {
+		if i := bytes.IndexByte(this.buf[over:], '\n'); i >= 0 {
+			over += i + 1
+		}
+		this.buf = append([]byte(nil), this.buf[over:]...)
+		this.dropped += int64(over)
+		this.total.Add(int64(over))
+	}
+	this.cond.Signal()
+	return len(p), nil
+}
+
+// Dropped returns the bytes dropped so far.
+func (this *Writer) Dropped() int64 {
+	return this.total.Load()
+}
+
+func (this *Writer) drain() {
+	defer close(this.done)
+	for {
+		this.mu.Lock()
+		for len(this.buf) == 0 && !this.closed {
+			this.cond.Wait()
+		}
+		if len(this.buf) == 0 {
+			this.mu.Unlock()
+			return
+		}
+		chunk, dropped := this.buf, this.dropped
+		this.buf, this.dropped = nil, 0
+		this.mu.Unlock()
+
+		if dropped > 0 && this.onDrop != nil {
+			this.onDrop(dropped)
+		}
+		this.w.Write(chunk)
+	}
+}
+
+// Close stops accepting writes and waits, for up to 5s, until the buffered
+// bytes are written.
+func (this *Writer) Close() error {
+	this.mu.Lock()
+	this.closed = true
+	this.cond.Signal()
+	this.mu.Unlock()
+
+	select {
+	case <-this.done:
+	case <-time.After(closeTimeout):
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package bufwriter_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBufwriter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Bufwriter Suite")
+}
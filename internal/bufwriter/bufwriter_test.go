@@ -0,0 +1,71 @@
+package bufwriter_test
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/internal/bufwriter"
+)
+
+// gatedWriter blocks every Write until its gate is opened.
+type gatedWriter struct {
+	gate chan struct{}
+	mu   sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (this *gatedWriter) Write(p []byte) (int, error) {
+	<-this.gate
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.buf.Write(p)
+}
+
+func (this *gatedWriter) String() string {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.buf.String()
+}
+
+var _ = Describe("Writer", func() {
+	It("passes writes through", func() {
+		dst := &gatedWriter{gate: make(chan struct{})}
+		close(dst.gate)
+		w := bufwriter.New(dst, 64, nil)
+		w.Write([]byte("one\n"))
+		w.Write([]byte("two\n"))
+		Expect(w.Close()).To(Succeed())
+		Expect(dst.String()).To(Equal("one\ntwo\n"))
+		Expect(w.Dropped()).To(BeZero())
+	})
+
+	It("drops the oldest whole lines instead of blocking", func() {
+		dst := &gatedWriter{gate: make(chan struct{})}
+		var reported atomic.Int64
+		w := bufwriter.New(dst, 12, func(n int64) { reported.Add(n) })
+
+		// The first write (perhaps with an x) is taken by the blocked
+		// destination; the rest overflow the 12-byte buffer.
+		w.Write([]byte("first\n"))
+		Eventually(func() int64 {
+			w.Write([]byte("x\n"))
+			return w.Dropped()
+		}).Should(BeNumerically(">", 0))
+		for _, line := range []string{"line1\n", "line2\n", "line3\n"} {
+			n, err := w.Write([]byte(line))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(len(line)))
+		}
+
+		close(dst.gate)
+		Expect(w.Close()).To(Succeed())
+		Expect(dst.String()).To(HavePrefix("first\n"))
+		Expect(dst.String()).To(HaveSuffix("line2\nline3\n"))
+		Expect(dst.String()).NotTo(ContainSubstring("line1"))
+		Expect(reported.Load()).To(Equal(w.Dropped()))
+	})
+})
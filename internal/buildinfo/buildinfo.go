@@ -1,8 +1,15 @@
 package buildinfo
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"runtime/debug"
+	"strings"
+)
 
-// Set via -ldflags at build time.
+// Set via -ldflags at build time. Without them, init fills in what the Go
+// toolchain recorded: the module version for `go install ...@version`, and
+// the commit and its date for builds in a git checkout.
 var (
 	Version = "dev"
 	Commit  = "unknown"
@@ -10,6 +17,46 @@ var (
 	Date    = "unknown"
 )
 
+func init() {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		fromBuildInfo(info)
+	}
+}
+
+// rePseudoVersion matches the pseudo-versions Go stamps on builds of
+// untagged commits, e.g. v0.0.0-20260102030405-0123456789ab.
+var rePseudoVersion = regexp.MustCompile(`[-.]\d{14}-[0-9a-f]{12}$`)
+
+// fromBuildInfo sets the variables ldflags left unset from info. Only a
+// release version replaces "dev", so checkout builds are never offered
+// self-updates.
+func fromBuildInfo(info *debug.BuildInfo) {
+	v := info.Main.Version
+	if Version == "dev" && strings.HasPrefix(v, "v") && !strings.Contains(v, "+") && !rePseudoVersion.MatchString(v) {
+		Version = v
+	}
+	modified := false
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if Commit == "unknown" && len(s.Value) >= 7 {
+				Commit = s.Value[:7]
+			}
+		case "vcs.time":
+			if Date == "unknown" {
+				Date = s.Value
+			}
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	if modified && Commit != "unknown" && Version == "dev" {
+		Commit += "-dirty"
+	}
+}
+
+// String returns the version, commit, branch, and build date for --version
+// and usage text.
 func String() string {
 	return fmt.Sprintf("version %s (commit %s, branch %s, built %s)", Version, Commit, Branch, Date)
 }
@@ -0,0 +1,59 @@
+package buildinfo
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestFromBuildInfo(t *testing.T) {
+	Version, Commit, Date = "dev", "unknown", "unknown"
+	fromBuildInfo(&debug.BuildInfo{
+		Main: debug.Module{Version: "v1.4.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "0123456789abcdef"},
+			{Key: "vcs.time", Value: "2026-01-02T03:04:05Z"},
+		},
+	})
+	if Version != "v1.4.0" || Commit != "0123456" || Date != "2026-01-02T03:04:05Z" {
+		t.Errorf("got %s %s %s", Version, Commit, Date)
+	}
+}
+
+func TestFromBuildInfoKeepsLdflags(t *testing.T) {
+	Version, Commit, Date = "v2.0.0", "abcdef0", "2026-05-01T00:00:00Z"
+	fromBuildInfo(&debug.BuildInfo{
+		Main: debug.Module{Version: "(devel)"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "0123456789abcdef"},
+			{Key: "vcs.time", Value: "2026-01-02T03:04:05Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	})
+	if Version != "v2.0.0" || Commit != "abcdef0" || Date != "2026-05-01T00:00:00Z" {
+		t.Errorf("got %s %s %s", Version, Commit, Date)
+	}
+}
+
+func TestFromBuildInfoDirtyCheckout(t *testing.T) {
+	Version, Commit, Date = "dev", "unknown", "unknown"
+	fromBuildInfo(&debug.BuildInfo{
+		Main: debug.Module{Version: "v0.0.0-20261016025953-0123456789ab+dirty"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "0123456789abcdef"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	})
+	if Version != "dev" || Commit != "0123456-dirty" {
+		t.Errorf("got %s %s", Version, Commit)
+	}
+}
+
+func TestFromBuildInfoPseudoVersion(t *testing.T) {
+	for _, v := range []string{"v0.0.0-20261016025953-0123456789ab", "v1.4.1-0.20261016025953-0123456789ab", "v1.5.0-rc.1.0.20261016025953-0123456789ab"} {
+		Version = "dev"
+		fromBuildInfo(&debug.BuildInfo{Main: debug.Module{Version: v}})
+		if Version != "dev" {
+			t.Errorf("%s: got %s, want dev", v, Version)
+		}
+	}
+}
@@ -1,28 +1,99 @@
 package color
 
 import (
+	"fmt"
 	"os"
 
 	"golang.org/x/term"
 )
 
-var enabled bool
+// Mode is the --color setting.
+type Mode string
 
-// Init detects whether stderr is a TTY and enables colors accordingly.
+const (
+	Auto   Mode = "auto"   // color terminals, unless NO_COLOR is set (default)
+	Always Mode = "always" // color even redirected output
+	Never  Mode = "never"
+)
+
+// ParseMode parses auto, always, or never.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case Auto, Always, Never:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown color mode %q (want auto, always, or never)", s)
+}
+
+var (
+	mode   = Auto
+	stdout Palette
+	stderr Palette
+	plain  bool
+)
+
+// Init enables colors on each of stdout and stderr per the mode: in auto
+// mode, if the stream is a terminal and NO_COLOR is unset or empty. Colors
+// stay off in plain mode.
 func Init() {
-	enabled = term.IsTerminal(int(os.Stderr.Fd()))
+	stdout = Palette{on: enabledFor(os.Stdout)}
+	stderr = Palette{on: enabledFor(os.Stderr)}
+}
+
+func enabledFor(f *os.File) bool {
+	switch {
+	case plain || mode == Never:
+		return false
+	case mode == Always:
+		return true
+	case os.Getenv("NO_COLOR") != "":
+		return false
+	}
+	return term.IsTerminal(int(f.Fd()))
 }
 
-func wrap(code, s string) string {
-	if !enabled {
+// SetMode sets the color mode (default: auto).
+func SetMode(m Mode) {
+	mode = m
+	Init()
+}
+
+// SetPlain turns plain mode on or off. Plain mode disables colors; loggers
+// check Plain to spell out the cues colors would carry ([OK], [FAIL]).
+func SetPlain(p bool) {
+	plain = p
+	Init()
+}
+
+// Plain reports whether plain mode is on.
+func Plain() bool { return plain }
+
+// Palette colors text for one output stream.
+type Palette struct {
+	on bool
+}
+
+// Stderr returns the palette for text written to stderr. The package-level
+// functions color text for stdout.
+func Stderr() Palette { return stderr }
+
+func (this Palette) wrap(code, s string) string {
+	if !this.on {
 		return s
 	}
 	return "\033[" + code + "m" + s + "\033[0m"
 }
 
-func Red(s string) string    { return wrap("31", s) }
-func Yellow(s string) string { return wrap("33", s) }
-func Green(s string) string  { return wrap("32", s) }
-func Bold(s string) string   { return wrap("1", s) }
-func Dim(s string) string    { return wrap("2", s) }
-func Cyan(s string) string   { return wrap("36", s) }
+func (this Palette) Red(s string) string    { return this.wrap("31", s) }
+func (this Palette) Yellow(s string) string { return this.wrap("33", s) }
+func (this Palette) Green(s string) string  { return this.wrap("32", s) }
+func (this Palette) Bold(s string) string   { return this.wrap("1", s) }
+func (this Palette) Dim(s string) string    { return this.wrap("2", s) }
+func (this Palette) Cyan(s string) string   { return this.wrap("36", s) }
+
+func Red(s string) string    { return stdout.Red(s) }
+func Yellow(s string) string { return stdout.Yellow(s) }
+func Green(s string) string  { return stdout.Green(s) }
+func Bold(s string) string   { return stdout.Bold(s) }
+func Dim(s string) string    { return stdout.Dim(s) }
+func Cyan(s string) string   { return stdout.Cyan(s) }
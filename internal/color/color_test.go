@@ -0,0 +1,47 @@
+package color_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/internal/color"
+)
+
+var _ = Describe("Mode", func() {
+	AfterEach(func() {
+		color.SetMode(color.Auto)
+	})
+
+	It("parses the -color values", func() {
+		for _, s := range []string{"auto", "always", "never"} {
+			m, err := color.ParseMode(s)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(m)).To(Equal(s))
+		}
+		_, err := color.ParseMode("rainbow")
+		Expect(err).To(MatchError(ContainSubstring("unknown color mode")))
+	})
+
+	It("leaves redirected output uncolored in auto mode", func() {
+		// Test output is not a terminal.
+		Expect(color.Red("x")).To(Equal("x"))
+		Expect(color.Stderr().Red("x")).To(Equal("x"))
+	})
+
+	It("colors both streams in always mode, even with NO_COLOR", func() {
+		GinkgoT().Setenv("NO_COLOR", "1")
+		color.SetMode(color.Always)
+		Expect(color.Red("x")).To(Equal("\033[31mx\033[0m"))
+		Expect(color.Stderr().Yellow("x")).To(Equal("\033[33mx\033[0m"))
+
+		color.SetMode(color.Never)
+		Expect(color.Red("x")).To(Equal("x"))
+	})
+
+	It("never colors in plain mode", func() {
+		color.SetMode(color.Always)
+		color.SetPlain(true)
+		DeferCleanup(color.SetPlain, false)
+		Expect(color.Green("x")).To(Equal("x"))
+	})
+})
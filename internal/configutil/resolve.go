@@ -2,28 +2,30 @@ package configutil
 
 import (
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 )
 
-// ResolveYAMLPath checks if the given config path exists. If it doesn't and
-// ends with ".yaml", it tries the ".yml" variant (and vice versa). This allows
-// users to use either extension for their config files.
-func ResolveYAMLPath(path string) string {
+// Extensions are the config file extensions, in lookup order.
+var Extensions = []string{".yaml", ".yml", ".json", ".toml"}
+
+// ResolveConfigPath checks if the given config path exists. If it doesn't
+// and ends in one of Extensions, it tries the other extensions in order, so
+// "execrun.yaml" also finds execrun.yml, execrun.json, or execrun.toml.
+func ResolveConfigPath(path string) string {
 	if _, err := os.Stat(path); err == nil {
 		return path
 	}
-
-	if base, ok := strings.CutSuffix(path, ".yaml"); ok {
-		alt := base + ".yml"
-		if _, err := os.Stat(alt); err == nil {
-			return alt
-		}
-	} else if base, ok := strings.CutSuffix(path, ".yml"); ok {
-		alt := base + ".yaml"
-		if _, err := os.Stat(alt); err == nil {
-			return alt
+	ext := filepath.Ext(path)
+	if !slices.Contains(Extensions, strings.ToLower(ext)) {
+		return path
+	}
+	base := strings.TrimSuffix(path, ext)
+	for _, e := range Extensions {
+		if _, err := os.Stat(base + e); err == nil {
+			return base + e
 		}
 	}
-
 	return path
 }
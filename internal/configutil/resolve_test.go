@@ -10,7 +10,7 @@ import (
 	"github.com/gur-shatz/go-run/internal/configutil"
 )
 
-var _ = Describe("ResolveYAMLPath", func() {
+var _ = Describe("ResolveConfigPath", func() {
 	var dir string
 
 	BeforeEach(func() {
@@ -21,7 +21,7 @@ var _ = Describe("ResolveYAMLPath", func() {
 		p := filepath.Join(dir, "gorun.yaml")
 		Expect(os.WriteFile(p, []byte("x"), 0644)).To(Succeed())
 
-		Expect(configutil.ResolveYAMLPath(p)).To(Equal(p))
+		Expect(configutil.ResolveConfigPath(p)).To(Equal(p))
 	})
 
 	It("falls back to .yml when .yaml does not exist", func() {
@@ -29,7 +29,7 @@ var _ = Describe("ResolveYAMLPath", func() {
 		ymlPath := filepath.Join(dir, "gorun.yml")
 		Expect(os.WriteFile(ymlPath, []byte("x"), 0644)).To(Succeed())
 
-		Expect(configutil.ResolveYAMLPath(yamlPath)).To(Equal(ymlPath))
+		Expect(configutil.ResolveConfigPath(yamlPath)).To(Equal(ymlPath))
 	})
 
 	It("falls back to .yaml when .yml does not exist", func() {
@@ -37,7 +37,7 @@ var _ = Describe("ResolveYAMLPath", func() {
 		yamlPath := filepath.Join(dir, "gorun.yaml")
 		Expect(os.WriteFile(yamlPath, []byte("x"), 0644)).To(Succeed())
 
-		Expect(configutil.ResolveYAMLPath(ymlPath)).To(Equal(yamlPath))
+		Expect(configutil.ResolveConfigPath(ymlPath)).To(Equal(yamlPath))
 	})
 
 	It("prefers the original extension when both exist", func() {
@@ -46,17 +46,30 @@ var _ = Describe("ResolveYAMLPath", func() {
 		Expect(os.WriteFile(yamlPath, []byte("x"), 0644)).To(Succeed())
 		Expect(os.WriteFile(ymlPath, []byte("x"), 0644)).To(Succeed())
 
-		Expect(configutil.ResolveYAMLPath(yamlPath)).To(Equal(yamlPath))
-		Expect(configutil.ResolveYAMLPath(ymlPath)).To(Equal(ymlPath))
+		Expect(configutil.ResolveConfigPath(yamlPath)).To(Equal(yamlPath))
+		Expect(configutil.ResolveConfigPath(ymlPath)).To(Equal(ymlPath))
 	})
 
 	It("returns the original path when neither exists", func() {
 		p := filepath.Join(dir, "gorun.yaml")
-		Expect(configutil.ResolveYAMLPath(p)).To(Equal(p))
+		Expect(configutil.ResolveConfigPath(p)).To(Equal(p))
 	})
 
-	It("returns the original path for non-yaml extensions", func() {
-		p := filepath.Join(dir, "config.toml")
-		Expect(configutil.ResolveYAMLPath(p)).To(Equal(p))
+	It("returns the original path for unknown extensions", func() {
+		p := filepath.Join(dir, "config.ini")
+		Expect(os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("x"), 0644)).To(Succeed())
+		Expect(configutil.ResolveConfigPath(p)).To(Equal(p))
+	})
+
+	It("falls back to .json and .toml", func() {
+		yamlPath := filepath.Join(dir, "runctl.yaml")
+		tomlPath := filepath.Join(dir, "runctl.toml")
+		Expect(os.WriteFile(tomlPath, []byte("x"), 0644)).To(Succeed())
+		Expect(configutil.ResolveConfigPath(yamlPath)).To(Equal(tomlPath))
+
+		jsonPath := filepath.Join(dir, "runctl.json")
+		Expect(os.WriteFile(jsonPath, []byte("x"), 0644)).To(Succeed())
+		Expect(configutil.ResolveConfigPath(yamlPath)).To(Equal(jsonPath))
+		Expect(configutil.ResolveConfigPath(tomlPath)).To(Equal(tomlPath))
 	})
 })
@@ -0,0 +1,103 @@
+// Package desktop shows native desktop notifications for build results:
+// osascript on macOS, notify-send on Linux, and a PowerShell toast on
+// Windows.
+package desktop
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Notifier notifies when a target's build fails, and when it builds again
+// after a failure. A nil *Notifier does nothing.
+type Notifier struct {
+	mu      sync.Mutex
+	app     string
+	failed  map[string]bool
+	command func(title, body string) []string
+	run     func(argv []string) // starts argv without waiting for it
+}
+
+// New returns a Notifier titling notifications with app, e.g. "execrun".
+// It fails if this platform has no notification command.
+func New(app string) (*Notifier, error) {
+	command, tool := commandFor(runtime.GOOS)
+	if command == nil {
+		return nil, fmt.Errorf("desktop notifications are not supported on %s", runtime.GOOS)
+	}
+	if _, err := exec.LookPath(tool); err != nil {
+		return nil, fmt.Errorf("desktop notifications need %s: %w", tool, err)
+	}
+	return &Notifier{app: app, failed: make(map[string]bool), command: command, run: start}, nil
+}
+
+// Report records whether name's build succeeded, notifying on a failure
+// and on the first success after one. It never blocks on the notification.
+func (this *Notifier) Report(name string, ok bool) {
+	if this == nil {
+		return
+	}
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	wasFailing := this.failed[name]
+	this.failed[name] = !ok
+	switch {
+	case !ok:
+		this.run(this.command(this.app, name+": build failed"))
+	case wasFailing:
+		this.run(this.command(this.app, name+": build fixed"))
+	}
+}
+
+// start runs argv in the background and reaps it.
+func start(argv []string) {
+	cmd := exec.Command(argv[0], argv[1:]...)
+	if cmd.Start() == nil {
+		go cmd.Wait()
+	}
+}
+
+// commandFor returns how to show a notification on goos, and the tool it
+// runs.
+func commandFor(goos string) (func(title, body string) []string, string) {
+	switch goos {
+	case "darwin":
+		return func(title, body string) []string {
+			return []string{"osascript", "-e",
+				fmt.Sprintf("display notification %s with title %s", appleString(body), appleString(title))}
+		}, "osascript"
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return func(title, body string) []string {
+			return []string{"notify-send", "--app-name=" + title, title, body}
+		}, "notify-send"
+	case "windows":
+		return func(title, body string) []string {
+			return []string{"powershell", "-NoProfile", "-NonInteractive", "-Command", toastScript(title, body)}
+		}, "powershell"
+	}
+	return nil, ""
+}
+
+// appleString quotes s as an AppleScript string literal.
+func appleString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// powershellAppID is the AUMID toasts are shown under; Windows drops
+// toasts from apps it doesn't know.
+const powershellAppID = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+
+// toastScript is a PowerShell script showing a toast with title and body.
+func toastScript(title, body string) string {
+	quote := func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
+	return `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null;` +
+		`$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02);` +
+		`$text = $xml.GetElementsByTagName('text');` +
+		`$text.Item(0).AppendChild($xml.CreateTextNode(` + quote(title) + `)) > $null;` +
+		`$text.Item(1).AppendChild($xml.CreateTextNode(` + quote(body) + `)) > $null;` +
+		`[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier(` + quote(powershellAppID) + `).Show([Windows.UI.Notifications.ToastNotification]::new($xml))`
+}
@@ -0,0 +1,57 @@
+package desktop
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReportNotifiesFailuresAndRecoveries(t *testing.T) {
+	var shown []string
+	n := &Notifier{
+		app:     "runctl",
+		failed:  make(map[string]bool),
+		command: func(title, body string) []string { return []string{title, body} },
+		run:     func(argv []string) { shown = append(shown, strings.Join(argv, ": ")) },
+	}
+	n.Report("api", true)
+	n.Report("api", false)
+	n.Report("worker", true)
+	n.Report("api", false)
+	n.Report("api", true)
+	n.Report("api", true)
+
+	want := []string{
+		"runctl: api: build failed",
+		"runctl: api: build failed",
+		"runctl: api: build fixed",
+	}
+	if !slices.Equal(shown, want) {
+		t.Errorf("notifications = %q, want %q", shown, want)
+	}
+
+	var nilNotifier *Notifier
+	nilNotifier.Report("api", false) // a nil Notifier is safe to use
+}
+
+func TestCommandFor(t *testing.T) {
+	for goos, want := range map[string][]string{
+		"darwin": {"osascript", "-e", `display notification "say \"hi\"" with title "execrun"`},
+		"linux":  {"notify-send", "--app-name=execrun", "execrun", `say "hi"`},
+	} {
+		command, _ := commandFor(goos)
+		if got := command("execrun", `say "hi"`); !slices.Equal(got, want) {
+			t.Errorf("%s: command = %q, want %q", goos, got, want)
+		}
+	}
+
+	command, tool := commandFor("windows")
+	argv := command("execrun", "it's broken")
+	if tool != "powershell" || argv[0] != "powershell" || !strings.Contains(argv[len(argv)-1], "'it''s broken'") {
+		t.Errorf("windows: command = %q", argv)
+	}
+
+	if command, _ := commandFor("plan9"); command != nil {
+		t.Error("plan9: want no command")
+	}
+}
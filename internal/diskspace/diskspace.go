@@ -0,0 +1,73 @@
+// Package diskspace checks free space on the volumes builds write to.
+package diskspace
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+var units = []struct {
+	suffix string
+	size   uint64
+}{
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize parses a size such as "500MB" or "2GB" (binary units; the
+// suffix is case-insensitive). A bare number is bytes.
+func ParseSize(s string) (uint64, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	mult := uint64(1)
+	for _, u := range units {
+		if num, ok := strings.CutSuffix(v, u.suffix); ok {
+			v, mult = strings.TrimSpace(num), u.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 500MB or 2GB)", s)
+	}
+	return uint64(n * float64(mult)), nil
+}
+
+// FormatSize renders n bytes with the largest unit that fits.
+func FormatSize(n uint64) string {
+	for _, u := range units {
+		if n >= u.size && u.size > 1 {
+			return strconv.FormatFloat(float64(n)/float64(u.size), 'f', 1, 64) + " " + u.suffix
+		}
+	}
+	return strconv.FormatUint(n, 10) + " B"
+}
+
+// Free returns the bytes available to unprivileged users on the volume
+// holding path.
+func Free(path string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, fmt.Errorf("statfs %s: %w", path, err)
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
+
+// Check returns an error naming the first of dirs whose volume has less
+// than minFree bytes free. Directories that can't be inspected are skipped.
+func Check(minFree uint64, dirs ...string) error {
+	for _, dir := range dirs {
+		free, err := Free(dir)
+		if err != nil {
+			continue
+		}
+		if free < minFree {
+			return fmt.Errorf("low disk space: %s has %s free, need %s", dir, FormatSize(free), FormatSize(minFree))
+		}
+	}
+	return nil
+}
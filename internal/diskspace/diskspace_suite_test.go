@@ -0,0 +1,13 @@
+package diskspace_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDiskspace(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Diskspace Suite")
+}
@@ -0,0 +1,45 @@
+package diskspace_test
+
+import (
+	"math"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/internal/diskspace"
+)
+
+var _ = Describe("Diskspace", func() {
+	Describe("ParseSize", func() {
+		It("parses units", func() {
+			Expect(diskspace.ParseSize("512")).To(Equal(uint64(512)))
+			Expect(diskspace.ParseSize("500MB")).To(Equal(uint64(500 << 20)))
+			Expect(diskspace.ParseSize("1.5gb")).To(Equal(uint64(3 << 29)))
+			Expect(diskspace.ParseSize("2 GB")).To(Equal(uint64(2 << 30)))
+		})
+
+		It("rejects garbage", func() {
+			_, err := diskspace.ParseSize("lots")
+			Expect(err).To(MatchError(ContainSubstring(`invalid size "lots"`)))
+			_, err = diskspace.ParseSize("-1GB")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	It("formats sizes", func() {
+		Expect(diskspace.FormatSize(100)).To(Equal("100 B"))
+		Expect(diskspace.FormatSize(3 << 29)).To(Equal("1.5 GB"))
+	})
+
+	Describe("Check", func() {
+		It("passes when enough space is free", func() {
+			Expect(diskspace.Check(1, GinkgoT().TempDir())).To(Succeed())
+		})
+
+		It("names the volume that is short on space", func() {
+			dir := GinkgoT().TempDir()
+			err := diskspace.Check(math.MaxUint64, dir)
+			Expect(err).To(MatchError(ContainSubstring("low disk space: " + dir + " has")))
+		})
+	})
+})
@@ -0,0 +1,178 @@
+// Package doctor holds the environment checks of `execrun doctor` and
+// `runctl doctor`, each with an actionable fix when it fails.
+package doctor
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/gur-shatz/go-run/internal/color"
+	"github.com/gur-shatz/go-run/internal/watcher"
+	"github.com/gur-shatz/go-run/pkg/execrun"
+)
+
+// Status is the outcome of a check.
+type Status int
+
+const (
+	OK   Status = iota
+	Warn        // works, but slower or surprising
+	Fail        // needs fixing
+)
+
+// Result is the outcome of a check.
+type Result struct {
+	Check  string
+	Status Status
+	Detail string
+	Fix    string // how to fix a Warn or Fail
+}
+
+func ok(check, format string, args ...any) Result {
+	return Result{Check: check, Status: OK, Detail: fmt.Sprintf(format, args...)}
+}
+
+// GoToolchain checks that the go command is on PATH.
+func GoToolchain() Result {
+	path, err := exec.LookPath("go")
+	if err != nil {
+		return Result{Check: "go toolchain", Status: Warn, Detail: "go is not on PATH",
+			Fix: "install Go from https://go.dev/dl/ or add its bin directory to PATH (only needed for Go builds)"}
+	}
+	out, err := exec.Command(path, "version").Output()
+	if err != nil {
+		return Result{Check: "go toolchain", Status: Fail, Detail: fmt.Sprintf("%s version: %v", path, err),
+			Fix: "reinstall Go from https://go.dev/dl/"}
+	}
+	return ok("go toolchain", "%s", strings.TrimSpace(string(out)))
+}
+
+// inotifyWatches is the file holding the inotify watch limit.
+var inotifyWatches = "/proc/sys/fs/inotify/max_user_watches"
+
+// WatchLimit checks that the inotify watch limit leaves room for watching
+// dirs directories; runctl and editors share it. Linux only.
+func WatchLimit(dirs int) Result {
+	if runtime.GOOS != "linux" {
+		return ok("watch limit", "no inotify limit on %s", runtime.GOOS)
+	}
+	data, err := os.ReadFile(inotifyWatches)
+	if err != nil {
+		return ok("watch limit", "unknown (%v)", err)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return ok("watch limit", "unknown (%s: %q)", inotifyWatches, data)
+	}
+	if dirs > limit/2 {
+		return Result{Check: "watch limit", Status: Warn,
+			Detail: fmt.Sprintf("%d directories to watch, fs.inotify.max_user_watches is %d; over the limit they are polled, which is slower", dirs, limit),
+			Fix:    watcher.WatchLimitHelp}
+	}
+	return ok("watch limit", "%d directories to watch, fs.inotify.max_user_watches is %d", dirs, limit)
+}
+
+// Dirs returns the number of directories holding files, the relative
+// paths of which are given, counting their parents too: the directories a
+// watcher of the files watches.
+func Dirs(files []string) int {
+	dirs := map[string]bool{".": true}
+	for _, f := range files {
+		for d := filepath.Dir(f); !dirs[d]; d = filepath.Dir(d) {
+			dirs[d] = true
+		}
+	}
+	return len(dirs)
+}
+
+// PortFree checks that nothing listens on port, which what is for.
+func PortFree(what string, port int) Result {
+	check := fmt.Sprintf("port %d", port)
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return Result{Check: check, Status: Fail, Detail: fmt.Sprintf("%s: %v", what, err),
+			Fix: fmt.Sprintf("stop the process using it (`lsof -i :%d`), e.g. a runctl already running, or pick another port", port)}
+	}
+	l.Close()
+	return ok(check, "free for %s", what)
+}
+
+// Writable checks that files can be created in dir, which what is for, or
+// in its nearest existing parent if dir is yet to be created.
+func Writable(what, dir string) Result {
+	fix := fmt.Sprintf("check the permissions of %s and the free space on its volume, or point %s elsewhere", dir, what)
+	existing := dir
+	for {
+		if _, err := os.Stat(existing); err == nil || filepath.Dir(existing) == existing {
+			break
+		}
+		existing = filepath.Dir(existing)
+	}
+	f, err := os.CreateTemp(existing, ".doctor-*")
+	if err != nil {
+		return Result{Check: what, Status: Fail, Detail: err.Error(), Fix: fix}
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return ok(what, "%s is writable", dir)
+}
+
+// WatchPatterns checks that the watch patterns of cfg, the config of what,
+// match files in rootDir, each of them, and returns the matched files.
+func WatchPatterns(what string, cfg *execrun.Config, rootDir string) (Result, []string) {
+	check := what + " watch"
+	files, err := cfg.WatchedFiles(rootDir)
+	if err != nil {
+		return Result{Check: check, Status: Fail, Detail: err.Error(), Fix: "fix the watch patterns"}, nil
+	}
+	if len(files) == 0 {
+		return Result{Check: check, Status: Fail, Detail: fmt.Sprintf("no files in %s match %s", rootDir, strings.Join(cfg.WatchPatterns(), ", ")),
+			Fix: "fix the watch patterns; they are relative to the config's directory, and .gitignore'd files only match when named explicitly"}, nil
+	}
+	unmatched, err := cfg.UnmatchedPatterns(rootDir)
+	if err != nil {
+		return Result{Check: check, Status: Fail, Detail: err.Error(), Fix: "fix the watch patterns"}, files
+	}
+	if len(unmatched) > 0 {
+		return Result{Check: check, Status: Warn, Detail: fmt.Sprintf("%d files, but %s match nothing", len(files), strings.Join(unmatched, ", ")),
+			Fix: "fix or remove the patterns that match nothing; a typo there silently skips changes"}, files
+	}
+	return ok(check, "%d files", len(files)), files
+}
+
+// Print prints a line per result, with the fix under each Warn and Fail,
+// and returns the number of Fails.
+func Print(results []Result) (failed int) {
+	for _, r := range results {
+		fmt.Printf("%s %s: %s\n", marker(r.Status), r.Check, r.Detail)
+		if r.Status != OK && r.Fix != "" {
+			fmt.Printf("    fix: %s\n", r.Fix)
+		}
+		if r.Status == Fail {
+			failed++
+		}
+	}
+	return failed
+}
+
+func marker(s Status) string {
+	switch {
+	case color.Plain() && s == OK:
+		return "[OK]"
+	case color.Plain() && s == Warn:
+		return "[WARN]"
+	case color.Plain():
+		return "[FAIL]"
+	case s == OK:
+		return color.Green("✔")
+	case s == Warn:
+		return color.Yellow("!")
+	}
+	return color.Red("✘")
+}
@@ -0,0 +1,100 @@
+package doctor_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/internal/doctor"
+	"github.com/gur-shatz/go-run/pkg/execrun"
+)
+
+var _ = Describe("Doctor", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		tmpDir = GinkgoT().TempDir()
+	})
+
+	Describe("Dirs", func() {
+		It("counts the directories of the files and their parents", func() {
+			Expect(doctor.Dirs([]string{"main.go", "a/b/x.go", "a/b/y.go", "c/z.go"})).To(Equal(4))
+			Expect(doctor.Dirs(nil)).To(Equal(1))
+		})
+	})
+
+	Describe("PortFree", func() {
+		It("fails on a port in use, with a fix", func() {
+			l, err := net.Listen("tcp", ":0")
+			Expect(err).NotTo(HaveOccurred())
+			defer l.Close()
+			port := l.Addr().(*net.TCPAddr).Port
+
+			r := doctor.PortFree("api", port)
+			Expect(r.Status).To(Equal(doctor.Fail))
+			Expect(r.Fix).To(ContainSubstring("lsof"))
+		})
+
+		It("passes on a free port", func() {
+			l, err := net.Listen("tcp", ":0")
+			Expect(err).NotTo(HaveOccurred())
+			port := l.Addr().(*net.TCPAddr).Port
+			l.Close()
+
+			Expect(doctor.PortFree("api", port).Status).To(Equal(doctor.OK))
+		})
+	})
+
+	Describe("Writable", func() {
+		It("checks the nearest existing parent without creating the dir", func() {
+			dir := filepath.Join(tmpDir, "logs", "today")
+			Expect(doctor.Writable("logs_dir", dir).Status).To(Equal(doctor.OK))
+			Expect(filepath.Join(tmpDir, "logs")).NotTo(BeADirectory())
+		})
+
+		It("fails on a read-only dir", func() {
+			if os.Geteuid() == 0 {
+				Skip("root can write anywhere")
+			}
+			Expect(os.Chmod(tmpDir, 0555)).To(Succeed())
+			defer os.Chmod(tmpDir, 0755)
+			Expect(doctor.Writable("logs_dir", tmpDir).Status).To(Equal(doctor.Fail))
+		})
+	})
+
+	Describe("WatchPatterns", func() {
+		load := func(watch string) *execrun.Config {
+			configPath := filepath.Join(tmpDir, "execrun.yaml")
+			Expect(os.WriteFile(configPath, []byte("watch: "+watch+"\nexec: [\"./bin/app\"]\n"), 0644)).To(Succeed())
+			cfg, _, err := execrun.LoadConfig(configPath)
+			Expect(err).NotTo(HaveOccurred())
+			return cfg
+		}
+
+		BeforeEach(func() {
+			Expect(os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\n"), 0644)).To(Succeed())
+		})
+
+		It("passes when every pattern matches", func() {
+			r, files := doctor.WatchPatterns("app", load(`["**/*.go"]`), tmpDir)
+			Expect(r.Status).To(Equal(doctor.OK))
+			Expect(files).To(ConsistOf("main.go"))
+		})
+
+		It("warns about a pattern matching nothing", func() {
+			r, files := doctor.WatchPatterns("app", load(`["**/*.go", "**/*.sql"]`), tmpDir)
+			Expect(r.Status).To(Equal(doctor.Warn))
+			Expect(r.Detail).To(ContainSubstring("**/*.sql"))
+			Expect(files).To(HaveLen(1))
+		})
+
+		It("fails when no file matches", func() {
+			r, _ := doctor.WatchPatterns("app", load(`["**/*.rs"]`), tmpDir)
+			Expect(r.Status).To(Equal(doctor.Fail))
+			Expect(r.Fix).NotTo(BeEmpty())
+		})
+	})
+})
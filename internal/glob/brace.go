@@ -0,0 +1,57 @@
+package glob
+
+import "strings"
+
+// ExpandBraces returns the patterns a brace pattern stands for, e.g.
+// "{cmd,internal}/**/*.go" gives "cmd/**/*.go" and "internal/**/*.go".
+// Nested and repeated groups multiply out. A pattern without braces, or
+// with an unbalanced one, comes back as is; escaped braces and braces in
+// a [...] class are literal.
+func ExpandBraces(pattern string) []string {
+	open, end, alts := braceGroup(pattern)
+	if open < 0 {
+		return []string{pattern}
+	}
+	var out []string
+	for _, alt := range alts {
+		out = append(out, ExpandBraces(pattern[:open]+alt+pattern[end+1:])...)
+	}
+	return out
+}
+
+// braceGroup finds the first brace group in pattern and returns the
+// positions of its braces and its comma-separated alternatives, which may
+// hold nested groups. open is -1 if there is none.
+func braceGroup(pattern string) (open, end int, alts []string) {
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			if j := strings.IndexByte(pattern[i:], ']'); j > 0 {
+				i += j
+			}
+		case '{':
+			depth, start := 1, i+1
+			for j := i + 1; j < len(pattern); j++ {
+				switch pattern[j] {
+				case '\\':
+					j++
+				case '{':
+					depth++
+				case '}':
+					if depth--; depth == 0 {
+						return i, j, append(alts, pattern[start:j])
+					}
+				case ',':
+					if depth == 1 {
+						alts = append(alts, pattern[start:j])
+						start = j + 1
+					}
+				}
+			}
+			return -1, -1, nil
+		}
+	}
+	return -1, -1, nil
+}
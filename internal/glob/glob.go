@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/bmatcuk/doublestar/v4"
@@ -17,52 +18,119 @@ type Pattern struct {
 
 // ExpandPatterns expands the patterns relative to the given root directory
 // and returns a sorted, deduplicated list of matching file paths (relative to root).
-func ExpandPatterns(root string, patterns []Pattern) ([]string, error) {
+// Braces are expanded first (see ExpandBraces).
+// Paths excluded by the .gitignore and .ignore files under root are left
+// out unless an include pattern names them explicitly (see Ignore.Skip).
+func ExpandPatterns(root string, patterns []Pattern, opts ...Option) ([]string, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
 	includes := make(map[string]bool)
+	ignore := NewIgnore(root)
+	var excludes []Pattern
+	for _, p := range patterns {
+		if p.Negated {
+			excludes = append(excludes, p)
+		}
+	}
 
 	for _, p := range patterns {
 		if p.Negated {
 			continue
 		}
-		matches, err := expandSinglePattern(root, p.Raw)
-		if err != nil {
-			return nil, fmt.Errorf("glob %q: %w", p.Raw, err)
-		}
-		for _, m := range matches {
-			includes[m] = true
+		for _, raw := range ExpandBraces(p.Raw) {
+			matches, err := expandSinglePattern(root, raw, ignore, excludes, o)
+			if err != nil {
+				return nil, fmt.Errorf("glob %q: %w", p.Raw, err)
+			}
+			for _, m := range matches {
+				includes[m] = true
+			}
 		}
 	}
 
 	// Apply exclusions
+	result := make([]string, 0, len(includes))
+	for path := range includes {
+		if !excluded(excludes, path) {
+			result = append(result, path)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// Match reports whether path (slash-separated, relative to the root) matches
+// an include pattern and no exclusion, expanding braces like ExpandPatterns.
+func Match(patterns []Pattern, path string) bool {
+	matched := false
 	for _, p := range patterns {
-		if !p.Negated {
-			continue
+		if matchBraces(p.Raw, path) {
+			if p.Negated {
+				return false
+			}
+			matched = true
 		}
-		matches, err := expandSinglePattern(root, p.Raw)
-		if err != nil {
-			return nil, fmt.Errorf("glob %q: %w", p.Raw, err)
+	}
+	return matched
+}
+
+// excluded reports whether one of the exclusions matches path.
+func excluded(excludes []Pattern, path string) bool {
+	for _, p := range excludes {
+		if matchBraces(p.Raw, path) {
+			return true
 		}
-		for _, m := range matches {
-			delete(includes, m)
+	}
+	return false
+}
+
+// excludesDir reports whether an exclusion covers everything under the
+// directory dir, like "node_modules/**" does, so the walk can skip it.
+func excludesDir(excludes []Pattern, dir string) bool {
+	for _, p := range excludes {
+		for _, raw := range ExpandBraces(p.Raw) {
+			if raw == "**" {
+				return true
+			}
+			prefix, ok := strings.CutSuffix(raw, "/**")
+			if !ok {
+				continue
+			}
+			if ok, _ := doublestar.Match(prefix, dir); ok {
+				return true
+			}
 		}
 	}
+	return false
+}
 
-	result := make([]string, 0, len(includes))
-	for path := range includes {
-		result = append(result, path)
+// matchBraces reports whether path matches one of pattern's brace
+// expansions.
+func matchBraces(pattern, path string) bool {
+	for _, raw := range ExpandBraces(pattern) {
+		if ok, _ := doublestar.Match(raw, path); ok {
+			return true
+		}
 	}
-	sortStrings(result)
-	return result, nil
+	return false
 }
 
 // expandSinglePattern handles a single glob pattern. For patterns starting with
 // "..", it resolves the directory prefix to an absolute path so os.DirFS can
 // access files outside the root, then re-prefixes results so they stay relative
-// to root.
-func expandSinglePattern(root, pattern string) ([]string, error) {
+// to root. A non-nil ignore hides ignored paths under root, and directories
+// the excludes cover entirely are not walked; patterns outside root are not
+// filtered.
+func expandSinglePattern(root, pattern string, ignore *Ignore, excludes []Pattern, o options) ([]string, error) {
+	var globOpts []doublestar.GlobOption
+	if !o.followSymlinks {
+		globOpts = append(globOpts, doublestar.WithNoFollow())
+	}
 	if !strings.HasPrefix(pattern, "..") {
-		fsys := os.DirFS(root)
-		return doublestar.Glob(fsys, pattern)
+		fsys := walkFS{FS: os.DirFS(root), root: root, ignore: ignore, patterns: []Pattern{{Raw: pattern}}, excludes: excludes, follow: o.followSymlinks}
+		return doublestar.Glob(fsys, pattern, globOpts...)
 	}
 
 	// Split into directory prefix and glob part.
@@ -72,8 +140,8 @@ func expandSinglePattern(root, pattern string) ([]string, error) {
 	// Resolve the directory prefix against root to get an absolute path.
 	absDir := filepath.Clean(filepath.Join(root, dir))
 
-	fsys := os.DirFS(absDir)
-	matches, err := doublestar.Glob(fsys, globPart)
+	fsys := walkFS{FS: os.DirFS(absDir), root: absDir, follow: o.followSymlinks}
+	matches, err := doublestar.Glob(fsys, globPart, globOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -86,11 +154,3 @@ func expandSinglePattern(root, pattern string) ([]string, error) {
 	}
 	return matches, nil
 }
-
-func sortStrings(s []string) {
-	for i := 1; i < len(s); i++ {
-		for j := i; j > 0 && s[j] < s[j-1]; j-- {
-			s[j], s[j-1] = s[j-1], s[j]
-		}
-	}
-}
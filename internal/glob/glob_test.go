@@ -3,6 +3,7 @@ package glob_test
 import (
 	"os"
 	"path/filepath"
+	"slices"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -50,4 +51,161 @@ var _ = Describe("Glob", func() {
 			Expect(files).To(ConsistOf("main.go"))
 		})
 	})
+
+	Describe("braces", func() {
+		DescribeTable("ExpandBraces",
+			func(pattern string, want ...string) {
+				Expect(glob.ExpandBraces(pattern)).To(Equal(want))
+			},
+			Entry("no braces", "**/*.go", "**/*.go"),
+			Entry("directories", "{cmd,internal}/**/*.go", "cmd/**/*.go", "internal/**/*.go"),
+			Entry("extensions", "*.{ts,tsx}", "*.ts", "*.tsx"),
+			Entry("several groups", "{a,b}/*.{x,y}", "a/*.x", "a/*.y", "b/*.x", "b/*.y"),
+			Entry("nested", "{a,b{1,2}}.go", "a.go", "b1.go", "b2.go"),
+			Entry("empty alternative", "main{,_test}.go", "main.go", "main_test.go"),
+			Entry("escaped", `\{a,b\}.go`, `\{a,b\}.go`),
+			Entry("in a class", "[{]a,b}.go", "[{]a,b}.go"),
+			Entry("unbalanced", "{a,b.go", "{a,b.go"),
+		)
+
+		It("expands them in ExpandPatterns and Match alike", func() {
+			for _, f := range []string{"cmd/app/main.go", "internal/x/x.go", "pkg/p.go", "web/app.ts", "web/view.tsx", "web/app.js"} {
+				Expect(os.MkdirAll(filepath.Join(tmpDir, filepath.Dir(f)), 0755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(tmpDir, f), nil, 0644)).To(Succeed())
+			}
+			patterns := []glob.Pattern{
+				{Raw: "{cmd,internal}/**/*.go"},
+				{Raw: "**/*.{ts,tsx}"},
+				{Raw: "{internal,web}/**/x.go", Negated: true},
+			}
+
+			files, err := glob.ExpandPatterns(tmpDir, patterns)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(ConsistOf("cmd/app/main.go", "web/app.ts", "web/view.tsx"))
+			for _, f := range []string{"cmd/app/main.go", "internal/x/x.go", "pkg/p.go", "web/app.ts", "web/view.tsx", "web/app.js"} {
+				Expect(glob.Match(patterns, f)).To(Equal(slices.Contains(files, f)), f)
+			}
+		})
+	})
+
+	Describe("ignore files", func() {
+		write := func(name, content string) {
+			p := filepath.Join(tmpDir, name)
+			Expect(os.MkdirAll(filepath.Dir(p), 0755)).To(Succeed())
+			Expect(os.WriteFile(p, []byte(content), 0644)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			write(".gitignore", "# build output\nnode_modules/\n/dist\n*.log\n!keep.log\n")
+			write("main.js", "")
+			write("node_modules/lib/index.js", "")
+			write("dist/app.js", "")
+			write("web/dist/app.js", "")
+			write("debug.log", "")
+			write("keep.log", "")
+			write(".git/hooks/pre-commit.js", "")
+		})
+
+		It("leaves out paths the .gitignore excludes, and .git", func() {
+			files, err := glob.ExpandPatterns(tmpDir, []glob.Pattern{{Raw: "**/*.js"}, {Raw: "**/*.log"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(ConsistOf("main.js", "web/dist/app.js", "keep.log"))
+		})
+
+		It("keeps ignored paths an include pattern names explicitly", func() {
+			files, err := glob.ExpandPatterns(tmpDir, []glob.Pattern{
+				{Raw: "**/*.js"},
+				{Raw: "dist/**/*.js"},
+				{Raw: "node_modules/lib/index.js"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(ConsistOf("main.js", "web/dist/app.js", "dist/app.js", "node_modules/lib/index.js"))
+		})
+
+		It("applies nested .gitignore and .ignore files to their own directory", func() {
+			write("web/.gitignore", "*.gen.js\n")
+			write("web/.ignore", "!keep.gen.js\n")
+			write("web/a.gen.js", "")
+			write("web/keep.gen.js", "")
+			write("a.gen.js", "")
+
+			files, err := glob.ExpandPatterns(tmpDir, []glob.Pattern{{Raw: "**/*.gen.js"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(ConsistOf("a.gen.js", "web/keep.gen.js"))
+		})
+
+		It("matches single paths", func() {
+			ignore := glob.NewIgnore(tmpDir)
+			Expect(ignore.Match("node_modules", true)).To(BeTrue())
+			Expect(ignore.Match("node_modules", false)).To(BeFalse()) // node_modules/ only matches directories
+			Expect(ignore.Match("web/dist", true)).To(BeFalse())      // /dist is anchored
+			Expect(ignore.Match("web/trace.log", false)).To(BeTrue())
+			Expect(ignore.Skip([]glob.Pattern{{Raw: "dist/*.js"}}, "dist", true)).To(BeFalse())
+		})
+
+		It("reads an exclude file as exclusions", func() {
+			write(".gorunignore", "# shared\n*.log\ngen/\n/tmp\n!keep.log\n")
+			Expect(glob.ReadExcludeFile(filepath.Join(tmpDir, ".gorunignore"))).To(Equal([]glob.Pattern{
+				{Raw: "**/*.log", Negated: true},
+				{Raw: "**/*.log/**", Negated: true},
+				{Raw: "**/gen/**", Negated: true},
+				{Raw: "tmp", Negated: true},
+				{Raw: "tmp/**", Negated: true},
+			}))
+			Expect(glob.ReadExcludeFile(filepath.Join(tmpDir, "missing"))).To(BeEmpty())
+		})
+
+		It("keeps ignored paths a brace alternative names explicitly", func() {
+			files, err := glob.ExpandPatterns(tmpDir, []glob.Pattern{{Raw: "{dist,node_modules/lib}/*.js"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(ConsistOf("dist/app.js", "node_modules/lib/index.js"))
+		})
+	})
+
+	Describe("symlinks", func() {
+		BeforeEach(func() {
+			shared := filepath.Join(tmpDir, "..", filepath.Base(tmpDir)+"-shared")
+			Expect(os.MkdirAll(filepath.Join(shared, "util"), 0755)).To(Succeed())
+			DeferCleanup(os.RemoveAll, shared)
+			Expect(os.WriteFile(filepath.Join(shared, "util", "util.go"), []byte("package util"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main"), 0644)).To(Succeed())
+			Expect(os.Symlink(shared, filepath.Join(tmpDir, "shared"))).To(Succeed())
+		})
+
+		It("doesn't descend into symlinked directories by default", func() {
+			files, err := glob.ExpandPatterns(tmpDir, []glob.Pattern{{Raw: "**/*.go"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(ConsistOf("main.go"))
+		})
+
+		It("follows them with FollowSymlinks", func() {
+			files, err := glob.ExpandPatterns(tmpDir, []glob.Pattern{{Raw: "**/*.go"}}, glob.FollowSymlinks())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(ConsistOf("main.go", "shared/util/util.go"))
+		})
+
+		It("skips links that loop back", func() {
+			Expect(os.Symlink("..", filepath.Join(tmpDir, "shared", "util", "up"))).To(Succeed())     // to an ancestor
+			Expect(os.Symlink(tmpDir, filepath.Join(tmpDir, "shared", "util", "root"))).To(Succeed()) // across links
+
+			files, err := glob.ExpandPatterns(tmpDir, []glob.Pattern{{Raw: "**/*.go"}}, glob.FollowSymlinks())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(ConsistOf("main.go", "shared/util/util.go"))
+		})
+	})
+
+	Describe("Match", func() {
+		It("matches includes minus exclusions", func() {
+			patterns := []glob.Pattern{
+				{Raw: "config/*.yaml"},
+				{Raw: "**/*.go"},
+				{Raw: "config/local.yaml", Negated: true},
+			}
+			Expect(glob.Match(patterns, "config/app.yaml")).To(BeTrue())
+			Expect(glob.Match(patterns, "cmd/main.go")).To(BeTrue())
+			Expect(glob.Match(patterns, "config/local.yaml")).To(BeFalse())
+			Expect(glob.Match(patterns, "config/sub/app.yaml")).To(BeFalse())
+			Expect(glob.Match(patterns, "README.md")).To(BeFalse())
+		})
+	})
 })
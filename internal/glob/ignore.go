@@ -0,0 +1,188 @@
+package glob
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// IgnoreFiles are read in every directory, later files taking precedence.
+var IgnoreFiles = []string{".gitignore", ".ignore"}
+
+// Ignore matches paths against the IgnoreFiles under a root directory, with
+// gitignore semantics. The .git directory is always ignored. Files are read
+// once per directory, when first needed.
+type Ignore struct {
+	root  string
+	mu    sync.Mutex
+	rules map[string][]ignoreRule // by directory, relative to root
+}
+
+type ignoreRule struct {
+	pattern string // doublestar pattern, relative to the ignore file's directory
+	negated bool
+	dirOnly bool
+}
+
+// NewIgnore returns an Ignore for the ignore files under root.
+func NewIgnore(root string) *Ignore {
+	return &Ignore{root: root, rules: make(map[string][]ignoreRule)}
+}
+
+// Match reports whether the ignore files exclude rel (slash-separated,
+// relative to the root). Only rel itself is checked: callers walk down from
+// the root and don't descend into ignored directories.
+func (this *Ignore) Match(rel string, isDir bool) bool {
+	if isDir && path.Base(rel) == ".git" {
+		return true
+	}
+	ignored := false
+	dir := path.Dir(rel)
+	for _, d := range ancestors(dir) {
+		sub := rel
+		if d != "." {
+			sub = strings.TrimPrefix(rel, d+"/")
+		}
+		for _, r := range this.rulesFor(d) {
+			if r.dirOnly && !isDir {
+				continue
+			}
+			if ok, _ := doublestar.Match(r.pattern, sub); ok {
+				ignored = !r.negated
+			}
+		}
+	}
+	return ignored
+}
+
+// Skip reports whether rel is ignored and no include pattern names it
+// explicitly. A pattern names the directories leading to its literal prefix,
+// e.g. node_modules/foo/*.js names node_modules and node_modules/foo.
+func (this *Ignore) Skip(patterns []Pattern, rel string, isDir bool) bool {
+	for _, p := range patterns {
+		if !p.Negated && names(p.Raw, rel) {
+			return false
+		}
+	}
+	return this.Match(rel, isDir)
+}
+
+// names reports whether rel is the literal prefix of one of pattern's
+// brace expansions or one of the directories above it.
+func names(pattern, rel string) bool {
+	for _, raw := range ExpandBraces(pattern) {
+		base, _ := doublestar.SplitPattern(raw)
+		if !hasMeta(raw) {
+			base = raw
+		}
+		if base == rel || strings.HasPrefix(base, rel+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func hasMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[{\`)
+}
+
+// ancestors returns dir and the directories above it, root first.
+func ancestors(dir string) []string {
+	dirs := []string{"."}
+	if dir == "." {
+		return dirs
+	}
+	parts := strings.Split(dir, "/")
+	for i := range parts {
+		dirs = append(dirs, strings.Join(parts[:i+1], "/"))
+	}
+	return dirs
+}
+
+// rulesFor returns the rules of the ignore files in dir.
+func (this *Ignore) rulesFor(dir string) []ignoreRule {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	if rules, ok := this.rules[dir]; ok {
+		return rules
+	}
+	var rules []ignoreRule
+	for _, name := range IgnoreFiles {
+		rules = append(rules, readIgnoreFile(filepath.Join(this.root, dir, name))...)
+	}
+	this.rules[dir] = rules
+	return rules
+}
+
+// readIgnoreFile parses a gitignore-style file. A missing file has no rules.
+func readIgnoreFile(p string) []ignoreRule {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if r, ok := parseIgnoreLine(sc.Text()); ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// ReadExcludeFile reads a gitignore-style file as exclusions for a set of
+// watch patterns, relative to the file's directory: each line excludes the
+// paths it matches and everything under them. Exclusions always win, so
+// "!" lines can't re-include anything and are skipped. A missing file has
+// no patterns.
+func ReadExcludeFile(p string) []Pattern {
+	var patterns []Pattern
+	for _, r := range readIgnoreFile(p) {
+		if r.negated {
+			continue
+		}
+		if !r.dirOnly {
+			patterns = append(patterns, Pattern{Raw: r.pattern, Negated: true})
+		}
+		patterns = append(patterns, Pattern{Raw: r.pattern + "/**", Negated: true})
+	}
+	return patterns
+}
+
+// parseIgnoreLine converts one gitignore line to a rule. Patterns without a
+// slash (other than a trailing one) match at any depth.
+func parseIgnoreLine(line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || line[0] == '#' {
+		return ignoreRule{}, false
+	}
+	var r ignoreRule
+	if line[0] == '!' {
+		r.negated = true
+		line = line[1:]
+	} else if line[0] == '\\' && len(line) > 1 && (line[1] == '#' || line[1] == '!') {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+	if strings.Contains(line, "/") {
+		line = strings.TrimPrefix(line, "/")
+	} else {
+		line = "**/" + line
+	}
+	r.pattern = line
+	return r, true
+}
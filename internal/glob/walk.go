@@ -0,0 +1,96 @@
+package glob
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Option configures ExpandPatterns.
+type Option func(*options)
+
+type options struct {
+	followSymlinks bool
+}
+
+// FollowSymlinks makes ExpandPatterns descend into symlinked directories.
+// A link back to a directory the walk is already in is skipped, so cycles
+// don't recurse forever. By default only symlinks in a pattern's literal
+// prefix are followed.
+func FollowSymlinks() Option {
+	return func(o *options) {
+		o.followSymlinks = true
+	}
+}
+
+// walkFS is the file system patterns are expanded against. Its directory
+// listings hide ignored entries, so globbing doesn't descend into ignored
+// directories (entries the patterns name explicitly stay visible), excluded
+// directories, and symlinks to directories that would close a cycle.
+type walkFS struct {
+	fs.FS
+	root     string
+	ignore   *Ignore // nil: no ignore files
+	patterns []Pattern
+	excludes []Pattern // directories these cover entirely are hidden
+	follow   bool
+}
+
+func (this walkFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(this.FS, name)
+	if err != nil {
+		return nil, err
+	}
+	var chain []string // real paths of name and the directories above it
+	kept := entries[:0]
+	for _, e := range entries {
+		p := path.Join(name, e.Name())
+		if this.ignore != nil && this.ignore.Skip(this.patterns, p, e.IsDir()) {
+			continue
+		}
+		if e.IsDir() && excludesDir(this.excludes, p) {
+			continue
+		}
+		if this.follow && e.Type()&fs.ModeSymlink != 0 {
+			if chain == nil {
+				chain = this.realChain(name)
+			}
+			if closesCycle(filepath.Join(this.root, p), chain) {
+				continue
+			}
+		}
+		kept = append(kept, e)
+	}
+	return kept, nil
+}
+
+// realChain resolves name and each directory above it, up to the root.
+func (this walkFS) realChain(name string) []string {
+	chain := []string{}
+	for _, d := range ancestors(name) {
+		if real, err := filepath.EvalSymlinks(filepath.Join(this.root, d)); err == nil {
+			chain = append(chain, real)
+		}
+	}
+	return chain
+}
+
+// closesCycle reports whether link points to a directory in chain or above
+// one, which the walk would reach again by following it.
+func closesCycle(link string, chain []string) bool {
+	real, err := filepath.EvalSymlinks(link)
+	if err != nil {
+		return false
+	}
+	if info, err := os.Stat(real); err != nil || !info.IsDir() {
+		return false
+	}
+	for _, d := range chain {
+		if d == real || strings.HasPrefix(d, real+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package glob
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestExcludesDir(t *testing.T) {
+	excludes := []Pattern{
+		{Raw: "node_modules/**", Negated: true},
+		{Raw: "**/{gen,dist}/**", Negated: true},
+		{Raw: "vendor", Negated: true},
+		{Raw: "**/*_test.go", Negated: true},
+	}
+	for dir, want := range map[string]bool{
+		"node_modules":     true,
+		"web/node_modules": false,
+		"gen":              true,
+		"api/dist":         true,
+		"vendor":           false, // only the path itself is excluded
+		"src":              false,
+	} {
+		if got := excludesDir(excludes, dir); got != want {
+			t.Errorf("excludesDir(%q) = %v, want %v", dir, got, want)
+		}
+	}
+}
+
+func TestWalkSkipsExcludedDirectories(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{"src/a.js", "node_modules/lib/index.js", "gen/out.js"} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.Dir(f)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, f), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fsys := walkFS{FS: os.DirFS(root), root: root, excludes: []Pattern{
+		{Raw: "{node_modules,gen}/**", Negated: true},
+	}}
+	entries, err := fsys.ReadDir(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if !slices.Equal(names, []string{"src"}) {
+		t.Errorf("ReadDir(.) = %v, want [src]", names)
+	}
+}
@@ -2,24 +2,179 @@ package hasher
 
 import (
 	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
+	"hash"
+	"hash/crc32"
 	"io"
 	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
 )
 
-// HashFile computes the SHA-256 hash of the file at the given path
+// Algorithm names the hash function used to detect changed files.
+type Algorithm string
+
+const (
+	// CRC32C is the default: hardware-accelerated on amd64 and arm64, and
+	// many times faster than SHA256 on large files.
+	CRC32C Algorithm = "crc32c"
+	// SHA256 is the algorithm sum files without a header were written with.
+	SHA256 Algorithm = "sha256"
+)
+
+var (
+	algorithm  atomic.Value // Algorithm, set by SetAlgorithm
+	castagnoli = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// ParseAlgorithm checks name against the supported algorithms.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	switch a := Algorithm(name); a {
+	case CRC32C, SHA256:
+		return a, nil
+	}
+	return "", fmt.Errorf("unknown hash algorithm %q (want %s or %s)", name, CRC32C, SHA256)
+}
+
+// SetAlgorithm sets the algorithm for all hashing afterwards. For a -hash
+// CLI flag; call it before scanning.
+func SetAlgorithm(a Algorithm) {
+	algorithm.Store(a)
+}
+
+// Current returns the algorithm in use (default: CRC32C).
+func Current() Algorithm {
+	if a, ok := algorithm.Load().(Algorithm); ok {
+		return a
+	}
+	return CRC32C
+}
+
+func newHash() hash.Hash {
+	if Current() == SHA256 {
+		return sha256.New()
+	}
+	return crc32.New(castagnoli)
+}
+
+// SampleSize is how much of each end of a large file is hashed.
+const SampleSize = 64 << 10
+
+// Option configures HashFile and HashFiles.
+type Option func(*options)
+
+type options struct {
+	largeFile int64
+}
+
+// LargeFiles makes files of at least threshold bytes hash by their size,
+// mtime, and first and last SampleSize bytes instead of their whole
+// content, so big generated assets don't stall a scan. An edit in the
+// middle that keeps both size and mtime goes unnoticed. 0 hashes every
+// file in full.
+func LargeFiles(threshold int64) Option {
+	return func(o *options) {
+		o.largeFile = threshold
+	}
+}
+
+// HashFile hashes the file at the given path with the current algorithm
 // and returns the first 7 hex characters.
-func HashFile(path string) (string, error) {
+func HashFile(path string, opts ...Option) (string, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	f, err := os.Open(path)
 	if err != nil {
 		return "", fmt.Errorf("open %s: %w", path, err)
 	}
 	defer f.Close()
 
-	h := sha256.New()
-	if _, err := io.Copy(h, f); err != nil {
+	h := newHash()
+	if o.largeFile > 0 {
+		err = hashLarge(h, f, o.largeFile)
+	} else {
+		_, err = io.Copy(h, f)
+	}
+	if err != nil {
 		return "", fmt.Errorf("hash %s: %w", path, err)
 	}
 
 	return fmt.Sprintf("%x", h.Sum(nil))[:7], nil
 }
+
+// hashLarge writes f to h in full if it is smaller than threshold, and
+// otherwise its size, mtime and both ends.
+func hashLarge(h hash.Hash, f *os.File, threshold int64) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size()
+	if size < threshold {
+		_, err := io.Copy(h, f)
+		return err
+	}
+
+	binary.Write(h, binary.LittleEndian, [2]int64{size, info.ModTime().UnixNano()})
+	if _, err := io.CopyN(h, f, SampleSize); err != nil && err != io.EOF {
+		return err
+	}
+	if size > SampleSize {
+		if _, err := f.Seek(max(size-SampleSize, SampleSize), io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HashFiles hashes files (relative to rootDir) on GOMAXPROCS workers and
+// returns a map of relative path → hash. Files that can't be read, e.g.
+// because they were deleted meanwhile, are left out.
+func HashFiles(rootDir string, files []string, opts ...Option) map[string]string {
+	sums := make(map[string]string, len(files))
+	workers := min(runtime.GOMAXPROCS(0), len(files))
+	if workers <= 1 {
+		for _, f := range files {
+			if hash, err := HashFile(filepath.Join(rootDir, f), opts...); err == nil {
+				sums[f] = hash
+			}
+		}
+		return sums
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		jobs = make(chan string)
+	)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range jobs {
+				hash, err := HashFile(filepath.Join(rootDir, f), opts...)
+				if err != nil {
+					continue
+				}
+				mu.Lock()
+				sums[f] = hash
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, f := range files {
+		jobs <- f
+	}
+	close(jobs)
+	wg.Wait()
+	return sums
+}
@@ -1,8 +1,11 @@
 package hasher_test
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -60,4 +63,139 @@ var _ = Describe("Hasher", func() {
 			Expect(err).To(HaveOccurred())
 		})
 	})
+
+	Describe("algorithms", func() {
+		It("hashes with the algorithm set, CRC32C by default", func() {
+			path := filepath.Join(tmpDir, "test.go")
+			Expect(os.WriteFile(path, []byte("package main\n"), 0644)).To(Succeed())
+			Expect(hasher.Current()).To(Equal(hasher.CRC32C))
+			crc, err := hasher.HashFile(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			hasher.SetAlgorithm(hasher.SHA256)
+			DeferCleanup(hasher.SetAlgorithm, hasher.CRC32C)
+			sha, err := hasher.HashFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sha).To(Equal("df1d036")) // sha256sum of "package main\n"
+			Expect(sha).NotTo(Equal(crc))
+		})
+
+		It("parses algorithm names", func() {
+			a, err := hasher.ParseAlgorithm("sha256")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a).To(Equal(hasher.SHA256))
+			_, err = hasher.ParseAlgorithm("md5")
+			Expect(err).To(MatchError(ContainSubstring(`unknown hash algorithm "md5"`)))
+		})
+	})
+
+	Describe("LargeFiles", func() {
+		var path string
+
+		BeforeEach(func() {
+			path = filepath.Join(tmpDir, "asset.bin")
+			Expect(os.WriteFile(path, make([]byte, 4*hasher.SampleSize), 0644)).To(Succeed())
+		})
+
+		It("misses changes between the sampled ends of a large file", func() {
+			before, err := hasher.HashFile(path, hasher.LargeFiles(1<<10))
+			Expect(err).NotTo(HaveOccurred())
+			full, err := hasher.HashFile(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			mtime := time.Now().Add(-time.Hour)
+			content := make([]byte, 4*hasher.SampleSize)
+			content[2*hasher.SampleSize] = 1
+			Expect(os.WriteFile(path, content, 0644)).To(Succeed())
+			Expect(os.Chtimes(path, mtime, mtime)).To(Succeed())
+			after, err := hasher.HashFile(path, hasher.LargeFiles(1<<10))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(path, make([]byte, 4*hasher.SampleSize), 0644)).To(Succeed())
+			Expect(os.Chtimes(path, mtime, mtime)).To(Succeed())
+			same, err := hasher.HashFile(path, hasher.LargeFiles(1<<10))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(after).To(Equal(same))
+			Expect(before).NotTo(Equal(full))
+		})
+
+		It("sees changes to the ends, the size and the mtime", func() {
+			sample := func() string {
+				h, err := hasher.HashFile(path, hasher.LargeFiles(1<<10))
+				Expect(err).NotTo(HaveOccurred())
+				return h
+			}
+			seen := map[string]bool{sample(): true}
+
+			content := make([]byte, 4*hasher.SampleSize)
+			content[len(content)-1] = 1
+			Expect(os.WriteFile(path, content, 0644)).To(Succeed())
+			seen[sample()] = true
+
+			Expect(os.WriteFile(path, append(content, 0), 0644)).To(Succeed())
+			seen[sample()] = true
+
+			mtime := time.Now().Add(-time.Hour)
+			Expect(os.Chtimes(path, mtime, mtime)).To(Succeed())
+			seen[sample()] = true
+			Expect(seen).To(HaveLen(4))
+		})
+
+		It("hashes files below the threshold in full", func() {
+			full, err := hasher.HashFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			h, err := hasher.HashFile(path, hasher.LargeFiles(8*hasher.SampleSize))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(h).To(Equal(full))
+		})
+	})
+
+	Describe("HashFiles", func() {
+		It("hashes every readable file", func() {
+			var files []string
+			for i := range 50 {
+				name := fmt.Sprintf("f%d.go", i)
+				Expect(os.WriteFile(filepath.Join(tmpDir, name), []byte(name), 0644)).To(Succeed())
+				files = append(files, name)
+			}
+			files = append(files, "missing.go")
+
+			sums := hasher.HashFiles(tmpDir, files)
+			Expect(sums).To(HaveLen(50))
+			Expect(sums).NotTo(HaveKey("missing.go"))
+			want, err := hasher.HashFile(filepath.Join(tmpDir, "f7.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sums).To(HaveKeyWithValue("f7.go", want))
+		})
+	})
 })
+
+// BenchmarkHashFiles compares hashing a tree serially, as HashFile in a
+// loop, with HashFiles' worker pool.
+func BenchmarkHashFiles(b *testing.B) {
+	dir := b.TempDir()
+	content := make([]byte, 32<<10)
+	files := make([]string, 2000)
+	for i := range files {
+		files[i] = fmt.Sprintf("f%d.go", i)
+		content[0] = byte(i)
+		if err := os.WriteFile(filepath.Join(dir, files[i]), content, 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Run("serial", func(b *testing.B) {
+		for b.Loop() {
+			for _, f := range files {
+				if _, err := hasher.HashFile(filepath.Join(dir, f)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for b.Loop() {
+			hasher.HashFiles(dir, files)
+		}
+	})
+}
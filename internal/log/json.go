@@ -0,0 +1,32 @@
+package log
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// jsonLine is a record in FormatJSON.
+type jsonLine struct {
+	Time   time.Time `json:"time"`
+	Level  string    `json:"level"`
+	Prefix string    `json:"prefix"`
+	Target string    `json:"target"`
+	Event  string    `json:"event"`
+	Msg    string    `json:"msg"`
+}
+
+func jsonRecord(t time.Time, target, event, msg string) {
+	line, err := json.Marshal(jsonLine{
+		Time:   t,
+		Level:  levelOf(event).String(),
+		Prefix: "[" + target + "]",
+		Target: target,
+		Event:  event,
+		Msg:    msg,
+	})
+	if err != nil {
+		return
+	}
+	os.Stdout.Write(append(line, '\n'))
+}
@@ -0,0 +1,73 @@
+package log_test
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/internal/sumfile"
+)
+
+var _ = Describe("JSON format", func() {
+	// capture returns the JSON objects fn prints to stdout.
+	capture := func(fn func()) []map[string]any {
+		r, w, err := os.Pipe()
+		Expect(err).NotTo(HaveOccurred())
+		stdout := os.Stdout
+		os.Stdout = w
+		fn()
+		os.Stdout = stdout
+		w.Close()
+		out, err := io.ReadAll(r)
+		Expect(err).NotTo(HaveOccurred())
+
+		var lines []map[string]any
+		for _, line := range strings.Split(strings.TrimSuffix(string(out), "\n"), "\n") {
+			var m map[string]any
+			Expect(json.Unmarshal([]byte(line), &m)).To(Succeed(), line)
+			lines = append(lines, m)
+		}
+		return lines
+	}
+
+	BeforeEach(func() {
+		log.SetFormat(log.FormatJSON)
+		DeferCleanup(log.SetFormat, log.FormatText)
+	})
+
+	It("prints one JSON object per message", func() {
+		l := log.New("[api]", true)
+		lines := capture(func() {
+			l.Success("Build OK (%s)", "1.2s")
+			l.Error("exit status 1")
+			l.Verbose("step\t1")
+			l.Change(sumfile.ChangeSet{Added: []string{"new.go"}})
+		})
+
+		Expect(lines).To(HaveLen(4))
+		for _, line := range lines {
+			Expect(line).To(HaveKeyWithValue("prefix", "[api]"))
+			Expect(line).To(HaveKeyWithValue("target", "api"))
+			_, err := time.Parse(time.RFC3339Nano, line["time"].(string))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(lines[0]).To(And(HaveKeyWithValue("level", "info"), HaveKeyWithValue("event", "success"), HaveKeyWithValue("msg", "Build OK (1.2s)")))
+		Expect(lines[1]).To(And(HaveKeyWithValue("level", "error"), HaveKeyWithValue("msg", "exit status 1")))
+		Expect(lines[2]).To(And(HaveKeyWithValue("level", "debug"), HaveKeyWithValue("msg", "step\t1")))
+		Expect(lines[3]).To(And(HaveKeyWithValue("event", "added"), HaveKeyWithValue("msg", "new.go")))
+	})
+
+	It("parses format names", func() {
+		f, err := log.ParseFormat("json")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f).To(Equal(log.FormatJSON))
+		_, err = log.ParseFormat("xml")
+		Expect(err).To(MatchError(ContainSubstring(`unknown log format "xml"`)))
+	})
+})
@@ -0,0 +1,120 @@
+package log
+
+import (
+	"fmt"
+	"time"
+)
+
+// Level is the severity of a message.
+type Level int
+
+const (
+	LevelDebug Level = iota // verbose messages
+	LevelInfo               // status, success, heartbeats, and changes
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{LevelDebug: "debug", LevelInfo: "info", LevelWarn: "warn", LevelError: "error"}
+
+func (this Level) String() string { return levelNames[this] }
+
+// ParseLevel parses debug, info, warn, or error.
+func ParseLevel(name string) (Level, error) {
+	for l, n := range levelNames {
+		if n == name {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q (want debug, info, warn, or error)", name)
+}
+
+// minLevel is set by SetLevel.
+var minLevel = LevelInfo
+
+// SetLevel hides messages below l in every logger (default: info). Debug
+// shows verbose messages of every logger, as if each were verbose; warn
+// and error hide them even for verbose loggers.
+func SetLevel(l Level) {
+	minLevel = l
+}
+
+// Enabled reports whether messages at level l are printed. For callers
+// printing their own lines.
+func Enabled(l Level) bool {
+	return l >= minLevel
+}
+
+// quiet is set by SetQuiet.
+var quiet bool
+
+// SetQuiet hides heartbeats and status, change, and verbose messages in
+// every logger; errors, warnings, and results (Success) still print.
+func SetQuiet(q bool) {
+	quiet = q
+}
+
+// Quiet reports whether SetQuiet is on. For callers printing their own
+// status lines.
+func Quiet() bool {
+	return quiet
+}
+
+// quietHides reports whether a record event is hidden in quiet mode.
+func quietHides(event string) bool {
+	switch event {
+	case EventHeartbeat, EventStatus, EventVerbose, EventModified, EventAdded, EventRemoved:
+		return true
+	}
+	return false
+}
+
+// VerboseEnabled reports whether a logger with the given verbosity prints
+// verbose messages.
+func VerboseEnabled(verbose bool) bool {
+	return !quiet && (minLevel == LevelDebug || (verbose && minLevel == LevelInfo))
+}
+
+// levelOf returns the level of a record event.
+func levelOf(event string) Level {
+	switch event {
+	case EventError:
+		return LevelError
+	case EventWarn:
+		return LevelWarn
+	case EventVerbose:
+		return LevelDebug
+	}
+	return LevelInfo
+}
+
+// timeLayout is set by SetTimeFormat.
+var timeLayout string
+
+// timePresets name common SetTimeFormat layouts.
+var timePresets = map[string]string{
+	"time":    time.TimeOnly,
+	"ms":      "15:04:05.000",
+	"rfc3339": time.RFC3339,
+}
+
+// SetTimeFormat starts every text line with the local time: "time"
+// (15:04:05), "ms" (15:04:05.000), "rfc3339", or any Go time layout. ""
+// turns timestamps off (default). Porcelain and JSON records always carry
+// their time.
+func SetTimeFormat(format string) {
+	if layout, ok := timePresets[format]; ok {
+		format = layout
+	}
+	timeLayout = format
+}
+
+// Timestamp returns the current time in the SetTimeFormat layout followed
+// by a space, or "" when timestamps are off. For callers printing their
+// own lines.
+func Timestamp() string {
+	if timeLayout == "" {
+		return ""
+	}
+	return time.Now().Format(timeLayout) + " "
+}
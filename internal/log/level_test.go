@@ -0,0 +1,105 @@
+package log_test
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/internal/color"
+	"github.com/gur-shatz/go-run/internal/log"
+)
+
+var _ = Describe("Levels and timestamps", func() {
+	// capture returns the lines fn prints to stdout.
+	capture := func(fn func()) []string {
+		r, w, err := os.Pipe()
+		Expect(err).NotTo(HaveOccurred())
+		stdout := os.Stdout
+		os.Stdout = w
+		fn()
+		os.Stdout = stdout
+		w.Close()
+		out, err := io.ReadAll(r)
+		Expect(err).NotTo(HaveOccurred())
+		if len(out) == 0 {
+			return nil
+		}
+		return strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	}
+
+	BeforeEach(func() {
+		color.SetPlain(true)
+		DeferCleanup(color.SetPlain, false)
+		DeferCleanup(log.SetLevel, log.LevelInfo)
+		DeferCleanup(log.SetTimeFormat, "")
+	})
+
+	It("parses level names", func() {
+		for _, name := range []string{"debug", "info", "warn", "error"} {
+			l, err := log.ParseLevel(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(l.String()).To(Equal(name))
+		}
+		_, err := log.ParseLevel("trace")
+		Expect(err).To(MatchError(ContainSubstring("unknown log level")))
+	})
+
+	It("shows verbose messages of quiet loggers at debug", func() {
+		quiet := log.New("[api]", false)
+		Expect(capture(func() { quiet.Verbose("hidden") })).To(BeEmpty())
+
+		log.SetLevel(log.LevelDebug)
+		Expect(capture(func() { quiet.Verbose("shown") })).To(ConsistOf("[api] shown"))
+	})
+
+	It("hides messages below the level", func() {
+		log.SetLevel(log.LevelWarn)
+		l := log.New("[api]", true)
+		Expect(capture(func() {
+			l.Verbose("step")
+			l.Status("building")
+			l.Success("Build OK")
+			l.Warn("slow build")
+		})).To(ConsistOf("[api] [WARN] slow build"))
+	})
+
+	It("starts text lines with a timestamp", func() {
+		log.SetTimeFormat("ms")
+		l := log.New("[api]", false)
+		lines := capture(func() { l.Status("building") })
+		Expect(lines).To(HaveLen(1))
+		Expect(lines[0]).To(MatchRegexp(`^\d{2}:\d{2}:\d{2}\.\d{3} \[api\] building$`))
+
+		log.SetTimeFormat("2006")
+		Expect(log.Timestamp()).To(MatchRegexp(`^\d{4} $`))
+		log.SetTimeFormat("")
+		Expect(log.Timestamp()).To(BeEmpty())
+	})
+
+	It("keeps only errors, warnings, and results in quiet mode", func() {
+		log.SetQuiet(true)
+		DeferCleanup(log.SetQuiet, false)
+		l := log.New("[api]", true)
+		Expect(capture(func() {
+			l.Verbose("step")
+			l.Status("building")
+			l.Tick(true, true)
+			l.Success("Build OK")
+			l.Warn("slow build")
+		})).To(Equal([]string{"[api] [OK] Build OK", "[api] [WARN] slow build"}))
+	})
+
+	It("drops records below the level", func() {
+		log.SetFormat(log.FormatPorcelain)
+		DeferCleanup(log.SetFormat, log.FormatText)
+		log.SetLevel(log.LevelError)
+		lines := capture(func() {
+			log.Record("api", log.EventWarn, "slow")
+			log.Record("api", log.EventStatus, "building")
+		})
+		Expect(lines).To(BeEmpty())
+	})
+})
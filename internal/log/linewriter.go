@@ -0,0 +1,67 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// LineWriter writes each complete line written to it to an underlying
+// writer, starting with the timestamp, if on (see SetTimeFormat), and a
+// tag such as "[api:build]". A trailing partial line is held back until
+// its newline or Close.
+type LineWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	tag     string
+	partial []byte
+}
+
+// NewLineWriter returns a LineWriter that tags the lines it writes to w.
+func NewLineWriter(w io.Writer, tag string) *LineWriter {
+	return &LineWriter{w: w, tag: tag}
+}
+
+func (this *LineWriter) Write(p []byte) (int, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	this.partial = append(this.partial, p...)
+	var out []byte
+	for {
+		i := bytes.IndexByte(this.partial, '\n')
+		if i < 0 {
+			break
+		}
+		out = this.appendLine(out, this.partial[:i+1])
+		this.partial = this.partial[i+1:]
+	}
+	if len(out) > 0 {
+		if _, err := this.w.Write(out); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close writes the held-back partial line, if any, with a newline.
+func (this *LineWriter) Close() error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	if len(this.partial) == 0 {
+		return nil
+	}
+	out := this.appendLine(nil, append(this.partial, '\n'))
+	this.partial = nil
+	_, err := this.w.Write(out)
+	return err
+}
+
+// appendLine appends line, newline included, with its timestamp and tag.
+func (this *LineWriter) appendLine(out, line []byte) []byte {
+	out = append(out, Timestamp()...)
+	out = append(out, this.tag...)
+	out = append(out, ' ')
+	return append(out, line...)
+}
@@ -0,0 +1,34 @@
+package log_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/internal/log"
+)
+
+var _ = Describe("LineWriter", func() {
+	It("tags complete lines and holds back partial ones", func() {
+		var out bytes.Buffer
+		w := log.NewLineWriter(&out, "[api:build]")
+
+		Expect(w.Write([]byte("one\ntw"))).To(Equal(6))
+		Expect(out.String()).To(Equal("[api:build] one\n"))
+		Expect(w.Write([]byte("o\nthree"))).To(Equal(7))
+		Expect(out.String()).To(Equal("[api:build] one\n[api:build] two\n"))
+
+		Expect(w.Close()).To(Succeed())
+		Expect(out.String()).To(Equal("[api:build] one\n[api:build] two\n[api:build] three\n"))
+	})
+
+	It("starts lines with the timestamp", func() {
+		log.SetTimeFormat("2006")
+		DeferCleanup(log.SetTimeFormat, "")
+		var out bytes.Buffer
+		w := log.NewLineWriter(&out, "[api]")
+		w.Write([]byte("ready\n"))
+		Expect(out.String()).To(MatchRegexp(`^\d{4} \[api\] ready\n$`))
+	})
+})
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gur-shatz/go-run/internal/color"
 	"github.com/gur-shatz/go-run/internal/sumfile"
+	"github.com/gur-shatz/go-run/pkg/messages"
 )
 
 // Logger is an instance-based logger with its own prefix and verbosity.
@@ -14,6 +15,20 @@ type Logger struct {
 	verbose bool
 }
 
+// redact is applied to every message before it is printed.
+var redact = func(s string) string { return s }
+
+// SetRedactor installs a function applied to every log message before it is
+// printed (e.g. to mask secrets). Affects all loggers.
+func SetRedactor(fn func(string) string) {
+	redact = fn
+}
+
+// head returns the start of a text line: the timestamp, if on, and prefix.
+func (this *Logger) head() string {
+	return Timestamp() + this.prefix
+}
+
 // New creates a new Logger with the given prefix and verbosity.
 func New(prefix string, verbose bool) *Logger {
 	return &Logger{prefix: prefix, verbose: verbose}
@@ -21,39 +36,94 @@ func New(prefix string, verbose bool) *Logger {
 
 // Error prints a red error message to stderr.
 func (this *Logger) Error(format string, args ...any) {
-	msg := fmt.Sprintf(format, args...)
-	fmt.Fprintf(os.Stderr, "%s %s %s\n", this.prefix, color.Red("Error:"), msg)
+	if !Enabled(LevelError) {
+		return
+	}
+	if Structured() {
+		this.record(EventError, fmt.Sprintf(format, args...))
+		return
+	}
+	msg := redact(fmt.Sprintf(format, args...))
+	if color.Plain() {
+		fmt.Fprintf(os.Stderr, "%s [FAIL] %s %s\n", this.head(), messages.Get(messages.ErrorLabel), msg)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s %s %s\n", this.head(), color.Stderr().Red(messages.Get(messages.ErrorLabel)), msg)
 }
 
 // Warn prints a yellow warning message to stdout.
 func (this *Logger) Warn(format string, args ...any) {
-	msg := fmt.Sprintf(format, args...)
-	fmt.Println(this.prefix + " " + color.Yellow(msg))
+	if !Enabled(LevelWarn) {
+		return
+	}
+	if Structured() {
+		this.record(EventWarn, fmt.Sprintf(format, args...))
+		return
+	}
+	msg := redact(fmt.Sprintf(format, args...))
+	if color.Plain() {
+		msg = "[WARN] " + msg
+	}
+	fmt.Println(this.head() + " " + color.Yellow(msg))
 }
 
 // Success prints a green success message to stdout.
 func (this *Logger) Success(format string, args ...any) {
-	msg := fmt.Sprintf(format, args...)
-	fmt.Println(this.prefix + " " + color.Green(msg))
+	if !Enabled(LevelInfo) {
+		return
+	}
+	if Structured() {
+		this.record(EventSuccess, fmt.Sprintf(format, args...))
+		return
+	}
+	msg := redact(fmt.Sprintf(format, args...))
+	if color.Plain() {
+		msg = "[OK] " + msg
+	}
+	fmt.Println(this.head() + " " + color.Green(msg))
 }
 
 // Status prints a bold status message to stdout.
 func (this *Logger) Status(format string, args ...any) {
-	msg := fmt.Sprintf(format, args...)
-	fmt.Println(color.Bold(this.prefix + " " + msg))
+	if !Enabled(LevelInfo) || quiet {
+		return
+	}
+	if Structured() {
+		this.record(EventStatus, fmt.Sprintf(format, args...))
+		return
+	}
+	msg := redact(fmt.Sprintf(format, args...))
+	fmt.Println(color.Bold(this.head() + " " + msg))
 }
 
-// Verbose prints a dim message to stdout, only if verbose mode is enabled.
+// Verbose prints a dim message to stdout, only if verbose mode is enabled
+// or the level is debug (see SetLevel).
 func (this *Logger) Verbose(format string, args ...any) {
-	if !this.verbose {
+	if !VerboseEnabled(this.verbose) {
+		return
+	}
+	if Structured() {
+		this.record(EventVerbose, fmt.Sprintf(format, args...))
 		return
 	}
-	msg := fmt.Sprintf(format, args...)
-	fmt.Println(color.Dim(this.prefix + " " + msg))
+	msg := redact(fmt.Sprintf(format, args...))
+	fmt.Println(color.Dim(this.head() + " " + msg))
 }
 
 // Tick prints a heartbeat dot — green if ok, red if not. No newline.
+// In plain mode it prints a line such as "[OK] STATE=running" instead.
 func (this *Logger) Tick(buildOK, execOK bool) {
+	if !Enabled(LevelInfo) || quiet {
+		return
+	}
+	if Structured() {
+		this.record(EventHeartbeat, porcelainTick(buildOK, execOK))
+		return
+	}
+	if color.Plain() {
+		fmt.Println(this.head() + " " + PlainTick(buildOK, execOK))
+		return
+	}
 	if buildOK {
 		fmt.Print(color.Green("."))
 	} else {
@@ -65,17 +135,36 @@ func (this *Logger) Tick(buildOK, execOK bool) {
 	os.Stdout.Sync()
 }
 
+// PlainTick returns the plain-mode text of a heartbeat tick.
+func PlainTick(buildOK, execOK bool) string {
+	marker, state := "[OK]", "running"
+	if !buildOK {
+		marker = "[FAIL]"
+	}
+	if !execOK {
+		state = "stopped"
+	}
+	return marker + " STATE=" + state
+}
+
 // Change prints a changeset with a cyan header and dim file paths.
 func (this *Logger) Change(changes sumfile.ChangeSet) {
-	fmt.Println(this.prefix + " " + color.Cyan("Changes detected:"))
+	if !Enabled(LevelInfo) || quiet {
+		return
+	}
+	if Structured() {
+		this.recordChange(changes)
+		return
+	}
+	fmt.Println(this.head() + " " + color.Cyan(messages.Get(messages.ChangesDetected)))
 	for _, f := range changes.Modified {
-		fmt.Println(color.Dim("  modified: " + f))
+		fmt.Println(color.Dim(messages.Sprintf(messages.ChangeModified, f)))
 	}
 	for _, f := range changes.Added {
-		fmt.Println(color.Dim("  added:    " + f))
+		fmt.Println(color.Dim(messages.Sprintf(messages.ChangeAdded, f)))
 	}
 	for _, f := range changes.Removed {
-		fmt.Println(color.Dim("  removed:  " + f))
+		fmt.Println(color.Dim(messages.Sprintf(messages.ChangeRemoved, f)))
 	}
 }
 
@@ -0,0 +1,118 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gur-shatz/go-run/internal/sumfile"
+)
+
+// Porcelain events. Records and their events are stable across minor
+// versions; new events may be added.
+const (
+	EventError     = "error"
+	EventWarn      = "warn"
+	EventSuccess   = "success"
+	EventStatus    = "status"
+	EventVerbose   = "verbose"
+	EventHeartbeat = "heartbeat" // detail: build=ok|fail run=running|stopped, or runctl's ok|pending|fail build=N run=N test=N
+	EventModified  = "modified"  // detail: path of a changed file
+	EventAdded     = "added"
+	EventRemoved   = "removed"
+)
+
+// Format is how loggers print messages.
+type Format string
+
+const (
+	FormatText      Format = "text"      // colored text for people (default)
+	FormatPorcelain Format = "porcelain" // "time<TAB>target<TAB>event<TAB>detail" records
+	FormatJSON      Format = "json"      // one JSON object per message
+)
+
+// format is set by SetFormat.
+var format = FormatText
+
+// ParseFormat checks name against the supported formats.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(name); f {
+	case FormatText, FormatPorcelain, FormatJSON:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown log format %q (want %s, %s, or %s)", name, FormatText, FormatPorcelain, FormatJSON)
+}
+
+// SetFormat switches every logger to f. Porcelain and JSON print one line
+// per message on stdout, for scripts and log pipelines.
+func SetFormat(f Format) {
+	format = f
+}
+
+// SetPorcelain is SetFormat(FormatPorcelain), or FormatText when off.
+func SetPorcelain(on bool) {
+	if on {
+		SetFormat(FormatPorcelain)
+	} else {
+		SetFormat(FormatText)
+	}
+}
+
+// Structured reports whether messages are printed as records, porcelain
+// or JSON, rather than text; callers printing their own lines should use
+// Record then.
+func Structured() bool { return format != FormatText }
+
+// Record prints a record in the current format, unless its level is
+// hidden (see SetLevel) or it is hidden in quiet mode (see SetQuiet);
+// callers decide about verbose records. Porcelain time is UTC RFC
+// 3339 with milliseconds; tabs, newlines, and backslashes in target and
+// detail are escaped as \t, \n, and \\.
+func Record(target, event, detail string) {
+	if !Enabled(levelOf(event)) && event != EventVerbose || quiet && quietHides(event) {
+		return
+	}
+	now := time.Now().UTC()
+	if format == FormatJSON {
+		jsonRecord(now, target, event, redact(detail))
+		return
+	}
+	fmt.Printf("%s\t%s\t%s\t%s\n", now.Format("2006-01-02T15:04:05.000Z"), escapeRecord(target), event, escapeRecord(redact(detail)))
+}
+
+var recordEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func escapeRecord(s string) string {
+	return recordEscaper.Replace(s)
+}
+
+// record prints a record for this logger; the target is its
+// prefix without brackets, e.g. api for [api].
+func (this *Logger) record(event, detail string) {
+	Record(strings.Trim(this.prefix, "[]"), event, detail)
+}
+
+// recordChange prints a record per changed file.
+func (this *Logger) recordChange(changes sumfile.ChangeSet) {
+	for _, f := range changes.Modified {
+		this.record(EventModified, f)
+	}
+	for _, f := range changes.Added {
+		this.record(EventAdded, f)
+	}
+	for _, f := range changes.Removed {
+		this.record(EventRemoved, f)
+	}
+}
+
+// porcelainTick returns the detail of a heartbeat record.
+func porcelainTick(buildOK, execOK bool) string {
+	build, run := "ok", "running"
+	if !buildOK {
+		build = "fail"
+	}
+	if !execOK {
+		run = "stopped"
+	}
+	return "build=" + build + " run=" + run
+}
@@ -0,0 +1,70 @@
+package log_test
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/internal/sumfile"
+)
+
+var _ = Describe("Porcelain", func() {
+	// capture returns what fn prints to stdout, split into records.
+	capture := func(fn func()) [][]string {
+		r, w, err := os.Pipe()
+		Expect(err).NotTo(HaveOccurred())
+		stdout := os.Stdout
+		os.Stdout = w
+		fn()
+		os.Stdout = stdout
+		w.Close()
+		out, err := io.ReadAll(r)
+		Expect(err).NotTo(HaveOccurred())
+
+		var records [][]string
+		for _, line := range strings.Split(strings.TrimSuffix(string(out), "\n"), "\n") {
+			records = append(records, strings.Split(line, "\t"))
+		}
+		return records
+	}
+
+	BeforeEach(func() {
+		log.SetPorcelain(true)
+		DeferCleanup(log.SetPorcelain, false)
+	})
+
+	It("prints one tab-separated record per message", func() {
+		l := log.New("[api]", true)
+		records := capture(func() {
+			l.Success("Build OK (%s)", "1.2s")
+			l.Error("exit status 1")
+			l.Verbose("step\t1\ndone")
+			l.Tick(false, true)
+			l.Change(sumfile.ChangeSet{Modified: []string{"main.go"}, Removed: []string{"old.go"}})
+		})
+
+		Expect(records).To(HaveLen(6))
+		for _, rec := range records {
+			Expect(rec).To(HaveLen(4))
+			Expect(rec[0]).To(MatchRegexp(`^\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{3}Z$`))
+			Expect(rec[1]).To(Equal("api"))
+		}
+		Expect(records[0][2:]).To(Equal([]string{"success", "Build OK (1.2s)"}))
+		Expect(records[1][2:]).To(Equal([]string{"error", "exit status 1"}))
+		Expect(records[2][2:]).To(Equal([]string{"verbose", `step\t1\ndone`}))
+		Expect(records[3][2:]).To(Equal([]string{"heartbeat", "build=fail run=running"}))
+		Expect(records[4][2:]).To(Equal([]string{"modified", "main.go"}))
+		Expect(records[5][2:]).To(Equal([]string{"removed", "old.go"}))
+	})
+
+	It("redacts details", func() {
+		log.SetRedactor(func(s string) string { return strings.ReplaceAll(s, "hunter2", "***") })
+		DeferCleanup(log.SetRedactor, func(s string) string { return s })
+		records := capture(func() { log.Record("runctl", log.EventWarn, "password hunter2") })
+		Expect(records[0][1:]).To(Equal([]string{"runctl", "warn", "password ***"}))
+	})
+})
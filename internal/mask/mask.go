@@ -0,0 +1,231 @@
+package mask
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Placeholder replaces masked values.
+const Placeholder = "***"
+
+// minSecretLen is the shortest value that gets masked inside free text.
+// Shorter values (e.g. "1", "on") would redact unrelated output.
+const minSecretLen = 4
+
+// sensitiveKeys are substrings that mark an env var as sensitive.
+var sensitiveKeys = []string{"SECRET", "PASSWORD", "TOKEN", "KEY", "CREDENTIAL"}
+
+// IsSensitive returns true if the env var name looks like it holds a secret.
+func IsSensitive(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, k := range sensitiveKeys {
+		if strings.Contains(upper, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns Placeholder if name is sensitive, otherwise value unchanged.
+func Value(name, value string) string {
+	if IsSensitive(name) {
+		return Placeholder
+	}
+	return value
+}
+
+// EnvEntry masks a single "KEY=VALUE" entry.
+func EnvEntry(entry string) string {
+	k, v, ok := strings.Cut(entry, "=")
+	if !ok {
+		return entry
+	}
+	return k + "=" + Value(k, v)
+}
+
+// registry holds values registered as secret process-wide, longest first.
+var registry struct {
+	sync.RWMutex
+	values []string
+}
+
+// Register marks values as secret wherever they show up, e.g. the contents
+// of a secretFile or a runctl secrets: entry. String and every Masker,
+// including a nil one, redact them from then on.
+func Register(values ...string) {
+	registry.Lock()
+	defer registry.Unlock()
+	// Copy on write: readers iterate the old slice without holding the lock.
+	all := slices.Clone(registry.values)
+	for _, v := range values {
+		if len(v) >= minSecretLen && !slices.Contains(all, v) {
+			all = append(all, v)
+		}
+	}
+	sortLongestFirst(all)
+	registry.values = all
+}
+
+func registered() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.values
+}
+
+func sortLongestFirst(values []string) {
+	sort.SliceStable(values, func(i, j int) bool {
+		return len(values[i]) > len(values[j])
+	})
+}
+
+// String replaces the values of sensitive env vars and registered secrets
+// found in s with Placeholder. env is a list of "KEY=VALUE" entries; if nil, os.Environ()
+// is used.
+func String(s string, env []string) string {
+	if env == nil {
+		env = os.Environ()
+	}
+	for _, e := range env {
+		k, v, ok := strings.Cut(e, "=")
+		if !ok || len(v) < minSecretLen || !IsSensitive(k) {
+			continue
+		}
+		s = strings.ReplaceAll(s, v, Placeholder)
+	}
+	for _, v := range registered() {
+		s = strings.ReplaceAll(s, v, Placeholder)
+	}
+	return s
+}
+
+// Masker redacts the values of a configured set of env vars from text.
+// A nil *Masker is valid and redacts nothing.
+type Masker struct {
+	secrets []string // longest first, so overlapping values mask fully
+}
+
+// New returns a Masker for the vars whose names fully match any of
+// patterns. Each pattern is a var name or a regular expression. Values are
+// looked up in envs in order; a name present in several maps contributes
+// every distinct value.
+func New(patterns []string, envs ...map[string]string) (*Masker, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile("^(?:" + p + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("mask pattern %q: %w", p, err)
+		}
+		res = append(res, re)
+	}
+
+	seen := make(map[string]bool)
+	m := &Masker{}
+	for _, env := range envs {
+		for k, v := range env {
+			if len(v) < minSecretLen || seen[v] {
+				continue
+			}
+			for _, re := range res {
+				if re.MatchString(k) {
+					seen[v] = true
+					m.secrets = append(m.secrets, v)
+					break
+				}
+			}
+		}
+	}
+	sortLongestFirst(m.secrets)
+	return m, nil
+}
+
+// values returns the masker's secrets plus the registered ones.
+func (this *Masker) values() []string {
+	reg := registered()
+	if this == nil || len(this.secrets) == 0 {
+		return reg
+	}
+	if len(reg) == 0 {
+		return this.secrets
+	}
+	all := append(slices.Clone(this.secrets), reg...)
+	sortLongestFirst(all)
+	return all
+}
+
+// String replaces every secret value in s with Placeholder.
+func (this *Masker) String(s string) string {
+	for _, v := range this.values() {
+		s = strings.ReplaceAll(s, v, Placeholder)
+	}
+	return s
+}
+
+// Writer wraps w so that secret values are redacted from everything
+// written through it. If there is nothing to mask yet, w is returned as is.
+func (this *Masker) Writer(w io.Writer) io.Writer {
+	if len(this.values()) == 0 {
+		return w
+	}
+	return &Writer{m: this, w: w}
+}
+
+// Writer is a redacting io.Writer. A secret split across two Write calls
+// is still masked: the tail of each write that could start a secret is held
+// back until the next write or Close.
+type Writer struct {
+	m       *Masker
+	w       io.Writer
+	mu      sync.Mutex
+	pending []byte
+}
+
+// Write redacts p and writes it to the underlying writer.
+func (this *Writer) Write(p []byte) (int, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	buf := this.m.String(string(this.pending) + string(p))
+	hold := this.partialSecretSuffix(buf)
+	this.pending = append(this.pending[:0], buf[len(buf)-hold:]...)
+	if _, err := io.WriteString(this.w, buf[:len(buf)-hold]); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close flushes any held-back bytes. The underlying writer is not closed.
+func (this *Writer) Close() error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	if len(this.pending) == 0 {
+		return nil
+	}
+	_, err := this.w.Write(this.pending)
+	this.pending = nil
+	return err
+}
+
+// partialSecretSuffix returns the length of the longest suffix of s that is
+// a proper prefix of some secret.
+func (this *Writer) partialSecretSuffix(s string) int {
+	best := 0
+	for _, v := range this.m.values() {
+		for n := min(len(v)-1, len(s)); n > best; n-- {
+			if strings.HasSuffix(s, v[:n]) {
+				best = n
+				break
+			}
+		}
+	}
+	return best
+}
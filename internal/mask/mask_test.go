@@ -0,0 +1,99 @@
+package mask_test
+
+import (
+	"bytes"
+	"io"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/internal/mask"
+)
+
+var _ = Describe("Mask", func() {
+	Describe("IsSensitive", func() {
+		It("matches sensitive substrings case-insensitively", func() {
+			Expect(mask.IsSensitive("GITHUB_TOKEN")).To(BeTrue())
+			Expect(mask.IsSensitive("db_password")).To(BeTrue())
+			Expect(mask.IsSensitive("API_KEY")).To(BeTrue())
+			Expect(mask.IsSensitive("PORT")).To(BeFalse())
+		})
+	})
+
+	Describe("EnvEntry", func() {
+		It("masks sensitive values", func() {
+			Expect(mask.EnvEntry("API_TOKEN=abc123")).To(Equal("API_TOKEN=***"))
+		})
+
+		It("leaves other values unchanged", func() {
+			Expect(mask.EnvEntry("PORT=8080")).To(Equal("PORT=8080"))
+		})
+	})
+
+	Describe("String", func() {
+		It("replaces sensitive values found in the text", func() {
+			env := []string{"API_TOKEN=s3cr3t-value", "PORT=8080"}
+			out := mask.String("./bin/app --token s3cr3t-value --port 8080", env)
+			Expect(out).To(Equal("./bin/app --token *** --port 8080"))
+		})
+
+		It("ignores very short values", func() {
+			env := []string{"DEBUG_KEY=1"}
+			Expect(mask.String("./bin/app -v 1", env)).To(Equal("./bin/app -v 1"))
+		})
+	})
+
+	Describe("Masker", func() {
+		env := map[string]string{"API_TOKEN": "tok-123456", "DB_PASS": "hunter22", "PORT": "8080"}
+
+		It("masks values of vars matched by name or regex", func() {
+			m, err := mask.New([]string{"API_TOKEN", "DB_.*"}, env)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.String("token=tok-123456 pass=hunter22 port=8080")).To(Equal("token=*** pass=*** port=8080"))
+		})
+
+		It("requires patterns to match the whole name", func() {
+			m, err := mask.New([]string{"TOKEN"}, env)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.String("tok-123456")).To(Equal("tok-123456"))
+		})
+
+		It("returns an error for an invalid regex", func() {
+			_, err := mask.New([]string{"("}, env)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("is a no-op when nil and nothing is registered", func() {
+			var m *mask.Masker
+			Expect(m.String("tok-123456")).To(Equal("tok-123456"))
+		})
+
+		It("masks registered secrets, even when nil", func() {
+			mask.Register("reg-secret-1", "ab")
+			var m *mask.Masker
+			Expect(m.String("x reg-secret-1 ab")).To(Equal("x *** ab"))
+			Expect(mask.String("x reg-secret-1", []string{})).To(Equal("x ***"))
+
+			var buf bytes.Buffer
+			w := m.Writer(&buf)
+			_, err := w.Write([]byte("reg-secret-1\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.String()).To(Equal("***\n"))
+		})
+
+		It("masks secrets split across writes", func() {
+			m, err := mask.New([]string{"API_TOKEN"}, env)
+			Expect(err).NotTo(HaveOccurred())
+
+			var buf bytes.Buffer
+			w := m.Writer(&buf)
+			_, err = w.Write([]byte("auth tok-12"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.String()).To(Equal("auth "))
+			_, err = w.Write([]byte("3456 done\nto"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(w.(io.Closer).Close()).To(Succeed())
+			Expect(buf.String()).To(Equal("auth *** done\nto"))
+		})
+	})
+})
@@ -0,0 +1,59 @@
+// Package procprio lowers the CPU and I/O priority of process groups, so
+// heavy build steps yield to the rest of the machine.
+package procprio
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// MaxNice is the lowest CPU priority.
+const MaxNice = 19
+
+// I/O scheduling classes, as numbered by the Linux kernel.
+const (
+	ClassBestEffort = 2
+	ClassIdle       = 3
+)
+
+// IOPriority is an I/O scheduling class and, for best-effort, a level from
+// 0 (highest) to 7 (lowest).
+type IOPriority struct {
+	Class int
+	Level int
+}
+
+// ParseIOPriority parses "idle", "best-effort", or "best-effort:N". A bare
+// "best-effort" means level 7. Realtime is not offered: it needs root and
+// is the opposite of throttling.
+func ParseIOPriority(s string) (IOPriority, error) {
+	class, level, hasLevel := strings.Cut(strings.TrimSpace(s), ":")
+	switch class {
+	case "idle":
+		if hasLevel {
+			return IOPriority{}, fmt.Errorf("idle takes no level, got %q", s)
+		}
+		return IOPriority{Class: ClassIdle}, nil
+	case "best-effort":
+		if !hasLevel {
+			return IOPriority{Class: ClassBestEffort, Level: 7}, nil
+		}
+		n, err := strconv.Atoi(level)
+		if err != nil || n < 0 || n > 7 {
+			return IOPriority{}, fmt.Errorf("best-effort level must be 0-7, got %q", level)
+		}
+		return IOPriority{Class: ClassBestEffort, Level: n}, nil
+	}
+	return IOPriority{}, fmt.Errorf("must be idle, best-effort, or best-effort:0-7, got %q", s)
+}
+
+// SetNice sets the nice value of every process in group pgid. Processes
+// the group starts later inherit it.
+func SetNice(pgid, nice int) error {
+	if err := syscall.Setpriority(syscall.PRIO_PGRP, pgid, nice); err != nil {
+		return fmt.Errorf("setpriority: %w", err)
+	}
+	return nil
+}
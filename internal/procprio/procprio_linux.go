@@ -0,0 +1,20 @@
+package procprio
+
+import (
+	"fmt"
+	"syscall"
+)
+
+const (
+	ioprioWhoPgrp    = 2
+	ioprioClassShift = 13
+)
+
+// SetIOPriority sets the I/O priority of every process in group pgid.
+func SetIOPriority(pgid int, p IOPriority) error {
+	prio := p.Class<<ioprioClassShift | p.Level
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioprioWhoPgrp, uintptr(pgid), uintptr(prio)); errno != 0 {
+		return fmt.Errorf("ioprio_set: %w", errno)
+	}
+	return nil
+}
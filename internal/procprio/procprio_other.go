@@ -0,0 +1,8 @@
+//go:build !linux
+
+package procprio
+
+// SetIOPriority is a no-op: I/O priorities are Linux-only.
+func SetIOPriority(pgid int, p IOPriority) error {
+	return nil
+}
@@ -0,0 +1,13 @@
+package procprio_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestProcprio(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Procprio Suite")
+}
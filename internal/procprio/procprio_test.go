@@ -0,0 +1,54 @@
+package procprio_test
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/internal/procprio"
+)
+
+var _ = Describe("Procprio", func() {
+	Describe("ParseIOPriority", func() {
+		It("parses classes and levels", func() {
+			Expect(procprio.ParseIOPriority("idle")).To(Equal(procprio.IOPriority{Class: procprio.ClassIdle}))
+			Expect(procprio.ParseIOPriority("best-effort")).To(Equal(procprio.IOPriority{Class: procprio.ClassBestEffort, Level: 7}))
+			Expect(procprio.ParseIOPriority("best-effort:3")).To(Equal(procprio.IOPriority{Class: procprio.ClassBestEffort, Level: 3}))
+		})
+
+		It("rejects garbage", func() {
+			for _, s := range []string{"", "realtime", "best-effort:8", "best-effort:x", "idle:1"} {
+				_, err := procprio.ParseIOPriority(s)
+				Expect(err).To(HaveOccurred(), s)
+			}
+		})
+	})
+
+	It("lowers the priority of a process group", func() {
+		if runtime.GOOS != "linux" {
+			Skip("reads /proc")
+		}
+		cmd := exec.Command("sleep", "30")
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		Expect(cmd.Start()).To(Succeed())
+		DeferCleanup(func() {
+			cmd.Process.Kill()
+			cmd.Wait()
+		})
+
+		Expect(procprio.SetNice(cmd.Process.Pid, 10)).To(Succeed())
+		Expect(procprio.SetIOPriority(cmd.Process.Pid, procprio.IOPriority{Class: procprio.ClassIdle})).To(Succeed())
+
+		stat, err := os.ReadFile("/proc/" + strconv.Itoa(cmd.Process.Pid) + "/stat")
+		Expect(err).NotTo(HaveOccurred())
+		// Fields after the ")" that closes the command name; nice is field 19.
+		fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+2:]))
+		Expect(fields[16]).To(Equal("10"))
+	})
+})
@@ -0,0 +1,9 @@
+// Package procstat samples the CPU time and memory of process groups.
+package procstat
+
+// Usage is the combined resource use of a process group at one moment.
+type Usage struct {
+	Processes  int     // live processes in the group
+	CPUSeconds float64 // user + system CPU time used so far
+	RSSBytes   uint64  // resident memory
+}
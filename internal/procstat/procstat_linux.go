@@ -0,0 +1,82 @@
+package procstat
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// clockTicks is USER_HZ, the unit of utime and stime in /proc/<pid>/stat.
+// It is 100 on every mainstream Linux build.
+const clockTicks = 100
+
+// Sample sums the usage of every process in group pgid from /proc.
+func Sample(pgid int) (Usage, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return Usage{}, err
+	}
+	var u Usage
+	var ticks uint64
+	page := uint64(os.Getpagesize())
+	for _, e := range entries {
+		if _, err := strconv.Atoi(e.Name()); err != nil {
+			continue
+		}
+		data, err := os.ReadFile("/proc/" + e.Name() + "/stat")
+		if err != nil {
+			continue // exited since ReadDir
+		}
+		st, err := parseStat(string(data))
+		if err != nil || st.pgrp != pgid {
+			continue
+		}
+		u.Processes++
+		ticks += st.utime + st.stime
+		u.RSSBytes += st.rss * page
+	}
+	if u.Processes == 0 {
+		return Usage{}, fmt.Errorf("process group %d not found", pgid)
+	}
+	u.CPUSeconds = float64(ticks) / clockTicks
+	return u, nil
+}
+
+type stat struct {
+	pgrp         int
+	utime, stime uint64
+	rss          uint64 // pages
+}
+
+// parseStat reads the fields we need from a /proc/<pid>/stat line. The
+// command name is in parentheses and may contain spaces, so fields are
+// counted from the last ")".
+func parseStat(line string) (stat, error) {
+	i := strings.LastIndexByte(line, ')')
+	if i < 0 {
+		return stat{}, fmt.Errorf("malformed stat")
+	}
+	// Fields after ")" start at field 3 (state).
+	f := strings.Fields(line[i+1:])
+	if len(f) < 22 {
+		return stat{}, fmt.Errorf("malformed stat")
+	}
+	var (
+		st  stat
+		err error
+	)
+	if st.pgrp, err = strconv.Atoi(f[2]); err != nil {
+		return stat{}, err
+	}
+	if st.utime, err = strconv.ParseUint(f[11], 10, 64); err != nil {
+		return stat{}, err
+	}
+	if st.stime, err = strconv.ParseUint(f[12], 10, 64); err != nil {
+		return stat{}, err
+	}
+	if st.rss, err = strconv.ParseUint(f[21], 10, 64); err != nil {
+		return stat{}, err
+	}
+	return st, nil
+}
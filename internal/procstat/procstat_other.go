@@ -0,0 +1,10 @@
+//go:build !linux
+
+package procstat
+
+import "errors"
+
+// Sample is not implemented outside Linux.
+func Sample(pgid int) (Usage, error) {
+	return Usage{}, errors.ErrUnsupported
+}
@@ -0,0 +1,13 @@
+package procstat_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestProcstat(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Procstat Suite")
+}
@@ -0,0 +1,44 @@
+package procstat_test
+
+import (
+	"os/exec"
+	"runtime"
+	"syscall"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/internal/procstat"
+)
+
+var _ = Describe("Sample", func() {
+	BeforeEach(func() {
+		if runtime.GOOS != "linux" {
+			Skip("reads /proc")
+		}
+	})
+
+	It("sums every process in the group", func() {
+		cmd := exec.Command("sh", "-c", "sleep 30 & sleep 30 & wait")
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		Expect(cmd.Start()).To(Succeed())
+		DeferCleanup(func() {
+			syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+			cmd.Wait()
+		})
+
+		Eventually(func() int {
+			u, _ := procstat.Sample(cmd.Process.Pid)
+			return u.Processes
+		}).Should(Equal(3))
+		u, err := procstat.Sample(cmd.Process.Pid)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(u.RSSBytes).To(BeNumerically(">", 0))
+		Expect(u.CPUSeconds).To(BeNumerically(">=", 0))
+	})
+
+	It("fails for a group that does not exist", func() {
+		_, err := procstat.Sample(1 << 30)
+		Expect(err).To(MatchError(ContainSubstring("not found")))
+	})
+})
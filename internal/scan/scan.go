@@ -2,44 +2,103 @@ package scan
 
 import (
 	"fmt"
+	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gur-shatz/go-run/internal/glob"
 	"github.com/gur-shatz/go-run/internal/hasher"
+	"github.com/gur-shatz/go-run/internal/sumfile"
 )
 
 // ParseWatchPatterns converts string patterns to glob.Pattern slice.
 // Patterns prefixed with "!" are treated as negation (exclusion) patterns.
-func ParseWatchPatterns(watch []string) []glob.Pattern {
+// A pattern ending in "/", like "migrations/", stands for everything under
+// that directory ("migrations/**"). Given rootDir, so does a pattern
+// without glob characters that names a directory there, like "migrations".
+func ParseWatchPatterns(watch []string, rootDir ...string) []glob.Pattern {
+	root := ""
+	if len(rootDir) > 0 {
+		root = rootDir[0]
+	}
 	patterns := make([]glob.Pattern, 0, len(watch))
 	for _, w := range watch {
+		p := glob.Pattern{Raw: w}
 		if len(w) > 0 && w[0] == '!' {
-			patterns = append(patterns, glob.Pattern{Raw: w[1:], Negated: true})
-		} else {
-			patterns = append(patterns, glob.Pattern{Raw: w})
+			p = glob.Pattern{Raw: w[1:], Negated: true}
+		}
+		if isDir(root, p.Raw) {
+			p.Raw = path.Join(p.Raw, "**")
 		}
+		patterns = append(patterns, p)
 	}
 	return patterns
 }
 
-// ScanFiles expands watch patterns and hashes all matching files.
-// Returns a map of relative path → hash.
-func ScanFiles(rootDir string, patterns []glob.Pattern) (map[string]string, error) {
-	files, err := glob.ExpandPatterns(rootDir, patterns)
+// isDir reports whether pattern is directory shorthand: it ends in "/", or
+// root is set and it is a plain path naming a directory under root.
+func isDir(root, pattern string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		return true
+	}
+	if root == "" || pattern == "" || strings.ContainsAny(pattern, `*?[{\`) {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(root, pattern))
+	return err == nil && info.IsDir()
+}
+
+// ScanFiles expands watch patterns and hashes all matching files in
+// parallel with hashOpts. Returns a map of relative path → hash.
+func ScanFiles(rootDir string, patterns []glob.Pattern, hashOpts []hasher.Option, opts ...glob.Option) (map[string]string, error) {
+	files, err := glob.ExpandPatterns(rootDir, patterns, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return hasher.HashFiles(rootDir, files, hashOpts...), nil
+}
+
+// ScanFilesCached is ScanFiles for a cold start: files whose size and mtime
+// match prev (as read by sumfile.ReadStats) keep their recorded hash, and
+// only the rest are hashed. The result is ready for sumfile.WriteStats.
+func ScanFilesCached(rootDir string, patterns []glob.Pattern, prev map[string]sumfile.Stat, hashOpts []hasher.Option, opts ...glob.Option) (map[string]sumfile.Stat, error) {
+	files, err := glob.ExpandPatterns(rootDir, patterns, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	sums := make(map[string]string, len(files))
+	stats := make(map[string]sumfile.Stat, len(files))
+	var stale []string
 	for _, f := range files {
-		hash, err := hasher.HashFile(filepath.Join(rootDir, f))
+		// Stat before hashing: a write in between changes the mtime, so
+		// the next scan hashes the file again.
+		info, err := os.Stat(filepath.Join(rootDir, f))
 		if err != nil {
 			continue
 		}
-		sums[f] = hash
+		st := sumfile.Stat{Size: info.Size(), ModTime: info.ModTime()}
+		if p, ok := prev[f]; ok && p.Matches(info) {
+			st.Hash = p.Hash
+		} else {
+			stale = append(stale, f)
+		}
+		stats[f] = st
+	}
+
+	hashes := hasher.HashFiles(rootDir, stale, hashOpts...)
+	for _, f := range stale {
+		hash, ok := hashes[f]
+		if !ok {
+			delete(stats, f)
+			continue
+		}
+		st := stats[f]
+		st.Hash = hash
+		stats[f] = st
 	}
-	return sums, nil
+	return stats, nil
 }
 
 // FormatDuration formats a duration as seconds with one decimal place.
@@ -0,0 +1,85 @@
+package scan_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/internal/glob"
+	"github.com/gur-shatz/go-run/internal/hasher"
+	"github.com/gur-shatz/go-run/internal/scan"
+	"github.com/gur-shatz/go-run/internal/sumfile"
+)
+
+var _ = Describe("ScanFilesCached", func() {
+	var (
+		tmpDir   string
+		patterns = []glob.Pattern{{Raw: "*.go"}}
+		mtime    = time.Unix(1700000000, 0)
+	)
+
+	BeforeEach(func() {
+		tmpDir = GinkgoT().TempDir()
+		for _, name := range []string{"a.go", "b.go"} {
+			path := filepath.Join(tmpDir, name)
+			Expect(os.WriteFile(path, []byte("package "+name[:1]+"\n"), 0644)).To(Succeed())
+			Expect(os.Chtimes(path, mtime, mtime)).To(Succeed())
+		}
+	})
+
+	It("hashes every file without a previous stat", func() {
+		stats, err := scan.ScanFilesCached(tmpDir, patterns, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		want, err := hasher.HashFile(filepath.Join(tmpDir, "a.go"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats).To(HaveLen(2))
+		Expect(stats["a.go"].Hash).To(Equal(want))
+		Expect(stats["a.go"].ModTime.Equal(mtime)).To(BeTrue())
+		Expect(stats["a.go"].Size).To(BeEquivalentTo(10))
+	})
+
+	It("trusts the recorded hash while size and mtime are unchanged", func() {
+		prev := map[string]sumfile.Stat{
+			"a.go": {Hash: "cached1", Size: 10, ModTime: mtime},
+			"b.go": {Hash: "cached2", Size: 10, ModTime: mtime.Add(time.Second)},
+		}
+
+		stats, err := scan.ScanFilesCached(tmpDir, patterns, prev, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats["a.go"].Hash).To(Equal("cached1"))
+		Expect(stats["b.go"].Hash).NotTo(Equal("cached2"))
+	})
+})
+
+var _ = Describe("ParseWatchPatterns", func() {
+	It("splits off exclusions", func() {
+		Expect(scan.ParseWatchPatterns([]string{"**/*.go", "!vendor/**"})).To(Equal([]glob.Pattern{
+			{Raw: "**/*.go"},
+			{Raw: "vendor/**", Negated: true},
+		}))
+	})
+
+	It("expands directory shorthand", func() {
+		root := GinkgoT().TempDir()
+		Expect(os.MkdirAll(filepath.Join(root, "migrations"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(root, "go.mod"), nil, 0644)).To(Succeed())
+
+		watch := []string{"migrations/", "!gen/", "migrations", "go.mod", "missing", "cmd/*"}
+		Expect(scan.ParseWatchPatterns(watch, root)).To(Equal([]glob.Pattern{
+			{Raw: "migrations/**"},
+			{Raw: "gen/**", Negated: true},
+			{Raw: "migrations/**"},
+			{Raw: "go.mod"},
+			{Raw: "missing"},
+			{Raw: "cmd/*"},
+		}))
+		// Without a root only a trailing slash marks a directory.
+		Expect(scan.ParseWatchPatterns([]string{"migrations", "migrations/"})).To(Equal([]glob.Pattern{
+			{Raw: "migrations"},
+			{Raw: "migrations/**"},
+		}))
+	})
+})
@@ -0,0 +1,337 @@
+// Package schema validates YAML config documents against JSON Schema.
+//
+// Only the keywords the embedded config schemas use are supported: type,
+// properties, additionalProperties, items, enum, required, minimum, maximum,
+// and $ref into $defs. Other keywords (description, default, ...) are
+// ignored. Types are checked the way yaml.v3 decodes: a null is accepted
+// for any type, a string accepts any scalar, and yes/no/on/off are booleans.
+// ValidateStrict drops all of that leniency except for nulls.
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema is a compiled JSON Schema.
+type Schema struct {
+	Type                 typeList           `json:"type"`
+	Properties           map[string]*Schema `json:"properties"`
+	AdditionalProperties *additional        `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	Enum                 []any              `json:"enum"`
+	Required             []string           `json:"required"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	Ref                  string             `json:"$ref"`
+	Defs                 map[string]*Schema `json:"$defs"`
+
+	root *Schema
+}
+
+// typeList is "type" as either a single name or a list of names.
+type typeList []string
+
+func (this *typeList) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*this = typeList{one}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(this))
+}
+
+// additional is "additionalProperties" as either a bool or a schema.
+type additional struct {
+	allowed bool
+	schema  *Schema
+}
+
+func (this *additional) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &this.allowed); err == nil {
+		return nil
+	}
+	this.allowed = true
+	return json.Unmarshal(data, &this.schema)
+}
+
+// Compile parses a JSON Schema document.
+func Compile(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse schema: %w", err)
+	}
+	s.link(&s)
+	return &s, nil
+}
+
+// MustCompile is like Compile but panics on error. For embedded schemas.
+func MustCompile(data []byte) *Schema {
+	s, err := Compile(data)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// link points every subschema at the root so $refs can be resolved.
+func (this *Schema) link(root *Schema) {
+	if this == nil {
+		return
+	}
+	this.root = root
+	for _, p := range this.Properties {
+		p.link(root)
+	}
+	for _, d := range this.Defs {
+		d.link(root)
+	}
+	if this.AdditionalProperties != nil {
+		this.AdditionalProperties.schema.link(root)
+	}
+	this.Items.link(root)
+}
+
+// Validate checks a YAML document against the schema and returns every
+// violation, one per line, each naming the offending key path. Syntax
+// errors are left to the YAML decoder that runs next.
+func (this *Schema) Validate(data []byte) error {
+	return this.validateDoc(data, false)
+}
+
+// ValidateStrict is like Validate, but values must have exactly the
+// schema's type: no numbers or booleans where a string goes, no yes/no
+// booleans, no 8080.0 integers.
+func (this *Schema) ValidateStrict(data []byte) error {
+	return this.validateDoc(data, true)
+}
+
+func (this *Schema) validateDoc(data []byte, strict bool) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	var errs []error
+	this.validate(doc.Content[0], "", strict, &errs)
+	return errors.Join(errs...)
+}
+
+func (this *Schema) validate(node *yaml.Node, path string, strict bool, errs *[]error) {
+	s := this.resolve()
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(t string) bool { return matchesType(node, t, strict) }) {
+		*errs = append(*errs, fmt.Errorf("%s must be %s, got %s", display(path), article(s.Type), describe(node)))
+		return
+	}
+	if len(s.Enum) > 0 && node.Kind == yaml.ScalarNode && !s.enumHas(node.Value) {
+		opts := make([]string, len(s.Enum))
+		for i, e := range s.Enum {
+			opts[i] = fmt.Sprint(e)
+		}
+		*errs = append(*errs, fmt.Errorf("%s must be one of %s, got %q", display(path), strings.Join(opts, ", "), node.Value))
+	}
+	if node.Kind == yaml.ScalarNode && (s.Minimum != nil || s.Maximum != nil) {
+		if n, err := strconv.ParseFloat(node.Value, 64); err == nil {
+			if s.Minimum != nil && n < *s.Minimum {
+				*errs = append(*errs, fmt.Errorf("%s must be at least %v, got %s", display(path), *s.Minimum, node.Value))
+			}
+			if s.Maximum != nil && n > *s.Maximum {
+				*errs = append(*errs, fmt.Errorf("%s must be at most %v, got %s", display(path), *s.Maximum, node.Value))
+			}
+		}
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		s.validateMapping(node, path, strict, errs)
+	case yaml.SequenceNode:
+		if s.Items != nil {
+			for i, item := range node.Content {
+				s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), strict, errs)
+			}
+		}
+	}
+}
+
+func (this *Schema) validateMapping(node *yaml.Node, path string, strict bool, errs *[]error) {
+	seen := make(map[string]bool, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, val := node.Content[i].Value, node.Content[i+1]
+		seen[key] = true
+		child := join(path, key)
+		if p, ok := this.Properties[key]; ok {
+			p.validate(val, child, strict, errs)
+			continue
+		}
+		ap := this.AdditionalProperties
+		switch {
+		case ap == nil || ap.allowed && ap.schema == nil:
+			// any key goes
+		case ap.schema != nil:
+			ap.schema.validate(val, child, strict, errs)
+		default:
+			msg := child + " is not a known field"
+			if s := suggest(key, this.Properties); s != "" {
+				msg += ", did you mean " + s + "?"
+			}
+			*errs = append(*errs, errors.New(msg))
+		}
+	}
+	for _, r := range this.Required {
+		if !seen[r] {
+			*errs = append(*errs, fmt.Errorf("%s is required", join(path, r)))
+		}
+	}
+}
+
+// resolve follows $ref to a schema under the root's $defs.
+func (this *Schema) resolve() *Schema {
+	s := this
+	for s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, "#/$defs/")
+		def := s.root.Defs[name]
+		if !ok || def == nil {
+			panic(fmt.Sprintf("schema: unresolvable $ref %q", s.Ref))
+		}
+		s = def
+	}
+	return s
+}
+
+func (this *Schema) enumHas(v string) bool {
+	for _, e := range this.Enum {
+		if fmt.Sprint(e) == v {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesType reports whether node can be decoded as JSON Schema type t.
+// In strict mode the node's own YAML type has to match.
+func matchesType(node *yaml.Node, t string, strict bool) bool {
+	switch t {
+	case "object":
+		return node.Kind == yaml.MappingNode
+	case "array":
+		return node.Kind == yaml.SequenceNode
+	case "string":
+		return node.Kind == yaml.ScalarNode && (!strict || node.Tag == "!!str")
+	case "integer":
+		if !strict && node.Kind == yaml.ScalarNode && node.Tag == "!!float" {
+			f, err := strconv.ParseFloat(node.Value, 64)
+			return err == nil && f == math.Trunc(f)
+		}
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!int"
+	case "number":
+		return node.Kind == yaml.ScalarNode && (node.Tag == "!!int" || node.Tag == "!!float")
+	case "boolean":
+		if !strict && node.Kind == yaml.ScalarNode && node.Tag == "!!str" && node.Style == 0 {
+			// yaml.v3 still decodes YAML 1.1 booleans into bool fields
+			switch strings.ToLower(node.Value) {
+			case "yes", "no", "on", "off", "y", "n":
+				return true
+			}
+		}
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!bool"
+	case "null":
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+	}
+	return false
+}
+
+// describe names what a node actually is, for type errors.
+func describe(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	switch node.Tag {
+	case "!!int":
+		return "integer " + node.Value
+	case "!!float":
+		return "number " + node.Value
+	case "!!bool":
+		return "boolean " + node.Value
+	}
+	return strconv.Quote(node.Value)
+}
+
+// article renders a type list as prose: "an integer", "a string or a list".
+func article(types []string) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		switch t {
+		case "object":
+			names[i] = "a mapping"
+		case "array":
+			names[i] = "a list"
+		case "integer":
+			names[i] = "an integer"
+		default:
+			names[i] = "a " + t
+		}
+	}
+	return strings.Join(names, " or ")
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func display(path string) string {
+	if path == "" {
+		return "the document"
+	}
+	return path
+}
+
+// suggest returns the known property closest to key, if it is close enough
+// to be a likely typo.
+func suggest(key string, props map[string]*Schema) string {
+	best, bestDist := "", len(key)/2+1
+	for p := range props {
+		if d := distance(key, p); d < bestDist || d == bestDist && best != "" && p < best {
+			best, bestDist = p, d
+		}
+	}
+	return best
+}
+
+// distance is the Levenshtein edit distance between a and b.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,92 @@
+package schema_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/internal/schema"
+)
+
+var _ = Describe("Schema", func() {
+	s := schema.MustCompile([]byte(`{
+		"type": "object",
+		"additionalProperties": false,
+		"properties": {
+			"port": {"type": "integer", "minimum": 1},
+			"mode": {"type": "string", "enum": ["dev", "prod"]},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"targets": {"type": "object", "additionalProperties": {"$ref": "#/$defs/target"}}
+		},
+		"$defs": {
+			"target": {
+				"type": "object",
+				"additionalProperties": false,
+				"required": ["cmd"],
+				"properties": {
+					"cmd": {"type": "string"},
+					"enabled": {"type": "boolean"}
+				}
+			}
+		}
+	}`))
+
+	It("accepts a valid document", func() {
+		Expect(s.Validate([]byte(`
+port: 8080
+mode: dev
+tags: [a, 1]
+targets:
+  api: {cmd: ./api, enabled: true}
+  web: {cmd: ./web, enabled: off}
+`))).To(Succeed())
+	})
+
+	It("accepts nulls and empty documents", func() {
+		Expect(s.Validate([]byte("port:\ntags:\n"))).To(Succeed())
+		Expect(s.Validate(nil)).To(Succeed())
+	})
+
+	It("suggests the closest known field for a typo", func() {
+		err := s.Validate([]byte("targets:\n  api:\n    cmd: x\n    enabld: true\n"))
+		Expect(err).To(MatchError("targets.api.enabld is not a known field, did you mean enabled?"))
+	})
+
+	It("does not suggest unrelated fields", func() {
+		err := s.Validate([]byte("colour: red\n"))
+		Expect(err).To(MatchError("colour is not a known field"))
+	})
+
+	It("reports type, enum, range, and required violations with their paths", func() {
+		err := s.Validate([]byte(`
+port: "80"
+mode: staging
+tags: [[nested]]
+targets:
+  web: {enabled: yes}
+`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(`port must be an integer, got "80"`))
+		Expect(err.Error()).To(ContainSubstring(`mode must be one of dev, prod, got "staging"`))
+		Expect(err.Error()).To(ContainSubstring(`tags[0] must be a string, got a list`))
+		Expect(err.Error()).To(ContainSubstring(`targets.web.cmd is required`))
+	})
+
+	It("checks minimum", func() {
+		Expect(s.Validate([]byte("port: 0\n"))).To(MatchError("port must be at least 1, got 0"))
+	})
+
+	It("rejects loosely typed values in strict mode", func() {
+		doc := []byte("port: 8080.0\ntags: [a, 1]\ntargets:\n  web: {cmd: ./web, enabled: off}\n")
+		Expect(s.Validate(doc)).To(Succeed())
+		err := s.ValidateStrict(doc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("port must be an integer, got number 8080.0"))
+		Expect(err.Error()).To(ContainSubstring("tags[1] must be a string, got integer 1"))
+		Expect(err.Error()).To(ContainSubstring(`targets.web.enabled must be a boolean, got "off"`))
+		Expect(s.ValidateStrict([]byte("port:\ntags: [a, '1']\n"))).To(Succeed())
+	})
+
+	It("leaves syntax errors to the decoder", func() {
+		Expect(s.Validate([]byte("port: [\n"))).To(Succeed())
+	})
+})
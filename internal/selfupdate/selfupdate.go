@@ -0,0 +1,227 @@
+// Package selfupdate replaces a running binary with the matching asset of
+// the latest GitHub release.
+//
+// Releases carry one raw binary per platform, named <binary>_<os>_<arch>,
+// and a checksums.txt in `sha256sum` format covering them (see `make
+// release`).
+package selfupdate
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ChecksumsAsset is the release asset listing the SHA-256 of every binary.
+const ChecksumsAsset = "checksums.txt"
+
+// Updater fetches releases of one binary from a GitHub repo.
+type Updater struct {
+	Binary     string       // e.g. "runctl"
+	APIURL     string       // latest-release endpoint
+	HTTPClient *http.Client // defaults to a client with a 30s timeout
+}
+
+// New returns an Updater for binary in the GitHub repo owner/name.
+func New(repo, binary string) *Updater {
+	return &Updater{
+		Binary: binary,
+		APIURL: "https://api.github.com/repos/" + repo + "/releases/latest",
+	}
+}
+
+// Release is the subset of a GitHub release we use.
+type Release struct {
+	Tag    string            // e.g. "v1.4.0"
+	Assets map[string]string // asset name -> download URL
+}
+
+// AssetName is the release asset for the running platform.
+func (this *Updater) AssetName() string {
+	return fmt.Sprintf("%s_%s_%s", this.Binary, runtime.GOOS, runtime.GOARCH)
+}
+
+func (this *Updater) client() *http.Client {
+	if this.HTTPClient != nil {
+		return this.HTTPClient
+	}
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+// Latest fetches the latest release.
+func (this *Updater) Latest(ctx context.Context) (*Release, error) {
+	body, err := this.get(ctx, this.APIURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetch latest release: %w", err)
+	}
+	defer body.Close()
+
+	var payload struct {
+		TagName string `json:"tag_name"`
+		Assets  []struct {
+			Name string `json:"name"`
+			URL  string `json:"browser_download_url"`
+		} `json:"assets"`
+	}
+	if err := json.NewDecoder(body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("parse latest release: %w", err)
+	}
+	rel := &Release{Tag: payload.TagName, Assets: make(map[string]string, len(payload.Assets))}
+	for _, a := range payload.Assets {
+		rel.Assets[a.Name] = a.URL
+	}
+	return rel, nil
+}
+
+// Apply downloads the release's binary for this platform, checks it against
+// checksums.txt, and atomically replaces exe with it.
+func (this *Updater) Apply(ctx context.Context, rel *Release, exe string) error {
+	name := this.AssetName()
+	binURL, ok := rel.Assets[name]
+	if !ok {
+		return fmt.Errorf("release %s has no %s binary", rel.Tag, name)
+	}
+	sumsURL, ok := rel.Assets[ChecksumsAsset]
+	if !ok {
+		return fmt.Errorf("release %s has no %s", rel.Tag, ChecksumsAsset)
+	}
+
+	want, err := this.checksum(ctx, sumsURL, name)
+	if err != nil {
+		return err
+	}
+
+	// Download next to exe so the final rename stays on one filesystem.
+	tmp, err := os.CreateTemp(filepath.Dir(exe), "."+filepath.Base(exe)+".new-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	body, err := this.get(ctx, binURL)
+	if err != nil {
+		return fmt.Errorf("download %s: %w", name, err)
+	}
+	defer body.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, h), body); err != nil {
+		return fmt.Errorf("download %s: %w", name, err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", name, got, want)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", tmp.Name(), err)
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return fmt.Errorf("chmod %s: %w", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		return fmt.Errorf("replace %s: %w", exe, err)
+	}
+	return nil
+}
+
+// checksum returns the expected hex SHA-256 of asset from checksums.txt.
+func (this *Updater) checksum(ctx context.Context, url, asset string) (string, error) {
+	body, err := this.get(ctx, url)
+	if err != nil {
+		return "", fmt.Errorf("download %s: %w", ChecksumsAsset, err)
+	}
+	defer body.Close()
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == asset {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("read %s: %w", ChecksumsAsset, err)
+	}
+	return "", fmt.Errorf("%s has no entry for %s", ChecksumsAsset, asset)
+}
+
+func (this *Updater) get(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := this.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// Newer reports whether version latest is newer than current. Versions look
+// like v1.2.3; anything after a '-' is ignored. Unparsable versions (such
+// as "dev" builds) are never older than anything.
+func Newer(latest, current string) bool {
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	return false
+}
+
+func parseVersion(v string) ([3]int, bool) {
+	var out [3]int
+	v = strings.TrimPrefix(v, "v")
+	v, _, _ = strings.Cut(v, "-")
+	parts := strings.Split(v, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return out, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return out, false
+		}
+		out[i] = n
+	}
+	return out, true
+}
+
+// CheckInterval is how often the background update check runs.
+const CheckInterval = 24 * time.Hour
+
+// Due reports whether the last check recorded in stampFile is older than
+// CheckInterval. When it is, the current time is recorded so concurrent and
+// later runs skip the check.
+func Due(stampFile string) bool {
+	if info, err := os.Stat(stampFile); err == nil && time.Since(info.ModTime()) < CheckInterval {
+		return false
+	}
+	if err := os.MkdirAll(filepath.Dir(stampFile), 0755); err != nil {
+		return false
+	}
+	return os.WriteFile(stampFile, []byte(time.Now().UTC().Format(time.RFC3339)+"\n"), 0644) == nil
+}
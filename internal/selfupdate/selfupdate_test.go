@@ -0,0 +1,110 @@
+package selfupdate_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/internal/selfupdate"
+)
+
+var _ = Describe("Selfupdate", func() {
+	Describe("Newer", func() {
+		It("compares release versions", func() {
+			Expect(selfupdate.Newer("v1.3.0", "v1.2.9")).To(BeTrue())
+			Expect(selfupdate.Newer("v1.10.0", "v1.9.0")).To(BeTrue())
+			Expect(selfupdate.Newer("v1.2.0", "v1.2.0")).To(BeFalse())
+			Expect(selfupdate.Newer("v1.2.0", "v1.3.0")).To(BeFalse())
+			Expect(selfupdate.Newer("v1.3.0", "v1.2.0-4-gabcdef-dirty")).To(BeTrue())
+		})
+
+		It("never treats unparsable versions as outdated", func() {
+			Expect(selfupdate.Newer("v9.0.0", "dev")).To(BeFalse())
+			Expect(selfupdate.Newer("nightly", "v1.0.0")).To(BeFalse())
+		})
+	})
+
+	Describe("Apply", func() {
+		var (
+			server   *httptest.Server
+			u        *selfupdate.Updater
+			exe      string
+			binary   = []byte("#!/bin/sh\necho new\n")
+			checksum string
+		)
+
+		BeforeEach(func() {
+			u = selfupdate.New("example/repo", "runctl")
+			sum := sha256.Sum256(binary)
+			checksum = hex.EncodeToString(sum[:])
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/latest", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `{"tag_name": "v2.0.0", "assets": [
+					{"name": %q, "browser_download_url": "%s/bin"},
+					{"name": "checksums.txt", "browser_download_url": "%s/sums"}
+				]}`, u.AssetName(), server.URL, server.URL)
+			})
+			mux.HandleFunc("/bin", func(w http.ResponseWriter, r *http.Request) {
+				w.Write(binary)
+			})
+			mux.HandleFunc("/sums", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, "%s  other_binary\n%s  %s\n", checksum, checksum, u.AssetName())
+			})
+			server = httptest.NewServer(mux)
+			DeferCleanup(server.Close)
+			u.APIURL = server.URL + "/latest"
+
+			exe = filepath.Join(GinkgoT().TempDir(), "runctl")
+			Expect(os.WriteFile(exe, []byte("old"), 0755)).To(Succeed())
+		})
+
+		It("replaces the binary with the verified release asset", func() {
+			rel, err := u.Latest(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rel.Tag).To(Equal("v2.0.0"))
+
+			Expect(u.Apply(context.Background(), rel, exe)).To(Succeed())
+			Expect(os.ReadFile(exe)).To(Equal(binary))
+			info, err := os.Stat(exe)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0755)))
+		})
+
+		It("keeps the old binary when the checksum doesn't match", func() {
+			checksum = hex.EncodeToString(make([]byte, 32))
+			rel, err := u.Latest(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(u.Apply(context.Background(), rel, exe)).To(MatchError(ContainSubstring("checksum mismatch")))
+			Expect(os.ReadFile(exe)).To(Equal([]byte("old")))
+			Expect(filepath.Glob(filepath.Join(filepath.Dir(exe), ".runctl.new-*"))).To(BeEmpty())
+		})
+
+		It("fails when the release has no binary for this platform", func() {
+			rel := &selfupdate.Release{Tag: "v2.0.0", Assets: map[string]string{}}
+			Expect(u.Apply(context.Background(), rel, exe)).To(MatchError(ContainSubstring("has no " + u.AssetName())))
+		})
+	})
+
+	Describe("Due", func() {
+		It("is due once per interval", func() {
+			stamp := filepath.Join(GinkgoT().TempDir(), "runctl", "update-check")
+			Expect(selfupdate.Due(stamp)).To(BeTrue())
+			Expect(selfupdate.Due(stamp)).To(BeFalse())
+
+			old := time.Now().Add(-selfupdate.CheckInterval - time.Minute)
+			Expect(os.Chtimes(stamp, old, old)).To(Succeed())
+			Expect(selfupdate.Due(stamp)).To(BeTrue())
+		})
+	})
+})
@@ -3,9 +3,15 @@ package sumfile
 import (
 	"bufio"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/gur-shatz/go-run/internal/hasher"
 )
 
 // Entry represents a single file and its hash in the sum file.
@@ -14,6 +20,29 @@ type Entry struct {
 	Hash string
 }
 
+// Stat is a file's hash with the size and mtime the file had when it was
+// hashed, so later scans can trust the hash while both are unchanged.
+type Stat struct {
+	Hash    string
+	Size    int64
+	ModTime time.Time // zero when unknown
+}
+
+// Matches reports whether info has the size and mtime the hash was
+// computed at.
+func (this Stat) Matches(info os.FileInfo) bool {
+	return !this.ModTime.IsZero() && this.Size == info.Size() && this.ModTime.Equal(info.ModTime())
+}
+
+// Hashes returns the path->hash map of stats.
+func Hashes(stats map[string]Stat) map[string]string {
+	sums := make(map[string]string, len(stats))
+	for p, st := range stats {
+		sums[p] = st.Hash
+	}
+	return sums
+}
+
 // ChangeSet describes the differences between two sum files.
 type ChangeSet struct {
 	Added    []string
@@ -26,18 +55,71 @@ func (this *ChangeSet) IsEmpty() bool {
 	return len(this.Added) == 0 && len(this.Modified) == 0 && len(this.Removed) == 0
 }
 
-// Read parses a sum file from disk into a map of path->hash.
+// Merge combines two changesets. A file listed in both keeps the first
+// category it appears in (added, then modified, then removed).
+func Merge(a, b *ChangeSet) *ChangeSet {
+	seen := make(map[string]bool)
+	result := &ChangeSet{}
+
+	for _, f := range append(a.Added, b.Added...) {
+		if !seen[f] {
+			result.Added = append(result.Added, f)
+			seen[f] = true
+		}
+	}
+	for _, f := range append(a.Modified, b.Modified...) {
+		if !seen[f] {
+			result.Modified = append(result.Modified, f)
+			seen[f] = true
+		}
+	}
+	for _, f := range append(a.Removed, b.Removed...) {
+		if !seen[f] {
+			result.Removed = append(result.Removed, f)
+			seen[f] = true
+		}
+	}
+	return result
+}
+
+// headerKey starts the header line recording the hash algorithm, e.g.
+// "# hash crc32c". Sum files without one were hashed with SHA-256.
+const headerKey = "hash"
+
+// Read parses a sum file from disk into a map of path->hash, whatever
+// algorithm it was hashed with.
 func Read(path string) (map[string]string, error) {
+	stats, _, err := read(path)
+	if stats == nil {
+		return nil, err
+	}
+	return Hashes(stats), nil
+}
+
+// ReadStats parses a sum file from disk into a map of path->Stat. Lines
+// written by Write, without size and mtime, have a zero ModTime. A file
+// hashed with another algorithm than hasher.Current reads as missing, since
+// its hashes can't be compared.
+func ReadStats(path string) (map[string]Stat, error) {
+	stats, alg, err := read(path)
+	if alg != hasher.Current() {
+		return nil, err
+	}
+	return stats, err
+}
+
+func read(path string) (map[string]Stat, hasher.Algorithm, error) {
+	alg := hasher.SHA256
 	f, err := os.Open(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, nil
+			return nil, alg, nil
 		}
-		return nil, fmt.Errorf("open sum file: %w", err)
+		return nil, alg, fmt.Errorf("open sum file: %w", err)
 	}
 	defer f.Close()
 
-	entries := make(map[string]string)
+	entries := make(map[string]Stat)
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -45,18 +127,30 @@ func Read(path string) (map[string]string, error) {
 			continue
 		}
 		parts := strings.Fields(line)
-		if len(parts) != 2 {
-			continue
+		switch len(parts) {
+		case 3: // never an entry
+			if parts[0] == "#" && parts[1] == headerKey {
+				alg = hasher.Algorithm(parts[2])
+			}
+		case 2:
+			entries[parts[0]] = Stat{Hash: parts[1]}
+		case 4:
+			size, err1 := strconv.ParseInt(parts[2], 10, 64)
+			mtime, err2 := strconv.ParseInt(parts[3], 10, 64)
+			if err1 != nil || err2 != nil || size < 0 || mtime == 0 {
+				continue
+			}
+			entries[parts[0]] = Stat{Hash: parts[1], Size: size, ModTime: time.Unix(0, mtime)}
 		}
-		entries[parts[0]] = parts[1]
 	}
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("read sum file: %w", err)
+		return nil, alg, fmt.Errorf("read sum file: %w", err)
 	}
-	return entries, nil
+	return entries, alg, nil
 }
 
-// Write writes a map of path->hash to a sum file, sorted alphabetically.
+// Write writes a map of path->hash to a sum file, sorted alphabetically,
+// after a header naming hasher.Current.
 func Write(path string, entries map[string]string) error {
 	sorted := make([]Entry, 0, len(entries))
 	for p, h := range entries {
@@ -73,12 +167,44 @@ func Write(path string, entries map[string]string) error {
 	defer f.Close()
 
 	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "# %s %s\n", headerKey, hasher.Current())
 	for _, e := range sorted {
 		fmt.Fprintf(w, "%s %s\n", e.Path, e.Hash)
 	}
 	return w.Flush()
 }
 
+// racyWindow is how recently a file may have changed for its stat to go
+// unrecorded: a write in the same mtime tick as the hash would go unnoticed.
+const racyWindow = 2 * time.Second
+
+// WriteStats writes stats to a sum file like Write, adding each file's size
+// and mtime (in Unix nanoseconds) after its hash: "path hash size mtime".
+// Files changed within the last two seconds are written without them, so
+// they are hashed again on the next scan.
+func WriteStats(path string, stats map[string]Stat) error {
+	paths := slices.Sorted(maps.Keys(stats))
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create sum file: %w", err)
+	}
+	defer f.Close()
+
+	racy := time.Now().Add(-racyWindow)
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "# %s %s\n", headerKey, hasher.Current())
+	for _, p := range paths {
+		st := stats[p]
+		if st.ModTime.IsZero() || st.ModTime.After(racy) {
+			fmt.Fprintf(w, "%s %s\n", p, st.Hash)
+			continue
+		}
+		fmt.Fprintf(w, "%s %s %d %d\n", p, st.Hash, st.Size, st.ModTime.UnixNano())
+	}
+	return w.Flush()
+}
+
 // Diff compares old and new entry maps and returns a ChangeSet.
 func Diff(old, new map[string]string) ChangeSet {
 	var cs ChangeSet
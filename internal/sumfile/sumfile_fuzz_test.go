@@ -0,0 +1,43 @@
+package sumfile_test
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gur-shatz/go-run/internal/sumfile"
+)
+
+// FuzzRead feeds arbitrary bytes, including truncated and malformed sum
+// files, to Read. Whatever Read accepts must survive a Write/Read round trip.
+func FuzzRead(f *testing.F) {
+	f.Add([]byte("main.go abc123\nutil.go def456\n"))
+	f.Add([]byte("main.go abc123\nutil.go de"))
+	f.Add([]byte("a b c\n\n   \nx\n"))
+	f.Add([]byte("\x00\xff \t\r\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "in.sum")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		entries, err := sumfile.Read(path)
+		if err != nil {
+			return
+		}
+
+		out := filepath.Join(dir, "out.sum")
+		if err := sumfile.Write(out, entries); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		again, err := sumfile.Read(out)
+		if err != nil {
+			t.Fatalf("Read after Write: %v", err)
+		}
+		if !maps.Equal(entries, again) {
+			t.Fatalf("round trip changed entries: %v != %v", entries, again)
+		}
+	})
+}
@@ -3,10 +3,12 @@ package sumfile_test
 import (
 	"os"
 	"path/filepath"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/gur-shatz/go-run/internal/hasher"
 	"github.com/gur-shatz/go-run/internal/sumfile"
 )
 
@@ -45,7 +47,7 @@ var _ = Describe("Sumfile", func() {
 
 			content, err := os.ReadFile(path)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(string(content)).To(Equal("a.go 2222222\nm.go 3333333\nz.go 1111111\n"))
+			Expect(string(content)).To(Equal("# hash crc32c\na.go 2222222\nm.go 3333333\nz.go 1111111\n"))
 		})
 
 		It("returns nil for non-existent file", func() {
@@ -55,6 +57,70 @@ var _ = Describe("Sumfile", func() {
 		})
 	})
 
+	Describe("WriteStats and ReadStats", func() {
+		It("records size and mtime after the hash", func() {
+			path := filepath.Join(tmpDir, "test.sum")
+			mtime := time.Unix(1700000000, 123456789)
+			stats := map[string]sumfile.Stat{
+				"a.go": {Hash: "1111111", Size: 42, ModTime: mtime},
+				"b.go": {Hash: "2222222"},
+			}
+
+			Expect(sumfile.WriteStats(path, stats)).To(Succeed())
+
+			content, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("# hash crc32c\na.go 1111111 42 1700000000123456789\nb.go 2222222\n"))
+
+			got, err := sumfile.ReadStats(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got["a.go"].ModTime.Equal(mtime)).To(BeTrue())
+			Expect(got["a.go"].Size).To(BeEquivalentTo(42))
+			Expect(got["b.go"]).To(Equal(sumfile.Stat{Hash: "2222222"}))
+
+			sums, err := sumfile.Read(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sums).To(Equal(map[string]string{"a.go": "1111111", "b.go": "2222222"}))
+		})
+
+		It("leaves out the stat of recently changed files", func() {
+			path := filepath.Join(tmpDir, "test.sum")
+			Expect(sumfile.WriteStats(path, map[string]sumfile.Stat{
+				"a.go": {Hash: "1111111", Size: 42, ModTime: time.Now()},
+			})).To(Succeed())
+
+			content, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("# hash crc32c\na.go 1111111\n"))
+		})
+	})
+
+	Describe("hash algorithm", func() {
+		It("reads a sum file hashed with another algorithm as missing for ReadStats", func() {
+			path := filepath.Join(tmpDir, "test.sum")
+			Expect(os.WriteFile(path, []byte("a.go 1111111 42 1700000000123456789\n"), 0644)).To(Succeed())
+
+			// No header: SHA-256, from before the algorithm was recorded.
+			stats, err := sumfile.ReadStats(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats).To(BeNil())
+			sums, err := sumfile.Read(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sums).To(Equal(map[string]string{"a.go": "1111111"}))
+
+			hasher.SetAlgorithm(hasher.SHA256)
+			DeferCleanup(hasher.SetAlgorithm, hasher.CRC32C)
+			stats, err = sumfile.ReadStats(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats).To(HaveKey("a.go"))
+
+			Expect(sumfile.Write(path, map[string]string{"a.go": "1111111"})).To(Succeed())
+			content, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(HavePrefix("# hash sha256\n"))
+		})
+	})
+
 	Describe("Diff", func() {
 		It("detects added files", func() {
 			old := map[string]string{"a.go": "1111111"}
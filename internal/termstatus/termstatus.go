@@ -0,0 +1,93 @@
+// Package termstatus reports build results on the terminal itself: a bell,
+// and a window title with each target's last result, e.g. "✔ api | ✘ worker".
+package termstatus
+
+import (
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+const (
+	bell       = "\a"
+	pushTitle  = "\033[22;0t" // save the title on xterm's title stack
+	popTitle   = "\033[23;0t" // restore it
+	titleStart = "\033]0;"
+	titleEnd   = "\a"
+)
+
+// Reporter rings the bell and updates the title as results come in.
+// A nil *Reporter does nothing.
+type Reporter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	bell    bool
+	title   bool
+	results map[string]bool
+}
+
+// New returns a Reporter writing to w, or nil if neither bell nor title is
+// on or w is a file that isn't a terminal. With title on, the terminal's
+// title is saved and Close restores it.
+func New(w io.Writer, bell, title bool) *Reporter {
+	if !bell && !title {
+		return nil
+	}
+	if f, ok := w.(*os.File); ok && !term.IsTerminal(int(f.Fd())) {
+		return nil
+	}
+	if title {
+		io.WriteString(w, pushTitle)
+	}
+	return &Reporter{w: w, bell: bell, title: title, results: make(map[string]bool)}
+}
+
+// Report records whether name's build succeeded.
+func (this *Reporter) Report(name string, ok bool) {
+	if this == nil {
+		return
+	}
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	this.results[name] = ok
+	var out string
+	if this.title {
+		out += titleStart + this.titleText() + titleEnd
+	}
+	if this.bell {
+		out += bell
+	}
+	io.WriteString(this.w, out)
+}
+
+// Close restores the title the terminal had before New.
+func (this *Reporter) Close() {
+	if this == nil || !this.title {
+		return
+	}
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	io.WriteString(this.w, popTitle)
+}
+
+// titleText lists the results by target name.
+func (this *Reporter) titleText() string {
+	names := make([]string, 0, len(this.results))
+	for name := range this.results {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for i, name := range names {
+		mark := "✔"
+		if !this.results[name] {
+			mark = "✘"
+		}
+		names[i] = mark + " " + name
+	}
+	return strings.Join(names, " | ")
+}
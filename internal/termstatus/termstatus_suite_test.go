@@ -0,0 +1,13 @@
+package termstatus_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTermstatus(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Termstatus Suite")
+}
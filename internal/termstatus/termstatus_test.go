@@ -0,0 +1,48 @@
+package termstatus_test
+
+import (
+	"bytes"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/internal/termstatus"
+)
+
+var _ = Describe("Reporter", func() {
+	It("titles the terminal with each target's last result", func() {
+		var buf bytes.Buffer
+		r := termstatus.New(&buf, false, true)
+		r.Report("worker", true)
+		r.Report("api", true)
+		r.Report("worker", false)
+		r.Close()
+		Expect(buf.String()).To(Equal("\033[22;0t" +
+			"\033]0;✔ worker\a" +
+			"\033]0;✔ api | ✔ worker\a" +
+			"\033]0;✔ api | ✘ worker\a" +
+			"\033[23;0t"))
+	})
+
+	It("rings the bell on every result", func() {
+		var buf bytes.Buffer
+		r := termstatus.New(&buf, true, false)
+		r.Report("api", true)
+		r.Report("api", false)
+		r.Close()
+		Expect(buf.String()).To(Equal("\a\a"))
+	})
+
+	It("is off when nothing is enabled or the output isn't a terminal", func() {
+		Expect(termstatus.New(&bytes.Buffer{}, false, false)).To(BeNil())
+
+		f, err := os.Create(GinkgoT().TempDir() + "/out")
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		r := termstatus.New(f, true, true)
+		Expect(r).To(BeNil())
+		r.Report("api", false) // a nil Reporter is safe to use
+		r.Close()
+	})
+})
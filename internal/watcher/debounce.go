@@ -0,0 +1,135 @@
+package watcher
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gur-shatz/go-run/internal/glob"
+	"github.com/gur-shatz/go-run/internal/sumfile"
+	"github.com/gur-shatz/go-run/pkg/clock"
+)
+
+// DebounceGroup gives the files matching Patterns their own debounce, e.g.
+// a longer one for SQL files that trigger code generation.
+type DebounceGroup struct {
+	Patterns []glob.Pattern
+	Debounce time.Duration
+}
+
+// SetDebounceGroups sets the debounce groups. A changed file belongs to the
+// first group it matches, or else uses the watcher's debounce; each group
+// collects its changes on its own timer. Call it before Run.
+func (this *Watcher) SetDebounceGroups(groups []DebounceGroup) {
+	this.groups = groups
+}
+
+// debouncer batches changes per debounce group and reports them to onChange
+// and events once a group has been quiet for its debounce.
+type debouncer struct {
+	mu       sync.Mutex
+	clock    clock.Clock
+	groups   []DebounceGroup // the default group is last
+	pending  []*sumfile.ChangeSet
+	timers   []clock.Timer
+	onChange OnChangeFunc
+	events   chan<- sumfile.ChangeSet
+	stopped  bool
+	done     chan struct{}  // closed by stop
+	firing   sync.WaitGroup // reports in progress
+}
+
+func (this *Watcher) newDebouncer() *debouncer {
+	groups := append(this.groups[:len(this.groups):len(this.groups)], DebounceGroup{Debounce: this.debounce})
+	return &debouncer{
+		clock:    this.clock,
+		groups:   groups,
+		pending:  make([]*sumfile.ChangeSet, len(groups)),
+		timers:   make([]clock.Timer, len(groups)),
+		onChange: this.onChange,
+		events:   this.events,
+		done:     make(chan struct{}),
+	}
+}
+
+// push adds changes and restarts the timers of the groups they touch.
+func (this *debouncer) push(changes sumfile.ChangeSet) {
+	parts := make([]sumfile.ChangeSet, len(this.groups))
+	for _, f := range changes.Added {
+		i := this.group(f)
+		parts[i].Added = append(parts[i].Added, f)
+	}
+	for _, f := range changes.Modified {
+		i := this.group(f)
+		parts[i].Modified = append(parts[i].Modified, f)
+	}
+	for _, f := range changes.Removed {
+		i := this.group(f)
+		parts[i].Removed = append(parts[i].Removed, f)
+	}
+
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	for i := range parts {
+		if parts[i].IsEmpty() {
+			continue
+		}
+		if this.pending[i] == nil {
+			this.pending[i] = &parts[i]
+		} else {
+			this.pending[i] = sumfile.Merge(this.pending[i], &parts[i])
+		}
+		if this.timers[i] != nil {
+			this.timers[i].Stop()
+		}
+		this.timers[i] = this.clock.AfterFunc(this.groups[i].Debounce, func() { this.fire(i) })
+	}
+}
+
+// group returns the index of the group f belongs to.
+func (this *debouncer) group(f string) int {
+	last := len(this.groups) - 1
+	for i, g := range this.groups[:last] {
+		if glob.Match(g.Patterns, f) {
+			return i
+		}
+	}
+	return last
+}
+
+func (this *debouncer) fire(i int) {
+	this.mu.Lock()
+	changes := this.pending[i]
+	this.pending[i] = nil
+	if this.stopped || changes == nil || changes.IsEmpty() {
+		this.mu.Unlock()
+		return
+	}
+	this.firing.Add(1)
+	this.mu.Unlock()
+	defer this.firing.Done()
+
+	if this.onChange != nil {
+		this.onChange(*changes)
+	}
+	if this.events != nil {
+		select {
+		case this.events <- *changes:
+		case <-this.done:
+		}
+	}
+}
+
+// stop cancels the pending timers and waits for reports in progress, so
+// nothing is sent on events once it returns.
+func (this *debouncer) stop() {
+	this.mu.Lock()
+	this.stopped = true
+	for _, t := range this.timers {
+		if t != nil {
+			t.Stop()
+		}
+	}
+	this.mu.Unlock()
+	close(this.done)
+	this.firing.Wait()
+}
@@ -0,0 +1,82 @@
+package watcher
+
+import (
+	"path"
+	"strings"
+)
+
+// editorSuffixes end the names of files editors write next to the ones
+// being edited: swap files, backups, and safe-write temporaries.
+var editorSuffixes = []string{
+	".swp", ".swo", ".swx", // vim swap files
+	"~",                            // vim and emacs backups
+	"___jb_tmp___", "___jb_old___", // JetBrains safe write
+	".kate-swp", // Kate
+}
+
+// editorPrefixes start the names of editor lock and temp files.
+var editorPrefixes = []string{
+	".#",              // emacs lock files
+	".goutputstream-", // gedit and other GIO-based editors
+}
+
+// IsEditorArtifact reports whether path (slash-separated) is a temporary
+// file an editor creates while saving, such as a vim swap file, rather than
+// a file a user edits.
+func IsEditorArtifact(p string) bool {
+	name := path.Base(p)
+	if name == "4913" { // vim checks it can write to the directory
+		return true
+	}
+	if len(name) > 2 && strings.HasPrefix(name, "#") && strings.HasSuffix(name, "#") { // emacs auto-save
+		return true
+	}
+	for _, s := range editorSuffixes {
+		if strings.HasSuffix(name, s) {
+			return true
+		}
+	}
+	for _, s := range editorPrefixes {
+		if strings.HasPrefix(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// skip reports whether the watcher ignores rel as an editor artifact.
+func (this *Watcher) skip(rel string) bool {
+	return this.ignoreEditorArtifacts && IsEditorArtifact(rel)
+}
+
+// withoutArtifacts drops editor artifacts from sums.
+func (this *Watcher) withoutArtifacts(sums map[string]string) map[string]string {
+	if !this.ignoreEditorArtifacts {
+		return sums
+	}
+	kept := make(map[string]string, len(sums))
+	for f, h := range sums {
+		if !IsEditorArtifact(f) {
+			kept[f] = h
+		}
+	}
+	return kept
+}
+
+// settle smooths over atomic saves, where an editor renames or deletes a
+// file and then writes it anew: a file missing from a scan keeps its old
+// hash and is reported removed only if the next scan still misses it.
+func (this *Watcher) settle(sums map[string]string) {
+	if !this.ignoreEditorArtifacts {
+		return
+	}
+	missing := make(map[string]bool)
+	for f, h := range this.currentSums {
+		if _, ok := sums[f]; ok || this.missing[f] {
+			continue
+		}
+		sums[f] = h
+		missing[f] = true
+	}
+	this.missing = missing
+}
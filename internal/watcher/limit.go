@@ -0,0 +1,80 @@
+package watcher
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// WatchLimitHelp tells how to raise the inotify watch limit.
+const WatchLimitHelp = "raise it with `sudo sysctl fs.inotify.max_user_watches=524288`, " +
+	"and add `fs.inotify.max_user_watches=524288` to /etc/sysctl.conf to keep it"
+
+// isWatchLimit reports whether err from fsnotify's Add means the inotify
+// watch limit (fs.inotify.max_user_watches) is used up.
+func isWatchLimit(err error) bool {
+	return errors.Is(err, syscall.ENOSPC)
+}
+
+// watchDir adds an fsnotify watch for the relative directory rel. A
+// directory over the watch limit is polled instead, without an error.
+func (this *Watcher) watchDir(rel string) error {
+	err := this.add(filepath.Join(this.rootDir, rel))
+	switch {
+	case err == nil:
+		delete(this.unwatched, rel)
+	case isWatchLimit(err):
+		if this.unwatched == nil {
+			this.unwatched = make(map[string]bool)
+		}
+		this.unwatched[rel] = true
+	default:
+		return err
+	}
+	return nil
+}
+
+// warnWatchLimit explains, once, that some directories are being polled.
+func (this *Watcher) warnWatchLimit() {
+	if len(this.unwatched) == 0 || this.limitWarned {
+		return
+	}
+	this.limitWarned = true
+	this.log.Warn("inotify watch limit reached: polling %d directories instead, which is slower; %s",
+		len(this.unwatched), WatchLimitHelp)
+}
+
+// pollUnwatched marks the files of directories without a watch for the
+// next scan, and picks up files and directories created in them.
+func (this *Watcher) pollUnwatched() {
+	if len(this.unwatched) == 0 {
+		return
+	}
+	for f := range this.trackedFiles {
+		if this.unwatched[filepath.Dir(f)] {
+			this.touched[f] = true
+			this.dirty = true
+		}
+	}
+	for dir := range this.unwatched {
+		entries, err := os.ReadDir(filepath.Join(this.rootDir, dir))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			rel := filepath.ToSlash(filepath.Join(dir, e.Name()))
+			if e.IsDir() {
+				if !this.trackedDirs[rel] {
+					this.maybeWatchDir(filepath.Join(this.rootDir, rel))
+				}
+				continue
+			}
+			if this.trackedFiles[rel] || this.skip(rel) || !this.matchesPatterns(rel) || this.ignore.Skip(this.patterns, rel, false) {
+				continue
+			}
+			this.touched[rel] = true
+			this.dirty = true
+		}
+	}
+}
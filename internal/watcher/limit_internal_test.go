@@ -0,0 +1,96 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gur-shatz/go-run/internal/glob"
+	"github.com/gur-shatz/go-run/internal/hasher"
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/internal/sumfile"
+	"github.com/gur-shatz/go-run/pkg/clock"
+)
+
+func TestWatchLimitFallsBackToPolling(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.txt", "a")
+	write("deep/b.txt", "b")
+
+	sums := make(map[string]string)
+	for _, f := range []string{"a.txt", "deep/b.txt"} {
+		h, err := hasher.HashFile(filepath.Join(root, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sums[f] = h
+	}
+
+	var mu sync.Mutex
+	var received sumfile.ChangeSet
+	w := New(root, []glob.Pattern{{Raw: "**/*.txt"}}, 50*time.Millisecond, 50*time.Millisecond, func(changes sumfile.ChangeSet) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = *sumfile.Merge(&received, &changes)
+	}, log.New("[test]", false))
+	w.SetCurrentSums(sums)
+	clk := clock.NewFake(time.Now())
+	w.SetClock(clk)
+	add := w.add
+	w.add = func(name string) error {
+		if filepath.Base(name) == "deep" {
+			return syscall.ENOSPC
+		}
+		return add(name)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+	clk.BlockUntil(1)
+
+	write("deep/b.txt", "b2")
+	write("deep/c.txt", "c")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		clk.Advance(50 * time.Millisecond)
+		mu.Lock()
+		got := slices.Clone(received.Modified)
+		added := slices.Clone(received.Added)
+		mu.Unlock()
+		if slices.Contains(got, "deep/b.txt") && slices.Contains(added, "deep/c.txt") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("changes in the polled directory not seen: modified %v, added %v", got, added)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	<-done
+	if !w.unwatched["deep"] || len(w.unwatched) != 1 {
+		t.Errorf("unwatched = %v, want only deep", w.unwatched)
+	}
+	if !w.limitWarned {
+		t.Error("watch limit not reported")
+	}
+}
@@ -0,0 +1,105 @@
+package watcher
+
+import (
+	"time"
+
+	"github.com/gur-shatz/go-run/internal/glob"
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/internal/sumfile"
+	"github.com/gur-shatz/go-run/pkg/clock"
+)
+
+// Option configures NewWatcher.
+type Option func(*Watcher)
+
+// NewWatcher creates a Watcher configured by opts. Changes go to the
+// WithOnChange callback, if any, and to Events. It polls every 500ms and
+// debounces for 300ms unless told otherwise.
+func NewWatcher(rootDir string, patterns []glob.Pattern, opts ...Option) *Watcher {
+	w := New(rootDir, patterns, 500*time.Millisecond, 300*time.Millisecond, nil, log.New("[watcher]", false))
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// WithPollInterval sets how often changed files are rescanned.
+func WithPollInterval(d time.Duration) Option {
+	return func(w *Watcher) {
+		w.pollInterval = d
+	}
+}
+
+// WithDebounce sets how long changes must be quiet before they're reported.
+func WithDebounce(d time.Duration) Option {
+	return func(w *Watcher) {
+		w.debounce = d
+	}
+}
+
+// WithOnChange sets a callback for each batch of changes.
+func WithOnChange(fn OnChangeFunc) Option {
+	return func(w *Watcher) {
+		w.onChange = fn
+	}
+}
+
+// WithLogger sets the logger for scan errors and verbose output.
+func WithLogger(l *log.Logger) Option {
+	return func(w *Watcher) {
+		w.log = l
+	}
+}
+
+// WithClock is SetClock as an Option.
+func WithClock(c clock.Clock) Option {
+	return func(w *Watcher) {
+		w.SetClock(c)
+	}
+}
+
+// WithCurrentSums is SetCurrentSums as an Option.
+func WithCurrentSums(sums map[string]string) Option {
+	return func(w *Watcher) {
+		w.SetCurrentSums(sums)
+	}
+}
+
+// WithFollowSymlinks is SetFollowSymlinks as an Option.
+func WithFollowSymlinks(on bool) Option {
+	return func(w *Watcher) {
+		w.SetFollowSymlinks(on)
+	}
+}
+
+// WithLargeFileThreshold is SetLargeFileThreshold as an Option.
+func WithLargeFileThreshold(n int64) Option {
+	return func(w *Watcher) {
+		w.SetLargeFileThreshold(n)
+	}
+}
+
+// WithIgnoreEditorArtifacts is SetIgnoreEditorArtifacts as an Option.
+func WithIgnoreEditorArtifacts(on bool) Option {
+	return func(w *Watcher) {
+		w.SetIgnoreEditorArtifacts(on)
+	}
+}
+
+// WithDebounceGroups is SetDebounceGroups as an Option.
+func WithDebounceGroups(groups []DebounceGroup) Option {
+	return func(w *Watcher) {
+		w.SetDebounceGroups(groups)
+	}
+}
+
+// Events returns a channel receiving each batch of changes, for selecting
+// on alongside other channels instead of, or as well as, the callback. Run
+// closes it when it returns. Call it before Run; a batch waits until it is
+// received.
+func (this *Watcher) Events() <-chan sumfile.ChangeSet {
+	if this.events == nil {
+		this.events = make(chan sumfile.ChangeSet)
+	}
+	return this.events
+}
@@ -2,17 +2,19 @@ package watcher
 
 import (
 	"context"
+	"maps"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"time"
 
-	"github.com/bmatcuk/doublestar/v4"
 	"github.com/fsnotify/fsnotify"
 
 	"github.com/gur-shatz/go-run/internal/glob"
 	"github.com/gur-shatz/go-run/internal/hasher"
 	"github.com/gur-shatz/go-run/internal/log"
 	"github.com/gur-shatz/go-run/internal/sumfile"
+	"github.com/gur-shatz/go-run/pkg/clock"
 )
 
 const refreshInterval = 60 * time.Second
@@ -34,26 +36,95 @@ type Watcher struct {
 	pollInterval time.Duration
 	debounce     time.Duration
 	onChange     OnChangeFunc
+	events       chan sumfile.ChangeSet
 	log          *log.Logger
+	clock        clock.Clock
+	followLinks  bool
+	largeFile    int64
+	groups       []DebounceGroup
+
+	ignoreEditorArtifacts bool
+	missing               map[string]bool // tracked files the last scan missed
 
 	currentSums  map[string]string
 	statCache    map[string]fileStat
 	trackedFiles map[string]bool
 	trackedDirs  map[string]bool
+	ignore       *glob.Ignore
 	fsw          *fsnotify.Watcher
+	add          func(name string) error // fsw.Add, replaced in tests
+	unwatched    map[string]bool         // tracked dirs over the watch limit, polled instead
+	limitWarned  bool
 	dirty        bool
+	touched      map[string]bool // files events reported since the last scan
+	scanAll      bool            // next scan checks every tracked file
+	paused       atomic.Bool
 }
 
 // New creates a new Watcher.
 func New(rootDir string, patterns []glob.Pattern, pollInterval, debounce time.Duration, onChange OnChangeFunc, logger *log.Logger) *Watcher {
-	return &Watcher{
+	w := &Watcher{
 		rootDir:      rootDir,
 		patterns:     patterns,
 		pollInterval: pollInterval,
 		debounce:     debounce,
 		onChange:     onChange,
 		log:          logger,
+		clock:        clock.Real,
+
+		ignoreEditorArtifacts: true,
 	}
+	w.add = func(name string) error { return w.fsw.Add(name) }
+	return w
+}
+
+// SetClock replaces the wall clock that drives polling, refreshes, and the
+// debounce. Call it before Run.
+func (this *Watcher) SetClock(c clock.Clock) {
+	this.clock = clock.Or(c)
+}
+
+// Pause stops reporting changes, e.g. during a rebase. Events are still
+// collected, so Resume reports everything changed meanwhile at once.
+// Safe to call from any goroutine.
+func (this *Watcher) Pause() {
+	this.paused.Store(true)
+}
+
+// Resume undoes Pause.
+func (this *Watcher) Resume() {
+	this.paused.Store(false)
+}
+
+// Paused reports whether the watcher is paused.
+func (this *Watcher) Paused() bool {
+	return this.paused.Load()
+}
+
+// SetIgnoreEditorArtifacts turns filtering of editor temp files (see
+// IsEditorArtifact) and atomic-save smoothing on or off (default: on).
+// Call it before Run.
+func (this *Watcher) SetIgnoreEditorArtifacts(on bool) {
+	this.ignoreEditorArtifacts = on
+}
+
+// SetFollowSymlinks makes the watcher descend into symlinked directories
+// (see glob.FollowSymlinks). Call it before Run.
+func (this *Watcher) SetFollowSymlinks(on bool) {
+	this.followLinks = on
+}
+
+func (this *Watcher) globOptions() []glob.Option {
+	if this.followLinks {
+		return []glob.Option{glob.FollowSymlinks()}
+	}
+	return nil
+}
+
+// SetLargeFileThreshold samples files of at least n bytes instead of
+// hashing them in full (see hasher.LargeFiles). Call it before Run.
+func (this *Watcher) SetLargeFileThreshold(n int64) {
+	this.largeFile = n
 }
 
 // SetCurrentSums sets the initial state of file hashes (from the initial build)
@@ -73,6 +144,11 @@ func (this *Watcher) SetCurrentSums(sums map[string]string) {
 
 // Run starts the watch loop. Blocks until the context is cancelled.
 func (this *Watcher) Run(ctx context.Context) {
+	if this.events != nil {
+		defer close(this.events)
+	}
+	this.currentSums = this.withoutArtifacts(this.currentSums)
+
 	fsw, err := fsnotify.NewWatcher()
 	if err != nil {
 		this.log.Error("fsnotify init failed: %v, falling back to polling", err)
@@ -86,24 +162,24 @@ func (this *Watcher) Run(ctx context.Context) {
 		this.log.Error("buildFileList failed: %v", err)
 		return
 	}
+	this.warnWatchLimit()
 
 	this.log.Verbose("Watching %d directories via fsnotify", len(this.trackedDirs))
+	this.touched = make(map[string]bool)
+	this.scanAll = true // reconcile the initial sums with the file list
 
-	pollTicker := time.NewTicker(this.pollInterval)
+	pollTicker := this.clock.NewTicker(this.pollInterval)
 	defer pollTicker.Stop()
 
-	refreshTicker := time.NewTicker(refreshInterval)
+	refreshTicker := this.clock.NewTicker(refreshInterval)
 	defer refreshTicker.Stop()
 
-	var debounceTimer *time.Timer
-	var pendingChanges *sumfile.ChangeSet
+	debounce := this.newDebouncer()
+	defer debounce.stop()
 
 	for {
 		select {
 		case <-ctx.Done():
-			if debounceTimer != nil {
-				debounceTimer.Stop()
-			}
 			return
 
 		case event, ok := <-this.fsw.Events:
@@ -118,13 +194,25 @@ func (this *Watcher) Run(ctx context.Context) {
 				continue
 			}
 			rel = filepath.ToSlash(rel)
-			if this.trackedFiles[rel] || this.matchesPatterns(rel) {
+			if this.skip(rel) {
+				continue
+			}
+			if this.trackedFiles[rel] || (this.matchesPatterns(rel) && !this.ignore.Skip(this.patterns, rel, false)) {
+				this.dirty = true
+				this.touched[rel] = true
+			}
+			// A directory moved or removed takes its files without an
+			// event for each; a new one may arrive with files in it.
+			if this.trackedDirs[rel] {
 				this.dirty = true
+				this.scanAll = true
 			}
 			// Watch newly created directories
 			if event.Op&fsnotify.Create != 0 {
 				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
 					this.maybeWatchDir(event.Name)
+					this.dirty = true
+					this.scanAll = true
 				}
 			}
 
@@ -132,16 +220,32 @@ func (this *Watcher) Run(ctx context.Context) {
 			if !ok {
 				return
 			}
-			// On any fsnotify error (including overflow), force a scan
+			// On any fsnotify error (including overflow), force a full scan
 			this.dirty = true
+			this.scanAll = true
 
-		case <-pollTicker.C:
-			if !this.dirty {
+		case <-pollTicker.C():
+			this.pollUnwatched()
+			if !this.dirty || this.paused.Load() {
 				continue
 			}
 			this.dirty = false
 
-			newSums, err := this.scan()
+			var (
+				newSums map[string]string
+				err     error
+			)
+			if this.scanAll {
+				for f := range this.touched {
+					this.trackedFiles[f] = true // new since the last refresh
+				}
+				newSums, err = this.scan()
+			} else {
+				newSums = this.scanTouched()
+			}
+			this.scanAll = false
+			clear(this.touched)
+			this.recheckMissing()
 			if err != nil {
 				continue
 			}
@@ -152,51 +256,38 @@ func (this *Watcher) Run(ctx context.Context) {
 			}
 
 			this.currentSums = newSums
+			debounce.push(changes)
 
-			if pendingChanges == nil {
-				pendingChanges = &changes
-			} else {
-				pendingChanges = mergeChanges(pendingChanges, &changes)
-			}
-
-			if debounceTimer != nil {
-				debounceTimer.Stop()
-			}
-			debounceTimer = time.AfterFunc(this.debounce, func() {
-				if pendingChanges != nil && !pendingChanges.IsEmpty() {
-					this.onChange(*pendingChanges)
-					pendingChanges = nil
-				}
-			})
-
-		case <-refreshTicker.C:
+		case <-refreshTicker.C():
 			if err := this.buildFileList(); err != nil {
 				this.log.Warn("refresh buildFileList failed: %v", err)
 				continue
 			}
+			this.warnWatchLimit()
 			// this.log.Verbose("Refreshed file list: %d files, %d directories,", len(this.trackedFiles), len(this.trackedDirs))
 			this.dirty = true
+			this.scanAll = true
 		}
 	}
 }
 
 // runPollOnly is the fallback when fsnotify is unavailable.
 func (this *Watcher) runPollOnly(ctx context.Context) {
-	ticker := time.NewTicker(this.pollInterval)
+	ticker := this.clock.NewTicker(this.pollInterval)
 	defer ticker.Stop()
 
-	var debounceTimer *time.Timer
-	var pendingChanges *sumfile.ChangeSet
+	debounce := this.newDebouncer()
+	defer debounce.stop()
 
 	for {
 		select {
 		case <-ctx.Done():
-			if debounceTimer != nil {
-				debounceTimer.Stop()
-			}
 			return
 
-		case <-ticker.C:
+		case <-ticker.C():
+			if this.paused.Load() {
+				continue
+			}
 			newSums, err := this.scanWithGlob()
 			if err != nil {
 				continue
@@ -208,22 +299,7 @@ func (this *Watcher) runPollOnly(ctx context.Context) {
 			}
 
 			this.currentSums = newSums
-
-			if pendingChanges == nil {
-				pendingChanges = &changes
-			} else {
-				pendingChanges = mergeChanges(pendingChanges, &changes)
-			}
-
-			if debounceTimer != nil {
-				debounceTimer.Stop()
-			}
-			debounceTimer = time.AfterFunc(this.debounce, func() {
-				if pendingChanges != nil && !pendingChanges.IsEmpty() {
-					this.onChange(*pendingChanges)
-					pendingChanges = nil
-				}
-			})
+			debounce.push(changes)
 		}
 	}
 }
@@ -231,7 +307,7 @@ func (this *Watcher) runPollOnly(ctx context.Context) {
 // buildFileList expands globs to determine tracked files and directories,
 // then syncs fsnotify watches to match.
 func (this *Watcher) buildFileList() error {
-	files, err := glob.ExpandPatterns(this.rootDir, this.patterns)
+	files, err := glob.ExpandPatterns(this.rootDir, this.patterns, this.globOptions()...)
 	if err != nil {
 		return err
 	}
@@ -242,6 +318,9 @@ func (this *Watcher) buildFileList() error {
 	newTrackedDirs["."] = true
 
 	for _, f := range files {
+		if this.skip(f) {
+			continue
+		}
 		newTrackedFiles[f] = true
 		dir := filepath.Dir(f)
 		for dir != "." {
@@ -258,18 +337,18 @@ func (this *Watcher) buildFileList() error {
 				if !newTrackedDirs[dir] {
 					absDir := filepath.Join(this.rootDir, dir)
 					this.fsw.Remove(absDir)
+					delete(this.unwatched, dir)
 				}
 			}
 		}
 
-		// Add new watches
+		// Add new watches, and retry those over the watch limit
 		for dir := range newTrackedDirs {
-			if this.trackedDirs == nil || !this.trackedDirs[dir] {
-				absDir := filepath.Join(this.rootDir, dir)
-				if err := this.fsw.Add(absDir); err != nil {
+			if this.trackedDirs == nil || !this.trackedDirs[dir] || this.unwatched[dir] {
+				if err := this.watchDir(dir); err != nil {
 					this.log.Warn("no watch %s: %v", dir, err)
-				} else {
-					this.log.Verbose("Watching: %s (%s)", dir, absDir)
+				} else if !this.unwatched[dir] {
+					this.log.Verbose("Watching: %s", dir)
 				}
 			}
 		}
@@ -277,6 +356,7 @@ func (this *Watcher) buildFileList() error {
 
 	this.trackedFiles = newTrackedFiles
 	this.trackedDirs = newTrackedDirs
+	this.ignore = glob.NewIgnore(this.rootDir) // re-read ignore files on refresh
 	return nil
 }
 
@@ -285,6 +365,7 @@ func (this *Watcher) buildFileList() error {
 func (this *Watcher) scan() (map[string]string, error) {
 	newStatCache := make(map[string]fileStat, len(this.trackedFiles))
 	sums := make(map[string]string, len(this.trackedFiles))
+	var stale []string // files whose stat changed, hashed in parallel
 
 	for f := range this.trackedFiles {
 		fullPath := this.rootDir + "/" + f
@@ -303,30 +384,72 @@ func (this *Watcher) scan() (map[string]string, error) {
 				continue
 			}
 		}
-
-		hash, err := hasher.HashFile(fullPath)
-		if err != nil {
-			continue
-		}
-		sums[f] = hash
+		stale = append(stale, f)
 	}
+	maps.Copy(sums, hasher.HashFiles(this.rootDir, stale, hasher.LargeFiles(this.largeFile)))
 
 	this.statCache = newStatCache
+	this.settle(sums)
 	return sums, nil
 }
 
+// scanTouched hashes only the files events reported since the last scan,
+// carrying every other hash over from currentSums. New files that match the
+// patterns join the tracked set.
+func (this *Watcher) scanTouched() map[string]string {
+	sums := maps.Clone(this.currentSums)
+	var stale []string
+
+	for f := range this.touched {
+		delete(sums, f)
+		info, err := os.Stat(this.rootDir + "/" + f)
+		if err != nil || info.IsDir() {
+			delete(this.statCache, f)
+			continue // file may have been deleted
+		}
+		this.trackedFiles[f] = true
+
+		st := fileStat{modTime: info.ModTime(), size: info.Size()}
+		if prev, ok := this.statCache[f]; ok && prev == st {
+			if hash, ok := this.currentSums[f]; ok {
+				sums[f] = hash
+				continue
+			}
+		}
+		this.statCache[f] = st
+		stale = append(stale, f)
+	}
+	maps.Copy(sums, hasher.HashFiles(this.rootDir, stale, hasher.LargeFiles(this.largeFile)))
+
+	this.settle(sums)
+	return sums
+}
+
+// recheckMissing schedules another scan of the files settle is holding
+// back, so they are reported removed if still gone.
+func (this *Watcher) recheckMissing() {
+	for f := range this.missing {
+		this.dirty = true
+		this.touched[f] = true
+	}
+}
+
 // scanWithGlob is the original scan that expands globs. Used as fallback
 // when fsnotify is unavailable.
 func (this *Watcher) scanWithGlob() (map[string]string, error) {
-	files, err := glob.ExpandPatterns(this.rootDir, this.patterns)
+	files, err := glob.ExpandPatterns(this.rootDir, this.patterns, this.globOptions()...)
 	if err != nil {
 		return nil, err
 	}
 
 	newStatCache := make(map[string]fileStat, len(files))
 	sums := make(map[string]string, len(files))
+	var stale []string
 
 	for _, f := range files {
+		if this.skip(f) {
+			continue
+		}
 		fullPath := this.rootDir + "/" + f
 
 		info, err := os.Stat(fullPath)
@@ -343,34 +466,24 @@ func (this *Watcher) scanWithGlob() (map[string]string, error) {
 				continue
 			}
 		}
-
-		hash, err := hasher.HashFile(fullPath)
-		if err != nil {
-			continue
-		}
-		sums[f] = hash
+		stale = append(stale, f)
 	}
+	maps.Copy(sums, hasher.HashFiles(this.rootDir, stale, hasher.LargeFiles(this.largeFile)))
 
 	this.statCache = newStatCache
+	this.settle(sums)
 	return sums, nil
 }
 
-// matchesPatterns checks if a relative path matches any of the include glob patterns.
+// matchesPatterns checks if a relative path matches an include pattern and no exclusion.
 // Used for detecting newly created files that aren't yet in trackedFiles.
 func (this *Watcher) matchesPatterns(rel string) bool {
-	for _, p := range this.patterns {
-		if p.Negated {
-			continue
-		}
-		if matched, _ := doublestar.Match(p.Raw, rel); matched {
-			return true
-		}
-	}
-	return false
+	return glob.Match(this.patterns, rel)
 }
 
 // maybeWatchDir adds an fsnotify watch to a newly created directory if it's
-// under a tracked directory (one that matched a watch pattern).
+// under a tracked directory (one that matched a watch pattern) and not
+// ignored by a .gitignore or .ignore file.
 func (this *Watcher) maybeWatchDir(absPath string) {
 	rel, err := filepath.Rel(this.rootDir, absPath)
 	if err != nil {
@@ -392,40 +505,19 @@ func (this *Watcher) maybeWatchDir(absPath string) {
 			}
 		}
 	}
-	if !tracked {
+	if !tracked || this.ignore.Skip(this.patterns, rel, true) {
 		return
 	}
-
-	if this.fsw != nil {
-		if err := this.fsw.Add(absPath); err == nil {
-			this.trackedDirs[rel] = true
-			this.log.Status("Watching new directory: %s (%s)", rel, absPath)
-		}
+	if info, err := os.Lstat(absPath); err == nil && info.Mode()&os.ModeSymlink != 0 && !this.followLinks {
+		return
 	}
-}
-
-// mergeChanges combines two changesets.
-func mergeChanges(a, b *sumfile.ChangeSet) *sumfile.ChangeSet {
-	seen := make(map[string]bool)
-	result := &sumfile.ChangeSet{}
 
-	for _, f := range append(a.Added, b.Added...) {
-		if !seen[f] {
-			result.Added = append(result.Added, f)
-			seen[f] = true
-		}
-	}
-	for _, f := range append(a.Modified, b.Modified...) {
-		if !seen[f] {
-			result.Modified = append(result.Modified, f)
-			seen[f] = true
-		}
-	}
-	for _, f := range append(a.Removed, b.Removed...) {
-		if !seen[f] {
-			result.Removed = append(result.Removed, f)
-			seen[f] = true
+	if this.fsw != nil && this.watchDir(rel) == nil {
+		this.trackedDirs[rel] = true
+		if this.unwatched[rel] {
+			this.warnWatchLimit()
+		} else {
+			this.log.Status("Watching new directory: %s (%s)", rel, absPath)
 		}
 	}
-	return result
 }
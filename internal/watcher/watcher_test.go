@@ -15,6 +15,7 @@ import (
 	"github.com/gur-shatz/go-run/internal/log"
 	"github.com/gur-shatz/go-run/internal/sumfile"
 	"github.com/gur-shatz/go-run/internal/watcher"
+	"github.com/gur-shatz/go-run/pkg/clock"
 )
 
 var _ = Describe("Watcher", func() {
@@ -67,22 +68,25 @@ var _ = Describe("Watcher", func() {
 				received = &changes
 			}, testLogger)
 			w.SetCurrentSums(initialSums)
+			clk := clock.NewFake(time.Now())
+			w.SetClock(clk)
 
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 			go w.Run(ctx)
 
-			// Let the watcher start
-			time.Sleep(100 * time.Millisecond)
+			// Wait for the watcher to scan and start its tickers
+			clk.BlockUntil(1)
 
 			// Modify the file
 			writeFile("a.txt", "modified content")
 
 			Eventually(func() *sumfile.ChangeSet {
+				clk.Advance(50 * time.Millisecond)
 				mu.Lock()
 				defer mu.Unlock()
 				return received
-			}, 3*time.Second, 50*time.Millisecond).ShouldNot(BeNil())
+			}, 2*time.Second, 5*time.Millisecond).ShouldNot(BeNil())
 
 			mu.Lock()
 			defer mu.Unlock()
@@ -108,20 +112,23 @@ var _ = Describe("Watcher", func() {
 				received = &changes
 			}, testLogger)
 			w.SetCurrentSums(initialSums)
+			clk := clock.NewFake(time.Now())
+			w.SetClock(clk)
 
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 			go w.Run(ctx)
 
 			// Trigger a scan by modifying a tracked file
-			time.Sleep(100 * time.Millisecond)
+			clk.BlockUntil(1)
 			writeFile("a.txt", "modified existing")
 
 			Eventually(func() *sumfile.ChangeSet {
+				clk.Advance(50 * time.Millisecond)
 				mu.Lock()
 				defer mu.Unlock()
 				return received
-			}, 5*time.Second, 50*time.Millisecond).ShouldNot(BeNil())
+			}, 2*time.Second, 5*time.Millisecond).ShouldNot(BeNil())
 
 			mu.Lock()
 			defer mu.Unlock()
@@ -131,6 +138,50 @@ var _ = Describe("Watcher", func() {
 			Expect(received.Added).To(ContainElement("b.txt"))
 		})
 
+		It("detects files created after it starts", func() {
+			writeFile("a.txt", "existing")
+
+			var mu sync.Mutex
+			var received []sumfile.ChangeSet
+
+			w := watcher.New(tmpDir, patterns, 50*time.Millisecond, 50*time.Millisecond, func(changes sumfile.ChangeSet) {
+				mu.Lock()
+				defer mu.Unlock()
+				received = append(received, changes)
+			}, testLogger)
+			w.SetCurrentSums(scanInitial())
+			clk := clock.NewFake(time.Now())
+			w.SetClock(clk)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go w.Run(ctx)
+
+			clk.BlockUntil(1)
+			writeFile("a.txt", "modified")
+
+			Eventually(func() int {
+				clk.Advance(50 * time.Millisecond)
+				mu.Lock()
+				defer mu.Unlock()
+				return len(received)
+			}, 2*time.Second, 5*time.Millisecond).Should(Equal(1))
+
+			// Only the new file is hashed; a.txt stays as it is.
+			writeFile("new.txt", "created")
+
+			Eventually(func() int {
+				clk.Advance(50 * time.Millisecond)
+				mu.Lock()
+				defer mu.Unlock()
+				return len(received)
+			}, 2*time.Second, 5*time.Millisecond).Should(Equal(2))
+
+			mu.Lock()
+			defer mu.Unlock()
+			Expect(received[1]).To(Equal(sumfile.ChangeSet{Added: []string{"new.txt"}}))
+		})
+
 		It("detects removed files", func() {
 			writeFile("a.txt", "to be removed")
 			writeFile("b.txt", "stays")
@@ -146,20 +197,23 @@ var _ = Describe("Watcher", func() {
 				received = &changes
 			}, testLogger)
 			w.SetCurrentSums(initialSums)
+			clk := clock.NewFake(time.Now())
+			w.SetClock(clk)
 
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 			go w.Run(ctx)
 
-			time.Sleep(100 * time.Millisecond)
+			clk.BlockUntil(1)
 
 			Expect(os.Remove(filepath.Join(tmpDir, "a.txt"))).To(Succeed())
 
 			Eventually(func() *sumfile.ChangeSet {
+				clk.Advance(50 * time.Millisecond)
 				mu.Lock()
 				defer mu.Unlock()
 				return received
-			}, 3*time.Second, 50*time.Millisecond).ShouldNot(BeNil())
+			}, 2*time.Second, 5*time.Millisecond).ShouldNot(BeNil())
 
 			mu.Lock()
 			defer mu.Unlock()
@@ -188,22 +242,250 @@ var _ = Describe("Watcher", func() {
 				received = &changes
 			}, testLogger)
 			w.SetCurrentSums(initialSums)
+			clk := clock.NewFake(time.Now())
+			w.SetClock(clk)
 
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 			go w.Run(ctx)
 
-			time.Sleep(100 * time.Millisecond)
+			clk.BlockUntil(1)
 
 			// Modify only the ignored file
 			writeFile("ignored.txt", "modified excluded content")
 
 			// Should NOT trigger a change
 			Consistently(func() *sumfile.ChangeSet {
+				clk.Advance(50 * time.Millisecond)
 				mu.Lock()
 				defer mu.Unlock()
 				return received
-			}, 500*time.Millisecond, 50*time.Millisecond).Should(BeNil())
+			}, 100*time.Millisecond, 5*time.Millisecond).Should(BeNil())
+		})
+	})
+
+	Describe("events", func() {
+		It("sends changes on the Events channel and closes it when Run returns", func() {
+			writeFile("a.txt", "original")
+
+			clk := clock.NewFake(time.Now())
+			w := watcher.NewWatcher(tmpDir, patterns,
+				watcher.WithPollInterval(50*time.Millisecond),
+				watcher.WithDebounce(50*time.Millisecond),
+				watcher.WithCurrentSums(scanInitial()),
+				watcher.WithClock(clk),
+				watcher.WithLogger(testLogger),
+			)
+			// The fake clock fires the debounce inside Advance, so receive
+			// on another goroutine, as a consumer's select loop would.
+			events := w.Events()
+			received := make(chan sumfile.ChangeSet, 10)
+			go func() {
+				for changes := range events {
+					received <- changes
+				}
+				close(received)
+			}()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			done := make(chan struct{})
+			go func() {
+				w.Run(ctx)
+				close(done)
+			}()
+
+			clk.BlockUntil(1)
+			writeFile("a.txt", "modified")
+
+			var changes sumfile.ChangeSet
+			Eventually(func() chan sumfile.ChangeSet {
+				clk.Advance(50 * time.Millisecond)
+				return received
+			}, 2*time.Second, 5*time.Millisecond).Should(Receive(&changes))
+			Expect(changes.Modified).To(ConsistOf("a.txt"))
+
+			cancel()
+			Eventually(done).Should(BeClosed())
+			Eventually(received).Should(BeClosed())
+		})
+	})
+
+	Describe("debounce groups", func() {
+		It("batches each group on its own timer", func() {
+			patterns = []glob.Pattern{{Raw: "**/*.txt"}, {Raw: "**/*.sql"}}
+			writeFile("a.txt", "original")
+			writeFile("b.sql", "original")
+
+			var mu sync.Mutex
+			var received []sumfile.ChangeSet
+
+			w := watcher.New(tmpDir, patterns, 50*time.Millisecond, 50*time.Millisecond, func(changes sumfile.ChangeSet) {
+				mu.Lock()
+				defer mu.Unlock()
+				received = append(received, changes)
+			}, testLogger)
+			w.SetCurrentSums(scanInitial())
+			w.SetDebounceGroups([]watcher.DebounceGroup{
+				{Patterns: []glob.Pattern{{Raw: "**/*.sql"}}, Debounce: time.Second},
+			})
+			clk := clock.NewFake(time.Now())
+			w.SetClock(clk)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go w.Run(ctx)
+
+			clk.BlockUntil(1)
+			start := clk.Now()
+			writeFile("a.txt", "modified")
+			writeFile("b.sql", "modified")
+
+			poll := func() int {
+				clk.Advance(50 * time.Millisecond)
+				mu.Lock()
+				defer mu.Unlock()
+				return len(received)
+			}
+			Eventually(poll, 2*time.Second, 5*time.Millisecond).Should(Equal(1))
+			Expect(clk.Now().Sub(start)).To(BeNumerically("<", time.Second))
+			Eventually(poll, 2*time.Second, 5*time.Millisecond).Should(Equal(2))
+
+			mu.Lock()
+			defer mu.Unlock()
+			Expect(received[0].Modified).To(ConsistOf("a.txt"))
+			Expect(received[1].Modified).To(ConsistOf("b.sql"))
+		})
+	})
+
+	Describe("pause", func() {
+		It("reports changes made while paused on resume", func() {
+			writeFile("a.txt", "original")
+
+			var mu sync.Mutex
+			var received *sumfile.ChangeSet
+
+			w := watcher.New(tmpDir, patterns, 50*time.Millisecond, 50*time.Millisecond, func(changes sumfile.ChangeSet) {
+				mu.Lock()
+				defer mu.Unlock()
+				received = &changes
+			}, testLogger)
+			w.SetCurrentSums(scanInitial())
+			clk := clock.NewFake(time.Now())
+			w.SetClock(clk)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go w.Run(ctx)
+
+			clk.BlockUntil(1)
+			w.Pause()
+			Expect(w.Paused()).To(BeTrue())
+			writeFile("a.txt", "during rebase")
+			writeFile("b.txt", "new")
+
+			poll := func() *sumfile.ChangeSet {
+				clk.Advance(50 * time.Millisecond)
+				mu.Lock()
+				defer mu.Unlock()
+				return received
+			}
+			Consistently(poll, 100*time.Millisecond, 5*time.Millisecond).Should(BeNil())
+
+			w.Resume()
+			Eventually(poll, 2*time.Second, 5*time.Millisecond).ShouldNot(BeNil())
+
+			mu.Lock()
+			defer mu.Unlock()
+			Expect(received.Modified).To(ConsistOf("a.txt"))
+			Expect(received.Added).To(ConsistOf("b.txt"))
+		})
+	})
+
+	Describe("editor artifacts", func() {
+		DescribeTable("IsEditorArtifact",
+			func(path string, want bool) {
+				Expect(watcher.IsEditorArtifact(path)).To(Equal(want))
+			},
+			Entry("vim swap", "src/.main.go.swp", true),
+			Entry("vim backup", "main.go~", true),
+			Entry("vim write check", "src/4913", true),
+			Entry("JetBrains temp", "main.go___jb_tmp___", true),
+			Entry("emacs lock", ".#main.go", true),
+			Entry("emacs auto-save", "#main.go#", true),
+			Entry("regular file", "src/main.go", false),
+			Entry("dotfile", ".env", false),
+		)
+
+		It("ignores swap and backup files", func() {
+			patterns = []glob.Pattern{{Raw: "**/*"}}
+			writeFile("a.txt", "watched")
+
+			var mu sync.Mutex
+			var received *sumfile.ChangeSet
+
+			w := watcher.New(tmpDir, patterns, 50*time.Millisecond, 50*time.Millisecond, func(changes sumfile.ChangeSet) {
+				mu.Lock()
+				defer mu.Unlock()
+				received = &changes
+			}, testLogger)
+			w.SetCurrentSums(scanInitial())
+			clk := clock.NewFake(time.Now())
+			w.SetClock(clk)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go w.Run(ctx)
+
+			clk.BlockUntil(1)
+
+			writeFile(".a.txt.swp", "swap")
+			writeFile("a.txt~", "backup")
+
+			Consistently(func() *sumfile.ChangeSet {
+				clk.Advance(50 * time.Millisecond)
+				mu.Lock()
+				defer mu.Unlock()
+				return received
+			}, 100*time.Millisecond, 5*time.Millisecond).Should(BeNil())
+		})
+
+		It("reports an atomic save as a modification", func() {
+			writeFile("a.txt", "original")
+
+			var mu sync.Mutex
+			var received *sumfile.ChangeSet
+
+			w := watcher.New(tmpDir, patterns, 50*time.Millisecond, 50*time.Millisecond, func(changes sumfile.ChangeSet) {
+				mu.Lock()
+				defer mu.Unlock()
+				received = &changes
+			}, testLogger)
+			w.SetCurrentSums(scanInitial())
+			clk := clock.NewFake(time.Now())
+			w.SetClock(clk)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go w.Run(ctx)
+
+			clk.BlockUntil(1)
+
+			Expect(os.Rename(filepath.Join(tmpDir, "a.txt"), filepath.Join(tmpDir, "a.txt~"))).To(Succeed())
+			clk.Advance(50 * time.Millisecond)
+			writeFile("a.txt", "saved")
+
+			Eventually(func() *sumfile.ChangeSet {
+				clk.Advance(50 * time.Millisecond)
+				mu.Lock()
+				defer mu.Unlock()
+				return received
+			}, 2*time.Second, 5*time.Millisecond).ShouldNot(BeNil())
+
+			mu.Lock()
+			defer mu.Unlock()
+			Expect(received.Removed).To(BeEmpty())
+			Expect(received.Modified).To(ConsistOf("a.txt"))
 		})
 	})
 
@@ -216,6 +498,8 @@ var _ = Describe("Watcher", func() {
 			done := make(chan struct{})
 			w := watcher.New(tmpDir, patterns, 50*time.Millisecond, 50*time.Millisecond, func(changes sumfile.ChangeSet) {}, testLogger)
 			w.SetCurrentSums(initialSums)
+			clk := clock.NewFake(time.Now())
+			w.SetClock(clk)
 
 			ctx, cancel := context.WithCancel(context.Background())
 			go func() {
@@ -223,7 +507,7 @@ var _ = Describe("Watcher", func() {
 				close(done)
 			}()
 
-			time.Sleep(100 * time.Millisecond)
+			clk.BlockUntil(1)
 			cancel()
 
 			Eventually(done, 2*time.Second).Should(BeClosed())
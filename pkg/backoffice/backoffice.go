@@ -15,6 +15,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/gur-shatz/go-run/internal/mask"
 	"github.com/gur-shatz/go-run/pkg/chiutil"
 )
 
@@ -33,19 +34,6 @@ const (
 
 var startTime = time.Now()
 
-// sensitiveKeys are substrings that mark an env var as sensitive.
-var sensitiveKeys = []string{"SECRET", "PASSWORD", "TOKEN", "KEY", "CREDENTIAL"}
-
-func isSensitive(name string) bool {
-	upper := strings.ToUpper(name)
-	for _, k := range sensitiveKeys {
-		if strings.Contains(upper, k) {
-			return true
-		}
-	}
-	return false
-}
-
 func handleEnv(w http.ResponseWriter, r *http.Request) {
 	env := make(map[string]string)
 	for _, e := range os.Environ() {
@@ -53,11 +41,7 @@ func handleEnv(w http.ResponseWriter, r *http.Request) {
 		if len(parts) != 2 {
 			continue
 		}
-		name, value := parts[0], parts[1]
-		if isSensitive(name) {
-			value = "***"
-		}
-		env[name] = value
+		env[parts[0]] = mask.Value(parts[0], parts[1])
 	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(env)
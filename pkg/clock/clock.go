@@ -0,0 +1,185 @@
+// Package clock abstracts timers and tickers so tests and embedders can
+// control time. Real is the wall clock; Fake only moves when told to.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells time and schedules work.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTicker(d time.Duration) Ticker
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Ticker delivers ticks on C every period until stopped.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Timer is a pending AfterFunc call.
+type Timer interface {
+	// Stop cancels the call. It reports whether the call was still pending.
+	Stop() bool
+}
+
+// Real is the wall clock, backed by package time.
+var Real Clock = realClock{}
+
+// Or returns c, or Real if c is nil.
+func Or(c Clock) Clock {
+	if c == nil {
+		return Real
+	}
+	return c
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+type realTicker struct{ t *time.Ticker }
+
+func (this realTicker) C() <-chan time.Time { return this.t.C }
+func (this realTicker) Stop()               { this.t.Stop() }
+
+// Fake is a Clock that stands still until Advance is called. Tickers drop
+// ticks nobody reads, like time.Ticker; AfterFunc callbacks run inside
+// Advance.
+type Fake struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*waiter
+}
+
+// waiter is a pending timer, ticker, or AfterFunc call.
+type waiter struct {
+	at     time.Time
+	period time.Duration // tickers only
+	ch     chan time.Time
+	fn     func()
+	clock  *Fake
+}
+
+// NewFake returns a Fake clock set to start.
+func NewFake(start time.Time) *Fake {
+	f := &Fake{now: start}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the fake time.
+func (this *Fake) Now() time.Time {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.now
+}
+
+// After returns a channel that receives the fake time once d has passed.
+func (this *Fake) After(d time.Duration) <-chan time.Time {
+	return this.add(&waiter{at: this.Now().Add(d), ch: make(chan time.Time, 1)}).ch
+}
+
+// NewTicker returns a ticker that fires every d of fake time.
+func (this *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	return fakeTicker{this.add(&waiter{at: this.Now().Add(d), period: d, ch: make(chan time.Time, 1)})}
+}
+
+// AfterFunc calls f once d of fake time has passed.
+func (this *Fake) AfterFunc(d time.Duration, f func()) Timer {
+	return fakeTimer{this.add(&waiter{at: this.Now().Add(d), fn: f})}
+}
+
+// Advance moves the clock forward by d, firing everything that comes due in
+// order.
+func (this *Fake) Advance(d time.Duration) {
+	this.mu.Lock()
+	end := this.now.Add(d)
+	for {
+		sort.SliceStable(this.waiters, func(i, j int) bool { return this.waiters[i].at.Before(this.waiters[j].at) })
+		if len(this.waiters) == 0 || this.waiters[0].at.After(end) {
+			break
+		}
+		w := this.waiters[0]
+		this.now = w.at
+		if w.period > 0 {
+			w.at = w.at.Add(w.period)
+		} else {
+			this.waiters = this.waiters[1:]
+		}
+		if w.fn != nil {
+			this.mu.Unlock()
+			w.fn()
+			this.mu.Lock()
+			continue
+		}
+		select {
+		case w.ch <- this.now:
+		default:
+		}
+	}
+	this.now = end
+	this.mu.Unlock()
+}
+
+// Waiters returns how many timers and tickers are pending.
+func (this *Fake) Waiters() int {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return len(this.waiters)
+}
+
+// BlockUntil waits until at least n timers and tickers are pending, so a
+// test knows the code under test has started waiting before it advances.
+func (this *Fake) BlockUntil(n int) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	for len(this.waiters) < n {
+		this.cond.Wait()
+	}
+}
+
+func (this *Fake) add(w *waiter) *waiter {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	w.clock = this
+	this.waiters = append(this.waiters, w)
+	this.cond.Broadcast()
+	return w
+}
+
+// remove drops w and reports whether it was pending.
+func (this *Fake) remove(w *waiter) bool {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	for i, p := range this.waiters {
+		if p == w {
+			this.waiters = append(this.waiters[:i], this.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTicker struct{ w *waiter }
+
+func (this fakeTicker) C() <-chan time.Time { return this.w.ch }
+func (this fakeTicker) Stop()               { this.w.clock.remove(this.w) }
+
+type fakeTimer struct{ w *waiter }
+
+func (this fakeTimer) Stop() bool { return this.w.clock.remove(this.w) }
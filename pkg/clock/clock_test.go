@@ -0,0 +1,75 @@
+package clock_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/clock"
+)
+
+var _ = Describe("Fake", func() {
+	var (
+		start time.Time
+		clk   *clock.Fake
+	)
+
+	BeforeEach(func() {
+		start = time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+		clk = clock.NewFake(start)
+	})
+
+	It("only moves when advanced", func() {
+		Expect(clk.Now()).To(Equal(start))
+		clk.Advance(time.Minute)
+		Expect(clk.Now()).To(Equal(start.Add(time.Minute)))
+	})
+
+	It("fires After once its duration has passed", func() {
+		ch := clk.After(time.Second)
+		clk.Advance(999 * time.Millisecond)
+		Expect(ch).NotTo(Receive())
+		clk.Advance(time.Millisecond)
+		Expect(ch).To(Receive(Equal(start.Add(time.Second))))
+		Expect(clk.Waiters()).To(BeZero())
+	})
+
+	It("ticks every period and drops unread ticks", func() {
+		t := clk.NewTicker(time.Second)
+		clk.Advance(3 * time.Second)
+		Expect(t.C()).To(Receive(Equal(start.Add(time.Second))))
+		Expect(t.C()).NotTo(Receive())
+
+		clk.Advance(time.Second)
+		Expect(t.C()).To(Receive(Equal(start.Add(4 * time.Second))))
+
+		t.Stop()
+		clk.Advance(time.Second)
+		Expect(t.C()).NotTo(Receive())
+	})
+
+	It("runs AfterFunc callbacks in order and lets them be stopped", func() {
+		var calls []string
+		clk.AfterFunc(2*time.Second, func() { calls = append(calls, "b") })
+		clk.AfterFunc(time.Second, func() {
+			calls = append(calls, "a")
+			clk.AfterFunc(time.Second, func() { calls = append(calls, "c") })
+		})
+		stopped := clk.AfterFunc(time.Second, func() { calls = append(calls, "never") })
+		Expect(stopped.Stop()).To(BeTrue())
+		Expect(stopped.Stop()).To(BeFalse())
+
+		clk.Advance(2 * time.Second)
+		Expect(calls).To(Equal([]string{"a", "b", "c"}))
+	})
+
+	It("blocks until enough waiters are pending", func() {
+		go func() {
+			defer GinkgoRecover()
+			<-clk.After(time.Second)
+		}()
+		clk.BlockUntil(1)
+		Expect(clk.Waiters()).To(Equal(1))
+	})
+})
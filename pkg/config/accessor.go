@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/mitchellh/mapstructure"
@@ -108,6 +109,67 @@ func GetNumberOrDefault[T ~int | ~int8 | ~int16 | ~int32 | ~int64 |
 	return defaultValue
 }
 
+// GetBool retrieves a value at the given path as a bool, with the same weak
+// typing as GetInto: "true", "1", and non-zero numbers are true.
+// Returns false and false if the key doesn't exist or can't be converted.
+func (this O) GetBool(path string) (bool, bool) {
+	var b bool
+	if err := this.GetInto(path, &b); err != nil {
+		return false, false
+	}
+	return b, true
+}
+
+// GetBoolOrDefault retrieves a value at the given path as a bool.
+// Returns the provided default value if the key doesn't exist or can't be converted.
+func (this O) GetBoolOrDefault(path string, defaultValue bool) bool {
+	if b, ok := this.GetBool(path); ok {
+		return b
+	}
+	return defaultValue
+}
+
+// GetDuration retrieves a value at the given path as a duration. Strings are
+// parsed with time.ParseDuration ("500ms"); numbers are nanoseconds, as with
+// GetInto. Returns 0 and false if the key doesn't exist or can't be converted.
+func (this O) GetDuration(path string) (time.Duration, bool) {
+	var d time.Duration
+	if err := this.GetInto(path, &d); err != nil {
+		return 0, false
+	}
+	return d, true
+}
+
+// GetDurationOrDefault retrieves a value at the given path as a duration.
+// Returns the provided default value if the key doesn't exist or can't be converted.
+func (this O) GetDurationOrDefault(path string, defaultValue time.Duration) time.Duration {
+	if d, ok := this.GetDuration(path); ok {
+		return d
+	}
+	return defaultValue
+}
+
+// GetStringSlice retrieves a value at the given path as a []string, with the
+// same weak typing as GetInto: list items are converted to strings and a
+// single value becomes a one-item list. Returns nil and false if the key
+// doesn't exist or can't be converted.
+func (this O) GetStringSlice(path string) ([]string, bool) {
+	var s []string
+	if err := this.GetInto(path, &s); err != nil {
+		return nil, false
+	}
+	return s, true
+}
+
+// GetStringSliceOrDefault retrieves a value at the given path as a []string.
+// Returns the provided default value if the key doesn't exist or can't be converted.
+func (this O) GetStringSliceOrDefault(path string, defaultValue []string) []string {
+	if s, ok := this.GetStringSlice(path); ok {
+		return s
+	}
+	return defaultValue
+}
+
 // GetIntoOption is a functional option for GetInto.
 type GetIntoOption func(*getIntoOptions)
 
@@ -1,6 +1,8 @@
 package config_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"gopkg.in/yaml.v3"
@@ -279,6 +281,55 @@ name: test
 		})
 	})
 
+	Describe("GetBool, GetDuration, and GetStringSlice", func() {
+		var cfg config.O
+
+		BeforeEach(func() {
+			yamlData := `
+debug: true
+verbose: "1"
+quiet: 0
+timeout: 500ms
+poll: 1000000
+hosts: [a, b, 3]
+host: single
+nested:
+  retry: 2s
+name: test
+`
+			Expect(yaml.Unmarshal([]byte(yamlData), &cfg)).To(Succeed())
+		})
+
+		It("should convert bools weakly", func() {
+			for path, want := range map[string]bool{"debug": true, "verbose": true, "quiet": false} {
+				b, ok := cfg.GetBool(path)
+				Expect(ok).To(BeTrue(), path)
+				Expect(b).To(Equal(want), path)
+			}
+			_, ok := cfg.GetBool("name")
+			Expect(ok).To(BeFalse())
+			Expect(cfg.GetBoolOrDefault("missing", true)).To(BeTrue())
+		})
+
+		It("should parse durations", func() {
+			d, ok := cfg.GetDuration("timeout")
+			Expect(ok).To(BeTrue())
+			Expect(d).To(Equal(500 * time.Millisecond))
+			Expect(cfg.GetDurationOrDefault("nested.retry", time.Second)).To(Equal(2 * time.Second))
+			Expect(cfg.GetDurationOrDefault("poll", time.Second)).To(Equal(time.Millisecond))
+			Expect(cfg.GetDurationOrDefault("name", time.Second)).To(Equal(time.Second))
+			Expect(cfg.GetDurationOrDefault("missing", time.Second)).To(Equal(time.Second))
+		})
+
+		It("should convert string slices weakly", func() {
+			s, ok := cfg.GetStringSlice("hosts")
+			Expect(ok).To(BeTrue())
+			Expect(s).To(Equal([]string{"a", "b", "3"}))
+			Expect(cfg.GetStringSliceOrDefault("host", nil)).To(Equal([]string{"single"}))
+			Expect(cfg.GetStringSliceOrDefault("missing", []string{"x"})).To(Equal([]string{"x"}))
+		})
+	})
+
 	Describe("GetInto", func() {
 		var cfg config.O
 
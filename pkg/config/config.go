@@ -2,12 +2,19 @@ package config
 
 import (
 	"bytes"
+	"cmp"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"text/template"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/gur-shatz/go-run/internal/mask"
 )
 
 const (
@@ -19,8 +26,16 @@ const (
 type Option func(*options)
 
 type options struct {
-	vars map[string]string // additional template vars (below env priority)
-	env  map[string]string // override env source (default: os.Environ())
+	vars         map[string]string // additional template vars (below env priority)
+	env          map[string]string // override env source (default: os.Environ())
+	dir          string            // base for include: paths (default: working dir)
+	format       Format            // input syntax (default: sniffed)
+	dotEnv       []string          // .env files (between env and vars)
+	strict       bool              // decode strictly (see IsStrict)
+	overlays     []string          // files deep-merged over the config
+	shell        bool              // enable the shell template function
+	funcs        template.FuncMap  // extra template functions
+	onDeprecated func(Warning)     // reports deprecated keys (default: log a warning)
 }
 
 // WithVars provides additional template variables.
@@ -40,50 +55,123 @@ func WithEnv(env map[string]string) Option {
 	}
 }
 
-// ProcessFile reads a YAML file, processes Go templates, and returns
-// the processed bytes ready for unmarshaling, plus resolved vars.
+// WithBaseDir sets the directory include: paths are relative to.
+// By default, it is the working directory.
+func WithBaseDir(dir string) Option {
+	return func(o *options) {
+		o.dir = dir
+	}
+}
+
+// WithFormat sets the syntax of the input. By default Process sniffs it and
+// ProcessFile goes by the file extension.
+func WithFormat(f Format) Option {
+	return func(o *options) {
+		o.format = f
+	}
+}
+
+// WithFuncs adds template functions, e.g. a secret store lookup, for
+// applications embedding this package. A function with a built-in's name
+// replaces the built-in.
+func WithFuncs(funcs template.FuncMap) Option {
+	return func(o *options) {
+		if o.funcs == nil {
+			o.funcs = template.FuncMap{}
+		}
+		maps.Copy(o.funcs, funcs)
+	}
+}
+
+// Stdin is the config path that reads the config from standard input, e.g.
+// `envsubst < tmpl | runctl -c -`. Its include: paths are relative to the
+// working directory, and its format is sniffed.
+const Stdin = "-"
+
+// ProcessFile reads a YAML, JSON, or TOML file (by extension, else sniffed),
+// processes Go templates, and returns the processed YAML ready for
+// unmarshaling, plus resolved vars. include: paths are relative to the
+// file's directory. A path of Stdin reads standard input.
 func ProcessFile(path string, opts ...Option) ([]byte, map[string]string, error) {
-	data, err := os.ReadFile(path)
+	var data []byte
+	var err error
+	if path == Stdin {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
 	if err != nil {
 		return nil, nil, fmt.Errorf("read config %s: %w", path, err)
 	}
-	return Process(data, opts...)
+	return Process(data, append([]Option{WithBaseDir(filepath.Dir(path)), WithFormat(DetectFormat(path, data))}, opts...)...)
 }
 
-// Process processes raw YAML bytes as a Go template.
+// Process processes raw config bytes as a Go template.
 // Returns processed bytes and resolved vars from the vars: section.
 //
 // Template features:
+//   - YAML, JSON, or TOML input (JSON and TOML are converted to YAML first)
+//   - !encrypted values and sops files decrypted with age keys, per file
+//   - include: list of files/globs merged in before templating
+//   - WithOverlay files deep-merged over the result, also before templating
+//   - env_file: list of .env files loaded as template variables
 //   - vars: section for defining template variables
 //   - Dual delimiters: {{ .VAR }} and [[ .VAR ]]
-//   - Template functions: default, required, env, add
+//   - Template functions: default, required, env, add, file, shell (opt-in),
+//     and any added with WithFuncs
 //   - Iterative resolution (max 10 passes) for recursive var definitions
-//   - Priority: env vars > WithVars() > config's vars: section
+//   - Priority: env vars > .env files > WithVars() > config's vars: section
 func Process(data []byte, opts ...Option) ([]byte, map[string]string, error) {
 	o := &options{}
 	for _, opt := range opts {
 		opt(o)
 	}
 
+	data, err := toYAML(data, cmp.Or(o.format, sniffFormat(data)))
+	if err != nil {
+		return nil, nil, err
+	}
+	if data, err = decrypt(data); err != nil {
+		return nil, nil, err
+	}
+	data, err = resolveIncludes(data, cmp.Or(o.dir, "."))
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err = applyOverlays(data, o.overlays)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Build env map
 	env := o.env
 	if env == nil {
 		env = environMap()
 	}
 
-	// Merge WithVars into env (env wins)
-	if o.vars != nil {
-		merged := make(map[string]string, len(env)+len(o.vars))
+	// .env files from WithDotEnv, then env_file: (later files win)
+	dotEnv, err := ReadDotEnv(cmp.Or(o.dir, "."), append(slices.Clip(o.dotEnv), envFileList(data)...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Merge WithVars and .env files into env (env wins, then .env files)
+	if o.vars != nil || len(dotEnv) > 0 {
+		merged := make(map[string]string, len(env)+len(dotEnv)+len(o.vars))
 		for k, v := range o.vars {
 			merged[k] = v
 		}
+		for k, v := range dotEnv {
+			merged[k] = v
+		}
 		for k, v := range env {
 			merged[k] = v
 		}
 		env = merged
 	}
 
-	result, err := processRawConfig(data, env)
+	dir := cmp.Or(o.dir, ".")
+	result, err := processRawConfig(data, env, dir, newShellRunner(dir, o.shell), o.funcs)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -108,11 +196,11 @@ func Process(data []byte, opts ...Option) ([]byte, map[string]string, error) {
 // It resolves the vars section first (iteratively, to handle inter-var
 // dependencies), then applies the fully-resolved vars to the rest of
 // the config in a single pass.
-func processRawConfig(data []byte, env map[string]string) ([]byte, error) {
+func processRawConfig(data []byte, env map[string]string, dir string, shell *shellRunner, funcs template.FuncMap) ([]byte, error) {
 	original := data
 
 	// Phase 1: resolve vars iteratively.
-	resolvedVars, err := resolveVars(data, env)
+	resolvedVars, err := resolveVars(data, env, dir, shell, funcs)
 	if err != nil {
 		return nil, err
 	}
@@ -129,12 +217,12 @@ func processRawConfig(data []byte, env map[string]string) ([]byte, error) {
 
 	result := data
 
-	result, err = executeTemplate(result, templateData, "[[", "]]", env)
+	result, err = executeTemplate(result, templateData, "[[", "]]", env, dir, shell, funcs)
 	if err != nil {
 		return nil, fmt.Errorf("template error (using [[ ]]): %w", err)
 	}
 
-	result, err = executeTemplate(result, templateData, "{{", "}}", env)
+	result, err = executeTemplate(result, templateData, "{{", "}}", env, dir, shell, funcs)
 	if err != nil {
 		return nil, fmt.Errorf("template error (using {{ }}): %w", err)
 	}
@@ -161,7 +249,7 @@ func processRawConfig(data []byte, env map[string]string) ([]byte, error) {
 // resolveVars extracts the vars section from YAML and resolves template
 // expressions iteratively. Each pass resolves vars whose dependencies
 // are already resolved, until all vars are stable or max iterations reached.
-func resolveVars(data []byte, env map[string]string) (map[string]string, error) {
+func resolveVars(data []byte, env map[string]string, dir string, shell *shellRunner, funcs template.FuncMap) (map[string]string, error) {
 	var rawCfg struct {
 		Vars map[string]any `yaml:"vars"`
 	}
@@ -195,7 +283,7 @@ func resolveVars(data []byte, env map[string]string) (map[string]string, error)
 			}
 
 			// Try to resolve this var's expression
-			val, err := ResolveExpr(expr, td, env)
+			val, err := resolveExpr(expr, td, env, dir, shell, funcs)
 			if err != nil {
 				continue // dependency not yet resolved
 			}
@@ -226,7 +314,7 @@ func resolveVars(data []byte, env map[string]string) (map[string]string, error)
 			td[k] = v
 		}
 		for k, expr := range unresolved {
-			_, err := ResolveExpr(expr, td, env)
+			_, err := resolveExpr(expr, td, env, dir, shell, funcs)
 			if err != nil {
 				return nil, fmt.Errorf("var %q: %w", k, err)
 			}
@@ -238,12 +326,17 @@ func resolveVars(data []byte, env map[string]string) (map[string]string, error)
 }
 
 // ResolveExpr evaluates a single template expression string, trying
-// both [[ ]] and {{ }} delimiters.
+// both [[ ]] and {{ }} delimiters. secretFile and file paths are relative
+// to the working directory, as are shell commands if SetShell enabled them.
 func ResolveExpr(expr string, templateData map[string]any, env map[string]string) (string, error) {
+	return resolveExpr(expr, templateData, env, ".", newShellRunner(".", false), nil)
+}
+
+func resolveExpr(expr string, templateData map[string]any, env map[string]string, dir string, shell *shellRunner, funcs template.FuncMap) (string, error) {
 	result := expr
 
 	if strings.Contains(result, "[[") {
-		out, err := executeTemplate([]byte(result), templateData, "[[", "]]", env)
+		out, err := executeTemplate([]byte(result), templateData, "[[", "]]", env, dir, shell, funcs)
 		if err != nil {
 			return "", err
 		}
@@ -251,7 +344,7 @@ func ResolveExpr(expr string, templateData map[string]any, env map[string]string
 	}
 
 	if strings.Contains(result, "{{") {
-		out, err := executeTemplate([]byte(result), templateData, "{{", "}}", env)
+		out, err := executeTemplate([]byte(result), templateData, "{{", "}}", env, dir, shell, funcs)
 		if err != nil {
 			return "", err
 		}
@@ -262,11 +355,14 @@ func ResolveExpr(expr string, templateData map[string]any, env map[string]string
 }
 
 // executeTemplate runs Go template substitution with the given delimiters.
-func executeTemplate(data []byte, templateData map[string]any, leftDelim, rightDelim string, env map[string]string) ([]byte, error) {
+// dir is what secretFile and file paths are relative to; shell is nil when
+// the shell function is disabled. funcs are added over the built-ins.
+func executeTemplate(data []byte, templateData map[string]any, leftDelim, rightDelim string, env map[string]string, dir string, shell *shellRunner, funcs template.FuncMap) ([]byte, error) {
 	tmpl, err := template.New("config").
 		Delims(leftDelim, rightDelim).
 		Option("missingkey=zero").
-		Funcs(templateFuncs(env)).
+		Funcs(templateFuncs(env, dir, shell)).
+		Funcs(funcs).
 		Parse(string(data))
 	if err != nil {
 		return nil, err
@@ -281,8 +377,8 @@ func executeTemplate(data []byte, templateData map[string]any, leftDelim, rightD
 }
 
 // templateFuncs returns custom functions available in templates.
-func templateFuncs(env map[string]string) template.FuncMap {
-	return template.FuncMap{
+func templateFuncs(env map[string]string, dir string, shell *shellRunner) template.FuncMap {
+	funcs := template.FuncMap{
 		"default": func(def, val any) any {
 			if val == nil {
 				return def
@@ -297,6 +393,41 @@ func templateFuncs(env map[string]string) template.FuncMap {
 			return env[name]
 		},
 
+		// secretFile reads a secret from a file (trailing newline dropped)
+		// and registers it for masking in logs and API responses.
+		// Usage: {{ secretFile ".secrets/db_password" }}
+		"secretFile": func(path string) (string, error) {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", fmt.Errorf("secretFile: %w", err)
+			}
+			v := strings.TrimRight(string(data), "\r\n")
+			mask.Register(v)
+			return v, nil
+		},
+
+		// file reads a file, trailing newline dropped.
+		// Usage: {{ file "VERSION" }}
+		"file": func(path string) (string, error) {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", fmt.Errorf("file: %w", err)
+			}
+			return strings.TrimRight(string(data), "\r\n"), nil
+		},
+
+		// shell runs a command with sh -c in dir and returns its output,
+		// trailing newline dropped. Disabled unless WithShell or SetShell
+		// enabled it.
+		// Usage: {{ shell "git rev-parse --short HEAD" }}
+		"shell": shell.run,
+
 		"required": func(msg string, val any) (any, error) {
 			if val == nil {
 				return nil, fmt.Errorf("%s", msg)
@@ -331,6 +462,8 @@ func templateFuncs(env map[string]string) template.FuncMap {
 			return aInt + bInt, nil
 		},
 	}
+	maps.Copy(funcs, stringFuncs())
+	return funcs
 }
 
 // toInt converts various numeric types to int.
@@ -377,20 +510,72 @@ func toInt(v any) (int, error) {
 	}
 }
 
-// removeVarsSection removes the vars: top-level key from YAML bytes.
+// removeVarsSection removes the vars: top-level key from YAML bytes. The
+// rest is left as written: in a block mapping the key's lines, with the
+// comments right above it, are cut out; a flow mapping is re-marshaled from
+// its node tree, keeping key order, comments, and anchors.
 func removeVarsSection(data []byte) []byte {
-	var raw map[string]any
-	if err := yaml.Unmarshal(data, &raw); err != nil {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
 		return data
 	}
-	delete(raw, varsKey)
-	out, err := yaml.Marshal(raw)
-	if err != nil {
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return data
+	}
+	i := mappingIndex(root, varsKey)
+	if i < 0 {
 		return data
 	}
+
+	var out []byte
+	if root.Style&yaml.FlowStyle == 0 {
+		end := -1
+		if i+2 < len(root.Content) {
+			end = root.Content[i+2].Line
+		}
+		out = cutLines(data, root.Content[i].Line, end)
+	} else {
+		root.Content = append(root.Content[:i:i], root.Content[i+2:]...)
+		out, _ = yaml.Marshal(&doc)
+	}
+
+	// An alias into vars: can't survive either; decode it away instead.
+	var check any
+	if out == nil || yaml.Unmarshal(out, &check) != nil {
+		var raw map[string]any
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return data
+		}
+		delete(raw, varsKey)
+		var err error
+		if out, err = yaml.Marshal(raw); err != nil {
+			return data
+		}
+	}
 	return out
 }
 
+// cutLines removes the lines from start up to end (1-based, end exclusive;
+// -1 for the rest), together with the comment lines right above start. The
+// comment lines right above end stay, as they describe what follows.
+func cutLines(data []byte, start, end int) []byte {
+	lines := strings.SplitAfter(string(data), "\n")
+	isComment := func(l string) bool { return strings.HasPrefix(strings.TrimSpace(l), "#") }
+	from := start - 1
+	for from > 0 && isComment(lines[from-1]) {
+		from--
+	}
+	to := len(lines)
+	if end > 0 {
+		to = end - 1
+		for to > from && isComment(lines[to-1]) {
+			to--
+		}
+	}
+	return []byte(strings.Join(slices.Concat(lines[:from], lines[to:]), ""))
+}
+
 // environMap converts os.Environ() to a map.
 func environMap() map[string]string {
 	env := make(map[string]string)
@@ -0,0 +1,37 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/gur-shatz/go-run/pkg/config"
+)
+
+// FuzzProcess runs arbitrary config bytes through the template processor.
+// Bad templates and YAML must come back as errors, never panics.
+func FuzzProcess(f *testing.F) {
+	f.Add([]byte("vars:\n  PORT: \"8080\"\nrun: \"serve --port {{ .PORT }}\"\n"))
+	f.Add([]byte("vars:\n  A: \"{{ .B }}\"\n  B: \"{{ .A }}\"\nx: [[ .A ]]\n"))
+	f.Add([]byte("port: {{ add .PORT 1 }}\nname: {{ required \"name\" .NAME }}\n"))
+	f.Add([]byte("x: {{ .PORT | asInt | int }}\ny: {{ default \"d\" (env \"HOME\") }}\n"))
+	f.Add([]byte("{{ define \"t\" }}{{ template \"t\" }}{{ end }}{{ template \"t\" }}"))
+	f.Add([]byte("vars:\n  - not\n  - a map\n{{"))
+
+	env := map[string]string{"PORT": "9000", "HOME": "/home/fuzz"}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		config.Process(data, config.WithEnv(env), config.WithVars(map[string]string{"NAME": "fuzz"}))
+	})
+}
+
+// FuzzResolveExpr evaluates arbitrary single expressions with both
+// delimiter styles.
+func FuzzResolveExpr(f *testing.F) {
+	f.Add("{{ .HOST }}:[[ .PORT ]]")
+	f.Add("[[ add .PORT \"x\" ]]")
+	f.Add("{{ required \"missing\" .NOPE }}")
+	f.Add("[[ {{ ]] }}")
+
+	data := map[string]any{"HOST": "localhost", "PORT": "8080"}
+	f.Fuzz(func(t *testing.T, expr string) {
+		config.ResolveExpr(expr, data, map[string]string{})
+	})
+}
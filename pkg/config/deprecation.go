@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gur-shatz/go-run/internal/log"
+)
+
+// Deprecation is a config key that was renamed. Configs using the old key
+// keep working: the key is renamed before the config is validated and
+// decoded, and a Warning is reported.
+type Deprecation struct {
+	Old   string // dot path of the old key; * matches any key, as in targets.*.exec
+	New   string // new name of the key's last element
+	Since string // version that renamed it
+}
+
+// Deprecations is a config type's list of renamed keys.
+type Deprecations []Deprecation
+
+// Warning is a deprecated key found in a config file.
+type Warning struct {
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	Key         string `json:"key"`         // path of the old key, e.g. targets.api.exec
+	Replacement string `json:"replacement"` // path to use instead, e.g. targets.api.run
+	Since       string `json:"since,omitempty"`
+}
+
+func (this Warning) String() string {
+	s := fmt.Sprintf("%s:%d: %s is deprecated, use %s", this.File, this.Line, this.Key, this.Replacement)
+	if this.Since != "" {
+		s += " (since " + this.Since + ")"
+	}
+	return s
+}
+
+// warnDeprecated is set by SetDeprecationHandler.
+var warnDeprecated = logDeprecated
+
+func logDeprecated(w Warning) {
+	log.Warn("%s", w)
+}
+
+// SetDeprecationHandler replaces how deprecated keys are reported when no
+// WithDeprecationHandler option is given. By default, and with a nil fn,
+// they are logged as warnings.
+func SetDeprecationHandler(fn func(Warning)) {
+	if fn == nil {
+		fn = logDeprecated
+	}
+	warnDeprecated = fn
+}
+
+// WithDeprecationHandler reports deprecated keys to fn instead of the
+// default handler, e.g. to collect them for a lint command.
+func WithDeprecationHandler(fn func(Warning)) Option {
+	return func(o *options) {
+		o.onDeprecated = fn
+	}
+}
+
+// Apply renames the deprecated keys in data, a processed YAML config read
+// from file, and reports each one. opts are the options the config was
+// processed with. It fails if a config sets both a key and its new name.
+func (this Deprecations) Apply(data []byte, file string, opts ...Option) ([]byte, error) {
+	if len(this) == 0 {
+		return data, nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return data, nil // decoding reports the syntax error
+	}
+	var found []Warning
+	for _, d := range this {
+		if err := d.rename(doc.Content[0], strings.Split(d.Old, "."), nil, file, &found); err != nil {
+			return nil, err
+		}
+	}
+	if len(found) == 0 {
+		return data, nil
+	}
+
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	report := warnDeprecated
+	if o.onDeprecated != nil {
+		report = o.onDeprecated
+	}
+	for _, w := range found {
+		report(w)
+	}
+	return yaml.Marshal(&doc)
+}
+
+// rename renames the keys under n that path matches; at is the path to n.
+func (this Deprecation) rename(n *yaml.Node, path, at []string, file string, found *[]Warning) error {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key := n.Content[i]
+		if path[0] != "*" && key.Value != path[0] {
+			continue
+		}
+		here := append(slices.Clip(at), key.Value)
+		if len(path) > 1 {
+			if err := this.rename(n.Content[i+1], path[1:], here, file, found); err != nil {
+				return err
+			}
+			continue
+		}
+		old := strings.Join(here, ".")
+		replacement := strings.Join(append(here[:len(here)-1:len(here)-1], this.New), ".")
+		if mappingIndex(n, this.New) >= 0 {
+			return fmt.Errorf("%s:%d: %s is deprecated and %s is set too; remove %s", file, key.Line, old, replacement, old)
+		}
+		key.Value = this.New
+		*found = append(*found, Warning{File: file, Line: key.Line, Key: old, Replacement: replacement, Since: this.Since})
+	}
+	return nil
+}
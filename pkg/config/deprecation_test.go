@@ -0,0 +1,75 @@
+package config_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gur-shatz/go-run/pkg/config"
+)
+
+var _ = Describe("Deprecations", func() {
+	deprecations := config.Deprecations{
+		{Old: "exec", New: "run", Since: "v1.2"},
+		{Old: "targets.*.wait_for", New: "depends_on"},
+	}
+
+	var warnings []config.Warning
+
+	BeforeEach(func() {
+		warnings = nil
+	})
+
+	apply := func(data string) (map[string]any, error) {
+		out, err := deprecations.Apply([]byte(data), "app.yaml", config.WithDeprecationHandler(func(w config.Warning) {
+			warnings = append(warnings, w)
+		}))
+		if err != nil {
+			return nil, err
+		}
+		var v map[string]any
+		Expect(yaml.Unmarshal(out, &v)).To(Succeed())
+		return v, nil
+	}
+
+	It("renames old keys and reports each one", func() {
+		v, err := apply(`
+exec: [./app]
+targets:
+  api:
+    wait_for: [tcp://localhost:5432]
+  web:
+    cmd: ./web
+`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(HaveKeyWithValue("run", []any{"./app"}))
+		Expect(v).NotTo(HaveKey("exec"))
+		Expect(v["targets"]).To(HaveKeyWithValue("api", map[string]any{"depends_on": []any{"tcp://localhost:5432"}}))
+		Expect(warnings).To(Equal([]config.Warning{
+			{File: "app.yaml", Line: 2, Key: "exec", Replacement: "run", Since: "v1.2"},
+			{File: "app.yaml", Line: 5, Key: "targets.api.wait_for", Replacement: "targets.api.depends_on"},
+		}))
+		Expect(warnings[0].String()).To(Equal("app.yaml:2: exec is deprecated, use run (since v1.2)"))
+	})
+
+	It("leaves configs without old keys untouched", func() {
+		data := []byte("# as written\nrun: [./app]\n")
+		out, err := deprecations.Apply(data, "app.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal(data))
+	})
+
+	It("rejects a config that sets both the old and the new key", func() {
+		_, err := apply("exec: [./a]\nrun: [./b]\n")
+		Expect(err).To(MatchError("app.yaml:1: exec is deprecated and run is set too; remove exec"))
+	})
+
+	It("reports to the default handler without an option", func() {
+		var got []config.Warning
+		config.SetDeprecationHandler(func(w config.Warning) { got = append(got, w) })
+		DeferCleanup(func() { config.SetDeprecationHandler(nil) })
+		_, err := deprecations.Apply([]byte("exec: [./app]\n"), "app.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const envFileKey = "env_file"
+
+// EnvFiles is the env_file: key, either one path or a list of paths.
+type EnvFiles []string
+
+func (this *EnvFiles) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var one string
+		if err := node.Decode(&one); err != nil {
+			return err
+		}
+		*this = EnvFiles{one}
+		return nil
+	}
+	return node.Decode((*[]string)(this))
+}
+
+// Abs returns the paths with relative ones resolved against dir.
+func (this EnvFiles) Abs(dir string) EnvFiles {
+	if len(this) == 0 {
+		return this
+	}
+	out := make(EnvFiles, len(this))
+	for i, p := range this {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		out[i] = p
+	}
+	return out
+}
+
+// WithDotEnv loads KEY=VALUE pairs from .env files as template variables.
+// Relative paths are resolved like include: paths. The files are read before
+// the config's own env_file: list, and later files win.
+func WithDotEnv(paths ...string) Option {
+	return func(o *options) {
+		o.dotEnv = append(o.dotEnv, paths...)
+	}
+}
+
+// ReadDotEnv reads .env files in order and returns their merged pairs;
+// later files win. Relative paths are resolved against dir.
+func ReadDotEnv(dir string, paths ...string) (map[string]string, error) {
+	vars := make(map[string]string)
+	for _, path := range EnvFiles(paths).Abs(dir) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read env file %s: %w", path, err)
+		}
+		fileVars, err := ParseDotEnv(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse env file %s: %w", path, err)
+		}
+		maps.Copy(vars, fileVars)
+	}
+	return vars, nil
+}
+
+// dotEnvLineRe matches an assignment, with an optional export prefix.
+var dotEnvLineRe = regexp.MustCompile(`^(?:export\s+)?([A-Za-z_][A-Za-z0-9_.]*)\s*=\s*(.*)$`)
+
+// ParseDotEnv parses .env syntax: KEY=VALUE lines, # comments, an optional
+// export prefix, and values that are bare (trailing " #" comments are
+// dropped), 'single-quoted' (literal), or "double-quoted" (Go escapes).
+func ParseDotEnv(data []byte) (map[string]string, error) {
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		m := dotEnvLineRe.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", n)
+		}
+		val, err := dotEnvValue(m[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		vars[m[1]] = val
+	}
+	return vars, scanner.Err()
+}
+
+func dotEnvValue(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, "'"):
+		end := strings.Index(raw[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quote")
+		}
+		return raw[1 : end+1], nil
+	case strings.HasPrefix(raw, `"`):
+		quoted, err := strconv.QuotedPrefix(raw)
+		if err != nil {
+			return "", fmt.Errorf("invalid double-quoted value")
+		}
+		return strconv.Unquote(quoted)
+	}
+	if i := strings.Index(raw, " #"); i >= 0 {
+		raw = raw[:i]
+	}
+	return strings.TrimSpace(raw), nil
+}
+
+// envFileList returns the env_file: paths of a raw YAML config. Errors are
+// left to the decoder that runs later.
+func envFileList(data []byte) []string {
+	if !bytes.Contains(data, []byte(envFileKey)) {
+		return nil
+	}
+	var raw struct {
+		EnvFile EnvFiles `yaml:"env_file"`
+	}
+	yaml.Unmarshal(data, &raw)
+	return raw.EnvFile
+}
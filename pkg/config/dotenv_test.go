@@ -0,0 +1,75 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/config"
+)
+
+var _ = Describe("DotEnv", func() {
+	var dir string
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	It("parses comments, export, and quoting", func() {
+		vars, err := config.ParseDotEnv([]byte(`
+# comment
+PLAIN=value # trailing comment
+export EXPORTED=yes
+SINGLE='keep $HOME # as is'
+DOUBLE="line\nbreak \"quoted\""
+EMPTY=
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vars).To(Equal(map[string]string{
+			"PLAIN":    "value",
+			"EXPORTED": "yes",
+			"SINGLE":   "keep $HOME # as is",
+			"DOUBLE":   "line\nbreak \"quoted\"",
+			"EMPTY":    "",
+		}))
+	})
+
+	It("reports the line of a malformed entry", func() {
+		_, err := config.ParseDotEnv([]byte("A=1\nnot an assignment\n"))
+		Expect(err).To(MatchError("line 2: expected KEY=VALUE"))
+	})
+
+	It("loads env_file: and WithDotEnv files in order, below the environment", func() {
+		write("base.env", "A=base\nB=base\nC=base\n")
+		write("local.env", "B=local\n")
+		cfgPath := write("app.yaml", `
+env_file: [base.env, local.env]
+vars:
+  A: default
+out: "{{ .A }} {{ .B }} {{ .C }} {{ .D }}"
+`)
+		extra := write("extra.env", "C=extra\nD=extra\nB=extra\n")
+
+		result, vars, err := config.ProcessFile(cfgPath,
+			config.WithEnv(map[string]string{"C": "env"}),
+			config.WithVars(map[string]string{"D": "parent"}),
+			config.WithDotEnv(extra))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(result)).To(ContainSubstring(`out: "base local env extra"`))
+		Expect(vars).To(Equal(map[string]string{"A": "default"}))
+	})
+
+	It("fails on a missing env_file", func() {
+		cfgPath := write("app.yaml", "env_file: missing.env\nout: x\n")
+		_, _, err := config.ProcessFile(cfgPath, config.WithEnv(map[string]string{}))
+		Expect(err).To(MatchError(ContainSubstring("read env file " + filepath.Join(dir, "missing.env"))))
+	})
+})
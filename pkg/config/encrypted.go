@@ -0,0 +1,192 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	sopsdecrypt "github.com/getsops/sops/v3/decrypt"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gur-shatz/go-run/internal/mask"
+)
+
+const (
+	encryptedTag = "!encrypted"
+	sopsKey      = "sops"
+)
+
+// sopsValueRe matches a value encrypted by sops; group 1 is its type.
+var sopsValueRe = regexp.MustCompile(`^ENC\[AES256_GCM,data:[^,]*,iv:[^,]+,tag:[^,]+,type:([a-z]+)\]$`)
+
+// decrypt replaces the !encrypted values of YAML data with their plaintext,
+// using the age keys from the environment (see ageIdentities), or, for a
+// file encrypted with sops, returns sops' decryption of it. !encrypted
+// values, and the string values of a sops file, are registered for
+// masking. Data with neither is returned unchanged.
+func decrypt(data []byte) ([]byte, error) {
+	tagged := bytes.Contains(data, []byte(encryptedTag))
+	if !tagged && !bytes.Contains(data, []byte(sopsKey+":")) {
+		return data, nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		if tagged {
+			return nil, fmt.Errorf("decrypt: %w (files with !encrypted values must be valid YAML before templating)", err)
+		}
+		return data, nil // not ours to report; template processing will
+	}
+	if len(doc.Content) == 0 {
+		return data, nil
+	}
+	root := doc.Content[0]
+
+	if root.Kind == yaml.MappingNode {
+		if i := mappingIndex(root, sopsKey); i >= 0 && mappingIndex(root.Content[i+1], "mac") >= 0 {
+			return decryptSOPS(data, root)
+		}
+	}
+	if !tagged {
+		return data, nil
+	}
+	found, err := decryptTagged(root)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return data, nil
+	}
+	return yaml.Marshal(&doc)
+}
+
+// decryptTagged decrypts the !encrypted scalars under n: age files, armored
+// or base64-encoded. It reports whether there were any.
+func decryptTagged(n *yaml.Node) (bool, error) {
+	if n.Kind == yaml.ScalarNode && n.Tag == encryptedTag {
+		var ciphertext io.Reader
+		if value := strings.TrimSpace(n.Value); strings.HasPrefix(value, armor.Header) {
+			ciphertext = armor.NewReader(strings.NewReader(value))
+		} else {
+			raw, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(n.Value), ""))
+			if err != nil {
+				return false, fmt.Errorf("decrypt line %d: value is neither armored nor base64: %w", n.Line, err)
+			}
+			ciphertext = bytes.NewReader(raw)
+		}
+		ids, err := ageIdentities()
+		if err != nil {
+			return false, fmt.Errorf("decrypt line %d: %w", n.Line, err)
+		}
+		r, err := age.Decrypt(ciphertext, ids...)
+		if err != nil {
+			return false, fmt.Errorf("decrypt line %d: %w", n.Line, err)
+		}
+		plaintext, err := io.ReadAll(r)
+		if err != nil {
+			return false, fmt.Errorf("decrypt line %d: %w", n.Line, err)
+		}
+		mask.Register(string(plaintext))
+		n.Tag, n.Value, n.Style = "!!str", string(plaintext), 0
+		return true, nil
+	}
+	found := false
+	for _, c := range n.Content {
+		ok, err := decryptTagged(c)
+		if err != nil {
+			return false, err
+		}
+		found = found || ok
+	}
+	return found, nil
+}
+
+// decryptSOPS decrypts data, a file encrypted with sops whose parsed form is
+// root, with sops' own decrypt package, which finds the keys the way the
+// sops command does and checks the file's MAC. The sops metadata is dropped.
+func decryptSOPS(data []byte, root *yaml.Node) ([]byte, error) {
+	plain, err := sopsdecrypt.Data(data, "yaml")
+	if err != nil {
+		return nil, fmt.Errorf("sops: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(plain, &doc); err != nil {
+		return nil, fmt.Errorf("sops: %w", err)
+	}
+	if len(doc.Content) > 0 {
+		maskSOPSStrings(root, doc.Content[0])
+	}
+	return plain, nil
+}
+
+// maskSOPSStrings registers the plaintext in dec of every string value that
+// is encrypted in enc, the same document before sops decrypted it.
+func maskSOPSStrings(enc, dec *yaml.Node) {
+	if enc.Kind != dec.Kind {
+		return
+	}
+	switch enc.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(enc.Content); i += 2 {
+			if j := mappingIndex(dec, enc.Content[i].Value); j >= 0 {
+				maskSOPSStrings(enc.Content[i+1], dec.Content[j+1])
+			}
+		}
+	case yaml.SequenceNode:
+		for i := 0; i < len(enc.Content) && i < len(dec.Content); i++ {
+			maskSOPSStrings(enc.Content[i], dec.Content[i])
+		}
+	case yaml.ScalarNode:
+		if m := sopsValueRe.FindStringSubmatch(enc.Value); m != nil && (m[1] == "str" || m[1] == "bytes") {
+			mask.Register(dec.Value)
+		}
+	}
+}
+
+// ageIdentities returns the age keys sops would use: the keys in
+// SOPS_AGE_KEY, then those in the SOPS_AGE_KEY_FILE file, which defaults to
+// sops/age/keys.txt in the user config directory.
+func ageIdentities() ([]age.Identity, error) {
+	var ids []age.Identity
+	if keys := os.Getenv("SOPS_AGE_KEY"); keys != "" {
+		parsed, err := age.ParseIdentities(strings.NewReader(keys))
+		if err != nil {
+			return nil, fmt.Errorf("SOPS_AGE_KEY: %w", err)
+		}
+		ids = append(ids, parsed...)
+	}
+	path := os.Getenv("SOPS_AGE_KEY_FILE")
+	if path == "" {
+		if dir, err := os.UserConfigDir(); err == nil {
+			if def := filepath.Join(dir, "sops", "age", "keys.txt"); fileExists(def) {
+				path = def
+			}
+		}
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read age key file: %w", err)
+		}
+		parsed, err := age.ParseIdentities(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("age key file %s: %w", path, err)
+		}
+		ids = append(ids, parsed...)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no age key found; set SOPS_AGE_KEY or SOPS_AGE_KEY_FILE")
+	}
+	return ids, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
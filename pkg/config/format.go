@@ -0,0 +1,98 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a config file syntax. JSON and TOML files are converted to YAML
+// before includes and templates are processed, so all three behave the same.
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+	FormatTOML Format = "toml"
+)
+
+// tomlHeaderRe matches a TOML table header such as [vars] or [[targets]].
+var tomlHeaderRe = regexp.MustCompile(`^\[\[?\s*[A-Za-z0-9_"-][A-Za-z0-9_." -]*\]\]?\s*(#.*)?$`)
+
+// tomlKeyRe matches a TOML key/value line such as title = "app".
+var tomlKeyRe = regexp.MustCompile(`^[A-Za-z0-9_."-]+(\s*\.\s*[A-Za-z0-9_."-]+)*\s*=`)
+
+// DetectFormat picks the format from path's extension (.yaml, .yml, .json,
+// .toml), or sniffs data for any other extension.
+func DetectFormat(path string, data []byte) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".json":
+		return FormatJSON
+	case ".toml":
+		return FormatTOML
+	}
+	return sniffFormat(data)
+}
+
+// sniffFormat looks at the first line that is not blank or a comment.
+func sniffFormat(data []byte) Format {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") || line == "---" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "{") && !strings.HasPrefix(line, "{{"):
+			return FormatJSON
+		case tomlHeaderRe.MatchString(line), tomlKeyRe.MatchString(line):
+			return FormatTOML
+		}
+		return FormatYAML
+	}
+	return FormatYAML
+}
+
+// toYAML converts data from format f to block-style YAML. Template
+// expressions survive as long as they sit inside strings.
+func toYAML(data []byte, f Format) ([]byte, error) {
+	switch f {
+	case FormatJSON:
+		// JSON is YAML already, but flow style would defeat the line-based
+		// include merge, so re-encode it in block style.
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("parse json: %w", err)
+		}
+		blockStyle(&doc)
+		return yaml.Marshal(&doc)
+	case FormatTOML:
+		var v map[string]any
+		if _, err := toml.Decode(string(data), &v); err != nil {
+			return nil, fmt.Errorf("parse toml: %w", err)
+		}
+		if len(v) == 0 {
+			return nil, nil
+		}
+		return yaml.Marshal(v)
+	}
+	return data, nil
+}
+
+// blockStyle clears flow style from mappings and sequences under n.
+func blockStyle(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode {
+		n.Style &^= yaml.FlowStyle
+	}
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
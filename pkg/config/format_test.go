@@ -0,0 +1,88 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gur-shatz/go-run/pkg/config"
+)
+
+var _ = Describe("Formats", func() {
+	var dir string
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return path
+	}
+
+	process := func(path string) (map[string]any, map[string]string) {
+		data, vars, err := config.ProcessFile(path, config.WithEnv(map[string]string{}))
+		Expect(err).NotTo(HaveOccurred())
+		var out map[string]any
+		Expect(yaml.Unmarshal(data, &out)).To(Succeed())
+		return out, vars
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	It("processes JSON with vars and templates", func() {
+		out, vars := process(write("execrun.json", `{
+  "vars": {"PORT": "8081", "ADDR": "localhost:{{ .PORT }}"},
+  "watch": ["**/*.go"],
+  "exec": ["./app --addr {{ .ADDR }}"]
+}`))
+		Expect(vars).To(HaveKeyWithValue("ADDR", "localhost:8081"))
+		Expect(out).To(HaveKeyWithValue("exec", []any{"./app --addr localhost:8081"}))
+		Expect(out).NotTo(HaveKey("vars"))
+	})
+
+	It("processes TOML with vars and templates", func() {
+		out, vars := process(write("runctl.toml", `
+title = "{{ .NAME | upper }}"
+
+[vars]
+NAME = "demo"
+
+[targets.api]
+config = "api/execrun.yaml"
+wait_for = ["tcp://localhost:5432"]
+`))
+		Expect(vars).To(HaveKeyWithValue("NAME", "demo"))
+		Expect(out).To(HaveKeyWithValue("title", "DEMO"))
+		Expect(out).To(HaveKeyWithValue("targets", HaveKeyWithValue("api", HaveKeyWithValue("config", "api/execrun.yaml"))))
+	})
+
+	It("includes files in other formats", func() {
+		write("common.toml", `
+[vars]
+BASE_PORT = "8000"
+`)
+		out, _ := process(write("runctl.yaml", `
+include: [common.toml]
+port: "{{ .BASE_PORT }}"
+`))
+		Expect(out).To(HaveKeyWithValue("port", "8000"))
+	})
+
+	It("sniffs the format when the extension is unknown", func() {
+		Expect(config.DetectFormat("runctl.conf", []byte(`{"title": "x"}`))).To(Equal(config.FormatJSON))
+		Expect(config.DetectFormat("runctl.conf", []byte("# comment\n[targets.api]\n"))).To(Equal(config.FormatTOML))
+		Expect(config.DetectFormat("runctl.conf", []byte("title = \"x\"\n"))).To(Equal(config.FormatTOML))
+		Expect(config.DetectFormat("runctl.conf", []byte("title: x\n"))).To(Equal(config.FormatYAML))
+		Expect(config.DetectFormat("runctl.conf", []byte("{{ if .X }}\ntitle: x\n{{ end }}\n"))).To(Equal(config.FormatYAML))
+		Expect(config.DetectFormat("runctl.conf", []byte("[[ .KEY ]]: x\n"))).To(Equal(config.FormatYAML))
+		Expect(config.DetectFormat("runctl.toml", []byte("title: x\n"))).To(Equal(config.FormatTOML))
+	})
+
+	It("reports TOML syntax errors", func() {
+		_, _, err := config.ProcessFile(write("runctl.toml", "title = \n"), config.WithEnv(map[string]string{}))
+		Expect(err).To(MatchError(ContainSubstring("parse toml")))
+	})
+})
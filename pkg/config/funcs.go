@@ -0,0 +1,167 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// stringFuncs is a curated, side-effect-free subset of the Sprig function
+// library. Names and argument order follow Sprig, so the piped value is
+// always the last argument: {{ .NAME | replace "-" "_" | upper }}.
+func stringFuncs() template.FuncMap {
+	return template.FuncMap{
+		"trim":       strings.TrimSpace,
+		"trimAll":    func(cutset, s string) string { return strings.Trim(s, cutset) },
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"repeat":     func(n int, s string) string { return strings.Repeat(s, max(n, 0)) },
+		"trunc":      trunc,
+		"nospace":    func(s string) string { return strings.Join(strings.Fields(s), "") },
+		"quote":      func(s string) string { return fmt.Sprintf("%q", s) },
+		"squote":     func(s string) string { return "'" + s + "'" },
+		"splitList":  func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":       join,
+		"toString":   func(v any) string { return fmt.Sprint(v) },
+
+		"b64enc": func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+		"b64dec": func(s string) (string, error) {
+			b, err := base64.StdEncoding.DecodeString(s)
+			return string(b), err
+		},
+		"sha256sum": func(s string) string {
+			sum := sha256.Sum256([]byte(s))
+			return hex.EncodeToString(sum[:])
+		},
+
+		"base":  filepath.Base,
+		"dir":   filepath.Dir,
+		"ext":   filepath.Ext,
+		"clean": filepath.Clean,
+
+		"ternary": func(yes, no any, cond bool) any {
+			if cond {
+				return yes
+			}
+			return no
+		},
+		"empty":    empty,
+		"coalesce": coalesce,
+
+		"sub": intOp("sub", func(a, b int) (int, error) { return a - b, nil }),
+		"mul": intOp("mul", func(a, b int) (int, error) { return a * b, nil }),
+		"div": intOp("div", func(a, b int) (int, error) {
+			if b == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return a / b, nil
+		}),
+		"mod": intOp("mod", func(a, b int) (int, error) {
+			if b == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return a % b, nil
+		}),
+
+		// now and uuid differ on every load; use them for values that are
+		// meant to change, such as build stamps.
+		"now":  time.Now,
+		"date": func(layout string, t time.Time) string { return t.Format(layout) },
+		"uuid": uuid,
+	}
+}
+
+// trunc keeps the first n bytes of s, or the last -n when n is negative.
+func trunc(n int, s string) string {
+	switch {
+	case n >= 0 && n < len(s):
+		return s[:n]
+	case n < 0 && -n < len(s):
+		return s[len(s)+n:]
+	}
+	return s
+}
+
+// join joins a list (or a single value) with sep.
+func join(sep string, v any) string {
+	switch l := v.(type) {
+	case []string:
+		return strings.Join(l, sep)
+	case []any:
+		parts := make([]string, len(l))
+		for i, p := range l {
+			parts[i] = fmt.Sprint(p)
+		}
+		return strings.Join(parts, sep)
+	}
+	return fmt.Sprint(v)
+}
+
+// empty reports whether v is nil, false, zero, or an empty string or list.
+func empty(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case string:
+		return x == ""
+	case bool:
+		return !x
+	case int:
+		return x == 0
+	case []string:
+		return len(x) == 0
+	case []any:
+		return len(x) == 0
+	}
+	return false
+}
+
+// coalesce returns the first non-empty argument.
+func coalesce(vals ...any) any {
+	for _, v := range vals {
+		if !empty(v) {
+			return v
+		}
+	}
+	return nil
+}
+
+// intOp wraps an integer operator so it accepts strings and YAML numbers.
+func intOp(name string, op func(a, b int) (int, error)) func(a, b any) (int, error) {
+	return func(a, b any) (int, error) {
+		aInt, err := toInt(a)
+		if err != nil {
+			return 0, fmt.Errorf("%s: first argument: %w", name, err)
+		}
+		bInt, err := toInt(b)
+		if err != nil {
+			return 0, fmt.Errorf("%s: second argument: %w", name, err)
+		}
+		n, err := op(aInt, bInt)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", name, err)
+		}
+		return n, nil
+	}
+}
+
+// uuid returns a random (version 4) UUID.
+func uuid() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const includeKey = "include"
+
+// section is one top-level key of a config file, kept as raw text so
+// template expressions survive merging untouched.
+type section struct {
+	key      string
+	text     []byte   // the key line and everything up to the next key
+	children []*entry // set when the value is a block mapping
+}
+
+// entry is one key of a block mapping, re-indented to two spaces.
+type entry struct {
+	key  string
+	text []byte
+}
+
+// resolveIncludes merges the files listed under a top-level include: key
+// into data, before any template processing. Paths and globs are relative
+// to dir. Included files are merged in order and the including file comes
+// last; later files win. Top-level block mappings (vars, targets, ...) are
+// merged key by key, any other value is replaced whole. Included files may
+// include others; cycles are an error.
+func resolveIncludes(data []byte, dir string) ([]byte, error) {
+	return resolveIncludesFrom(data, dir, nil)
+}
+
+func resolveIncludesFrom(data []byte, dir string, stack []string) ([]byte, error) {
+	if !bytes.Contains(data, []byte(includeKey)) {
+		return data, nil
+	}
+	sections, includes, err := splitSections(data)
+	if err != nil || len(includes) == 0 {
+		return data, nil // not ours to report; template processing will
+	}
+
+	var merged []*section
+	for _, pattern := range includes {
+		paths, err := includePaths(dir, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			if i := slices.Index(stack, path); i >= 0 {
+				return nil, fmt.Errorf("include cycle: %s", strings.Join(append(stack[i:], path), " -> "))
+			}
+			inc, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("include %s: %w", path, err)
+			}
+			if inc, err = toYAML(inc, DetectFormat(path, inc)); err != nil {
+				return nil, fmt.Errorf("include %s: %w", path, err)
+			}
+			if inc, err = decrypt(inc); err != nil {
+				return nil, fmt.Errorf("include %s: %w", path, err)
+			}
+			inc, err = resolveIncludesFrom(inc, filepath.Dir(path), append(slices.Clip(stack), path))
+			if err != nil {
+				return nil, err
+			}
+			incSections, _, err := splitSections(inc)
+			if err != nil {
+				return nil, fmt.Errorf("include %s: %w", path, err)
+			}
+			merged = mergeSections(merged, incSections)
+		}
+	}
+	merged = mergeSections(merged, sections)
+
+	var out bytes.Buffer
+	for _, s := range merged {
+		if s.children == nil {
+			out.Write(s.text)
+			if !bytes.HasSuffix(s.text, []byte("\n")) {
+				out.WriteString("\n")
+			}
+			continue
+		}
+		fmt.Fprintf(&out, "%s:\n", s.key)
+		for _, c := range s.children {
+			out.Write(c.text)
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// includePaths expands one include: entry. Globs may match nothing; plain
+// paths must exist.
+func includePaths(dir, pattern string) ([]string, error) {
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(dir, pattern)
+	}
+	abs, err := filepath.Abs(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.ContainsAny(abs, "*?[") {
+		if _, err := os.Stat(abs); err != nil {
+			return nil, fmt.Errorf("include: %w", err)
+		}
+		return []string{abs}, nil
+	}
+	paths, err := filepath.Glob(abs)
+	if err != nil {
+		return nil, fmt.Errorf("include %q: %w", pattern, err)
+	}
+	return paths, nil
+}
+
+// splitSections cuts data into its top-level sections and returns the
+// include: list separately.
+func splitSections(data []byte) ([]*section, []string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("top level must be a mapping")
+	}
+
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	var (
+		sections []*section
+		includes []string
+	)
+	for i := 0; i < len(root.Content); i += 2 {
+		key, val := root.Content[i], root.Content[i+1]
+		end := len(lines)
+		if i+2 < len(root.Content) {
+			end = root.Content[i+2].Line - 1
+		}
+
+		if key.Value == includeKey {
+			var list []string
+			if err := val.Decode(&list); err != nil {
+				var one string
+				if err := val.Decode(&one); err != nil {
+					return nil, nil, fmt.Errorf("include must be a path or a list of paths")
+				}
+				list = []string{one}
+			}
+			includes = list
+			continue
+		}
+
+		s := &section{key: key.Value, text: bytes.Join(lines[key.Line-1:end], nil)}
+		if val.Kind == yaml.MappingNode && val.Style&yaml.FlowStyle == 0 && len(val.Content) > 0 {
+			s.children = splitEntries(lines, val, end)
+		}
+		sections = append(sections, s)
+	}
+	return sections, includes, nil
+}
+
+// splitEntries cuts a block mapping ending before line end into its keys.
+func splitEntries(lines [][]byte, m *yaml.Node, end int) []*entry {
+	entries := make([]*entry, 0, len(m.Content)/2)
+	for i := 0; i < len(m.Content); i += 2 {
+		key := m.Content[i]
+		stop := end
+		if i+2 < len(m.Content) {
+			stop = m.Content[i+2].Line - 1
+		}
+		indent := key.Column - 1
+		var text bytes.Buffer
+		for _, line := range lines[key.Line-1 : stop] {
+			if len(bytes.TrimSpace(line)) == 0 {
+				text.WriteString("\n")
+				continue
+			}
+			if len(line) >= indent && len(bytes.TrimLeft(line[:indent], " ")) == 0 {
+				line = line[indent:]
+			}
+			text.WriteString("  ")
+			text.Write(line)
+		}
+		if !bytes.HasSuffix(text.Bytes(), []byte("\n")) {
+			text.WriteString("\n")
+		}
+		entries = append(entries, &entry{key: key.Value, text: text.Bytes()})
+	}
+	return entries
+}
+
+// mergeSections overlays next onto base: matching block mappings merge key
+// by key, anything else is replaced, and new keys are appended.
+func mergeSections(base, next []*section) []*section {
+	for _, s := range next {
+		i := slices.IndexFunc(base, func(b *section) bool { return b.key == s.key })
+		if i < 0 {
+			base = append(base, s)
+			continue
+		}
+		if base[i].children == nil || s.children == nil {
+			base[i] = s
+			continue
+		}
+		children := append([]*entry(nil), base[i].children...)
+		for _, c := range s.children {
+			if j := slices.IndexFunc(children, func(b *entry) bool { return b.key == c.key }); j >= 0 {
+				children[j] = c
+			} else {
+				children = append(children, c)
+			}
+		}
+		base[i] = &section{key: s.key, children: children}
+	}
+	return base
+}
@@ -0,0 +1,105 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gur-shatz/go-run/pkg/config"
+)
+
+var _ = Describe("Include", func() {
+	var dir string
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return path
+	}
+
+	process := func(path string) (map[string]any, map[string]string, error) {
+		data, vars, err := config.ProcessFile(path, config.WithEnv(map[string]string{}))
+		if err != nil {
+			return nil, nil, err
+		}
+		var out map[string]any
+		Expect(yaml.Unmarshal(data, &out)).To(Succeed())
+		return out, vars, nil
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	It("merges vars and targets from included files before templating", func() {
+		write("common.yaml", `
+vars:
+  BASE_PORT: "9000"
+  GREETING: hello
+api:
+  port: 1
+`)
+		write("targets/api.yaml", `
+targets:
+    api:
+        url: "http://localhost:{{ .BASE_PORT }}"
+`)
+		write("targets/web.yaml", `
+targets:
+  web:
+    port: [[ add .BASE_PORT 1 ]]
+`)
+		main := write("runctl.yaml", `
+include: [common.yaml, "targets/*.yaml"]
+vars:
+  BASE_PORT: "8000"
+api:
+  port: 2
+targets:
+  worker:
+    cmd: "echo {{ .GREETING }}"
+`)
+
+		cfg, vars, err := process(main)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vars).To(Equal(map[string]string{"BASE_PORT": "8000", "GREETING": "hello"}))
+		Expect(cfg).NotTo(HaveKey("include"))
+		Expect(cfg["api"]).To(Equal(map[string]any{"port": 2}))
+		Expect(cfg["targets"]).To(Equal(map[string]any{
+			"api":    map[string]any{"url": "http://localhost:8000"},
+			"web":    map[string]any{"port": 8001},
+			"worker": map[string]any{"cmd": "echo hello"},
+		}))
+	})
+
+	It("resolves nested includes relative to the including file", func() {
+		write("shared/steps.yaml", "include: vars.yaml\nexec:\n  - go build .\n")
+		write("shared/vars.yaml", "vars:\n  NAME: app\n")
+		main := write("execrun.yaml", "include: shared/steps.yaml\nrun:\n  - ./{{ .NAME }}\n")
+
+		cfg, _, err := process(main)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg["exec"]).To(Equal([]any{"go build ."}))
+		Expect(cfg["run"]).To(Equal([]any{"./app"}))
+	})
+
+	It("rejects include cycles", func() {
+		write("a.yaml", "include: b.yaml\n")
+		write("b.yaml", "include: a.yaml\n")
+		_, _, err := process(filepath.Join(dir, "a.yaml"))
+		Expect(err).To(MatchError(ContainSubstring("include cycle")))
+	})
+
+	It("requires plain paths to exist but allows empty globs", func() {
+		_, _, err := process(write("missing.yaml", "include: nope.yaml\nname: x\n"))
+		Expect(err).To(MatchError(ContainSubstring("nope.yaml")))
+
+		cfg, _, err := process(write("glob.yaml", "include: [\"extra/*.yaml\"]\nname: x\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg).To(Equal(map[string]any{"name": "x"}))
+	})
+})
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"text/template"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/gur-shatz/go-run/internal/configutil"
 )
 
 const defaultConfigFilename = "config.yml"
@@ -16,6 +19,7 @@ type LoadOption func(*loadOptions)
 type loadOptions struct {
 	env   map[string]string
 	quiet bool
+	funcs template.FuncMap
 }
 
 // WithLoadEnv sets the environment variables to use for template substitution.
@@ -26,6 +30,13 @@ func WithLoadEnv(env map[string]string) LoadOption {
 	}
 }
 
+// WithLoadFuncs adds template functions, as WithFuncs does for Process.
+func WithLoadFuncs(funcs template.FuncMap) LoadOption {
+	return func(o *loadOptions) {
+		o.funcs = funcs
+	}
+}
+
 // WithQuiet suppresses info-level logging during config loading.
 func WithQuiet() LoadOption {
 	return func(o *loadOptions) {
@@ -38,9 +49,9 @@ func LoadQuiet(path string) (O, map[string]string, error) {
 	return Load(path, WithQuiet())
 }
 
-// Load reads configuration from a YAML file.
+// Load reads configuration from a YAML, JSON, or TOML file.
 // If path is provided, it must exist (no fallback).
-// If path is empty, tries to find config.yml in:
+// If path is empty, tries to find config.yml (or .yaml, .json, .toml) in:
 //  1. Current working directory
 //  2. Executable directory
 //
@@ -58,36 +69,35 @@ func Load(path string, opts ...LoadOption) (O, map[string]string, error) {
 	}
 
 	if path != "" {
-		return loadFromFile(path, env, options.quiet)
+		return loadFromFile(path, env, options)
 	}
 
 	// Try current working directory
 	if cwdPath, err := findConfigInCWD(); err == nil {
-		return loadFromFile(cwdPath, env, options.quiet)
+		return loadFromFile(cwdPath, env, options)
 	}
 
 	// Try executable directory
 	if exePath, err := findConfigInExeDir(); err == nil {
-		return loadFromFile(exePath, env, options.quiet)
+		return loadFromFile(exePath, env, options)
 	}
 
 	return O{}, nil, nil
 }
 
 // loadFromFile reads and parses a config file, returning config, resolved vars, and error.
-func loadFromFile(path string, env map[string]string, quiet bool) (O, map[string]string, error) {
+func loadFromFile(path string, env map[string]string, options loadOptions) (O, map[string]string, error) {
 	// Look for vars.yml in the same directory as config file
 	configDir := filepath.Dir(path)
-	varsPath := filepath.Join(configDir, "vars.yml")
+	varsPath := configutil.ResolveConfigPath(filepath.Join(configDir, "vars.yml"))
 	env = loadVarsFile(varsPath, env)
 
-	data, err := os.ReadFile(path)
-	if err != nil {
+	if _, err := os.Stat(path); err != nil {
 		return nil, nil, err
 	}
 
 	// Process template substitution
-	processed, resolvedVars, err := Process(data, WithEnv(env))
+	processed, resolvedVars, err := ProcessFile(path, WithEnv(env), WithFuncs(options.funcs))
 	if err != nil {
 		return nil, nil, fmt.Errorf("template substitution failed: %w", err)
 	}
@@ -104,7 +114,7 @@ func loadFromFile(path string, env map[string]string, quiet bool) (O, map[string
 	return cfg, resolvedVars, nil
 }
 
-// LoadEnvFile reads a YAML file of key-value pairs and sets them as
+// LoadEnvFile reads a YAML, JSON, or TOML file of key-value pairs and sets them as
 // environment variables. Existing environment variables take precedence
 // (they are not overwritten). This is intended for use with the -e CLI flag.
 func LoadEnvFile(path string) error {
@@ -114,7 +124,7 @@ func LoadEnvFile(path string) error {
 	}
 
 	var vars map[string]any
-	if err := yaml.Unmarshal(data, &vars); err != nil {
+	if err := unmarshalFile(path, data, &vars); err != nil {
 		return fmt.Errorf("parse env file %s: %w", path, err)
 	}
 
@@ -136,7 +146,7 @@ func loadVarsFile(path string, env map[string]string) map[string]string {
 	}
 
 	var vars map[string]any
-	if err := yaml.Unmarshal(data, &vars); err != nil {
+	if err := unmarshalFile(path, data, &vars); err != nil {
 		return env
 	}
 
@@ -158,7 +168,7 @@ func findConfigInCWD() (string, error) {
 		return "", err
 	}
 
-	path := filepath.Join(cwd, defaultConfigFilename)
+	path := configutil.ResolveConfigPath(filepath.Join(cwd, defaultConfigFilename))
 	if _, err := os.Stat(path); err != nil {
 		return "", err
 	}
@@ -184,10 +194,22 @@ func findConfigInExeDir() (string, error) {
 	}
 
 	exeDir := filepath.Dir(exe)
-	path := filepath.Join(exeDir, defaultConfigFilename)
+	path := configutil.ResolveConfigPath(filepath.Join(exeDir, defaultConfigFilename))
 	if _, err := os.Stat(path); err != nil {
 		return "", err
 	}
 
 	return path, nil
 }
+
+// unmarshalFile decodes a YAML, JSON, or TOML file without templating.
+func unmarshalFile(path string, data []byte, v any) error {
+	data, err := toYAML(data, DetectFormat(path, data))
+	if err != nil {
+		return err
+	}
+	if data, err = decrypt(data); err != nil {
+		return err
+	}
+	return yaml.Unmarshal(data, v)
+}
@@ -3,6 +3,7 @@ package config_test
 import (
 	"os"
 	"path/filepath"
+	"text/template"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -219,6 +220,19 @@ value: '{{ .OS_VAR | default "not_found" }}'
 		})
 	})
 
+	Describe("WithLoadFuncs option", func() {
+		It("should add template functions", func() {
+			path := writeConfig(`
+value: '{{ greet "world" }}'
+`)
+			cfg, _, err := config.Load(path, config.WithLoadFuncs(template.FuncMap{
+				"greet": func(name string) string { return "hello " + name },
+			}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.GetString("value")).To(Equal("hello world"))
+		})
+	})
+
 	Describe("vars.yml sidecar", func() {
 		It("should load vars from vars.yml in same directory", func() {
 			// Write vars.yml
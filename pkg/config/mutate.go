@@ -0,0 +1,270 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Set stores value at the dot-notation path, creating objects along the
+// way. It fails if part of the path holds something other than an object.
+func (this O) Set(path string, value any) error {
+	parts := strings.Split(path, ".")
+	m := map[string]any(this)
+	for i, p := range parts[:len(parts)-1] {
+		next, ok := m[p]
+		if !ok {
+			child := O{}
+			m[p] = child
+			m = child
+			continue
+		}
+		if m, ok = asMap(next); !ok {
+			return fmt.Errorf("set %s: %s is not an object", path, strings.Join(parts[:i+1], "."))
+		}
+	}
+	m[parts[len(parts)-1]] = value
+	return nil
+}
+
+// Delete removes the value at the dot-notation path and reports whether
+// there was one.
+func (this O) Delete(path string) bool {
+	parts := strings.Split(path, ".")
+	m := map[string]any(this)
+	for _, p := range parts[:len(parts)-1] {
+		var ok bool
+		if m, ok = asMap(m[p]); !ok {
+			return false
+		}
+	}
+	last := parts[len(parts)-1]
+	if _, ok := m[last]; !ok {
+		return false
+	}
+	delete(m, last)
+	return true
+}
+
+// Merge deep-merges other into the config, the way overlays are merged:
+// objects are merged key by key at every depth, anything else (lists,
+// scalars) is replaced whole. Objects are copied, so later changes to
+// other don't show through.
+func (this O) Merge(other O) {
+	mergeMaps(this, other)
+}
+
+func mergeMaps(dst, src map[string]any) {
+	for k, v := range src {
+		sm, ok := asMap(v)
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		dm, ok := asMap(dst[k])
+		if !ok {
+			dm = O{}
+			dst[k] = dm
+		}
+		mergeMaps(dm, sm)
+	}
+}
+
+// asMap returns v as a map if it is an object.
+func asMap(v any) (map[string]any, bool) {
+	switch m := v.(type) {
+	case O:
+		return m, true
+	case map[string]any:
+		return m, true
+	}
+	return nil, false
+}
+
+// WriteFile writes the config to path, in the format its extension (or
+// existing content) calls for. An existing YAML file is updated in place:
+// keys keep their order and comments, and only values that changed are
+// re-encoded. Configs returned by Load have their templates resolved, so
+// tools that edit a file should read it with yaml.Unmarshal instead, or
+// the template expressions are replaced with their values.
+func (this O) WriteFile(path string) error {
+	old, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	mode := fs.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	var data []byte
+	switch DetectFormat(path, old) {
+	case FormatJSON:
+		data, err = json.MarshalIndent(this, "", "  ")
+		data = append(data, '\n')
+	case FormatTOML:
+		var buf bytes.Buffer
+		err = toml.NewEncoder(&buf).Encode(this)
+		data = buf.Bytes()
+	default:
+		data, err = this.updateYAML(old)
+	}
+	if err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := os.WriteFile(tmp, data, mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// updateYAML returns old, a YAML document, changed to hold the config.
+func (this O) updateYAML(old []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(old, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if err := syncNode(doc.Content[0], map[string]any(this)); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return keepBlankLines(old, buf.Bytes()), nil
+}
+
+// keepBlankLines puts back the blank lines old had above top-level keys,
+// which yaml.v3 drops.
+func keepBlankLines(old, out []byte) []byte {
+	spaced := map[string]bool{}
+	oldLines := strings.Split(string(old), "\n")
+	for _, key := range topLevelKeys(old) {
+		if first := keyStart(key); first >= 2 && strings.TrimSpace(oldLines[first-2]) == "" {
+			spaced[key.Value] = true
+		}
+	}
+	lines := strings.Split(string(out), "\n")
+	keys := topLevelKeys(out)
+	for i := len(keys) - 1; i > 0; i-- {
+		if first := keyStart(keys[i]); spaced[keys[i].Value] && first >= 2 && lines[first-2] != "" {
+			lines = slices.Insert(lines, first-1, "")
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// topLevelKeys returns the key nodes of a YAML document's top-level mapping.
+func topLevelKeys(data []byte) []*yaml.Node {
+	var doc yaml.Node
+	if yaml.Unmarshal(data, &doc) != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	var keys []*yaml.Node
+	for i := 0; i+1 < len(doc.Content[0].Content); i += 2 {
+		keys = append(keys, doc.Content[0].Content[i])
+	}
+	return keys
+}
+
+// keyStart returns the 1-based line of key's head comment, or of key if it
+// has none.
+func keyStart(key *yaml.Node) int {
+	if key.HeadComment == "" {
+		return key.Line
+	}
+	return key.Line - strings.Count(key.HeadComment, "\n") - 1
+}
+
+// syncNode changes n to hold v. Mapping keys that stay keep their node,
+// position, and comments; new keys are appended in sorted order.
+func syncNode(n *yaml.Node, v any) error {
+	if m, ok := asMap(v); ok && n.Kind == yaml.MappingNode {
+		content := n.Content[:0:0]
+		seen := make(map[string]bool, len(m))
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i].Value
+			val, ok := m[key]
+			if !ok || seen[key] {
+				continue
+			}
+			seen[key] = true
+			if err := syncNode(n.Content[i+1], val); err != nil {
+				return err
+			}
+			content = append(content, n.Content[i], n.Content[i+1])
+		}
+		var added []string
+		for k := range m {
+			if !seen[k] {
+				added = append(added, k)
+			}
+		}
+		slices.Sort(added)
+		for _, k := range added {
+			var key, val yaml.Node
+			key.SetString(k)
+			if err := val.Encode(m[k]); err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+			content = append(content, &key, &val)
+		}
+		n.Content = content
+		return nil
+	}
+
+	var cur any
+	if n.Kind != yaml.AliasNode && n.Decode(&cur) == nil && reflect.DeepEqual(cur, normalize(v)) {
+		return nil
+	}
+	var val yaml.Node
+	if err := val.Encode(v); err != nil {
+		return err
+	}
+	val.HeadComment, val.LineComment, val.FootComment = n.HeadComment, n.LineComment, n.FootComment
+	*n = val
+	return nil
+}
+
+// normalize converts O values to the plain maps YAML decodes into, so a
+// value can be compared with what a node holds.
+func normalize(v any) any {
+	switch t := v.(type) {
+	case O:
+		return normalize(map[string]any(t))
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, e := range t {
+			out[k] = normalize(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, e := range t {
+			out[i] = normalize(e)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,113 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gur-shatz/go-run/pkg/config"
+)
+
+var _ = Describe("Config O mutation", func() {
+	var cfg config.O
+
+	get := func(path string) any {
+		v, _ := cfg.Get(path)
+		return v
+	}
+
+	BeforeEach(func() {
+		Expect(yaml.Unmarshal([]byte(`
+name: app
+server:
+  port: 8080
+  tags: [a, b]
+`), &cfg)).To(Succeed())
+	})
+
+	It("sets values, creating objects along the way", func() {
+		Expect(cfg.Set("server.port", 9090)).To(Succeed())
+		Expect(cfg.Set("db.pool.size", 4)).To(Succeed())
+		Expect(get("server.port")).To(Equal(9090))
+		Expect(config.GetNumberOrDefault(cfg, "db.pool.size", 0)).To(Equal(4))
+
+		Expect(cfg.Set("name.first", "x")).To(MatchError("set name.first: name is not an object"))
+	})
+
+	It("deletes values", func() {
+		Expect(cfg.Delete("server.port")).To(BeTrue())
+		Expect(cfg.Delete("server.port")).To(BeFalse())
+		Expect(cfg.Delete("missing.key")).To(BeFalse())
+		Expect(get("server")).To(And(HaveKeyWithValue("tags", []any{"a", "b"}), Not(HaveKey("port"))))
+	})
+
+	It("deep-merges objects and replaces everything else", func() {
+		other := config.O{"server": config.O{"tags": []any{"c"}, "host": "0.0.0.0"}, "debug": true}
+		cfg.Merge(other)
+		Expect(get("server.port")).To(Equal(8080))
+		Expect(get("server.host")).To(Equal("0.0.0.0"))
+		Expect(get("server.tags")).To(Equal([]any{"c"}))
+		Expect(get("debug")).To(BeTrue())
+
+		Expect(other.Set("server.host", "localhost")).To(Succeed())
+		Expect(get("server.host")).To(Equal("0.0.0.0"))
+	})
+
+	Describe("WriteFile", func() {
+		var path string
+
+		BeforeEach(func() {
+			path = filepath.Join(GinkgoT().TempDir(), "app.yaml")
+		})
+
+		It("keeps key order and comments of an existing file", func() {
+			Expect(os.WriteFile(path, []byte(`# Application config
+name: app # shown in the UI
+
+server:
+  # where to listen
+  port: 8080
+  host: localhost
+old: gone
+`), 0600)).To(Succeed())
+			var cfg config.O
+			data, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(yaml.Unmarshal(data, &cfg)).To(Succeed())
+
+			Expect(cfg.Set("server.port", 9090)).To(Succeed())
+			Expect(cfg.Set("server.debug", true)).To(Succeed())
+			cfg.Delete("old")
+			Expect(cfg.WriteFile(path)).To(Succeed())
+
+			Expect(os.ReadFile(path)).To(Equal([]byte(`# Application config
+name: app # shown in the UI
+
+server:
+  # where to listen
+  port: 9090
+  host: localhost
+  debug: true
+`)))
+			info, err := os.Stat(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+		})
+
+		It("creates a new file", func() {
+			Expect(cfg.WriteFile(path)).To(Succeed())
+			loaded, _, err := config.LoadQuiet(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loaded.GetString("server.port")).To(Equal("8080"))
+		})
+
+		It("writes JSON files as JSON", func() {
+			path = filepath.Join(filepath.Dir(path), "app.json")
+			Expect(config.O{"name": "app"}.WriteFile(path)).To(Succeed())
+			Expect(os.ReadFile(path)).To(Equal([]byte("{\n  \"name\": \"app\"\n}\n")))
+		})
+	})
+})
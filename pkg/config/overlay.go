@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WithOverlay deep-merges the given files over the config, in order,
+// before templates are processed. Overlays may use include: themselves.
+func WithOverlay(paths ...string) Option {
+	return func(o *options) {
+		o.overlays = append(o.overlays, paths...)
+	}
+}
+
+// applyOverlays merges each overlay file over data: mappings are merged key
+// by key at every depth, anything else (lists, scalars) is replaced whole.
+func applyOverlays(data []byte, paths []string) ([]byte, error) {
+	if len(paths) == 0 {
+		return data, nil
+	}
+	var base yaml.Node
+	if err := yaml.Unmarshal(data, &base); err != nil {
+		return nil, fmt.Errorf("overlay: parse config: %w", err)
+	}
+	for _, path := range paths {
+		over, err := readOverlay(path)
+		if err != nil {
+			return nil, fmt.Errorf("overlay %s: %w", path, err)
+		}
+		if len(over.Content) == 0 {
+			continue
+		}
+		if len(base.Content) == 0 {
+			base = *over
+			continue
+		}
+		if base.Content[0].Kind != yaml.MappingNode || over.Content[0].Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("overlay %s: top level must be a mapping", path)
+		}
+		mergeNode(base.Content[0], over.Content[0])
+	}
+	return yaml.Marshal(&base)
+}
+
+// readOverlay reads an overlay file as YAML with its includes resolved.
+func readOverlay(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = toYAML(data, DetectFormat(path, data)); err != nil {
+		return nil, err
+	}
+	if data, err = decrypt(data); err != nil {
+		return nil, err
+	}
+	if data, err = resolveIncludes(data, filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// mergeNode merges mapping over into mapping base.
+func mergeNode(base, over *yaml.Node) {
+	for i := 0; i+1 < len(over.Content); i += 2 {
+		key, val := over.Content[i], over.Content[i+1]
+		j := mappingIndex(base, key.Value)
+		switch {
+		case j < 0:
+			base.Content = append(base.Content, key, val)
+		case base.Content[j+1].Kind == yaml.MappingNode && val.Kind == yaml.MappingNode:
+			mergeNode(base.Content[j+1], val)
+		default:
+			base.Content[j+1] = val
+		}
+	}
+}
+
+// mappingIndex returns the index of key in mapping m, or -1.
+func mappingIndex(m *yaml.Node, key string) int {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,90 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gur-shatz/go-run/pkg/config"
+)
+
+var _ = Describe("Overlay", func() {
+	var dir string
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	process := func(base string, overlays ...string) map[string]any {
+		data, _, err := config.ProcessFile(base, config.WithEnv(map[string]string{}), config.WithOverlay(overlays...))
+		Expect(err).NotTo(HaveOccurred())
+		var out map[string]any
+		Expect(yaml.Unmarshal(data, &out)).To(Succeed())
+		return out
+	}
+
+	It("deep-merges mappings and replaces lists and scalars", func() {
+		base := write("runctl.yaml", `
+vars:
+  PORT: "8080"
+  HOST: localhost
+targets:
+  api:
+    config: api/execrun.yaml
+    vars: {MODE: prod, LEVEL: info}
+    watch: ["*.go"]
+  web:
+    cmd: npm run dev
+`)
+		over := write("runctl.dev.yaml", `
+vars:
+  PORT: "9090"
+targets:
+  api:
+    vars: {MODE: dev}
+    watch: ["**/*.go"]
+  web:
+    enabled: false
+addr: "{{ .HOST }}:{{ .PORT }}"
+`)
+		Expect(process(base, over)).To(Equal(map[string]any{
+			"targets": map[string]any{
+				"api": map[string]any{
+					"config": "api/execrun.yaml",
+					"vars":   map[string]any{"MODE": "dev", "LEVEL": "info"},
+					"watch":  []any{"**/*.go"},
+				},
+				"web": map[string]any{"cmd": "npm run dev", "enabled": false},
+			},
+			"addr": "localhost:9090",
+		}))
+	})
+
+	It("applies overlays in order and resolves their includes", func() {
+		base := write("execrun.yaml", "watch: [\"*.go\"]\nexec: [./app]\n")
+		write("overlays/common.yaml", "build: [make]\n")
+		first := write("overlays/dev.yaml", "include: [common.yaml]\nexec: [./app -dev]\n")
+		second := write("local.toml", "exec = [\"./app -dev -v\"]\n")
+		Expect(process(base, first, second)).To(Equal(map[string]any{
+			"watch": []any{"*.go"},
+			"build": []any{"make"},
+			"exec":  []any{"./app -dev -v"},
+		}))
+	})
+
+	It("fails on a missing overlay", func() {
+		base := write("execrun.yaml", "watch: [\"*.go\"]\n")
+		_, _, err := config.ProcessFile(base, config.WithOverlay(filepath.Join(dir, "nope.yaml")))
+		Expect(err).To(MatchError(ContainSubstring("overlay " + filepath.Join(dir, "nope.yaml"))))
+	})
+})
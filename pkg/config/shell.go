@@ -0,0 +1,86 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// shellTimeout bounds each command run by the shell template function.
+const shellTimeout = 30 * time.Second
+
+// shellAll is set by SetShell.
+var shellAll atomic.Bool
+
+// SetShell turns the shell template function on or off for every config
+// loaded afterwards. For an -allow-shell CLI flag.
+func SetShell(on bool) {
+	shellAll.Store(on)
+}
+
+// WithShell enables the shell template function, which is off by default:
+// with it, a config can run any command on the machine that loads it.
+func WithShell() Option {
+	return func(o *options) {
+		o.shell = true
+	}
+}
+
+// shellRunner runs the commands of the shell template function in dir.
+// Each command runs at most once per Process, however often the template
+// is executed while resolving vars.
+type shellRunner struct {
+	dir string
+
+	mu   sync.Mutex
+	runs map[string]shellRun
+}
+
+type shellRun struct {
+	out string
+	err error
+}
+
+// newShellRunner returns a runner for dir, or nil if neither on nor
+// SetShell enabled the shell function.
+func newShellRunner(dir string, on bool) *shellRunner {
+	if !on && !shellAll.Load() {
+		return nil
+	}
+	return &shellRunner{dir: dir, runs: make(map[string]shellRun)}
+}
+
+// run runs cmd with sh -c and returns its output, trailing newlines
+// dropped. A nil runner reports that the function is disabled.
+func (this *shellRunner) run(cmd string) (string, error) {
+	if this == nil {
+		return "", fmt.Errorf("shell: disabled; enable it with the -allow-shell flag")
+	}
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if r, ok := this.runs[cmd]; ok {
+		return r.out, r.err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shellTimeout)
+	defer cancel()
+	c := exec.CommandContext(ctx, "sh", "-c", cmd)
+	c.Dir = this.dir
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	out, err := c.Output()
+	r := shellRun{out: strings.TrimRight(string(out), "\r\n")}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		r = shellRun{err: fmt.Errorf("shell %q: %w", cmd, err)}
+	}
+	this.runs[cmd] = r
+	return r.out, r.err
+}
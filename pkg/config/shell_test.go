@@ -0,0 +1,65 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/config"
+)
+
+var _ = Describe("file and shell template functions", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	process := func(content string, opts ...config.Option) (string, map[string]string, error) {
+		cfgPath := filepath.Join(dir, "app.yaml")
+		Expect(os.WriteFile(cfgPath, []byte(content), 0644)).To(Succeed())
+		result, vars, err := config.ProcessFile(cfgPath, append([]config.Option{config.WithEnv(map[string]string{})}, opts...)...)
+		return string(result), vars, err
+	}
+
+	It("reads a file relative to the config", func() {
+		Expect(os.WriteFile(filepath.Join(dir, "VERSION"), []byte("1.4.2\n"), 0644)).To(Succeed())
+		result, vars, err := process(`
+vars:
+  VERSION: '{{ file "VERSION" }}'
+image: "app:{{ .VERSION }}"
+`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vars["VERSION"]).To(Equal("1.4.2"))
+		Expect(result).To(ContainSubstring(`image: "app:1.4.2"`))
+
+		_, _, err = process(`x: '{{ file "MISSING" }}'`)
+		Expect(err).To(MatchError(ContainSubstring("file: open " + filepath.Join(dir, "MISSING"))))
+	})
+
+	It("rejects shell unless enabled", func() {
+		_, _, err := process(`x: '{{ shell "echo hi" }}'`)
+		Expect(err).To(MatchError(ContainSubstring("shell: disabled; enable it with the -allow-shell flag")))
+	})
+
+	It("runs shell commands once each in the config's directory", func() {
+		result, vars, err := process(`
+vars:
+  HERE: '{{ shell "echo run >> runs.txt; basename \"$PWD\"" }}'
+  LOUD: '{{ .HERE | upper }}'
+name: "{{ .LOUD }}"
+`, config.WithShell())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vars["HERE"]).To(Equal(filepath.Base(dir)))
+		Expect(result).To(ContainSubstring(`name: "` + strings.ToUpper(filepath.Base(dir)) + `"`))
+		Expect(os.ReadFile(filepath.Join(dir, "runs.txt"))).To(Equal([]byte("run\n")))
+	})
+
+	It("fails with the stderr of a failing command", func() {
+		_, _, err := process(`x: '{{ shell "echo nope >&2; exit 3" }}'`, config.WithShell())
+		Expect(err).To(MatchError(ContainSubstring(`shell "echo nope >&2; exit 3": exit status 3: nope`)))
+	})
+})
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+const strictKey = "strict"
+
+// strictAll is set by SetStrict.
+var strictAll atomic.Bool
+
+// SetStrict turns strict mode on or off for every config loaded
+// afterwards, as if each file set strict: true. For a -strict CLI flag.
+func SetStrict(on bool) {
+	strictAll.Store(on)
+}
+
+// WithStrict asks the loader that decodes the processed config (execrun,
+// runctl) to do so strictly, as if the file set strict: true.
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// IsStrict reports whether a processed config should be decoded strictly:
+// SetStrict or WithStrict turned it on, or the file sets strict: true.
+func IsStrict(data []byte, opts ...Option) bool {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if strictAll.Load() || o.strict {
+		return true
+	}
+	if !bytes.Contains(data, []byte(strictKey)) {
+		return false
+	}
+	var raw struct {
+		Strict bool `yaml:"strict"`
+	}
+	yaml.Unmarshal(data, &raw)
+	return raw.Strict
+}
+
+// UnmarshalStrict is yaml.Unmarshal, except that keys v has no field for
+// are an error.
+func UnmarshalStrict(data []byte, v any) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
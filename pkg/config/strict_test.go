@@ -0,0 +1,31 @@
+package config_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/config"
+)
+
+var _ = Describe("Strict", func() {
+	It("is on when the file, an option, or SetStrict asks for it", func() {
+		Expect(config.IsStrict([]byte("build: [make]\n"))).To(BeFalse())
+		Expect(config.IsStrict([]byte("strict: true\nbuild: [make]\n"))).To(BeTrue())
+		Expect(config.IsStrict([]byte("strict: false\n"))).To(BeFalse())
+		Expect(config.IsStrict(nil, config.WithStrict())).To(BeTrue())
+
+		config.SetStrict(true)
+		DeferCleanup(config.SetStrict, false)
+		Expect(config.IsStrict(nil)).To(BeTrue())
+	})
+
+	It("rejects fields the target has no field for", func() {
+		var v struct {
+			Build []string `yaml:"build"`
+		}
+		Expect(config.UnmarshalStrict([]byte("build: [make]\n"), &v)).To(Succeed())
+		Expect(v.Build).To(Equal([]string{"make"}))
+		Expect(config.UnmarshalStrict([]byte("buil: [make]\n"), &v)).To(MatchError(ContainSubstring("field buil not found")))
+		Expect(config.UnmarshalStrict(nil, &v)).To(Succeed())
+	})
+})
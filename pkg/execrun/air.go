@@ -0,0 +1,236 @@
+package execrun
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/google/shlex"
+)
+
+// AirImport is the result of converting an air config.
+type AirImport struct {
+	Config   Config
+	Warnings []string // settings that were dropped or need a manual look
+}
+
+// airConfig is the subset of .air.toml that maps onto execrun.
+type airConfig struct {
+	Root   string `toml:"root"`
+	TmpDir string `toml:"tmp_dir"`
+	Build  struct {
+		PreCmd        []string `toml:"pre_cmd"`
+		Cmd           string   `toml:"cmd"`
+		PostCmd       []string `toml:"post_cmd"`
+		Bin           string   `toml:"bin"`
+		FullBin       string   `toml:"full_bin"`
+		ArgsBin       []string `toml:"args_bin"`
+		IncludeExt    []string `toml:"include_ext"`
+		ExcludeDir    []string `toml:"exclude_dir"`
+		IncludeDir    []string `toml:"include_dir"`
+		IncludeFile   []string `toml:"include_file"`
+		ExcludeFile   []string `toml:"exclude_file"`
+		ExcludeRegex  []string `toml:"exclude_regex"`
+		Delay         int      `toml:"delay"` // milliseconds
+		KillDelay     any      `toml:"kill_delay"`
+		SendInterrupt bool     `toml:"send_interrupt"`
+		Rerun         bool     `toml:"rerun"`
+	} `toml:"build"`
+	Proxy struct {
+		Enabled bool `toml:"enabled"`
+	} `toml:"proxy"`
+}
+
+// Defaults air fills in for keys the file leaves out.
+var (
+	airDefaultExt        = []string{"go", "tpl", "tmpl", "html"}
+	airDefaultExcludeDir = []string{"assets", "tmp", "vendor", "testdata"}
+	airDefaultExclude    = []string{"_test.go"}
+)
+
+// ImportAir converts an air config (.air.toml) into an execrun config: the
+// build command and pre_cmd become build steps, bin/full_bin the managed
+// process, the include/exclude settings watch patterns, and delay the
+// debounce. Settings execrun has no equivalent for are reported as warnings.
+func ImportAir(airPath string) (*AirImport, error) {
+	data, err := os.ReadFile(airPath)
+	if err != nil {
+		return nil, fmt.Errorf("read air config: %w", err)
+	}
+	var air airConfig
+	meta, err := toml.Decode(string(data), &air)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", airPath, err)
+	}
+	b := air.Build
+	out := &AirImport{}
+	warn := func(format string, args ...any) {
+		out.Warnings = append(out.Warnings, fmt.Sprintf(format, args...))
+	}
+
+	if air.Root != "" && air.Root != "." {
+		warn("root = %q: watch patterns are relative to execrun.yaml; move it to %s", air.Root, air.Root)
+	}
+	if b.Cmd == "" {
+		b.Cmd = "go build -o ./tmp/main ."
+	}
+	if b.Bin == "" && b.FullBin == "" {
+		b.Bin = "./tmp/main"
+	}
+	if !meta.IsDefined("build", "include_ext") {
+		b.IncludeExt = airDefaultExt
+	}
+	if !meta.IsDefined("build", "exclude_dir") {
+		b.ExcludeDir = airDefaultExcludeDir
+	}
+	if !meta.IsDefined("build", "exclude_regex") {
+		b.ExcludeRegex = airDefaultExclude
+	}
+	if !meta.IsDefined("build", "delay") {
+		b.Delay = 1000
+	}
+
+	cfg := &out.Config
+	cfg.Watch = Watches(airWatch(air.TmpDir, b.IncludeExt, b.IncludeDir, b.IncludeFile, b.ExcludeDir, b.ExcludeFile)...)
+	for _, re := range b.ExcludeRegex {
+		if glob, ok := regexToGlob(re); ok {
+			cfg.Watch = append(cfg.Watch, WatchEntry{Pattern: "!" + glob})
+		} else {
+			warn("exclude_regex %q has no glob equivalent, dropped", re)
+		}
+	}
+
+	for _, c := range append(slices.Clip(b.PreCmd), b.Cmd) {
+		cmd, err := airCommand(c)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Build = append(cfg.Build, cmd)
+	}
+
+	run := b.FullBin
+	if run == "" {
+		run = b.Bin
+		for _, a := range b.ArgsBin {
+			run += " " + quoteArg(a)
+		}
+	}
+	if words, err := shlex.Split(run); err == nil && len(words) > 0 && strings.Contains(words[0], "=") {
+		// full_bin = "APP_ENV=dev ./tmp/main": no shell to apply the
+		// assignments, so let env(1) do it.
+		run = "env " + run
+	}
+	exec, err := airCommand(run)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Exec = []string{exec}
+
+	if b.Delay > 0 {
+		cfg.Debounce = (time.Duration(b.Delay) * time.Millisecond).String()
+	}
+
+	if len(b.PostCmd) > 0 {
+		warn("post_cmd is not supported, dropped")
+	}
+	if d := fmt.Sprint(b.KillDelay); b.KillDelay != nil && d != "0" && d != "0s" {
+		warn("kill_delay is not supported, dropped (execrun sends SIGTERM, then SIGKILL after 5s)")
+	}
+	if b.SendInterrupt {
+		warn("send_interrupt is not supported, dropped (execrun stops with SIGTERM)")
+	}
+	if b.Rerun {
+		warn("rerun is not supported, dropped")
+	}
+	if air.Proxy.Enabled {
+		warn("proxy is not supported, dropped")
+	}
+	return out, nil
+}
+
+// airWatch builds watch patterns from air's include and exclude settings.
+func airWatch(tmpDir string, exts, dirs, files, excludeDirs, excludeFiles []string) []string {
+	var watch []string
+	if len(dirs) == 0 {
+		dirs = []string{""}
+	}
+	for _, dir := range dirs {
+		for _, ext := range exts {
+			watch = append(watch, path.Join(dir, "**", "*."+strings.TrimPrefix(ext, ".")))
+		}
+	}
+	watch = append(watch, files...)
+	if tmpDir == "" {
+		tmpDir = "tmp"
+	}
+	if !slices.Contains(excludeDirs, tmpDir) {
+		excludeDirs = append(slices.Clip(excludeDirs), tmpDir)
+	}
+	for _, dir := range excludeDirs {
+		watch = append(watch, "!"+path.Join(dir, "**"))
+	}
+	for _, f := range excludeFiles {
+		watch = append(watch, "!"+f)
+	}
+	return watch
+}
+
+// reRegexMeta matches regexp syntax beyond an escaped dot and anchors.
+var reRegexMeta = regexp.MustCompile(`[*+?()\[\]{}|\\]`)
+
+// regexToGlob converts the literal exclude_regex entries air configs use in
+// practice, such as "_test.go", "_templ\.go$", or "^gen/.*", to a glob.
+func regexToGlob(re string) (string, bool) {
+	lit, fromRoot := strings.CutPrefix(re, "^")
+	if dir, ok := strings.CutSuffix(lit, "/.*"); ok && fromRoot && !reRegexMeta.MatchString(dir) {
+		return dir + "/**", true
+	}
+	lit, anchored := strings.CutSuffix(lit, "$")
+	lit = strings.ReplaceAll(lit, `\.`, ".")
+	if lit == "" || reRegexMeta.MatchString(lit) {
+		return "", false
+	}
+	if anchored || path.Ext(lit) != "" {
+		return "**/*" + lit, true
+	}
+	return "**/*" + lit + "*", true
+}
+
+// reShellOps matches shell syntax that needs sh to run.
+var reShellOps = regexp.MustCompile("&&|\\|\\||[|;<>`]")
+
+// reEnvRef matches $VAR and ${VAR} references.
+var reEnvRef = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// airCommand adapts a command air runs through sh: variable references
+// become env template calls, and commands using shell operators are wrapped
+// in sh -c.
+func airCommand(cmd string) (string, error) {
+	cmd = reEnvRef.ReplaceAllStringFunc(cmd, func(m string) string {
+		sub := reEnvRef.FindStringSubmatch(m)
+		return `{{ env "` + sub[1] + sub[2] + `" }}`
+	})
+	if strings.Contains(cmd, "$(") {
+		return "", fmt.Errorf("command %q uses $(...) substitution; rewrite it as a script", cmd)
+	}
+	if reShellOps.MatchString(cmd) {
+		return "sh -c " + quoteArg(cmd), nil
+	}
+	return cmd, nil
+}
+
+// reSafeArg matches arguments shlex takes literally without quoting.
+var reSafeArg = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,=-]+$`)
+
+// quoteArg single-quotes an argument for shlex unless it is safe as is.
+func quoteArg(a string) string {
+	if reSafeArg.MatchString(a) {
+		return a
+	}
+	return "'" + strings.ReplaceAll(a, "'", `'"'"'`) + "'"
+}
@@ -0,0 +1,87 @@
+package execrun_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/execrun"
+)
+
+var _ = Describe("ImportAir", func() {
+	importFile := func(content string) *execrun.AirImport {
+		path := filepath.Join(GinkgoT().TempDir(), ".air.toml")
+		Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		result, err := execrun.ImportAir(path)
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	It("fills in air's defaults for an empty build section", func() {
+		result := importFile("[build]\n")
+		Expect(result.Warnings).To(BeEmpty())
+		Expect(result.Config).To(Equal(execrun.Config{
+			Watch: execrun.Watches(
+				"**/*.go", "**/*.tpl", "**/*.tmpl", "**/*.html",
+				"!assets/**", "!tmp/**", "!vendor/**", "!testdata/**",
+				"!**/*_test.go",
+			),
+			Build:    []string{"go build -o ./tmp/main ."},
+			Exec:     []string{"./tmp/main"},
+			Debounce: "1s",
+		}))
+		Expect(result.Config.Validate()).To(Succeed())
+	})
+
+	It("translates commands, includes, excludes, and the delay", func() {
+		result := importFile(`
+tmp_dir = "build"
+
+[build]
+  pre_cmd = ["templ generate"]
+  cmd = "go build -ldflags \"-X main.version=$VERSION\" -o ./build/app ./cmd/app && echo ok"
+  bin = "build/app"
+  args_bin = ["-addr", ":8080", "hello world"]
+  include_ext = ["go"]
+  include_dir = ["cmd", "internal"]
+  include_file = ["go.mod"]
+  exclude_dir = ["internal/mocks"]
+  exclude_file = ["cmd/app/gen.go"]
+  exclude_regex = ["_templ\\.go$", "^gen/.*", "(foo|bar)"]
+  delay = 250
+  send_interrupt = true
+`)
+		Expect(result.Config).To(Equal(execrun.Config{
+			Watch: execrun.Watches(
+				"cmd/**/*.go", "internal/**/*.go", "go.mod",
+				"!internal/mocks/**", "!build/**", "!cmd/app/gen.go",
+				"!**/*_templ.go", "!gen/**",
+			),
+			Build: []string{
+				"templ generate",
+				`sh -c 'go build -ldflags "-X main.version={{ env "VERSION" }}" -o ./build/app ./cmd/app && echo ok'`,
+			},
+			Exec:     []string{"build/app -addr :8080 'hello world'"},
+			Debounce: "250ms",
+		}))
+		Expect(result.Warnings).To(Equal([]string{
+			`exclude_regex "(foo|bar)" has no glob equivalent, dropped`,
+			"send_interrupt is not supported, dropped (execrun stops with SIGTERM)",
+		}))
+	})
+
+	It("runs full_bin env assignments through env", func() {
+		result := importFile("[build]\n  full_bin = \"APP_ENV=dev ./tmp/main serve\"\n  delay = 0\n")
+		Expect(result.Config.Exec).To(Equal([]string{"env APP_ENV=dev ./tmp/main serve"}))
+		Expect(result.Config.Debounce).To(BeEmpty())
+	})
+
+	It("rejects command substitution", func() {
+		path := filepath.Join(GinkgoT().TempDir(), ".air.toml")
+		Expect(os.WriteFile(path, []byte("[build]\n  cmd = \"go build -ldflags=-X=main.sha=$(git rev-parse HEAD) .\"\n"), 0644)).To(Succeed())
+		_, err := execrun.ImportAir(path)
+		Expect(err).To(MatchError(ContainSubstring("uses $(...) substitution")))
+	})
+})
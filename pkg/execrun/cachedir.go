@@ -0,0 +1,75 @@
+package execrun
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gur-shatz/go-run/internal/glob"
+)
+
+// DefaultCacheDir is where sum files go, relative to the config, unless
+// cache_dir says otherwise.
+const DefaultCacheDir = ".gorun"
+
+// CachePath returns the absolute cache directory for a config in rootDir.
+func (this *Config) CachePath(rootDir string) string {
+	dir := this.CacheDir
+	if dir == "" {
+		dir = DefaultCacheDir
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(rootDir, dir)
+	}
+	return dir
+}
+
+// cacheExclusion excludes the cache directory from watching when it is
+// under rootDir.
+func (this *Config) cacheExclusion(rootDir string) []glob.Pattern {
+	rel, err := filepath.Rel(rootDir, this.CachePath(rootDir))
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	return []glob.Pattern{{Raw: filepath.ToSlash(rel) + "/**", Negated: true}}
+}
+
+// PrepareCacheDir creates dir with a .gitignore that ignores everything in
+// it, so the cache never shows up in git status. An existing .gitignore is
+// left alone. rootDir is the config's directory; a dir that is rootDir or
+// one of its parents is rejected, since it would ignore the whole project.
+func PrepareCacheDir(dir, rootDir string) error {
+	if err := checkCacheDir(dir, rootDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+	path := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err := os.WriteFile(path, []byte("*\n"), 0644); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+	return nil
+}
+
+// checkCacheDir fails if dir is rootDir or one of its parents.
+func checkCacheDir(dir, rootDir string) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("cache dir: %w", err)
+	}
+	absRoot, err := filepath.Abs(rootDir)
+	if err != nil {
+		return fmt.Errorf("cache dir: %w", err)
+	}
+	rel, err := filepath.Rel(absDir, absRoot)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	return fmt.Errorf("cache dir %s contains the config dir %s; use a dedicated dir such as %s", absDir, absRoot, DefaultCacheDir)
+}
@@ -1,15 +1,19 @@
 package execrun
 
 import (
+	"cmp"
 	"context"
 	_ "embed"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -20,13 +24,20 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/gur-shatz/go-run/internal/color"
+	"github.com/gur-shatz/go-run/internal/diskspace"
 	"github.com/gur-shatz/go-run/internal/glob"
+	"github.com/gur-shatz/go-run/internal/hasher"
 	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/internal/mask"
+	"github.com/gur-shatz/go-run/internal/procprio"
 	"github.com/gur-shatz/go-run/internal/scan"
+	"github.com/gur-shatz/go-run/internal/schema"
 	"github.com/gur-shatz/go-run/internal/sumfile"
 	"github.com/gur-shatz/go-run/internal/watcher"
 	"github.com/gur-shatz/go-run/pkg/backoffice"
+	"github.com/gur-shatz/go-run/pkg/clock"
 	"github.com/gur-shatz/go-run/pkg/config"
+	"github.com/gur-shatz/go-run/pkg/messages"
 )
 
 // Config represents the execrun.yaml configuration.
@@ -35,12 +46,107 @@ import (
 // long-running process whose lifecycle is managed (SIGTERM/SIGKILL on restart).
 // If build is non-empty and exec is empty, the target is build-only.
 type Config struct {
-	Title       string   `yaml:"title,omitempty"`
-	Description string   `yaml:"description,omitempty"`
-	Watch       []string `yaml:"watch"`
-	Build       []string `yaml:"build,omitempty"` // prep commands, run to completion
-	Test        []string `yaml:"test,omitempty"`  // test commands, run after build and before exec
-	Exec        []string `yaml:"exec,omitempty"`  // run commands; last is the managed process
+	Title       string    `yaml:"title,omitempty"`
+	Description string    `yaml:"description,omitempty"`
+	Watch       WatchList `yaml:"watch"`
+	Build       []string  `yaml:"build,omitempty"` // prep commands, run to completion
+	Test        []string  `yaml:"test,omitempty"`  // test commands, run after build and before exec
+	Exec        []string  `yaml:"exec,omitempty"`  // run commands; last is the managed process
+	// MinFreeSpace fails builds early when the temp or working directory
+	// volume has less free space than this, e.g. "1GB" (default: no check).
+	MinFreeSpace string `yaml:"min_free_space,omitempty"`
+	// BuildNice lowers the CPU priority of build and test steps, 1-19
+	// (default: 0, unchanged).
+	BuildNice int `yaml:"build_nice,omitempty"`
+	// BuildIonice lowers their I/O priority on Linux: "idle",
+	// "best-effort", or "best-effort:0-7" (default: unchanged).
+	BuildIonice string `yaml:"build_ionice,omitempty"`
+	// Debounce is how long to wait after the last file change before
+	// rebuilding, e.g. "1s" (default: 300ms). Options.Debounce overrides it.
+	Debounce string `yaml:"debounce,omitempty"`
+	// RestartWatch lists patterns of files the process reads at startup,
+	// such as templates. When every changed file matches one (or
+	// ReloadWatch), the process is restarted without build or test steps.
+	RestartWatch []string `yaml:"restart_watch,omitempty"`
+	// ReloadWatch lists patterns of config-only files. When every changed
+	// file matches one, ReloadSignal is sent to the running process
+	// instead of rebuilding and restarting it.
+	ReloadWatch []string `yaml:"reload_watch,omitempty"`
+	// ReloadSignal is the signal sent for reload_watch changes, e.g.
+	// "SIGUSR1" (default: SIGHUP).
+	ReloadSignal string `yaml:"reload_signal,omitempty"`
+	// EnvFile lists .env files whose pairs are template vars and are added
+	// to every command's environment; the real environment wins.
+	EnvFile config.EnvFiles `yaml:"env_file,omitempty"`
+	// Strict rejects unknown fields and loosely typed values such as
+	// yes/no booleans when loading (see config.IsStrict).
+	Strict bool `yaml:"strict,omitempty"`
+	// FollowSymlinks makes watch patterns descend into symlinked
+	// directories, e.g. a linked vendor or shared dir. Links that loop
+	// back are skipped.
+	FollowSymlinks bool `yaml:"follow_symlinks,omitempty"`
+	// IgnoreEditorArtifacts keeps editor temp files (vim swap files,
+	// JetBrains ___jb_tmp___ files) and atomic-save renames from
+	// triggering rebuilds (default: true).
+	IgnoreEditorArtifacts *bool `yaml:"ignore_editor_artifacts,omitempty"`
+	// LargeFileThreshold, e.g. "50MB", makes watched files of at least
+	// that size hash by size, mtime, and their first and last 64KB
+	// instead of their whole content (default: hash everything in full).
+	LargeFileThreshold string `yaml:"large_file_threshold,omitempty"`
+	// CacheDir holds the sum file, relative to the config (default:
+	// .gorun). It is created with a .gitignore and never watched.
+	CacheDir string `yaml:"cache_dir,omitempty"`
+	// Hooks maps "on_" + a hook event (see HookRebuilt and friends) to a
+	// command run in the background with the HookEvent JSON on stdin,
+	// e.g. on_build_failed: "say 'build broken'".
+	Hooks map[string]string `yaml:"hooks,omitempty"`
+	// Heartbeat turns the console heartbeat off (false) or sets its
+	// interval, e.g. 30s (default: 10s).
+	Heartbeat Heartbeat `yaml:"heartbeat,omitempty"`
+	// Output is "prefixed" to tag each line of child output with the
+	// target and stage, or "raw" (default) for interactive apps.
+	Output string `yaml:"output,omitempty"`
+	// OutputBuffer, e.g. 1MB, buffers child output in memory so a blocked
+	// destination can't stall the child; when full, the oldest lines are
+	// dropped (default: unbuffered).
+	OutputBuffer string `yaml:"output_buffer,omitempty"`
+}
+
+// ShouldIgnoreEditorArtifacts returns whether the watcher filters editor
+// temp files, defaulting to true.
+func (this *Config) ShouldIgnoreEditorArtifacts() bool {
+	if this.IgnoreEditorArtifacts == nil {
+		return true
+	}
+	return *this.IgnoreEditorArtifacts
+}
+
+// globOptions returns the options watch patterns are expanded with.
+func (this *Config) globOptions() []glob.Option {
+	if this.FollowSymlinks {
+		return []glob.Option{glob.FollowSymlinks()}
+	}
+	return nil
+}
+
+// hashOptions returns the options watched files are hashed with.
+func (this *Config) hashOptions() []hasher.Option {
+	return []hasher.Option{hasher.LargeFiles(this.LargeFileBytes())}
+}
+
+// scanSums hashes the watched files and rewrites the sum file at sumPath
+// with their sizes and mtimes. Files unchanged since the sum file was
+// written keep its hash instead of being read again.
+func (this *Config) scanSums(rootDir string, patterns []glob.Pattern, sumPath string) (map[string]string, error) {
+	prev, _ := sumfile.ReadStats(sumPath) // unreadable: hash everything
+	stats, err := scan.ScanFilesCached(rootDir, patterns, prev, this.hashOptions(), this.globOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+	if err := sumfile.WriteStats(sumPath, stats); err != nil {
+		return nil, fmt.Errorf("write sum file: %w", err)
+	}
+	return sumfile.Hashes(stats), nil
 }
 
 // IsBuildOnly returns true when there are no exec commands (build-only target).
@@ -58,8 +164,17 @@ type Options struct {
 	ContinueOnError bool
 	// DisableHeartbeat suppresses periodic console dots.
 	DisableHeartbeat bool
-	Stdout           io.Writer
-	Stderr           io.Writer
+	// PrefixOutput tags each line of child output with the log prefix and
+	// stage, unless the output: setting says otherwise.
+	PrefixOutput bool
+	// HeartbeatInterval overrides the heartbeat: setting's interval.
+	HeartbeatInterval time.Duration
+	// Quiet hides, process-wide, heartbeats and status, change, and verbose
+	// lines; errors, warnings, and build results still print (see
+	// log.SetQuiet).
+	Quiet  bool
+	Stdout io.Writer
+	Stderr io.Writer
 
 	// RootDir overrides the working directory (default: os.Getwd()).
 	// Commands are executed with this as the working directory.
@@ -68,7 +183,31 @@ type Options struct {
 	// LogPrefix overrides the log prefix (default: "[execrun]").
 	LogPrefix string
 
-	SumFile string // sum file path (relative to RootDir), e.g. "execrun.sum"
+	// LogFormat switches execrun's own messages, process-wide, to "text"
+	// (default), "porcelain", or "json" lines (see log.Format).
+	LogFormat string
+
+	// Color sets, process-wide, whether messages are colored: "auto"
+	// (default), "always", or "never" (see color.SetMode).
+	Color string
+
+	// LogLevel hides execrun's messages, process-wide, below "debug",
+	// "info" (default), "warn", or "error" (see log.SetLevel).
+	LogLevel string
+
+	// LogTime starts text log lines, process-wide, with a timestamp:
+	// "time", "ms", "rfc3339", or a Go time layout (see log.SetTimeFormat).
+	LogTime string
+
+	SumFile string // sum file path (absolute, or relative to RootDir) (default: execrun.sum in the cache dir)
+
+	// Force takes over the lock file (see LockPath) of another live
+	// instance watching the same sum file instead of failing with ErrLocked.
+	Force bool
+
+	// Clock drives the watcher's polling and debounce, the heartbeat, and the
+	// SIGTERM → SIGKILL stop timeout (default: the wall clock).
+	Clock clock.Clock
 
 	// ExecStdout and ExecStderr override output for exec steps (build commands).
 	// Defaults to Stdout/Stderr if nil.
@@ -94,6 +233,8 @@ type Options struct {
 	TestTrigger  <-chan struct{} // triggers tests only
 	ExecStop     <-chan struct{} // stops just the managed process
 	ExecStart    <-chan struct{} // starts just the managed process (no rebuild)
+	WatchPause   <-chan struct{} // stops reacting to file changes, e.g. during a rebase
+	WatchResume  <-chan struct{} // resumes, picking up changes made while paused
 }
 
 // exitInfo describes how the child process exited.
@@ -102,6 +243,11 @@ type exitInfo struct {
 	Err      error
 }
 
+// Deprecations lists the renamed keys of Config, e.g.
+// {Old: "exec", New: "run", Since: "v1.2"}. Configs using an old key
+// still load, with a warning.
+var Deprecations = config.Deprecations{}
+
 // LoadConfig reads and parses a YAML config file.
 // Accepts optional config.Option values to control template processing
 // (e.g. config.WithVars to inject parent variables from runctl).
@@ -110,11 +256,23 @@ func LoadConfig(path string, opts ...config.Option) (*Config, map[string]string,
 	if err != nil {
 		return nil, nil, err
 	}
+	if data, err = Deprecations.Apply(data, path, opts...); err != nil {
+		return nil, nil, err
+	}
+
+	validate, unmarshal := configSchema.Validate, yaml.Unmarshal
+	if config.IsStrict(data, opts...) {
+		validate, unmarshal = configSchema.ValidateStrict, config.UnmarshalStrict
+	}
+	if err := validate(data); err != nil {
+		return nil, nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
 
 	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	if err := unmarshal(data, &cfg); err != nil {
 		return nil, nil, fmt.Errorf("parse config %s: %w", path, err)
 	}
+	cfg.EnvFile = cfg.EnvFile.Abs(filepath.Dir(path))
 
 	if err := cfg.Validate(); err != nil {
 		return nil, nil, fmt.Errorf("invalid config: %w", err)
@@ -128,7 +286,7 @@ func DefaultConfig() Config {
 	return Config{
 		Title:       "App",
 		Description: "Watched app target",
-		Watch:       []string{"**/*.go", "go.mod", "go.sum"},
+		Watch:       Watches("**/*.go", "go.mod", "go.sum"),
 		Build:       []string{"go build -o ./bin/app ."},
 		Test:        []string{"go test ./..."},
 		Exec:        []string{"./bin/app"},
@@ -168,9 +326,25 @@ func checkShellVars(cmd string) error {
 // Validate checks that the config has required fields and trims whitespace
 // from commands (YAML literal blocks add trailing newlines).
 func (this *Config) Validate() error {
-	if len(this.Watch) == 0 {
+	if len(this.WatchPatterns()) == 0 {
 		return fmt.Errorf("watch must have at least one pattern")
 	}
+	if err := this.validateWatch(); err != nil {
+		return err
+	}
+	if err := this.Heartbeat.Validate(); err != nil {
+		return err
+	}
+	if err := this.validateOutput(); err != nil {
+		return err
+	}
+	if this.OutputBuffer != "" {
+		if n, err := diskspace.ParseSize(this.OutputBuffer); err != nil {
+			return fmt.Errorf("output_buffer: %w", err)
+		} else if n == 0 {
+			return fmt.Errorf("output_buffer must be positive, got %q", this.OutputBuffer)
+		}
+	}
 	if len(this.Build)+len(this.Test)+len(this.Exec) == 0 {
 		return fmt.Errorf("at least one build, test, or exec command is required")
 	}
@@ -192,9 +366,56 @@ func (this *Config) Validate() error {
 			return err
 		}
 	}
+	if this.MinFreeSpace != "" {
+		if _, err := diskspace.ParseSize(this.MinFreeSpace); err != nil {
+			return fmt.Errorf("min_free_space: %w", err)
+		}
+	}
+	if this.LargeFileThreshold != "" {
+		if _, err := diskspace.ParseSize(this.LargeFileThreshold); err != nil {
+			return fmt.Errorf("large_file_threshold: %w", err)
+		}
+	}
+	if this.Debounce != "" {
+		if d, err := time.ParseDuration(this.Debounce); err != nil || d <= 0 {
+			return fmt.Errorf("debounce must be a positive duration like 500ms, got %q", this.Debounce)
+		}
+	}
+	if err := this.validateGroups(); err != nil {
+		return err
+	}
+	if err := this.validateHooks(); err != nil {
+		return err
+	}
+	if this.BuildNice < 0 || this.BuildNice > procprio.MaxNice {
+		return fmt.Errorf("build_nice must be 0-%d, got %d", procprio.MaxNice, this.BuildNice)
+	}
+	if this.BuildIonice != "" {
+		if _, err := procprio.ParseIOPriority(this.BuildIonice); err != nil {
+			return fmt.Errorf("build_ionice: %w", err)
+		}
+	}
 	return nil
 }
 
+// DebounceDuration returns the debounce: setting, or 0 if unset.
+func (this *Config) DebounceDuration() time.Duration {
+	d, _ := time.ParseDuration(this.Debounce)
+	return d
+}
+
+// MinFreeBytes returns the min_free_space threshold in bytes, or 0 if unset.
+func (this *Config) MinFreeBytes() uint64 {
+	n, _ := diskspace.ParseSize(this.MinFreeSpace)
+	return n
+}
+
+// LargeFileBytes returns the large_file_threshold in bytes, or 0 if unset.
+func (this *Config) LargeFileBytes() int64 {
+	n, _ := diskspace.ParseSize(this.LargeFileThreshold)
+	return int64(n)
+}
+
 // BuildSteps returns the build commands.
 func (this *Config) BuildSteps() []string { return this.Build }
 
@@ -238,6 +459,27 @@ func (this *Config) RunCmd() string {
 	return this.Exec[len(this.Exec)-1]
 }
 
+// environ returns the environment for commands: the process environment
+// plus pairs from env_file: that it doesn't set. Nil when there are none,
+// so commands inherit the environment. Relative paths are resolved
+// against rootDir.
+func (this *Config) environ(rootDir string) ([]string, error) {
+	if len(this.EnvFile) == 0 {
+		return nil, nil
+	}
+	vars, err := config.ReadDotEnv(rootDir, this.EnvFile...)
+	if err != nil {
+		return nil, err
+	}
+	env := os.Environ()
+	for _, k := range slices.Sorted(maps.Keys(vars)) {
+		if _, ok := os.LookupEnv(k); !ok {
+			env = append(env, k+"="+vars[k])
+		}
+	}
+	return env, nil
+}
+
 // runner manages the lifecycle of the child process.
 type runner struct {
 	cfg     Config
@@ -246,6 +488,7 @@ type runner struct {
 	stdout  io.Writer
 	stderr  io.Writer
 	rootDir string
+	env     []string // command environment; nil inherits ours
 	log     *log.Logger
 
 	mu       sync.Mutex
@@ -258,7 +501,8 @@ type runner struct {
 	backofficeCancel   context.CancelFunc
 }
 
-func newRunner(ctx context.Context, cfg Config, opts Options, rootDir string, logger *log.Logger) *runner {
+func newRunner(ctx context.Context, cfg Config, opts Options, rootDir string, env []string, logger *log.Logger) *runner {
+	opts.Clock = clock.Or(opts.Clock)
 	return &runner{
 		cfg:     cfg,
 		opts:    opts,
@@ -266,6 +510,7 @@ func newRunner(ctx context.Context, cfg Config, opts Options, rootDir string, lo
 		stdout:  opts.Stdout,
 		stderr:  opts.Stderr,
 		rootDir: rootDir,
+		env:     env,
 		log:     logger,
 		exited:  make(chan exitInfo, 1),
 	}
@@ -292,6 +537,7 @@ func (this *runner) buildCmd(ctx context.Context, cmdStr string) (*exec.Cmd, err
 	}
 	c := exec.CommandContext(ctx, args[0], args[1:]...)
 	c.Dir = this.rootDir
+	c.Env = this.env
 	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 	return c, nil
 }
@@ -305,6 +551,7 @@ func (this *runner) buildCmdNoCtx(cmdStr string) (*exec.Cmd, error) {
 	}
 	c := exec.Command(args[0], args[1:]...)
 	c.Dir = this.rootDir
+	c.Env = this.env
 	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 	return c, nil
 }
@@ -316,37 +563,116 @@ func (this *runner) logTo(w io.Writer, format string, args ...any) {
 	fmt.Fprintf(w, "======== %s : %s\n", ts, msg)
 }
 
+// logCommand prints the command line, working directory, and any env vars
+// that differ from the parent environment. Verbose mode only. Values of
+// sensitive vars are masked.
+func (this *runner) logCommand(c *exec.Cmd, cmdStr string) {
+	if !this.opts.Verbose {
+		return
+	}
+	extra := envDiff(c.Env)
+	this.log.Verbose("Running: %s", mask.String(cmdStr, append(os.Environ(), extra...)))
+	this.log.Verbose("  dir: %s", c.Dir)
+	for _, e := range extra {
+		this.log.Verbose("  env: %s", mask.EnvEntry(e))
+	}
+}
+
+// envDiff returns the entries of env that are not present, with the same
+// value, in the current process environment. A nil env means the command
+// inherits the environment unchanged.
+func envDiff(env []string) []string {
+	if env == nil {
+		return nil
+	}
+	current := make(map[string]bool)
+	for _, e := range os.Environ() {
+		current[e] = true
+	}
+	var diff []string
+	for _, e := range env {
+		if !current[e] {
+			diff = append(diff, e)
+		}
+	}
+	return diff
+}
+
 // runStep runs a single command with the given stdout/stderr writers.
-// The command is cancelled if the runner's context is done.
-func (this *runner) runStep(cmd string, stdout, stderr io.Writer) error {
-	this.log.Verbose("Running: %s", cmd)
-	this.logTo(stdout, "Running: %s", cmd)
-	c, err := this.buildCmd(this.ctx, cmd)
+// The command is cancelled (SIGTERM to its process group) when ctx is done;
+// the returned error then wraps ctx.Err(). Throttled steps run at the
+// build_nice/build_ionice priority.
+func (this *runner) runStep(ctx context.Context, cmd string, stdout, stderr io.Writer, throttle bool) error {
+	this.logTo(stdout, "%s", messages.Sprintf(messages.LogRunning, cmd))
+	c, err := this.buildCmd(ctx, cmd)
 	if err != nil {
 		return err
 	}
+	this.logCommand(c, cmd)
 	c.Stdout = stdout
 	c.Stderr = stderr
 	c.Cancel = func() error {
 		return killProcessGroup(c.Process, syscall.SIGTERM)
 	}
 	c.WaitDelay = 5 * time.Second
-	if err := c.Run(); err != nil {
-		this.logTo(stdout, "Command failed: %s", err)
+	err = c.Start()
+	if err == nil {
+		if throttle {
+			this.throttle(c.Process.Pid)
+		}
+		err = c.Wait()
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			this.logTo(stdout, "%s", messages.Sprintf(messages.LogCommandCancel))
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		}
+		this.logTo(stdout, "%s", messages.Sprintf(messages.LogCommandFailed, err))
 		return err
 	}
 	return nil
 }
 
-func (this *runner) runBuildSteps() (time.Duration, error) {
+// throttle lowers the priority of the step running as process group pgid.
+// Failures are logged and the step keeps its normal priority; a step that
+// already exited is not a failure.
+func (this *runner) throttle(pgid int) {
+	if this.cfg.BuildNice > 0 {
+		if err := procprio.SetNice(pgid, this.cfg.BuildNice); err != nil && !errors.Is(err, syscall.ESRCH) {
+			this.log.Warn("build_nice: %v", err)
+		}
+	}
+	if this.cfg.BuildIonice != "" {
+		p, _ := procprio.ParseIOPriority(this.cfg.BuildIonice)
+		if err := procprio.SetIOPriority(pgid, p); err != nil && !errors.Is(err, syscall.ESRCH) {
+			this.log.Warn("build_ionice: %v", err)
+		}
+	}
+}
+
+// runBuildSteps runs the build commands. A cancelled build (ctx done) does
+// not report OnBuildDone; the build that superseded it will.
+func (this *runner) runBuildSteps(ctx context.Context) (time.Duration, error) {
 	start := time.Now()
 	if this.opts.OnBuildStart != nil {
 		this.opts.OnBuildStart()
 	}
 
+	if err := this.checkDiskSpace(); err != nil {
+		dur := time.Since(start)
+		this.logTo(this.opts.ExecStderr, "%v", err)
+		if this.opts.OnBuildDone != nil {
+			this.opts.OnBuildDone(dur, err)
+		}
+		return dur, err
+	}
+
 	for _, cmd := range this.cfg.BuildSteps() {
-		if err := this.runStep(cmd, this.opts.ExecStdout, this.opts.ExecStderr); err != nil {
+		if err := this.runStep(ctx, cmd, this.opts.ExecStdout, this.opts.ExecStderr, true); err != nil {
 			dur := time.Since(start)
+			if ctx.Err() != nil {
+				return dur, err
+			}
 			if this.opts.OnBuildDone != nil {
 				this.opts.OnBuildDone(dur, err)
 			}
@@ -356,7 +682,7 @@ func (this *runner) runBuildSteps() (time.Duration, error) {
 
 	dur := time.Since(start)
 	if len(this.cfg.BuildSteps()) > 0 {
-		this.logTo(this.opts.ExecStdout, "Build done (%s)", scan.FormatDuration(dur))
+		this.logTo(this.opts.ExecStdout, "%s", messages.Sprintf(messages.LogBuildDone, scan.FormatDuration(dur)))
 	}
 	if this.opts.OnBuildDone != nil {
 		this.opts.OnBuildDone(dur, nil)
@@ -364,15 +690,30 @@ func (this *runner) runBuildSteps() (time.Duration, error) {
 	return dur, nil
 }
 
-func (this *runner) runTestSteps() (time.Duration, error) {
+// checkDiskSpace fails fast when the temp or working directory volume is
+// below min_free_space, rather than letting build steps die on write errors.
+func (this *runner) checkDiskSpace() error {
+	minFree := this.cfg.MinFreeBytes()
+	if minFree == 0 || len(this.cfg.BuildSteps()) == 0 {
+		return nil
+	}
+	return diskspace.Check(minFree, os.TempDir(), this.rootDir)
+}
+
+// runTestSteps runs the test commands. Like runBuildSteps, a cancelled run
+// does not report OnTestDone.
+func (this *runner) runTestSteps(ctx context.Context) (time.Duration, error) {
 	start := time.Now()
 	if this.opts.OnTestStart != nil {
 		this.opts.OnTestStart()
 	}
 
 	for _, cmd := range this.cfg.TestSteps() {
-		if err := this.runStep(cmd, this.opts.TestStdout, this.opts.TestStderr); err != nil {
+		if err := this.runStep(ctx, cmd, this.opts.TestStdout, this.opts.TestStderr, true); err != nil {
 			dur := time.Since(start)
+			if ctx.Err() != nil {
+				return dur, err
+			}
 			if this.opts.OnTestDone != nil {
 				this.opts.OnTestDone(dur, err)
 			}
@@ -382,7 +723,7 @@ func (this *runner) runTestSteps() (time.Duration, error) {
 
 	dur := time.Since(start)
 	if len(this.cfg.TestSteps()) > 0 {
-		this.logTo(this.opts.TestStdout, "Tests done (%s)", scan.FormatDuration(dur))
+		this.logTo(this.opts.TestStdout, "%s", messages.Sprintf(messages.LogTestsDone, scan.FormatDuration(dur)))
 	}
 	if this.opts.OnTestDone != nil {
 		this.opts.OnTestDone(dur, nil)
@@ -395,23 +736,32 @@ func (this *runner) runTestSteps() (time.Duration, error) {
 // Test steps write to TestStdout/TestStderr (test log).
 // Exec prep steps write to Stdout/Stderr (run log).
 // Returns the total duration and any error.
-func (this *runner) execSteps() (time.Duration, error) {
+func (this *runner) execSteps(ctx context.Context) (time.Duration, error) {
 	start := time.Now()
 
-	if _, err := this.runBuildSteps(); err != nil {
+	if _, err := this.runBuildSteps(ctx); err != nil {
+		return time.Since(start), err
+	}
+
+	if _, err := this.runTestSteps(ctx); err != nil {
 		return time.Since(start), err
 	}
 
-	if _, err := this.runTestSteps(); err != nil {
+	if _, err := this.execPrepSteps(ctx); err != nil {
 		return time.Since(start), err
 	}
 
+	return time.Since(start), nil
+}
+
+// execPrepSteps runs the exec prep steps, writing to Stdout/Stderr.
+func (this *runner) execPrepSteps(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
 	for _, cmd := range this.cfg.ExecPrepSteps() {
-		if err := this.runStep(cmd, this.stdout, this.stderr); err != nil {
+		if err := this.runStep(ctx, cmd, this.stdout, this.stderr, false); err != nil {
 			return time.Since(start), fmt.Errorf("command %q failed: %w", cmd, err)
 		}
 	}
-
 	return time.Since(start), nil
 }
 
@@ -423,7 +773,7 @@ func (this *runner) start() error {
 	this.stopping = false
 	cmd, err := this.buildCmdNoCtx(this.cfg.RunCmd())
 	if err != nil {
-		this.logTo(this.stdout, "Start failed: %s", err)
+		this.logTo(this.stdout, "%s", messages.Sprintf(messages.LogStartFailed, err))
 		return fmt.Errorf("start: %w", err)
 	}
 	this.cmd = cmd
@@ -434,21 +784,26 @@ func (this *runner) start() error {
 	// Set up backoffice UDS for the child process
 	sockDir, err := os.MkdirTemp("", "gorun-bo-*")
 	if err != nil {
-		this.logTo(this.stdout, "Start failed: create backoffice temp dir: %s", err)
+		this.logTo(this.stdout, "%s", messages.Sprintf(messages.LogStartFailed, fmt.Sprintf("create backoffice temp dir: %s", err)))
 		return fmt.Errorf("start: create backoffice temp dir: %w", err)
 	}
 	sockPath := filepath.Join(sockDir, "bo.sock")
 	this.backofficeSockDir = sockDir
 	this.backofficeSockPath = sockPath
-	this.cmd.Env = append(os.Environ(), backoffice.EnvSockPath+"="+sockPath)
+	env := slices.Clip(this.env)
+	if env == nil {
+		env = os.Environ()
+	}
+	this.cmd.Env = append(env, backoffice.EnvSockPath+"="+sockPath)
+	this.logCommand(this.cmd, this.cfg.RunCmd())
 
 	if err := this.cmd.Start(); err != nil {
 		os.RemoveAll(sockDir)
-		this.logTo(this.stdout, "Start failed: %s", err)
+		this.logTo(this.stdout, "%s", messages.Sprintf(messages.LogStartFailed, err))
 		return fmt.Errorf("start: %w", err)
 	}
 
-	this.logTo(this.stdout, "Process started (pid %d): %s", this.cmd.Process.Pid, this.cfg.RunCmd())
+	this.logTo(this.stdout, "%s", messages.Sprintf(messages.LogProcessStarted, this.cmd.Process.Pid, this.cfg.RunCmd()))
 
 	if this.opts.OnProcessStart != nil {
 		this.opts.OnProcessStart(this.cmd.Process.Pid)
@@ -487,7 +842,7 @@ func (this *runner) start() error {
 		}
 
 		if !wasStopping {
-			this.logTo(this.stdout, "Process exited (code %d)", exitCode)
+			this.logTo(this.stdout, "%s", messages.Sprintf(messages.LogProcessExited, exitCode))
 			if this.opts.OnProcessExit != nil {
 				this.opts.OnProcessExit(exitCode, err)
 			}
@@ -549,7 +904,7 @@ func (this *runner) stop() error {
 		return nil
 	}
 
-	this.logTo(this.stdout, "Stopping process (pid %d, SIGTERM)", cmd.Process.Pid)
+	this.logTo(this.stdout, "%s", messages.Sprintf(messages.LogStopping, cmd.Process.Pid))
 
 	// Kill the entire process group (process + children)
 	if err := killProcessGroup(cmd.Process, syscall.SIGTERM); err != nil {
@@ -567,13 +922,13 @@ func (this *runner) stop() error {
 
 	select {
 	case <-done:
-		this.logTo(this.stdout, "Process stopped")
-	case <-time.After(5 * time.Second):
-		this.log.Warn("Process group didn't exit after SIGTERM, sending SIGKILL...")
-		this.logTo(this.stdout, "Process didn't exit after SIGTERM, sending SIGKILL")
+		this.logTo(this.stdout, "%s", messages.Sprintf(messages.LogStopped))
+	case <-this.opts.Clock.After(5 * time.Second):
+		this.log.Warn("%s", messages.Sprintf(messages.SIGKILLWarning))
+		this.logTo(this.stdout, "%s", messages.Sprintf(messages.LogSIGKILL))
 		killProcessGroup(cmd.Process, syscall.SIGKILL)
 		<-done
-		this.logTo(this.stdout, "Process killed")
+		this.logTo(this.stdout, "%s", messages.Sprintf(messages.LogKilled))
 	}
 
 	if sockDir != "" {
@@ -608,9 +963,14 @@ func killProcessGroup(p *os.Process, sig syscall.Signal) error {
 }
 
 // restart runs preparation steps, stops old process, starts new one.
-// If any step fails, the old process keeps running.
-func (this *runner) restart() (time.Duration, error) {
-	buildDuration, err := this.execSteps()
+// Without rebuild only the exec prep steps run, for restart_watch changes.
+// If any step fails or ctx is cancelled, the old process keeps running.
+func (this *runner) restart(ctx context.Context, rebuild bool) (time.Duration, error) {
+	steps := this.execSteps
+	if !rebuild {
+		steps = this.execPrepSteps
+	}
+	buildDuration, err := steps(ctx)
 	if err != nil {
 		return buildDuration, err
 	}
@@ -665,8 +1025,9 @@ func Run(ctx context.Context, cfg Config, opts Options) error {
 		opts.PollInterval = 500 * time.Millisecond
 	}
 	if opts.Debounce == 0 {
-		opts.Debounce = 300 * time.Millisecond
+		opts.Debounce = cmp.Or(cfg.DebounceDuration(), 300*time.Millisecond)
 	}
+	opts.Clock = clock.Or(opts.Clock)
 	if opts.Stdout == nil {
 		opts.Stdout = os.Stdout
 	}
@@ -686,12 +1047,17 @@ func Run(ctx context.Context, cfg Config, opts Options) error {
 		opts.TestStderr = opts.Stderr
 	}
 
+	if err := applyLogOptions(opts); err != nil {
+		return err
+	}
 	color.Init()
-	prefix := "[execrun]"
+	prefix := messages.Get(messages.ExecrunPrefix)
 	if opts.LogPrefix != "" {
 		prefix = opts.LogPrefix
 	}
 	l := log.New(prefix, opts.Verbose)
+	defer bufferOutput(cfg, &opts, l)()
+	defer prefixOutput(cfg, &opts, prefix)()
 
 	rootDir := opts.RootDir
 	if rootDir == "" {
@@ -703,7 +1069,8 @@ func Run(ctx context.Context, cfg Config, opts Options) error {
 	}
 
 	// Convert watch patterns
-	patterns := scan.ParseWatchPatterns(cfg.Watch)
+	patterns := cfg.patterns(rootDir)
+	groups := cfg.watchGroups(rootDir)
 
 	l.Verbose("Watching patterns:")
 	for _, p := range patterns {
@@ -714,25 +1081,44 @@ func Run(ctx context.Context, cfg Config, opts Options) error {
 		l.Verbose("%s%s", pfx, p.Raw)
 	}
 
-	// Initial scan
-	initialSums, err := scan.ScanFiles(rootDir, patterns)
-	if err != nil {
-		return fmt.Errorf("initial scan: %w", err)
-	}
-	l.Verbose("Watching %d files", len(initialSums))
-
-	// Write sum file (persisted in working directory)
+	// Sum file (persisted in the cache dir)
 	sumFile := opts.SumFile
 	if sumFile == "" {
-		sumFile = "execrun.sum"
+		cacheDir := cfg.CachePath(rootDir)
+		if err := PrepareCacheDir(cacheDir, rootDir); err != nil {
+			return err
+		}
+		sumFile = filepath.Join(cacheDir, "execrun.sum")
+	}
+	sumPath := sumFile
+	if !filepath.IsAbs(sumPath) {
+		sumPath = filepath.Join(rootDir, sumFile)
+	}
+
+	// One instance per sum file
+	release, took, err := lockFile(LockPath(sumPath), opts.Force)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if took != 0 {
+		l.Warn("%s", messages.Sprintf(messages.LockTakenOver, took))
 	}
-	sumPath := filepath.Join(rootDir, sumFile)
-	if err := sumfile.Write(sumPath, initialSums); err != nil {
-		return fmt.Errorf("write sum file: %w", err)
+
+	// Initial scan, trusting the sum file's hashes for unchanged files
+	initialSums, err := cfg.scanSums(rootDir, patterns, sumPath)
+	if err != nil {
+		return err
 	}
+	l.Verbose("Watching %d files", len(initialSums))
 
 	// Execute steps and start process
-	r := newRunner(ctx, cfg, opts, rootDir, l)
+	env, err := cfg.environ(rootDir)
+	if err != nil {
+		return err
+	}
+	opts = cfg.withHooks(opts, rootDir, env, l)
+	r := newRunner(ctx, cfg, opts, rootDir, env, l)
 	defer r.cleanup()
 
 	if cfg.IsBuildOnly() {
@@ -745,64 +1131,87 @@ func Run(ctx context.Context, cfg Config, opts Options) error {
 
 	// Set up watcher before the initial execution so ContinueOnError can keep
 	// watching even if startup fails.
-	w := watcher.New(rootDir, patterns, opts.PollInterval, opts.Debounce, func(changes sumfile.ChangeSet) {
-		if opts.OnFilesChanged != nil {
-			opts.OnFilesChanged(time.Now(), changes)
-		}
-		l.Change(changes)
-
-		l.Status("Rebuilding...")
-		dur, err := r.execSteps()
-		if err != nil {
-			l.Error("Build failed: %v", err)
-			l.Warn("Keeping previous process running.")
-			healthy.Store(false)
-			return
-		}
-		l.Success("Build done (%s).", scan.FormatDuration(dur))
-
-		l.Status("Executing...")
-		if err := r.stop(); err != nil {
-			l.Error("Stop failed: %v", err)
-			healthy.Store(false)
-			return
-		}
-		// Drain stale exit info
-		select {
-		case <-r.exited:
+	// Builds from file changes and BuildTrigger go through a queue: a newer
+	// request cancels the build in progress.
+	queue := newBuildQueue()
+	rebuild := func(buildCtx context.Context, changes *sumfile.ChangeSet) {
+		// restart_watch changes skip the build, unless the last build
+		// failed and the running process is stale anyway.
+		full := true
+		switch {
+		case changes == nil:
+			l.Status("%s", messages.Sprintf(messages.BuildTriggered))
+		case healthy.Load() && groups.action(*changes) != actionRebuild:
+			l.Status("%s", messages.Sprintf(messages.Restarting))
+			full = false
 		default:
+			l.Status("%s", messages.Sprintf(messages.Rebuilding))
 		}
-		if err := r.start(); err != nil {
-			l.Error("Start failed: %v", err)
+		dur, err := r.restart(buildCtx, full)
+		if err != nil {
+			if buildCtx.Err() != nil {
+				if ctx.Err() == nil {
+					l.Status("%s", messages.Sprintf(messages.BuildCancelled))
+				}
+				return
+			}
+			l.Error("%s", messages.Sprintf(messages.BuildFailed, err))
+			l.Warn("%s", messages.Sprintf(messages.KeepingPrevious))
 			healthy.Store(false)
 			return
 		}
-		l.Success("Started (pid %d).", r.pid())
+		l.Success("%s", messages.Sprintf(messages.BuildDone, r.pid(), scan.FormatDuration(dur)))
 		healthy.Store(true)
 
 		// Update sum file
-		newSums, err := scan.ScanFiles(rootDir, patterns)
-		if err == nil {
-			if writeErr := sumfile.Write(sumPath, newSums); writeErr != nil {
-				l.Verbose("update sum file: %v", writeErr)
+		if _, err := cfg.scanSums(rootDir, patterns, sumPath); err != nil {
+			l.Verbose("update sum file: %v", err)
+		}
+	}
+
+	w := watcher.New(rootDir, patterns, opts.PollInterval, opts.Debounce, func(changes sumfile.ChangeSet) {
+		if opts.OnFilesChanged != nil {
+			opts.OnFilesChanged(opts.Clock.Now(), changes)
+		}
+		l.Change(changes)
+		if groups.action(changes) == actionReload {
+			// Config-only change: signal the process rather than restart
+			// it. With no process running, fall through to a restart.
+			if pid, err := r.reload(); err != nil {
+				l.Error("%s", messages.Sprintf(messages.ReloadFailed, err))
+				return
+			} else if pid != 0 {
+				_, name := cfg.reloadSignal()
+				l.Success("%s", messages.Sprintf(messages.Reloading, name, pid))
+				return
 			}
 		}
+		queue.push(&changes)
 	}, l)
 	w.SetCurrentSums(initialSums)
+	w.SetClock(opts.Clock)
+	w.SetFollowSymlinks(cfg.FollowSymlinks)
+	w.SetLargeFileThreshold(cfg.LargeFileBytes())
+	w.SetIgnoreEditorArtifacts(cfg.ShouldIgnoreEditorArtifacts())
+	w.SetDebounceGroups(cfg.debounceGroups(rootDir))
 
 	go w.Run(ctx)
+	go watchTrigger(ctx, opts.Clock, filepath.Join(rootDir, ReloadFile), opts.PollInterval, func() {
+		l.Status("%s", messages.Sprintf(messages.ReloadFileTouched, ReloadFile))
+		queue.push(nil)
+	})
 
 	if len(cfg.Steps()) > 0 {
-		l.Status("Executing...")
-		dur, err := r.execSteps()
+		l.Status("%s", messages.Sprintf(messages.Executing))
+		dur, err := r.execSteps(ctx)
 		if err != nil {
 			if !opts.ContinueOnError {
 				return fmt.Errorf("exec failed: %w", err)
 			}
-			l.Error("Initial build failed: %v", err)
-			l.Status("Watching for file changes...")
+			l.Error("%s", messages.Sprintf(messages.InitialBuildFailed, err))
+			l.Status("%s", messages.Sprintf(messages.WatchingForChanges))
 		} else {
-			l.Success("Done in %s", scan.FormatDuration(dur))
+			l.Success("%s", messages.Sprintf(messages.DoneIn, scan.FormatDuration(dur)))
 			healthy.Store(true)
 		}
 	}
@@ -813,64 +1222,63 @@ func Run(ctx context.Context, cfg Config, opts Options) error {
 				return fmt.Errorf("initial start: %w", err)
 			}
 			healthy.Store(false)
-			l.Error("Initial start failed: %v", err)
-			l.Status("Watching for file changes...")
+			l.Error("%s", messages.Sprintf(messages.InitialStartFailed, err))
+			l.Status("%s", messages.Sprintf(messages.WatchingForChanges))
 		} else {
-			l.Success("Started (pid %d).", r.pid())
+			l.Success("%s", messages.Sprintf(messages.Started, r.pid()))
 			healthy.Store(true)
 		}
 	}
 
+	go queue.run(ctx, rebuild)
+
 	// Heartbeat ticker
 	var tick <-chan time.Time
-	var ticker *time.Ticker
-	if !opts.DisableHeartbeat {
-		ticker = time.NewTicker(10 * time.Second)
-		tick = ticker.C
+	if interval := heartbeatInterval(r.cfg, opts); interval > 0 {
+		ticker := opts.Clock.NewTicker(interval)
+		tick = ticker.C()
 		defer ticker.Stop()
 	}
 
 	for {
 		select {
 		case <-ctx.Done():
-			l.Status("Shutting down...")
+			l.Status("%s", messages.Sprintf(messages.ShuttingDown))
 			return nil
 		case info := <-r.exited:
 			if info.ExitCode != 0 {
-				l.Error("Exited with code %d. Waiting for file changes...", info.ExitCode)
+				l.Error("%s", messages.Sprintf(messages.ExitedWithCode, info.ExitCode))
 			} else {
-				l.Status("Completed. Waiting for file changes...")
+				l.Status("%s", messages.Sprintf(messages.Completed))
 			}
 		case <-opts.BuildTrigger:
-			l.Status("Build triggered...")
-			dur, err := r.restart()
-			if err != nil {
-				l.Error("Build failed: %v", err)
-				healthy.Store(false)
-			} else {
-				l.Success("Build done (pid %d, %s).", r.pid(), scan.FormatDuration(dur))
-				healthy.Store(true)
-			}
+			queue.push(nil)
+		case <-opts.WatchPause:
+			w.Pause()
+			l.Status("%s", messages.Sprintf(messages.WatchPaused))
+		case <-opts.WatchResume:
+			w.Resume()
+			l.Status("%s", messages.Sprintf(messages.WatchResumed))
 		case <-opts.TestTrigger:
-			l.Status("Tests triggered...")
-			dur, err := r.runTestSteps()
+			l.Status("%s", messages.Sprintf(messages.TestsTriggered))
+			dur, err := r.runTestSteps(ctx)
 			if err != nil {
-				l.Error("Tests failed: %v", err)
+				l.Error("%s", messages.Sprintf(messages.TestsFailed, err))
 				healthy.Store(false)
 			} else {
-				l.Success("Tests done (%s).", scan.FormatDuration(dur))
+				l.Success("%s", messages.Sprintf(messages.TestsDone, scan.FormatDuration(dur)))
 				healthy.Store(true)
 			}
 		case <-opts.ExecStop:
-			l.Status("Stopping process...")
+			l.Status("%s", messages.Sprintf(messages.StoppingProcess))
 			r.stop()
 		case <-opts.ExecStart:
-			l.Status("Starting process...")
+			l.Status("%s", messages.Sprintf(messages.StartingProcess))
 			if err := r.start(); err != nil {
-				l.Error("Start failed: %v", err)
+				l.Error("%s", messages.Sprintf(messages.StartFailed, err))
 				healthy.Store(false)
 			} else {
-				l.Success("Started (pid %d).", r.pid())
+				l.Success("%s", messages.Sprintf(messages.Started, r.pid()))
 				healthy.Store(true)
 			}
 		case <-tick:
@@ -882,74 +1290,92 @@ func Run(ctx context.Context, cfg Config, opts Options) error {
 // runBuildOnly handles build mode: run all commands as steps, then watch for
 // changes and re-run. No managed process is started.
 func runBuildOnly(ctx context.Context, r *runner, rootDir string, patterns []glob.Pattern, initialSums map[string]string, sumPath string, opts Options, l *log.Logger) error {
-	l.Status("Build mode: executing all commands...")
-	dur, err := r.execSteps()
+	l.Status("%s", messages.Sprintf(messages.BuildModeStart))
+	dur, err := r.execSteps(ctx)
 	if err != nil {
 		return fmt.Errorf("build failed: %w", err)
 	}
-	l.Success("Build done in %s. Watching for changes...", scan.FormatDuration(dur))
+	l.Success("%s", messages.Sprintf(messages.BuildModeDone, scan.FormatDuration(dur)))
 
 	var healthy atomic.Bool
 	healthy.Store(true)
 
-	w := watcher.New(rootDir, patterns, r.opts.PollInterval, r.opts.Debounce, func(changes sumfile.ChangeSet) {
-		if opts.OnFilesChanged != nil {
-			opts.OnFilesChanged(time.Now(), changes)
+	queue := newBuildQueue()
+	rebuild := func(buildCtx context.Context, changes *sumfile.ChangeSet) {
+		if changes != nil {
+			l.Status("%s", messages.Sprintf(messages.Rebuilding))
+		} else {
+			l.Status("%s", messages.Sprintf(messages.BuildTriggered))
 		}
-		l.Change(changes)
-
-		l.Status("Rebuilding...")
-		dur, err := r.execSteps()
+		dur, err := r.execSteps(buildCtx)
 		if err != nil {
-			l.Error("Build failed: %v", err)
+			if buildCtx.Err() != nil {
+				if ctx.Err() == nil {
+					l.Status("%s", messages.Sprintf(messages.BuildCancelled))
+				}
+				return
+			}
+			l.Error("%s", messages.Sprintf(messages.BuildFailed, err))
 			healthy.Store(false)
 			return
 		}
-		l.Success("Build done in %s", scan.FormatDuration(dur))
+		l.Success("%s", messages.Sprintf(messages.BuildDoneIn, scan.FormatDuration(dur)))
 		healthy.Store(true)
 
-		newSums, err := scan.ScanFiles(rootDir, patterns)
-		if err == nil {
-			if writeErr := sumfile.Write(sumPath, newSums); writeErr != nil {
-				l.Verbose("update sum file: %v", writeErr)
-			}
+		if _, err := r.cfg.scanSums(rootDir, patterns, sumPath); err != nil {
+			l.Verbose("update sum file: %v", err)
+		}
+	}
+
+	w := watcher.New(rootDir, patterns, r.opts.PollInterval, r.opts.Debounce, func(changes sumfile.ChangeSet) {
+		if opts.OnFilesChanged != nil {
+			opts.OnFilesChanged(opts.Clock.Now(), changes)
 		}
+		l.Change(changes)
+		queue.push(&changes)
 	}, l)
 	w.SetCurrentSums(initialSums)
+	w.SetClock(opts.Clock)
+	w.SetFollowSymlinks(r.cfg.FollowSymlinks)
+	w.SetLargeFileThreshold(r.cfg.LargeFileBytes())
+	w.SetIgnoreEditorArtifacts(r.cfg.ShouldIgnoreEditorArtifacts())
+	w.SetDebounceGroups(r.cfg.debounceGroups(rootDir))
 
 	go w.Run(ctx)
+	go watchTrigger(ctx, opts.Clock, filepath.Join(rootDir, ReloadFile), opts.PollInterval, func() {
+		l.Status("%s", messages.Sprintf(messages.ReloadFileTouched, ReloadFile))
+		queue.push(nil)
+	})
+	go queue.run(ctx, rebuild)
 
 	var tick <-chan time.Time
-	var ticker *time.Ticker
-	if !opts.DisableHeartbeat {
-		ticker = time.NewTicker(10 * time.Second)
-		tick = ticker.C
+	if interval := heartbeatInterval(r.cfg, opts); interval > 0 {
+		ticker := opts.Clock.NewTicker(interval)
+		tick = ticker.C()
 		defer ticker.Stop()
 	}
 
 	for {
 		select {
 		case <-ctx.Done():
-			l.Status("Shutting down...")
+			l.Status("%s", messages.Sprintf(messages.ShuttingDown))
 			return nil
 		case <-opts.BuildTrigger:
-			l.Status("Build triggered...")
-			dur, err := r.execSteps()
-			if err != nil {
-				l.Error("Build failed: %v", err)
-				healthy.Store(false)
-			} else {
-				l.Success("Build done in %s", scan.FormatDuration(dur))
-				healthy.Store(true)
-			}
+			queue.push(nil)
+		case <-opts.WatchPause:
+			w.Pause()
+			l.Status("%s", messages.Sprintf(messages.WatchPaused))
+		case <-opts.WatchResume:
+			w.Resume()
+			l.Status("%s", messages.Sprintf(messages.WatchResumed))
 		case <-opts.TestTrigger:
-			l.Status("Tests triggered...")
-			dur, err := r.runTestSteps()
+			l.Status("%s", messages.Sprintf(messages.TestsTriggered))
+			dur, err := r.runTestSteps(ctx)
 			if err != nil {
-				l.Error("Tests failed: %v", err)
+				l.Error("%s", messages.Sprintf(messages.TestsFailed, err))
 				healthy.Store(false)
 			} else {
-				l.Success("Tests done in %s", scan.FormatDuration(dur))
+				l.Success("%s", messages.Sprintf(messages.TestsDoneIn, scan.FormatDuration(dur)))
 				healthy.Store(true)
 			}
 		case <-tick:
@@ -973,8 +1399,8 @@ func ScanFiles(cfg *Config, rootDir ...string) (map[string]string, error) {
 			return nil, fmt.Errorf("get working directory: %w", err)
 		}
 	}
-	patterns := scan.ParseWatchPatterns(cfg.Watch)
-	return scan.ScanFiles(dir, patterns)
+	patterns := cfg.patterns(dir)
+	return scan.ScanFiles(dir, patterns, cfg.hashOptions(), cfg.globOptions()...)
 }
 
 // RunBuild runs just the build (preparation) steps and returns.
@@ -1003,12 +1429,17 @@ func RunBuild(ctx context.Context, cfg Config, opts Options) error {
 		opts.TestStderr = opts.Stderr
 	}
 
+	if err := applyLogOptions(opts); err != nil {
+		return err
+	}
 	color.Init()
-	prefix := "[execrun]"
+	prefix := messages.Get(messages.ExecrunPrefix)
 	if opts.LogPrefix != "" {
 		prefix = opts.LogPrefix
 	}
 	l := log.New(prefix, opts.Verbose)
+	defer bufferOutput(cfg, &opts, l)()
+	defer prefixOutput(cfg, &opts, prefix)()
 
 	rootDir := opts.RootDir
 	if rootDir == "" {
@@ -1019,8 +1450,12 @@ func RunBuild(ctx context.Context, cfg Config, opts Options) error {
 		}
 	}
 
-	r := newRunner(ctx, cfg, opts, rootDir, l)
-	_, err := r.runBuildSteps()
+	env, err := cfg.environ(rootDir)
+	if err != nil {
+		return err
+	}
+	r := newRunner(ctx, cfg, opts, rootDir, env, l)
+	_, err = r.runBuildSteps(r.ctx)
 	return err
 }
 
@@ -1050,12 +1485,17 @@ func RunTests(ctx context.Context, cfg Config, opts Options) error {
 		opts.TestStderr = opts.Stderr
 	}
 
+	if err := applyLogOptions(opts); err != nil {
+		return err
+	}
 	color.Init()
-	prefix := "[execrun]"
+	prefix := messages.Get(messages.ExecrunPrefix)
 	if opts.LogPrefix != "" {
 		prefix = opts.LogPrefix
 	}
 	l := log.New(prefix, opts.Verbose)
+	defer bufferOutput(cfg, &opts, l)()
+	defer prefixOutput(cfg, &opts, prefix)()
 
 	rootDir := opts.RootDir
 	if rootDir == "" {
@@ -1066,8 +1506,12 @@ func RunTests(ctx context.Context, cfg Config, opts Options) error {
 		}
 	}
 
-	r := newRunner(ctx, cfg, opts, rootDir, l)
-	_, err := r.runTestSteps()
+	env, err := cfg.environ(rootDir)
+	if err != nil {
+		return err
+	}
+	r := newRunner(ctx, cfg, opts, rootDir, env, l)
+	_, err = r.runTestSteps(r.ctx)
 	return err
 }
 
@@ -1075,3 +1519,43 @@ func RunTests(ctx context.Context, cfg Config, opts Options) error {
 //
 //go:embed execrun.default.yaml
 var DefaultConfigYAML string
+
+// ConfigSchema is the JSON Schema for execrun.yaml. LoadConfig checks configs
+// against it after template processing; editors can use it for completion.
+//
+//go:embed execrun.schema.json
+var ConfigSchema []byte
+
+var configSchema = schema.MustCompile(ConfigSchema)
+
+// applyLogOptions applies the process-wide log settings in opts.
+func applyLogOptions(opts Options) error {
+	if opts.Color != "" {
+		mode, err := color.ParseMode(opts.Color)
+		if err != nil {
+			return err
+		}
+		color.SetMode(mode)
+	}
+	if opts.LogFormat != "" {
+		format, err := log.ParseFormat(opts.LogFormat)
+		if err != nil {
+			return err
+		}
+		log.SetFormat(format)
+	}
+	if opts.LogLevel != "" {
+		level, err := log.ParseLevel(opts.LogLevel)
+		if err != nil {
+			return err
+		}
+		log.SetLevel(level)
+	}
+	if opts.LogTime != "" {
+		log.SetTimeFormat(opts.LogTime)
+	}
+	if opts.Quiet {
+		log.SetQuiet(true)
+	}
+	return nil
+}
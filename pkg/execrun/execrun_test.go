@@ -1,9 +1,14 @@
 package execrun_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"syscall"
 	"time"
 
 	"github.com/google/shlex"
@@ -11,6 +16,8 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/gur-shatz/go-run/internal/sumfile"
+	"github.com/gur-shatz/go-run/pkg/clock"
+	"github.com/gur-shatz/go-run/pkg/config"
 	"github.com/gur-shatz/go-run/pkg/execrun"
 )
 
@@ -47,7 +54,7 @@ exec:
 			Expect(err).NotTo(HaveOccurred())
 			Expect(cfg.Title).To(Equal("Hello App"))
 			Expect(cfg.Description).To(Equal("Main HTTP service"))
-			Expect(cfg.Watch).To(Equal([]string{"**/*.go", "!vendor/**"}))
+			Expect(cfg.Watch).To(Equal(execrun.Watches("**/*.go", "!vendor/**")))
 			Expect(cfg.Build).To(Equal([]string{"go build -o ./bin/app ."}))
 			Expect(cfg.Test).To(BeNil())
 			Expect(cfg.Exec).To(Equal([]string{"./bin/app"}))
@@ -55,6 +62,67 @@ exec:
 			Expect(cfg.RunCmd()).To(Equal("./bin/app"))
 		})
 
+		It("loads watch entries with their own debounce", func() {
+			configPath := filepath.Join(tmpDir, "execrun.yaml")
+			content := `watch:
+  - "**/*.go"
+  - pattern: "db/**/*.sql"
+    debounce: 2s
+exec:
+  - "./bin/app"
+`
+			Expect(os.WriteFile(configPath, []byte(content), 0644)).To(Succeed())
+
+			cfg, _, err := execrun.LoadConfig(configPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Watch).To(Equal(execrun.WatchList{
+				{Pattern: "**/*.go"},
+				{Pattern: "db/**/*.sql", Debounce: "2s"},
+			}))
+			Expect(cfg.WatchPatterns()).To(Equal([]string{"**/*.go", "db/**/*.sql"}))
+		})
+
+		It("loads heartbeat as false or an interval", func() {
+			configPath := filepath.Join(tmpDir, "execrun.yaml")
+			for content, want := range map[string]time.Duration{
+				"heartbeat: false\n": 0,
+				"heartbeat: 30s\n":   30 * time.Second,
+				"heartbeat: true\n":  execrun.DefaultHeartbeat,
+				"":                   execrun.DefaultHeartbeat,
+			} {
+				content = "watch: [\"**/*.go\"]\nexec: [\"./bin/app\"]\n" + content
+				Expect(os.WriteFile(configPath, []byte(content), 0644)).To(Succeed())
+
+				cfg, _, err := execrun.LoadConfig(configPath)
+				Expect(err).NotTo(HaveOccurred(), content)
+				Expect(cfg.Heartbeat.Interval()).To(Equal(want), content)
+			}
+
+			Expect(os.WriteFile(configPath, []byte("watch: [\"**/*.go\"]\nexec: [\"./bin/app\"]\nheartbeat: often\n"), 0644)).To(Succeed())
+			_, _, err := execrun.LoadConfig(configPath)
+			Expect(err).To(MatchError(ContainSubstring("heartbeat must be")))
+		})
+
+		It("loads a TOML config", func() {
+			configPath := filepath.Join(tmpDir, "execrun.toml")
+			content := `watch = ["**/*.go"]
+build = ["go build -o ./bin/app ."]
+exec = ["./bin/app --port {{ .PORT }}"]
+build_nice = 10
+
+[vars]
+PORT = "8081"
+`
+			Expect(os.WriteFile(configPath, []byte(content), 0644)).To(Succeed())
+
+			cfg, vars, err := execrun.LoadConfig(configPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vars).To(HaveKeyWithValue("PORT", "8081"))
+			Expect(cfg.Watch).To(Equal(execrun.Watches("**/*.go")))
+			Expect(cfg.RunCmd()).To(Equal("./bin/app --port 8081"))
+			Expect(cfg.BuildNice).To(Equal(10))
+		})
+
 		It("loads config with multiple build steps", func() {
 			configPath := filepath.Join(tmpDir, "execrun.yaml")
 			content := `watch:
@@ -192,6 +260,42 @@ exec:
 			Expect(err).To(HaveOccurred())
 		})
 
+		It("rejects unknown fields after template processing", func() {
+			configPath := filepath.Join(tmpDir, "execrun.yaml")
+			content := "vars:\n  KEY: excec\nwatch: [\"*.go\"]\n{{ .KEY }}: [./app]\n"
+			Expect(os.WriteFile(configPath, []byte(content), 0644)).To(Succeed())
+
+			_, _, err := execrun.LoadConfig(configPath)
+			Expect(err).To(MatchError(ContainSubstring("excec is not a known field, did you mean exec?")))
+		})
+
+		It("loads env_file pairs as template vars and resolves its paths", func() {
+			Expect(os.WriteFile(filepath.Join(tmpDir, ".env"), []byte("EXECRUN_TEST_APP=server\n"), 0644)).To(Succeed())
+			configPath := filepath.Join(tmpDir, "execrun.yaml")
+			content := "env_file: .env\nwatch: [\"*.go\"]\nexec: [\"./{{ .EXECRUN_TEST_APP }}\"]\n"
+			Expect(os.WriteFile(configPath, []byte(content), 0644)).To(Succeed())
+
+			cfg, _, err := execrun.LoadConfig(configPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Exec).To(Equal([]string{"./server"}))
+			Expect(cfg.EnvFile).To(Equal(config.EnvFiles{filepath.Join(tmpDir, ".env")}))
+		})
+
+		It("rejects loosely typed values only in strict mode", func() {
+			configPath := filepath.Join(tmpDir, "execrun.yaml")
+			content := "title: 42\nwatch: [\"*.go\"]\nexec: [./app]\n"
+			Expect(os.WriteFile(configPath, []byte(content), 0644)).To(Succeed())
+
+			_, _, err := execrun.LoadConfig(configPath)
+			Expect(err).NotTo(HaveOccurred())
+			_, _, err = execrun.LoadConfig(configPath, config.WithStrict())
+			Expect(err).To(MatchError(ContainSubstring("title must be a string, got integer 42")))
+
+			Expect(os.WriteFile(configPath, []byte("strict: true\n"+content), 0644)).To(Succeed())
+			_, _, err = execrun.LoadConfig(configPath)
+			Expect(err).To(MatchError(ContainSubstring("title must be a string, got integer 42")))
+		})
+
 		It("trims whitespace from YAML literal blocks", func() {
 			configPath := filepath.Join(tmpDir, "execrun.yaml")
 			content := "watch:\n  - \"**/*.go\"\nbuild:\n  - |\n    go build .\nexec:\n  - |\n    ./app\n"
@@ -209,7 +313,7 @@ exec:
 		It("writes and reads back a config", func() {
 			configPath := filepath.Join(tmpDir, "out.yaml")
 			cfg := execrun.Config{
-				Watch: []string{"**/*.py"},
+				Watch: execrun.Watches("**/*.py"),
 				Build: []string{"lint", "make"},
 				Exec:  []string{"./app"},
 			}
@@ -239,6 +343,123 @@ exec:
 		})
 	})
 
+	Describe("ScanFiles", func() {
+		It("treats a watched directory as everything under it", func() {
+			for _, f := range []string{"migrations/001.sql", "migrations/old/000.sql", "templates/index.html", "main.go"} {
+				Expect(os.MkdirAll(filepath.Join(tmpDir, filepath.Dir(f)), 0755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(tmpDir, f), []byte(f), 0644)).To(Succeed())
+			}
+			cfg := &execrun.Config{Watch: execrun.Watches("migrations", "templates/", "!migrations/old/")}
+
+			sums, err := execrun.ScanFiles(cfg, tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sums).To(HaveLen(2))
+			Expect(sums).To(HaveKey("migrations/001.sql"))
+			Expect(sums).To(HaveKey("templates/index.html"))
+		})
+
+		It("excludes the patterns of .gorunignore and .execrunignore", func() {
+			for _, f := range []string{"main.go", "gen/api.go", "web/gen/ui.go", "tools/tool.go", "tools/keep.go"} {
+				Expect(os.MkdirAll(filepath.Join(tmpDir, filepath.Dir(f)), 0755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(tmpDir, f), []byte(f), 0644)).To(Succeed())
+			}
+			Expect(os.WriteFile(filepath.Join(tmpDir, ".gorunignore"), []byte("gen/\n"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(tmpDir, ".execrunignore"), []byte("/tools\n"), 0644)).To(Succeed())
+			cfg := &execrun.Config{Watch: execrun.Watches("**/*.go")}
+
+			sums, err := execrun.ScanFiles(cfg, tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sums).To(HaveLen(1))
+			Expect(sums).To(HaveKey("main.go"))
+		})
+
+		It("never watches the cache dir", func() {
+			cfg := &execrun.Config{Watch: execrun.Watches("**/*")}
+			Expect(execrun.PrepareCacheDir(cfg.CachePath(tmpDir), tmpDir)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(tmpDir, "main.go"), nil, 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(tmpDir, ".gorun", "execrun.sum"), nil, 0644)).To(Succeed())
+
+			sums, err := execrun.ScanFiles(cfg, tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sums).To(HaveLen(1))
+			Expect(sums).To(HaveKey("main.go"))
+			Expect(os.ReadFile(filepath.Join(tmpDir, ".gorun", ".gitignore"))).To(Equal([]byte("*\n")))
+		})
+
+		It("refuses a cache dir that holds the config", func() {
+			for _, dir := range []string{".", ".."} {
+				cfg := &execrun.Config{CacheDir: dir}
+				Expect(execrun.PrepareCacheDir(cfg.CachePath(tmpDir), tmpDir)).To(MatchError(ContainSubstring("contains the config dir")))
+			}
+			Expect(filepath.Join(tmpDir, ".gitignore")).NotTo(BeAnExistingFile())
+		})
+	})
+
+	Describe("Verify", func() {
+		var (
+			cfg     *execrun.Config
+			sumPath string
+		)
+
+		BeforeEach(func() {
+			for _, f := range []string{"main.go", "gen/api.go", "gen/old.go"} {
+				Expect(os.MkdirAll(filepath.Join(tmpDir, filepath.Dir(f)), 0755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(tmpDir, f), []byte(f), 0644)).To(Succeed())
+			}
+			cfg = &execrun.Config{Watch: execrun.Watches("**/*.go")}
+			sums, err := execrun.ScanFiles(cfg, tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+			sumPath = filepath.Join(tmpDir, "execrun.sum")
+			Expect(sumfile.Write(sumPath, sums)).To(Succeed())
+		})
+
+		It("finds no differences in an unchanged tree", func() {
+			changes, err := execrun.Verify(cfg, tmpDir, sumPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes.IsEmpty()).To(BeTrue())
+		})
+
+		It("lists added, modified, and removed files", func() {
+			Expect(os.WriteFile(filepath.Join(tmpDir, "gen", "api.go"), []byte("regenerated"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(tmpDir, "gen", "new.go"), []byte("new"), 0644)).To(Succeed())
+			Expect(os.Remove(filepath.Join(tmpDir, "gen", "old.go"))).To(Succeed())
+
+			changes, err := execrun.Verify(cfg, tmpDir, sumPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes.Added).To(Equal([]string{"gen/new.go"}))
+			Expect(changes.Modified).To(Equal([]string{"gen/api.go"}))
+			Expect(changes.Removed).To(Equal([]string{"gen/old.go"}))
+		})
+
+		It("fails without a sum file", func() {
+			_, err := execrun.Verify(cfg, tmpDir, filepath.Join(tmpDir, "missing.sum"))
+			Expect(err).To(MatchError(ContainSubstring("run sum first")))
+		})
+	})
+
+	Describe("Explain", func() {
+		It("resolves the patterns and lists the watched files and commands", func() {
+			for _, f := range []string{"main.go", "migrations/001.sql", "gen/api.go"} {
+				Expect(os.MkdirAll(filepath.Join(tmpDir, filepath.Dir(f)), 0755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(tmpDir, f), []byte(f), 0644)).To(Succeed())
+			}
+			Expect(os.WriteFile(filepath.Join(tmpDir, ".gorunignore"), []byte("gen/\n"), 0644)).To(Succeed())
+			cfg := &execrun.Config{
+				Watch: execrun.Watches("**/*.go", "migrations"),
+				Build: []string{"go build -o bin/app ."},
+				Exec:  []string{"./bin/app"},
+			}
+
+			e, err := cfg.Explain(tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(e.RootDir).To(Equal(tmpDir))
+			Expect(e.Patterns).To(Equal([]string{"**/*.go", "migrations/**", "!**/gen/**", "!.gorun/**"}))
+			Expect(e.Files).To(Equal([]string{"main.go", "migrations/001.sql"}))
+			Expect(e.Build).To(Equal(cfg.Build))
+			Expect(e.Exec).To(Equal(cfg.Exec))
+		})
+	})
+
 	Describe("Command Parsing (shlex)", func() {
 		It("splits a simple command", func() {
 			args, err := shlex.Split("go build .")
@@ -279,7 +500,7 @@ exec:
 	Describe("Validate", func() {
 		It("accepts config with watch and single exec command", func() {
 			cfg := &execrun.Config{
-				Watch: []string{"*.go"},
+				Watch: execrun.Watches("*.go"),
 				Exec:  []string{"./app"},
 			}
 			Expect(cfg.Validate()).NotTo(HaveOccurred())
@@ -287,7 +508,7 @@ exec:
 
 		It("accepts config with watch and build-only", func() {
 			cfg := &execrun.Config{
-				Watch: []string{"*.go"},
+				Watch: execrun.Watches("*.go"),
 				Build: []string{"make"},
 			}
 			Expect(cfg.Validate()).NotTo(HaveOccurred())
@@ -302,14 +523,14 @@ exec:
 
 		It("rejects config with no build, test, or exec commands", func() {
 			cfg := &execrun.Config{
-				Watch: []string{"*.go"},
+				Watch: execrun.Watches("*.go"),
 			}
 			Expect(cfg.Validate()).To(HaveOccurred())
 		})
 
 		It("accepts config with test-only", func() {
 			cfg := &execrun.Config{
-				Watch: []string{"*.go"},
+				Watch: execrun.Watches("*.go"),
 				Test:  []string{"go test ./..."},
 			}
 			Expect(cfg.Validate()).NotTo(HaveOccurred())
@@ -317,7 +538,7 @@ exec:
 
 		It("rejects build command with $VAR syntax", func() {
 			cfg := &execrun.Config{
-				Watch: []string{"*.go"},
+				Watch: execrun.Watches("*.go"),
 				Build: []string{"echo $MY_VAR"},
 			}
 			err := cfg.Validate()
@@ -328,7 +549,7 @@ exec:
 
 		It("rejects exec command with ${VAR} syntax", func() {
 			cfg := &execrun.Config{
-				Watch: []string{"*.go"},
+				Watch: execrun.Watches("*.go"),
 				Exec:  []string{"./app --port=${PORT}"},
 			}
 			err := cfg.Validate()
@@ -338,7 +559,7 @@ exec:
 
 		It("rejects command with $(...) substitution", func() {
 			cfg := &execrun.Config{
-				Watch: []string{"*.go"},
+				Watch: execrun.Watches("*.go"),
 				Build: []string{"echo $(date)"},
 			}
 			err := cfg.Validate()
@@ -348,18 +569,420 @@ exec:
 
 		It("accepts commands without shell variable syntax", func() {
 			cfg := &execrun.Config{
-				Watch: []string{"*.go"},
+				Watch: execrun.Watches("*.go"),
 				Build: []string{"go build -o ./bin/app ."},
 				Exec:  []string{"./bin/app --port=8080"},
 			}
 			Expect(cfg.Validate()).NotTo(HaveOccurred())
 		})
+
+		It("rejects an invalid min_free_space", func() {
+			cfg := &execrun.Config{
+				Watch:        execrun.Watches("*.go"),
+				Build:        []string{"go build ./..."},
+				MinFreeSpace: "plenty",
+			}
+			err := cfg.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("min_free_space"))
+		})
+
+		It("checks large_file_threshold", func() {
+			cfg := &execrun.Config{Watch: execrun.Watches("*.go"), Exec: []string{"./app"}, LargeFileThreshold: "huge"}
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring("large_file_threshold")))
+			cfg.LargeFileThreshold = "50MB"
+			Expect(cfg.Validate()).To(Succeed())
+			Expect(cfg.LargeFileBytes()).To(BeNumerically(">=", 50_000_000))
+		})
+
+		It("checks hooks", func() {
+			cfg := &execrun.Config{Watch: execrun.Watches("*.go"), Exec: []string{"./app"}, Hooks: map[string]string{"on_explode": "true"}}
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring(`unknown hook "on_explode"`)))
+			cfg.Hooks = map[string]string{"on_build_failed": "say $MSG"}
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring("shell variable syntax")))
+			cfg.Hooks = map[string]string{"on_build_failed": "say 'build broken'"}
+			Expect(cfg.Validate()).To(Succeed())
+		})
+
+		It("rejects a debounce that isn't a positive duration", func() {
+			cfg := &execrun.Config{Watch: execrun.Watches("*.go"), Exec: []string{"./app"}, Debounce: "soon"}
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring(`debounce must be a positive duration like 500ms, got "soon"`)))
+			cfg.Debounce = "750ms"
+			Expect(cfg.Validate()).To(Succeed())
+		})
+
+		It("checks per-pattern debounces", func() {
+			cfg := &execrun.Config{Watch: execrun.WatchList{{Pattern: "*.sql", Debounce: "later"}}, Exec: []string{"./app"}}
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring(`watch: "*.sql": debounce must be a positive duration like 2s, got "later"`)))
+
+			cfg.Watch = execrun.WatchList{{Pattern: "!gen/**", Debounce: "2s"}}
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring("!gen/**")))
+
+			cfg.Watch = execrun.WatchList{{Pattern: "*.go"}, {Pattern: "*.sql", Debounce: "2s"}}
+			Expect(cfg.Validate()).To(Succeed())
+		})
+
+		It("rejects an out-of-range build_nice or unknown build_ionice", func() {
+			cfg := &execrun.Config{
+				Watch:     execrun.Watches("*.go"),
+				Build:     []string{"go build ./..."},
+				BuildNice: 20,
+			}
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring("build_nice must be 0-19")))
+
+			cfg.BuildNice = 10
+			cfg.BuildIonice = "realtime"
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring("build_ionice")))
+		})
+
+		It("checks reload_watch and reload_signal", func() {
+			cfg := &execrun.Config{Watch: execrun.Watches("*.go"), Exec: []string{"./app"}, ReloadSignal: "SIGHUP"}
+			Expect(cfg.Validate()).To(MatchError("reload_signal needs reload_watch patterns"))
+
+			cfg.ReloadWatch = []string{"config/*.yaml"}
+			cfg.ReloadSignal = "SIGBOGUS"
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring(`reload_signal: unknown signal "SIGBOGUS"`)))
+
+			cfg.ReloadSignal = "usr1"
+			Expect(cfg.Validate()).To(Succeed())
+
+			cfg.Exec = nil
+			cfg.Build = []string{"go build ./..."}
+			Expect(cfg.Validate()).To(MatchError("reload_watch needs an exec command to signal"))
+		})
+
+		It("checks restart_watch", func() {
+			cfg := &execrun.Config{RestartWatch: []string{"templates/**"}, Exec: []string{"./app"}}
+			Expect(cfg.Validate()).To(Succeed())
+
+			cfg.Exec = nil
+			cfg.Build = []string{"go build ./..."}
+			Expect(cfg.Validate()).To(MatchError("restart_watch needs an exec command to restart"))
+		})
 	})
 
 	Describe("Run", func() {
+		It("signals the process for reload_watch changes and restarts it for others", func() {
+			cfg := execrun.Config{
+				Watch:       execrun.Watches("*.go"),
+				ReloadWatch: []string{"*.yaml"},
+				Exec:        []string{`sh -c "trap 'echo reloaded >> reloads.txt' HUP; while true; do sleep 0.05; done"`},
+			}
+			Expect(os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\n"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(tmpDir, "app.yaml"), []byte("v: 1\n"), 0644)).To(Succeed())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			starts := make(chan int, 10)
+			runDone := make(chan error, 1)
+			go func() {
+				runDone <- execrun.Run(ctx, cfg, execrun.Options{
+					RootDir:          tmpDir,
+					PollInterval:     50 * time.Millisecond,
+					Debounce:         50 * time.Millisecond,
+					DisableHeartbeat: true,
+					OnProcessStart:   func(pid int) { starts <- pid },
+				})
+			}()
+			var pid int
+			Eventually(starts, 5*time.Second).Should(Receive(&pid))
+
+			Expect(os.WriteFile(filepath.Join(tmpDir, "app.yaml"), []byte("v: 2\n"), 0644)).To(Succeed())
+			Eventually(func() string {
+				data, _ := os.ReadFile(filepath.Join(tmpDir, "reloads.txt"))
+				return string(data)
+			}, 5*time.Second).Should(Equal("reloaded\n"))
+			Consistently(starts, 300*time.Millisecond).ShouldNot(Receive())
+
+			Expect(os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main // v2\n"), 0644)).To(Succeed())
+			Eventually(starts, 5*time.Second).Should(Receive(Not(Equal(pid))))
+
+			cancel()
+			Eventually(runDone, 10*time.Second).Should(Receive(BeNil()))
+		})
+
+		It("restarts the process without building for restart_watch changes", func() {
+			cfg := execrun.Config{
+				Watch:        execrun.Watches("*.go"),
+				RestartWatch: []string{"templates/*"},
+				Build:        []string{`sh -c "echo built >> builds.txt"`},
+				Exec:         []string{"sleep 60"},
+			}
+			Expect(os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\n"), 0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(tmpDir, "templates"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(tmpDir, "templates", "index.html"), []byte("v1\n"), 0644)).To(Succeed())
+			builds := func() string {
+				data, _ := os.ReadFile(filepath.Join(tmpDir, "builds.txt"))
+				return string(data)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			starts := make(chan int, 10)
+			runDone := make(chan error, 1)
+			go func() {
+				runDone <- execrun.Run(ctx, cfg, execrun.Options{
+					RootDir:          tmpDir,
+					PollInterval:     50 * time.Millisecond,
+					Debounce:         50 * time.Millisecond,
+					DisableHeartbeat: true,
+					OnProcessStart:   func(pid int) { starts <- pid },
+				})
+			}()
+			var pid int
+			Eventually(starts, 5*time.Second).Should(Receive(&pid))
+			Expect(builds()).To(Equal("built\n"))
+
+			Expect(os.WriteFile(filepath.Join(tmpDir, "templates", "index.html"), []byte("v2\n"), 0644)).To(Succeed())
+			Eventually(starts, 5*time.Second).Should(Receive(Not(Equal(pid))))
+			Expect(builds()).To(Equal("built\n"))
+
+			Expect(os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main // v2\n"), 0644)).To(Succeed())
+			Eventually(starts, 5*time.Second).Should(Receive())
+			Expect(builds()).To(Equal("built\nbuilt\n"))
+
+			cancel()
+			Eventually(runDone, 10*time.Second).Should(Receive(BeNil()))
+		})
+
+		It("rebuilds and restarts when the reload file is touched", func() {
+			cfg := execrun.Config{
+				Watch: execrun.Watches("*.go"),
+				Build: []string{`sh -c "echo built >> builds.txt"`},
+				Exec:  []string{"sleep 60"},
+			}
+			builds := func() string {
+				data, _ := os.ReadFile(filepath.Join(tmpDir, "builds.txt"))
+				return string(data)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			starts := make(chan int, 10)
+			runDone := make(chan error, 1)
+			go func() {
+				runDone <- execrun.Run(ctx, cfg, execrun.Options{
+					RootDir:          tmpDir,
+					PollInterval:     50 * time.Millisecond,
+					DisableHeartbeat: true,
+					OnProcessStart:   func(pid int) { starts <- pid },
+				})
+			}()
+			var pid int
+			Eventually(starts, 5*time.Second).Should(Receive(&pid))
+
+			reload := filepath.Join(tmpDir, execrun.ReloadFile)
+			Expect(os.WriteFile(reload, nil, 0644)).To(Succeed())
+			Eventually(starts, 5*time.Second).Should(Receive(Not(Equal(pid))))
+			Expect(builds()).To(Equal("built\nbuilt\n"))
+
+			later := time.Now().Add(time.Minute)
+			Expect(os.Chtimes(reload, later, later)).To(Succeed())
+			Eventually(starts, 5*time.Second).Should(Receive())
+			Expect(builds()).To(Equal("built\nbuilt\nbuilt\n"))
+
+			cancel()
+			Eventually(runDone, 10*time.Second).Should(Receive(BeNil()))
+		})
+
+		It("refuses to start while another instance holds the lock, unless forced", func() {
+			cfg := execrun.Config{Watch: execrun.Watches("*.go"), Exec: []string{"sleep 60"}}
+			lock := filepath.Join(tmpDir, ".gorun", "execrun.lock")
+			Expect(execrun.PrepareCacheDir(filepath.Dir(lock), tmpDir)).To(Succeed())
+			// flock locks belong to the open file, so holding one here
+			// stands in for another instance.
+			other, err := os.Create(lock)
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(other.Close)
+			Expect(syscall.Flock(int(other.Fd()), syscall.LOCK_EX)).To(Succeed())
+			fmt.Fprintf(other, "%d\n", 4242)
+
+			err = execrun.Run(context.Background(), cfg, execrun.Options{RootDir: tmpDir, DisableHeartbeat: true})
+			Expect(err).To(MatchError(execrun.ErrLocked))
+			Expect(err).To(MatchError(ContainSubstring("pid 4242")))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			starts := make(chan int, 1)
+			runDone := make(chan error, 1)
+			go func() {
+				runDone <- execrun.Run(ctx, cfg, execrun.Options{
+					RootDir:          tmpDir,
+					DisableHeartbeat: true,
+					Force:            true,
+					OnProcessStart:   func(pid int) { starts <- pid },
+				})
+			}()
+			Eventually(starts, 5*time.Second).Should(Receive())
+			Expect(os.ReadFile(lock)).To(Equal(fmt.Appendf(nil, "%d\n", os.Getpid())))
+
+			cancel()
+			Eventually(runDone, 10*time.Second).Should(Receive(BeNil()))
+			Expect(lock).NotTo(BeAnExistingFile())
+		})
+
+		It("replaces a stale lock, even one naming a live pid", func() {
+			cfg := execrun.Config{Watch: execrun.Watches("*.go"), Build: []string{"true"}}
+			lock := filepath.Join(tmpDir, ".gorun", "execrun.lock")
+			Expect(execrun.PrepareCacheDir(filepath.Dir(lock), tmpDir)).To(Succeed())
+			// Left by a crash, with its pid since reused: nothing holds it.
+			Expect(os.WriteFile(lock, fmt.Appendf(nil, "%d\n", os.Getpid()), 0644)).To(Succeed())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			buildDone := make(chan error, 1)
+			runDone := make(chan error, 1)
+			go func() {
+				runDone <- execrun.Run(ctx, cfg, execrun.Options{
+					RootDir:          tmpDir,
+					DisableHeartbeat: true,
+					OnBuildDone:      func(_ time.Duration, err error) { buildDone <- err },
+				})
+			}()
+			Eventually(buildDone, 5*time.Second).Should(Receive(BeNil()))
+			Expect(os.ReadFile(lock)).To(Equal(fmt.Appendf(nil, "%d\n", os.Getpid())))
+
+			cancel()
+			Eventually(runDone, 10*time.Second).Should(Receive(BeNil()))
+		})
+
+		It("runs hooks with the event JSON on stdin", func() {
+			cfg := execrun.Config{
+				Watch: execrun.Watches("*.go"),
+				Build: []string{"true"},
+				Exec:  []string{"sleep 60"},
+				Hooks: map[string]string{
+					"on_rebuilt": `sh -c "cat > rebuilt.json"`,
+					"on_started": `sh -c "cat > started.json"`,
+				},
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			starts := make(chan int, 1)
+			runDone := make(chan error, 1)
+			go func() {
+				runDone <- execrun.Run(ctx, cfg, execrun.Options{
+					RootDir:          tmpDir,
+					DisableHeartbeat: true,
+					OnProcessStart:   func(pid int) { starts <- pid },
+				})
+			}()
+			var pid int
+			Eventually(starts, 5*time.Second).Should(Receive(&pid))
+
+			event := func(name string) func() execrun.HookEvent {
+				return func() execrun.HookEvent {
+					var e execrun.HookEvent
+					data, _ := os.ReadFile(filepath.Join(tmpDir, name))
+					json.Unmarshal(data, &e)
+					return e
+				}
+			}
+			Eventually(event("rebuilt.json"), 5*time.Second).Should(HaveField("Event", execrun.HookRebuilt))
+			Eventually(event("started.json"), 5*time.Second).Should(And(
+				HaveField("Event", execrun.HookStarted),
+				HaveField("PID", pid),
+			))
+
+			cancel()
+			Eventually(runDone, 10*time.Second).Should(Receive(BeNil()))
+		})
+
+		It("runs build steps at build_nice", func() {
+			if runtime.GOOS != "linux" {
+				Skip("reads /proc")
+			}
+			cfg := execrun.Config{
+				Watch:       execrun.Watches("trigger.txt"),
+				Build:       []string{`sh -c "sleep 0.2; cut -d' ' -f19 /proc/self/stat > nice.txt"`},
+				BuildNice:   7,
+				BuildIonice: "best-effort",
+			}
+			Expect(os.WriteFile(filepath.Join(tmpDir, "trigger.txt"), []byte("ok\n"), 0644)).To(Succeed())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			buildDone := make(chan error, 1)
+			go execrun.Run(ctx, cfg, execrun.Options{
+				RootDir:          tmpDir,
+				DisableHeartbeat: true,
+				OnBuildDone:      func(_ time.Duration, err error) { buildDone <- err },
+			})
+			Eventually(buildDone, 5*time.Second).Should(Receive(BeNil()))
+			Expect(os.ReadFile(filepath.Join(tmpDir, "nice.txt"))).To(Equal([]byte("7\n")))
+		})
+
+		It("adds env_file pairs to the command environment", func() {
+			Expect(os.WriteFile(filepath.Join(tmpDir, "build.env"), []byte("EXECRUN_TEST_GREETING='hi there'\n"), 0644)).To(Succeed())
+			cfg := execrun.Config{
+				Watch:   execrun.Watches("trigger.txt"),
+				Build:   []string{`sh -c "env > out.txt"`},
+				EnvFile: config.EnvFiles{"build.env"},
+			}
+			Expect(execrun.RunBuild(context.Background(), cfg, execrun.Options{RootDir: tmpDir})).To(Succeed())
+			out, err := os.ReadFile(filepath.Join(tmpDir, "out.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(out)).To(ContainSubstring("EXECRUN_TEST_GREETING=hi there\n"))
+		})
+
+		It("tags each line of child output with output: prefixed", func() {
+			cfg := execrun.Config{
+				Watch:  execrun.Watches("trigger.txt"),
+				Build:  []string{`sh -c "echo one; echo two"`},
+				Output: execrun.OutputPrefixed,
+			}
+			var out bytes.Buffer
+			Expect(execrun.RunBuild(context.Background(), cfg, execrun.Options{RootDir: tmpDir, LogPrefix: "[api]", ExecStdout: &out})).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("[api:build] one\n[api:build] two\n"))
+
+			cfg.Output = execrun.OutputRaw
+			out.Reset()
+			Expect(execrun.RunBuild(context.Background(), cfg, execrun.Options{RootDir: tmpDir, ExecStdout: &out, PrefixOutput: true})).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("one\ntwo"))
+			Expect(out.String()).NotTo(ContainSubstring(":build]"))
+		})
+
+		It("writes child output through output_buffer", func() {
+			cfg := execrun.Config{
+				Watch:        execrun.Watches("trigger.txt"),
+				Build:        []string{`sh -c "echo one; echo two"`},
+				Output:       execrun.OutputPrefixed,
+				OutputBuffer: "1KB",
+			}
+			var out bytes.Buffer // read after RunBuild closes the buffer
+			Expect(execrun.RunBuild(context.Background(), cfg, execrun.Options{RootDir: tmpDir, LogPrefix: "[api]", ExecStdout: &out})).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("[api:build] one\n[api:build] two\n"))
+
+			cfg.OutputBuffer = "lots"
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring("output_buffer")))
+		})
+
+		It("fails the build early when disk space is low", func() {
+			cfg := execrun.Config{
+				Watch:        execrun.Watches("trigger.txt"),
+				Build:        []string{"touch built.txt"},
+				Exec:         []string{"sleep 30"},
+				MinFreeSpace: "1000000TB",
+			}
+
+			var buildErr error
+			err := execrun.Run(context.Background(), cfg, execrun.Options{
+				RootDir:          tmpDir,
+				DisableHeartbeat: true,
+				OnBuildDone:      func(_ time.Duration, err error) { buildErr = err },
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(buildErr).To(MatchError(ContainSubstring("low disk space")))
+			Expect(filepath.Join(tmpDir, "built.txt")).NotTo(BeAnExistingFile())
+		})
+
 		It("returns initial build errors by default", func() {
 			cfg := execrun.Config{
-				Watch: []string{"trigger.txt"},
+				Watch: execrun.Watches("trigger.txt"),
 				Build: []string{"grep -q ok trigger.txt"},
 				Exec:  []string{"sleep 30"},
 			}
@@ -378,7 +1001,7 @@ exec:
 
 		It("keeps watching after initial build failure when ContinueOnError is enabled", func() {
 			cfg := execrun.Config{
-				Watch: []string{"trigger.txt"},
+				Watch: execrun.Watches("trigger.txt"),
 				Build: []string{"grep -q ok trigger.txt"},
 				Exec:  []string{"sleep 30"},
 			}
@@ -388,6 +1011,8 @@ exec:
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
+			start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			clk := clock.NewFake(start)
 			fileChanges := make(chan time.Time, 10)
 			starts := make(chan int, 10)
 			runDone := make(chan error, 1)
@@ -397,6 +1022,7 @@ exec:
 					RootDir:          tmpDir,
 					ContinueOnError:  true,
 					DisableHeartbeat: true,
+					Clock:            clk,
 					OnFilesChanged: func(at time.Time, _ sumfile.ChangeSet) {
 						fileChanges <- at
 					},
@@ -406,19 +1032,108 @@ exec:
 				})
 			}()
 
-			Consistently(runDone, 400*time.Millisecond).ShouldNot(Receive())
+			// The failed build leaves the watcher running.
+			clk.BlockUntil(1)
+			Expect(runDone).NotTo(Receive())
 			Expect(os.WriteFile(triggerPath, []byte("ok\n"), 0644)).To(Succeed())
 
-			Eventually(fileChanges, 5*time.Second).Should(Receive())
+			var at time.Time
+			Eventually(func() <-chan time.Time {
+				clk.Advance(500 * time.Millisecond)
+				return fileChanges
+			}, 5*time.Second, 10*time.Millisecond).Should(Receive(&at))
+			Expect(at).To(BeTemporally(">", start))
 			Eventually(starts, 5*time.Second).Should(Receive(BeNumerically(">", 0)))
 
 			cancel()
 			Eventually(runDone).Should(Receive(BeNil()))
 		})
 
+		It("cancels an in-flight build when newer changes arrive", func() {
+			cfg := execrun.Config{
+				Watch: execrun.Watches("trigger.txt"),
+				Build: []string{"sleep 2"},
+				Exec:  []string{"sleep 30"},
+			}
+			triggerPath := filepath.Join(tmpDir, "trigger.txt")
+			Expect(os.WriteFile(triggerPath, []byte("v0\n"), 0644)).To(Succeed())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			buildStarts := make(chan struct{}, 10)
+			buildDone := make(chan error, 10)
+			starts := make(chan int, 10)
+			runDone := make(chan error, 1)
+
+			go func() {
+				runDone <- execrun.Run(ctx, cfg, execrun.Options{
+					RootDir:          tmpDir,
+					PollInterval:     50 * time.Millisecond,
+					Debounce:         50 * time.Millisecond,
+					DisableHeartbeat: true,
+					OnBuildStart:     func() { buildStarts <- struct{}{} },
+					OnBuildDone:      func(_ time.Duration, err error) { buildDone <- err },
+					OnProcessStart:   func(pid int) { starts <- pid },
+				})
+			}()
+
+			// Initial build and start.
+			Eventually(buildStarts, 5*time.Second).Should(Receive())
+			Eventually(starts, 5*time.Second).Should(Receive())
+			Expect(buildDone).To(Receive(BeNil()))
+
+			// A change starts a build; a second change mid-build cancels it.
+			Expect(os.WriteFile(triggerPath, []byte("v1\n"), 0644)).To(Succeed())
+			Eventually(buildStarts, 5*time.Second).Should(Receive())
+			Expect(os.WriteFile(triggerPath, []byte("v2\n"), 0644)).To(Succeed())
+			Eventually(buildStarts, 5*time.Second).Should(Receive())
+
+			// Only the superseding build reports and restarts the process.
+			Eventually(buildDone, 5*time.Second).Should(Receive(BeNil()))
+			Eventually(starts, 5*time.Second).Should(Receive())
+			Consistently(buildDone, 500*time.Millisecond).ShouldNot(Receive())
+			Expect(starts).NotTo(Receive())
+
+			cancel()
+			Eventually(runDone, 10*time.Second).Should(Receive(BeNil()))
+		})
+
+		It("escalates to SIGKILL only after the stop timeout", func() {
+			cfg := execrun.Config{
+				Watch: execrun.Watches("trigger.txt"),
+				Exec:  []string{`sh -c "trap '' TERM; sleep 30"`},
+			}
+			Expect(os.WriteFile(filepath.Join(tmpDir, "trigger.txt"), []byte("ok\n"), 0644)).To(Succeed())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			clk := clock.NewFake(time.Now())
+			starts := make(chan int, 1)
+			runDone := make(chan error, 1)
+			go func() {
+				runDone <- execrun.Run(ctx, cfg, execrun.Options{
+					RootDir:          tmpDir,
+					DisableHeartbeat: true,
+					Clock:            clk,
+					OnProcessStart:   func(pid int) { starts <- pid },
+				})
+			}()
+			Eventually(starts, 5*time.Second).Should(Receive())
+
+			// SIGTERM is ignored, so nothing happens until the timeout passes.
+			cancel()
+			Consistently(runDone, 200*time.Millisecond).ShouldNot(Receive())
+			Eventually(func() <-chan error {
+				clk.Advance(time.Second)
+				return runDone
+			}, 5*time.Second, 10*time.Millisecond).Should(Receive(BeNil()))
+		})
+
 		It("writes child start failures to the run log", func() {
 			cfg := execrun.Config{
-				Watch: []string{"trigger.txt"},
+				Watch: execrun.Watches("trigger.txt"),
 				Exec:  []string{"./missing-binary"},
 			}
 			Expect(os.WriteFile(filepath.Join(tmpDir, "trigger.txt"), []byte("ok\n"), 0644)).To(Succeed())
@@ -0,0 +1,39 @@
+package execrun
+
+// Explanation is what a run of a config would do, without running
+// anything: see Config.Explain.
+type Explanation struct {
+	RootDir string `json:"root_dir"` // where commands run and patterns are resolved
+	// Patterns are the watch, restart_watch, and reload_watch patterns
+	// after directory shorthand, with the exclusions of the IgnoreFiles and
+	// the cache dir. Exclusions start with "!".
+	Patterns []string `json:"patterns"`
+	Files    []string `json:"files"` // the watched files, relative to RootDir
+	Build    []string `json:"build,omitempty"`
+	Test     []string `json:"test,omitempty"`
+	Exec     []string `json:"exec,omitempty"`
+}
+
+// Explain resolves the watch patterns and expands them in rootDir. The
+// config's commands are already templated by LoadConfig.
+func (this *Config) Explain(rootDir string) (*Explanation, error) {
+	files, err := this.WatchedFiles(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	e := &Explanation{
+		RootDir: rootDir,
+		Files:   files,
+		Build:   this.Build,
+		Test:    this.Test,
+		Exec:    this.Exec,
+	}
+	for _, p := range this.patterns(rootDir) {
+		if p.Negated {
+			e.Patterns = append(e.Patterns, "!"+p.Raw)
+		} else {
+			e.Patterns = append(e.Patterns, p.Raw)
+		}
+	}
+	return e, nil
+}
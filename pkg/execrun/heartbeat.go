@@ -0,0 +1,60 @@
+package execrun
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultHeartbeat is the console heartbeat interval.
+const DefaultHeartbeat = 10 * time.Second
+
+// Heartbeat is the heartbeat: setting. false turns the console heartbeat
+// off, and a duration like 30s sets its interval (default: true, 10s).
+type Heartbeat string
+
+func (this *Heartbeat) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: heartbeat must be true, false, or a duration like 30s", node.Line)
+	}
+	*this = Heartbeat(node.Value)
+	return nil
+}
+
+// Interval returns the heartbeat interval, or 0 if the heartbeat is off.
+func (this Heartbeat) Interval() time.Duration {
+	switch this {
+	case "", "true":
+		return DefaultHeartbeat
+	case "false":
+		return 0
+	}
+	d, _ := time.ParseDuration(string(this))
+	return d
+}
+
+// Validate checks the setting is true, false, or a positive duration.
+func (this Heartbeat) Validate() error {
+	switch this {
+	case "", "true", "false":
+		return nil
+	}
+	if d, err := time.ParseDuration(string(this)); err != nil || d <= 0 {
+		return fmt.Errorf("heartbeat must be true, false, or a positive duration like 30s, got %q", string(this))
+	}
+	return nil
+}
+
+// heartbeatInterval returns the heartbeat interval for Run, or 0 if it is
+// off: Options.DisableHeartbeat wins over Options.HeartbeatInterval, which
+// wins over the heartbeat: setting.
+func heartbeatInterval(cfg Config, opts Options) time.Duration {
+	if opts.DisableHeartbeat || opts.Quiet {
+		return 0
+	}
+	if opts.HeartbeatInterval > 0 {
+		return opts.HeartbeatInterval
+	}
+	return cfg.Heartbeat.Interval()
+}
@@ -0,0 +1,139 @@
+package execrun
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gur-shatz/go-run/internal/log"
+)
+
+// Hook events. A hooks: key is "on_" followed by one of them.
+const (
+	HookRebuilt     = "rebuilt"      // the build steps succeeded
+	HookBuildFailed = "build_failed" // a build step failed
+	HookTestFailed  = "test_failed"  // a test step failed
+	HookStarted     = "started"      // the managed process started
+	HookExited      = "exited"       // the managed process exited
+)
+
+var hookEvents = []string{HookRebuilt, HookBuildFailed, HookTestFailed, HookStarted, HookExited}
+
+// hookTimeout bounds how long a hook command may run.
+const hookTimeout = time.Minute
+
+// HookEvent is the JSON a hook command gets on stdin.
+type HookEvent struct {
+	Event        string    `json:"event"`
+	Time         time.Time `json:"time"`
+	DurationSecs float64   `json:"duration_secs,omitempty"` // rebuilt, build_failed, test_failed
+	Error        string    `json:"error,omitempty"`
+	PID          int       `json:"pid,omitempty"`       // started
+	ExitCode     int       `json:"exit_code,omitempty"` // exited
+}
+
+// validateHooks checks that every hooks: key names a hook event and every
+// command parses.
+func (this *Config) validateHooks() error {
+	for key, cmd := range this.Hooks {
+		event, ok := strings.CutPrefix(key, "on_")
+		if !ok || !slices.Contains(hookEvents, event) {
+			return fmt.Errorf("hooks: unknown hook %q (want on_ followed by one of %s)", key, strings.Join(hookEvents, ", "))
+		}
+		if _, err := parseCmd(cmd); err != nil {
+			return fmt.Errorf("hooks: %s: %w", key, err)
+		}
+		if err := checkShellVars(cmd); err != nil {
+			return fmt.Errorf("hooks: %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// withHooks returns opts with lifecycle callbacks that also run the hooks
+// in rootDir with env. Hooks run in the background, so a slow one never
+// holds up a rebuild; failures are logged.
+func (this *Config) withHooks(opts Options, rootDir string, env []string, l *log.Logger) Options {
+	if len(this.Hooks) == 0 {
+		return opts
+	}
+	fire := func(e HookEvent) {
+		cmd, ok := this.Hooks["on_"+e.Event]
+		if !ok {
+			return
+		}
+		e.Time = time.Now()
+		go func() {
+			if err := runHook(cmd, e, rootDir, env, opts); err != nil {
+				l.Warn("hook on_%s: %v", e.Event, err)
+			}
+		}()
+	}
+
+	onBuildDone := opts.OnBuildDone
+	opts.OnBuildDone = func(d time.Duration, err error) {
+		if onBuildDone != nil {
+			onBuildDone(d, err)
+		}
+		e := HookEvent{Event: HookRebuilt, DurationSecs: d.Seconds()}
+		if err != nil {
+			e.Event, e.Error = HookBuildFailed, err.Error()
+		}
+		fire(e)
+	}
+	onTestDone := opts.OnTestDone
+	opts.OnTestDone = func(d time.Duration, err error) {
+		if onTestDone != nil {
+			onTestDone(d, err)
+		}
+		if err != nil {
+			fire(HookEvent{Event: HookTestFailed, DurationSecs: d.Seconds(), Error: err.Error()})
+		}
+	}
+	onProcessStart := opts.OnProcessStart
+	opts.OnProcessStart = func(pid int) {
+		if onProcessStart != nil {
+			onProcessStart(pid)
+		}
+		fire(HookEvent{Event: HookStarted, PID: pid})
+	}
+	onProcessExit := opts.OnProcessExit
+	opts.OnProcessExit = func(exitCode int, err error) {
+		if onProcessExit != nil {
+			onProcessExit(exitCode, err)
+		}
+		e := HookEvent{Event: HookExited, ExitCode: exitCode}
+		if err != nil {
+			e.Error = err.Error()
+		}
+		fire(e)
+	}
+	return opts
+}
+
+// runHook runs cmd with e as JSON on stdin and its output going where
+// build output goes.
+func runHook(cmd string, e HookEvent, rootDir string, env []string, opts Options) error {
+	args, err := parseCmd(cmd)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	c := exec.CommandContext(ctx, args[0], args[1:]...)
+	c.Dir = rootDir
+	c.Env = env
+	c.Stdin = bytes.NewReader(body)
+	c.Stdout = opts.ExecStdout
+	c.Stderr = opts.ExecStderr
+	return c.Run()
+}
@@ -0,0 +1,98 @@
+package execrun
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ErrLocked is returned by Run when another live instance holds the lock
+// file of the same sum file.
+var ErrLocked = errors.New("another instance is running")
+
+// LockPath returns the lock file that guards sumPath: the same name with
+// a .lock extension, next to it.
+func LockPath(sumPath string) string {
+	return strings.TrimSuffix(sumPath, filepath.Ext(sumPath)) + ".lock"
+}
+
+// lockFile claims path for this process with an advisory flock, which the
+// kernel drops when the process exits, so a lock left by a crash is never
+// in the way. The file holds the pid for messages only. A lock held by
+// another instance is an ErrLocked error, unless force, in which case the
+// file is replaced and the holder's pid returned; the holder keeps running
+// with its lock on the unlinked file. release removes the file if it is
+// still ours.
+func lockFile(path string, force bool) (release func(), took int, err error) {
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, 0, fmt.Errorf("open lock file: %w", err)
+		}
+		if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+			holder := lockHolder(f)
+			f.Close()
+			if !errors.Is(err, syscall.EWOULDBLOCK) {
+				return nil, 0, fmt.Errorf("lock %s: %w", path, err)
+			}
+			if !force {
+				return nil, 0, fmt.Errorf("%w (pid %d, lock %s); stop it or pass -force to take over", ErrLocked, holder, path)
+			}
+			took = holder
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, 0, fmt.Errorf("take over lock file: %w", err)
+			}
+			continue
+		}
+		// Another instance may have replaced the file between our open and
+		// flock; then the lock we hold guards nothing.
+		if !lockIsPath(f, path) {
+			f.Close()
+			continue
+		}
+		if err := f.Truncate(0); err != nil {
+			f.Close()
+			return nil, 0, fmt.Errorf("write lock file: %w", err)
+		}
+		if _, err := fmt.Fprintf(f, "%d\n", os.Getpid()); err != nil {
+			f.Close()
+			return nil, 0, fmt.Errorf("write lock file: %w", err)
+		}
+		return func() { unlock(f, path) }, took, nil
+	}
+}
+
+// lockHolder returns the pid in the lock file, or 0 if it can't be read.
+func lockHolder(f *os.File) int {
+	data, err := io.ReadAll(io.NewSectionReader(f, 0, 64))
+	if err != nil {
+		return 0
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid
+}
+
+// lockIsPath reports whether path still names the open file f.
+func lockIsPath(f *os.File, path string) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	pi, err := os.Stat(path)
+	return err == nil && os.SameFile(fi, pi)
+}
+
+// unlock removes the lock file unless another instance has taken it over,
+// then drops the lock. Removing it first, while still locked, keeps a
+// newcomer from locking the file just before it disappears.
+func unlock(f *os.File, path string) {
+	if lockIsPath(f, path) {
+		os.Remove(path)
+	}
+	f.Close()
+}
@@ -0,0 +1,100 @@
+package execrun
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gur-shatz/go-run/internal/bufwriter"
+	"github.com/gur-shatz/go-run/internal/diskspace"
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/pkg/messages"
+)
+
+// output: settings.
+const (
+	OutputRaw      = "raw"      // child output passes through untouched
+	OutputPrefixed = "prefixed" // each line gets the target/stage tag
+)
+
+// validateOutput checks the output: setting.
+func (this *Config) validateOutput() error {
+	switch this.Output {
+	case "", OutputRaw, OutputPrefixed:
+		return nil
+	}
+	return fmt.Errorf("output must be %s or %s, got %q", OutputRaw, OutputPrefixed, this.Output)
+}
+
+// prefixesOutput reports whether child output is tagged line by line: the
+// output: setting wins over Options.PrefixOutput.
+func prefixesOutput(cfg Config, opts Options) bool {
+	if cfg.Output != "" {
+		return cfg.Output == OutputPrefixed
+	}
+	return opts.PrefixOutput
+}
+
+// prefixOutput wraps the child output writers in opts, if prefixesOutput,
+// with LineWriters tagging build lines "[<name>:build]", test lines
+// "[<name>:test]", and run lines with the log prefix. Returns a func that
+// flushes held-back partial lines.
+func prefixOutput(cfg Config, opts *Options, prefix string) (flush func()) {
+	if !prefixesOutput(cfg, *opts) {
+		return func() {}
+	}
+	name := strings.Trim(prefix, "[]")
+	var writers []*log.LineWriter
+	wrap := func(w *io.Writer, tag string) {
+		lw := log.NewLineWriter(*w, tag)
+		writers = append(writers, lw)
+		*w = lw
+	}
+	wrap(&opts.ExecStdout, "["+name+":build]")
+	wrap(&opts.ExecStderr, "["+name+":build]")
+	wrap(&opts.TestStdout, "["+name+":test]")
+	wrap(&opts.TestStderr, "["+name+":test]")
+	wrap(&opts.Stdout, prefix)
+	wrap(&opts.Stderr, prefix)
+	return func() {
+		for _, lw := range writers {
+			lw.Close()
+		}
+	}
+}
+
+// OutputBufferBytes returns the output_buffer size in bytes, or 0 if unset.
+func (this *Config) OutputBufferBytes() int {
+	n, _ := diskspace.ParseSize(this.OutputBuffer)
+	return int(n)
+}
+
+// bufferOutput wraps the child output writers in opts, if output_buffer is
+// set, with bufwriter.Writers so a blocked destination can't stall the
+// child; drops are logged as verbose messages. Returns a func that closes
+// the buffers, once the writers wrapping them are flushed.
+func bufferOutput(cfg Config, opts *Options, l *log.Logger) (closeAll func()) {
+	size := cfg.OutputBufferBytes()
+	if size == 0 {
+		return func() {}
+	}
+	var writers []*bufwriter.Writer
+	wrap := func(w *io.Writer, stage string) {
+		bw := bufwriter.New(*w, size, func(n int64) {
+			l.Verbose("%s", messages.Sprintf(messages.OutputDropped, diskspace.FormatSize(uint64(n)), stage))
+		})
+		writers = append(writers, bw)
+		*w = bw
+	}
+	wrap(&opts.ExecStdout, "build")
+	wrap(&opts.ExecStderr, "build")
+	wrap(&opts.TestStdout, "test")
+	wrap(&opts.TestStderr, "test")
+	wrap(&opts.Stdout, "run")
+	wrap(&opts.Stderr, "run")
+	return func() {
+		for _, bw := range writers {
+			bw.Close()
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package execrun
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gur-shatz/go-run/internal/sumfile"
+)
+
+// buildFunc runs one build. ctx is cancelled when a newer build request
+// supersedes it. changes is nil for manually triggered builds.
+type buildFunc func(ctx context.Context, changes *sumfile.ChangeSet)
+
+// buildQueue runs builds one at a time. A request that arrives while a build
+// is running cancels it; the next build covers all changes seen since the
+// last build that ran to completion, so only the latest code gets built.
+type buildQueue struct {
+	mu       sync.Mutex
+	pending  bool
+	manual   bool               // a manual trigger is pending
+	changes  *sumfile.ChangeSet // merged changes of pending requests
+	cancel   context.CancelFunc // cancels the in-flight build, if any
+	inflight *sumfile.ChangeSet // changes of the in-flight build; nil if manual
+	wake     chan struct{}
+}
+
+func newBuildQueue() *buildQueue {
+	return &buildQueue{wake: make(chan struct{}, 1)}
+}
+
+// push requests a build, cancelling the in-flight one. changes is nil for a
+// manual trigger.
+func (this *buildQueue) push(changes *sumfile.ChangeSet) {
+	this.mu.Lock()
+	if this.cancel != nil {
+		// The cancelled build's changes still need building.
+		this.cancel()
+		this.cancel = nil
+		this.add(this.inflight)
+	}
+	this.add(changes)
+	this.mu.Unlock()
+
+	select {
+	case this.wake <- struct{}{}:
+	default:
+	}
+}
+
+// add merges a request into the pending one. Callers hold mu.
+func (this *buildQueue) add(changes *sumfile.ChangeSet) {
+	this.pending = true
+	switch {
+	case changes == nil:
+		this.manual = true
+	case this.changes == nil:
+		this.changes = changes
+	default:
+		this.changes = sumfile.Merge(this.changes, changes)
+	}
+}
+
+// run processes build requests until ctx is cancelled. A manual trigger
+// merged with file changes runs as a manual build.
+func (this *buildQueue) run(ctx context.Context, build buildFunc) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-this.wake:
+		}
+
+		this.mu.Lock()
+		if !this.pending {
+			this.mu.Unlock()
+			continue
+		}
+		changes := this.changes
+		if this.manual {
+			changes = nil
+		}
+		this.pending, this.manual, this.changes = false, false, nil
+		buildCtx, cancel := context.WithCancel(ctx)
+		this.cancel = cancel
+		this.inflight = changes
+		this.mu.Unlock()
+
+		build(buildCtx, changes)
+
+		this.mu.Lock()
+		this.cancel = nil
+		this.mu.Unlock()
+		cancel()
+	}
+}
@@ -0,0 +1,43 @@
+package execrun
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/gur-shatz/go-run/pkg/clock"
+)
+
+// ReloadFile is the trigger file in RootDir: touching it forces a rebuild
+// and restart, e.g. after a dependency outside the watch patterns changed.
+const ReloadFile = ".reload"
+
+// watchTrigger polls path every interval and calls fire when the file
+// appears or its modification time changes. A file present at startup
+// doesn't fire.
+func watchTrigger(ctx context.Context, clk clock.Clock, path string, interval time.Duration, fire func()) {
+	last := modTime(path)
+	ticker := clk.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+		t := modTime(path)
+		if !t.IsZero() && !t.Equal(last) {
+			fire()
+		}
+		last = t
+	}
+}
+
+// modTime returns path's modification time, or zero if it doesn't exist.
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,30 @@
+package execrun
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gur-shatz/go-run/internal/hasher"
+	"github.com/gur-shatz/go-run/internal/sumfile"
+)
+
+// Verify compares the watched files in rootDir to the sum file at sumPath,
+// as written by `execrun sum`, and returns the differences. It fails if
+// the sum file is missing or was hashed with another algorithm.
+func Verify(cfg *Config, rootDir, sumPath string) (sumfile.ChangeSet, error) {
+	if _, err := os.Stat(sumPath); err != nil {
+		return sumfile.ChangeSet{}, fmt.Errorf("no sum file to verify against (run sum first): %w", err)
+	}
+	recorded, err := sumfile.ReadStats(sumPath)
+	if err != nil {
+		return sumfile.ChangeSet{}, err
+	}
+	if recorded == nil {
+		return sumfile.ChangeSet{}, fmt.Errorf("%s was not hashed with %s; pass the --hash it was written with, or run sum again", sumPath, hasher.Current())
+	}
+	sums, err := ScanFiles(cfg, rootDir)
+	if err != nil {
+		return sumfile.ChangeSet{}, fmt.Errorf("scan files: %w", err)
+	}
+	return sumfile.Diff(sumfile.Hashes(recorded), sums), nil
+}
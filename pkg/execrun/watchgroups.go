@@ -0,0 +1,310 @@
+package execrun
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gur-shatz/go-run/internal/glob"
+	"github.com/gur-shatz/go-run/internal/scan"
+	"github.com/gur-shatz/go-run/internal/sumfile"
+	"github.com/gur-shatz/go-run/internal/watcher"
+	"github.com/gur-shatz/go-run/pkg/messages"
+)
+
+// reloadSignals are the signals reload_signal accepts.
+var reloadSignals = map[string]syscall.Signal{
+	"HUP":   syscall.SIGHUP,
+	"INT":   syscall.SIGINT,
+	"QUIT":  syscall.SIGQUIT,
+	"TERM":  syscall.SIGTERM,
+	"USR1":  syscall.SIGUSR1,
+	"USR2":  syscall.SIGUSR2,
+	"WINCH": syscall.SIGWINCH,
+}
+
+// ParseSignal parses a reload_signal name like "SIGHUP" or "hup".
+func ParseSignal(name string) (syscall.Signal, error) {
+	sig, ok := reloadSignals[strings.TrimPrefix(strings.ToUpper(name), "SIG")]
+	if !ok {
+		return 0, fmt.Errorf("unknown signal %q (use SIGHUP, SIGUSR1, SIGUSR2, SIGINT, SIGQUIT, SIGTERM, or SIGWINCH)", name)
+	}
+	return sig, nil
+}
+
+// reloadSignal returns the reload_signal setting and its canonical name,
+// SIGHUP if unset.
+func (this *Config) reloadSignal() (syscall.Signal, string) {
+	name := "SIG" + strings.TrimPrefix(strings.ToUpper(cmp.Or(this.ReloadSignal, "SIGHUP")), "SIG")
+	sig, _ := ParseSignal(name)
+	return sig, name
+}
+
+// WatchEntry is a watch pattern. In YAML it is a plain pattern string, or
+// an object that also sets the pattern's debounce:
+//
+//	watch:
+//	  - "**/*.go"
+//	  - pattern: "**/*.sql"
+//	    debounce: 2s
+type WatchEntry struct {
+	Pattern  string `yaml:"pattern"`
+	Debounce string `yaml:"debounce,omitempty"` // overrides debounce: for matching files
+}
+
+func (this *WatchEntry) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&this.Pattern)
+	}
+	type entry WatchEntry // without this method
+	return node.Decode((*entry)(this))
+}
+
+// MarshalYAML writes entries without a debounce as plain strings.
+func (this WatchEntry) MarshalYAML() (any, error) {
+	if this.Debounce == "" {
+		return this.Pattern, nil
+	}
+	type entry WatchEntry
+	return entry(this), nil
+}
+
+// WatchList is the watch: setting.
+type WatchList []WatchEntry
+
+// Watches returns a WatchList of plain patterns.
+func Watches(patterns ...string) WatchList {
+	list := make(WatchList, len(patterns))
+	for i, p := range patterns {
+		list[i].Pattern = p
+	}
+	return list
+}
+
+// Patterns returns the entries' patterns.
+func (this WatchList) Patterns() []string {
+	patterns := make([]string, len(this))
+	for i, e := range this {
+		patterns[i] = e.Pattern
+	}
+	return patterns
+}
+
+// WatchPatterns returns the watch patterns followed by the restart_watch
+// and reload_watch patterns: every file a change to which is acted on.
+func (this *Config) WatchPatterns() []string {
+	return slices.Concat(this.Watch.Patterns(), this.RestartWatch, this.ReloadWatch)
+}
+
+// validateWatch checks the watch entries' patterns and debounces.
+func (this *Config) validateWatch() error {
+	for i, e := range this.Watch {
+		if strings.TrimSpace(e.Pattern) == "" {
+			return fmt.Errorf("watch: entry %d has no pattern", i+1)
+		}
+		if e.Debounce == "" {
+			continue
+		}
+		if strings.HasPrefix(e.Pattern, "!") {
+			return fmt.Errorf("watch: %q: an exclusion can't have a debounce", e.Pattern)
+		}
+		if d, err := time.ParseDuration(e.Debounce); err != nil || d <= 0 {
+			return fmt.Errorf("watch: %q: debounce must be a positive duration like 2s, got %q", e.Pattern, e.Debounce)
+		}
+	}
+	return nil
+}
+
+// IgnoreFiles are read from the root directory. Their gitignore-style lines
+// are added to watch as exclusions, so exclusion lists can be shared
+// between configs.
+var IgnoreFiles = []string{".gorunignore", ".execrunignore"}
+
+// patterns parses the watch patterns, resolving directory shorthand against
+// rootDir, and adds the exclusions of the IgnoreFiles there and of the
+// cache dir.
+func (this *Config) patterns(rootDir string) []glob.Pattern {
+	patterns := append(scan.ParseWatchPatterns(this.WatchPatterns(), rootDir), ignoreFilePatterns(rootDir)...)
+	return append(patterns, this.cacheExclusion(rootDir)...)
+}
+
+// WatchedFiles returns the files, relative to rootDir, that the watch
+// patterns match.
+func (this *Config) WatchedFiles(rootDir string) ([]string, error) {
+	return this.expandFiles(rootDir, this.patterns(rootDir))
+}
+
+// expandFiles expands patterns in rootDir, leaving out the directories
+// "dir/**" matches along with their files.
+func (this *Config) expandFiles(rootDir string, patterns []glob.Pattern) ([]string, error) {
+	paths, err := glob.ExpandPatterns(rootDir, patterns, this.globOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(paths, func(p string) bool {
+		info, err := os.Stat(filepath.Join(rootDir, p))
+		return err == nil && info.IsDir()
+	}), nil
+}
+
+// UnmatchedPatterns returns the watch, restart_watch, and reload_watch
+// patterns that match no file in rootDir, which usually means a typo.
+func (this *Config) UnmatchedPatterns(rootDir string) ([]string, error) {
+	exclusions := this.exclusions(rootDir)
+	var unmatched []string
+	for _, p := range scan.ParseWatchPatterns(this.WatchPatterns(), rootDir) {
+		if p.Negated {
+			continue
+		}
+		files, err := this.expandFiles(rootDir, append([]glob.Pattern{p}, exclusions...))
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			unmatched = append(unmatched, p.Raw)
+		}
+	}
+	return unmatched, nil
+}
+
+// exclusions returns the exclusions of watch, of the IgnoreFiles in rootDir
+// and of the cache dir, which apply to every watch group.
+func (this *Config) exclusions(rootDir string) []glob.Pattern {
+	var patterns []glob.Pattern
+	for _, p := range scan.ParseWatchPatterns(this.Watch.Patterns(), rootDir) {
+		if p.Negated {
+			patterns = append(patterns, p)
+		}
+	}
+	patterns = append(patterns, ignoreFilePatterns(rootDir)...)
+	return append(patterns, this.cacheExclusion(rootDir)...)
+}
+
+func ignoreFilePatterns(rootDir string) []glob.Pattern {
+	var patterns []glob.Pattern
+	for _, name := range IgnoreFiles {
+		patterns = append(patterns, glob.ReadExcludeFile(filepath.Join(rootDir, name))...)
+	}
+	return patterns
+}
+
+// debounceGroups returns a watcher group per distinct watch entry debounce,
+// in the order they first appear. The exclusions of watch apply to them.
+// Directory shorthand resolves against rootDir.
+func (this *Config) debounceGroups(rootDir string) []watcher.DebounceGroup {
+	var groups []watcher.DebounceGroup
+	index := make(map[time.Duration]int)
+	for _, e := range this.Watch {
+		d, err := time.ParseDuration(e.Debounce)
+		if err != nil || d <= 0 {
+			continue
+		}
+		i, ok := index[d]
+		if !ok {
+			i = len(groups)
+			index[d] = i
+			groups = append(groups, watcher.DebounceGroup{Debounce: d})
+		}
+		groups[i].Patterns = append(groups[i].Patterns, scan.ParseWatchPatterns([]string{e.Pattern}, rootDir)...)
+	}
+	exclusions := this.exclusions(rootDir)
+	for i := range groups {
+		groups[i].Patterns = append(groups[i].Patterns, exclusions...)
+	}
+	return groups
+}
+
+// validateGroups checks the restart_watch, reload_watch, and reload_signal
+// settings.
+func (this *Config) validateGroups() error {
+	if this.ReloadSignal != "" {
+		if len(this.ReloadWatch) == 0 {
+			return fmt.Errorf("reload_signal needs reload_watch patterns")
+		}
+		if _, err := ParseSignal(this.ReloadSignal); err != nil {
+			return fmt.Errorf("reload_signal: %w", err)
+		}
+	}
+	if len(this.RestartWatch) > 0 && this.IsBuildOnly() {
+		return fmt.Errorf("restart_watch needs an exec command to restart")
+	}
+	if len(this.ReloadWatch) > 0 && this.IsBuildOnly() {
+		return fmt.Errorf("reload_watch needs an exec command to signal")
+	}
+	return nil
+}
+
+// action is what a change calls for, from least to most work.
+type action int
+
+const (
+	actionReload  action = iota // signal the process (reload_watch)
+	actionRestart               // restart it without building (restart_watch)
+	actionRebuild               // run the whole pipeline (watch)
+)
+
+// watchGroups holds the parsed restart_watch and reload_watch patterns.
+type watchGroups struct {
+	restart []glob.Pattern
+	reload  []glob.Pattern
+}
+
+// watchGroups parses the restart_watch and reload_watch patterns, resolving
+// directory shorthand against rootDir. The exclusions of watch apply to
+// them as well.
+func (this *Config) watchGroups(rootDir string) watchGroups {
+	return watchGroups{
+		restart: this.groupPatterns(this.RestartWatch, rootDir),
+		reload:  this.groupPatterns(this.ReloadWatch, rootDir),
+	}
+}
+
+func (this *Config) groupPatterns(group []string, rootDir string) []glob.Pattern {
+	if len(group) == 0 {
+		return nil
+	}
+	return append(scan.ParseWatchPatterns(group, rootDir), this.exclusions(rootDir)...)
+}
+
+// action returns what changes call for: the most work any changed file
+// needs. A file in several groups counts for the one needing the least, so
+// reload_watch and restart_watch can carve files out of watch.
+func (this watchGroups) action(changes sumfile.ChangeSet) action {
+	if len(this.restart)+len(this.reload) == 0 || changes.IsEmpty() {
+		return actionRebuild
+	}
+	result := actionReload
+	for _, files := range [][]string{changes.Added, changes.Modified, changes.Removed} {
+		for _, f := range files {
+			switch {
+			case glob.Match(this.reload, f):
+			case glob.Match(this.restart, f):
+				result = max(result, actionRestart)
+			default:
+				return actionRebuild
+			}
+		}
+	}
+	return result
+}
+
+// reload sends the reload signal to the managed process and returns its
+// PID, or 0 when no process is running.
+func (this *runner) reload() (int, error) {
+	this.mu.Lock()
+	cmd := this.cmd
+	this.mu.Unlock()
+	if cmd == nil || cmd.Process == nil {
+		return 0, nil
+	}
+	sig, name := this.cfg.reloadSignal()
+	this.logTo(this.stdout, "%s", messages.Sprintf(messages.LogReloading, name, cmd.Process.Pid))
+	return cmd.Process.Pid, cmd.Process.Signal(sig)
+}
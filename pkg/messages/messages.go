@@ -0,0 +1,194 @@
+// Package messages is the catalog of user-facing log and status strings
+// printed by execrun and runctl. Programs that embed those packages can
+// reword any message or prefix with Set; everything else keeps the built-in
+// English text.
+package messages
+
+import (
+	"fmt"
+	"maps"
+	"sync"
+)
+
+// ID names a message. Its text is a fmt format string; the comment on each
+// ID lists the arguments it is formatted with.
+type ID string
+
+// Prefixes and labels.
+const (
+	ExecrunPrefix ID = "execrun_prefix" // prefix of standalone execrun output
+	RunctlPrefix  ID = "runctl_prefix"  // prefix of runctl's own output
+	TargetPrefix  ID = "target_prefix"  // %s: target name
+	ErrorLabel    ID = "error_label"    // precedes error messages
+	WarningLabel  ID = "warning_label"  // precedes runctl warnings
+)
+
+// Console status lines of the execrun run loop.
+const (
+	Executing          ID = "executing"
+	DoneIn             ID = "done_in"              // %s: duration
+	Started            ID = "started"              // %d: pid
+	InitialBuildFailed ID = "initial_build_failed" // %v: error
+	InitialStartFailed ID = "initial_start_failed" // %v: error
+	WatchingForChanges ID = "watching_for_changes"
+	ChangesDetected    ID = "changes_detected"
+	ChangeModified     ID = "change_modified" // %s: path
+	ChangeAdded        ID = "change_added"    // %s: path
+	ChangeRemoved      ID = "change_removed"  // %s: path
+	Rebuilding         ID = "rebuilding"
+	Restarting         ID = "restarting"
+	BuildTriggered     ID = "build_triggered"
+	ReloadFileTouched  ID = "reload_file_touched" // %s: file name
+	LockTakenOver      ID = "lock_taken_over"     // %d: pid
+	OutputDropped      ID = "output_dropped"      // %s: size, %s: stage
+	BuildCancelled     ID = "build_cancelled"
+	BuildFailed        ID = "build_failed" // %v: error
+	KeepingPrevious    ID = "keeping_previous"
+	BuildDone          ID = "build_done" // %d: pid, %s: duration
+	BuildModeStart     ID = "build_mode_start"
+	BuildModeDone      ID = "build_mode_done" // %s: duration
+	BuildDoneIn        ID = "build_done_in"   // %s: duration
+	TestsTriggered     ID = "tests_triggered"
+	TestsFailed        ID = "tests_failed"  // %v: error
+	TestsDone          ID = "tests_done"    // %s: duration
+	TestsDoneIn        ID = "tests_done_in" // %s: duration
+	Reloading          ID = "reloading"     // %s: signal name, %d: pid
+	ReloadFailed       ID = "reload_failed" // %v: error
+	StoppingProcess    ID = "stopping_process"
+	WatchPaused        ID = "watch_paused"
+	WatchResumed       ID = "watch_resumed"
+	StartingProcess    ID = "starting_process"
+	StartFailed        ID = "start_failed"     // %v: error
+	ExitedWithCode     ID = "exited_with_code" // %d: exit code
+	Completed          ID = "completed"
+	ShuttingDown       ID = "shutting_down"
+	SIGKILLWarning     ID = "sigkill_warning"
+)
+
+// Markers written to the build, test, and run logs.
+const (
+	LogRunning        ID = "log_running"        // %s: command
+	LogCommandFailed  ID = "log_command_failed" // %s: error
+	LogCommandCancel  ID = "log_command_cancelled"
+	LogBuildDone      ID = "log_build_done"      // %s: duration
+	LogTestsDone      ID = "log_tests_done"      // %s: duration
+	LogStartFailed    ID = "log_start_failed"    // %s: error
+	LogProcessStarted ID = "log_process_started" // %d: pid, %s: command
+	LogProcessExited  ID = "log_process_exited"  // %d: exit code
+	LogReloading      ID = "log_reloading"       // %s: signal name, %d: pid
+	LogStopping       ID = "log_stopping"        // %d: pid
+	LogStopped        ID = "log_stopped"
+	LogSIGKILL        ID = "log_sigkill"
+	LogKilled         ID = "log_killed"
+)
+
+// Catalog maps message IDs to format strings.
+type Catalog map[ID]string
+
+var defaults = Catalog{
+	ExecrunPrefix: "[execrun]",
+	RunctlPrefix:  "[runctl]",
+	TargetPrefix:  "[%s]",
+	ErrorLabel:    "Error:",
+	WarningLabel:  "Warning:",
+
+	Executing:          "Executing...",
+	DoneIn:             "Done in %s",
+	Started:            "Started (pid %d).",
+	InitialBuildFailed: "Initial build failed: %v",
+	InitialStartFailed: "Initial start failed: %v",
+	WatchingForChanges: "Watching for file changes...",
+	ChangesDetected:    "Changes detected:",
+	ChangeModified:     "  modified: %s",
+	ChangeAdded:        "  added:    %s",
+	ChangeRemoved:      "  removed:  %s",
+	Rebuilding:         "Rebuilding...",
+	Restarting:         "Restarting without rebuilding...",
+	BuildTriggered:     "Build triggered...",
+	ReloadFileTouched:  "%s touched.",
+	LockTakenOver:      "Taking over from the instance with pid %d.",
+	OutputDropped:      "Output buffer full: dropped %s of %s output.",
+	BuildCancelled:     "Build cancelled, newer changes pending.",
+	BuildFailed:        "Build failed: %v",
+	KeepingPrevious:    "Keeping previous process running.",
+	BuildDone:          "Build done (pid %d, %s).",
+	BuildModeStart:     "Build mode: executing all commands...",
+	BuildModeDone:      "Build done in %s. Watching for changes...",
+	BuildDoneIn:        "Build done in %s",
+	TestsTriggered:     "Tests triggered...",
+	TestsFailed:        "Tests failed: %v",
+	TestsDone:          "Tests done (%s).",
+	TestsDoneIn:        "Tests done in %s",
+	Reloading:          "Reloading: sent %s to pid %d.",
+	ReloadFailed:       "Reload failed: %v",
+	StoppingProcess:    "Stopping process...",
+	WatchPaused:        "Watching paused; changes are picked up on resume.",
+	WatchResumed:       "Watching resumed.",
+	StartingProcess:    "Starting process...",
+	StartFailed:        "Start failed: %v",
+	ExitedWithCode:     "Exited with code %d. Waiting for file changes...",
+	Completed:          "Completed. Waiting for file changes...",
+	ShuttingDown:       "Shutting down...",
+	SIGKILLWarning:     "Process group didn't exit after SIGTERM, sending SIGKILL...",
+
+	LogRunning:        "Running: %s",
+	LogCommandFailed:  "Command failed: %s",
+	LogCommandCancel:  "Command cancelled",
+	LogBuildDone:      "Build done (%s)",
+	LogTestsDone:      "Tests done (%s)",
+	LogStartFailed:    "Start failed: %s",
+	LogProcessStarted: "Process started (pid %d): %s",
+	LogProcessExited:  "Process exited (code %d)",
+	LogReloading:      "Sending %s to process (pid %d)",
+	LogStopping:       "Stopping process (pid %d, SIGTERM)",
+	LogStopped:        "Process stopped",
+	LogSIGKILL:        "Process didn't exit after SIGTERM, sending SIGKILL",
+	LogKilled:         "Process killed",
+}
+
+var (
+	mu        sync.RWMutex
+	overrides = Catalog{}
+)
+
+// Defaults returns a copy of the built-in catalog.
+func Defaults() Catalog {
+	return maps.Clone(defaults)
+}
+
+// Set overrides the text of the messages in c, on top of earlier calls.
+// Each text must take the same arguments as the message it replaces.
+func Set(c Catalog) {
+	mu.Lock()
+	defer mu.Unlock()
+	maps.Copy(overrides, c)
+}
+
+// Reset drops all overrides.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	overrides = Catalog{}
+}
+
+// Get returns the format string of id. An unknown ID returns itself.
+func Get(id ID) string {
+	mu.RLock()
+	text, ok := overrides[id]
+	mu.RUnlock()
+	if ok {
+		return text
+	}
+	if text, ok := defaults[id]; ok {
+		return text
+	}
+	return string(id)
+}
+
+// Sprintf formats message id with args.
+func Sprintf(id ID, args ...any) string {
+	if len(args) == 0 {
+		return Get(id)
+	}
+	return fmt.Sprintf(Get(id), args...)
+}
@@ -0,0 +1,44 @@
+package messages_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/messages"
+)
+
+var _ = Describe("Messages", func() {
+	AfterEach(func() {
+		messages.Reset()
+	})
+
+	It("returns the built-in text by default", func() {
+		Expect(messages.Get(messages.ExecrunPrefix)).To(Equal("[execrun]"))
+		Expect(messages.Sprintf(messages.Started, 42)).To(Equal("Started (pid 42)."))
+	})
+
+	It("overrides only the messages that are set", func() {
+		messages.Set(messages.Catalog{messages.ExecrunPrefix: "[acme-dev]"})
+		messages.Set(messages.Catalog{messages.Started: "Up, pid %d"})
+
+		Expect(messages.Get(messages.ExecrunPrefix)).To(Equal("[acme-dev]"))
+		Expect(messages.Sprintf(messages.Started, 7)).To(Equal("Up, pid 7"))
+		Expect(messages.Get(messages.RunctlPrefix)).To(Equal("[runctl]"))
+	})
+
+	It("restores the defaults on Reset", func() {
+		messages.Set(messages.Catalog{messages.ShuttingDown: "Bye"})
+		messages.Reset()
+		Expect(messages.Get(messages.ShuttingDown)).To(Equal("Shutting down..."))
+	})
+
+	It("returns an unknown ID as its own text", func() {
+		Expect(messages.Get("no_such_message")).To(Equal("no_such_message"))
+	})
+
+	It("does not let callers modify the defaults", func() {
+		d := messages.Defaults()
+		d[messages.Rebuilding] = "changed"
+		Expect(messages.Get(messages.Rebuilding)).To(Equal("Rebuilding..."))
+	})
+})
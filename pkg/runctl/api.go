@@ -1,47 +1,81 @@
 package runctl
 
 import (
+	"compress/gzip"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"net/http/httputil"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+
+	"github.com/gur-shatz/go-run/internal/buildinfo"
 )
 
 // Routes returns a chi.Router with all API routes mounted.
-// Caller mounts it at any prefix: mainRouter.Mount("/api", ctrl.Routes())
+// Caller mounts it at the configured prefix:
+// mainRouter.Mount(cfg.API.APIPrefix(), ctrl.Routes())
 func (this *Controller) Routes() chi.Router {
 	r := chi.NewRouter()
 
 	r.Get("/health", this.handleHealth)
+	r.Get("/ready", this.handleReady)
 	r.Get("/overview", this.handleOverview)
+	r.Get("/info", this.handleInfo)
+	r.Get("/resources", this.handleResources)
 	r.Get("/targets", this.handleListTargets)
+	r.Post("/targets/batch", this.handleBatch)
 	r.Get("/targets/{name}", this.handleGetTarget)
 	r.Post("/targets/{name}/build", this.handleBuildTarget)
 	r.Post("/targets/{name}/test", this.handleTestTarget)
 	r.Post("/targets/{name}/start", this.handleStartExec)
 	r.Post("/targets/{name}/stop", this.handleStopExec)
 	r.Post("/targets/{name}/restart", this.handleRestartTarget)
+	r.Post("/targets/{name}/watch/pause", this.handlePauseWatch)
+	r.Post("/targets/{name}/watch/resume", this.handleResumeWatch)
 	r.Post("/targets/{name}/enable", this.handleEnableTarget)
 	r.Post("/targets/{name}/disable", this.handleDisableTarget)
+	r.Post("/targets/{name}/exec", this.handleExec)
+	r.Get("/targets/{name}/badge.svg", this.handleBadge)
 	r.Get("/targets/{name}/logs", this.handleGetLogs)
+	r.Get("/targets/{name}/logs/download", this.handleDownloadLogs)
 	r.Post("/targets/{name}/logs/marker", this.handleInsertLogMarker)
 	r.HandleFunc("/targets/{name}/backoffice/*", this.handleBackofficeProxy)
 	r.Get("/file", this.handleServeFile)
+	r.Get("/events", this.handleEvents)
+	r.Get("/ws", this.handleWS)
+	r.Get("/openapi.json", this.handleOpenAPI)
+	r.Get("/docs", this.handleDocs)
 
 	return r
 }
 
 func (this *Controller) handleHealth(w http.ResponseWriter, r *http.Request) {
-	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status":  "ok",
+		"version": buildinfo.Version,
+		"commit":  buildinfo.Commit,
+		"date":    buildinfo.Date,
+	})
 }
 
 func (this *Controller) handleOverview(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, this.Overview())
 }
 
+func (this *Controller) handleInfo(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, this.StateInfo())
+}
+
 func (this *Controller) handleListTargets(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, this.Status())
 }
@@ -59,11 +93,7 @@ func (this *Controller) handleGetTarget(w http.ResponseWriter, r *http.Request)
 func (this *Controller) handleBuildTarget(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
 	if err := this.BuildTarget(name); err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeError(w, http.StatusNotFound, err.Error())
-		} else {
-			writeError(w, http.StatusBadRequest, err.Error())
-		}
+		writeTargetError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]string{"status": "building"})
@@ -72,11 +102,7 @@ func (this *Controller) handleBuildTarget(w http.ResponseWriter, r *http.Request
 func (this *Controller) handleTestTarget(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
 	if err := this.TestTarget(name); err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeError(w, http.StatusNotFound, err.Error())
-		} else {
-			writeError(w, http.StatusBadRequest, err.Error())
-		}
+		writeTargetError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]string{"status": "testing"})
@@ -85,11 +111,7 @@ func (this *Controller) handleTestTarget(w http.ResponseWriter, r *http.Request)
 func (this *Controller) handleStartExec(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
 	if err := this.StartExec(name); err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeError(w, http.StatusNotFound, err.Error())
-		} else {
-			writeError(w, http.StatusBadRequest, err.Error())
-		}
+		writeTargetError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]string{"status": "started"})
@@ -98,24 +120,34 @@ func (this *Controller) handleStartExec(w http.ResponseWriter, r *http.Request)
 func (this *Controller) handleStopExec(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
 	if err := this.StopExec(name); err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeError(w, http.StatusNotFound, err.Error())
-		} else {
-			writeError(w, http.StatusBadRequest, err.Error())
-		}
+		writeTargetError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]string{"status": "stopped"})
 }
 
+func (this *Controller) handlePauseWatch(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if err := this.PauseWatch(name); err != nil {
+		writeTargetError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "paused"})
+}
+
+func (this *Controller) handleResumeWatch(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if err := this.ResumeWatch(name); err != nil {
+		writeTargetError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "resumed"})
+}
+
 func (this *Controller) handleRestartTarget(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
 	if err := this.BuildTarget(name); err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeError(w, http.StatusNotFound, err.Error())
-		} else {
-			writeError(w, http.StatusBadRequest, err.Error())
-		}
+		writeTargetError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]string{"status": "restarting"})
@@ -124,11 +156,7 @@ func (this *Controller) handleRestartTarget(w http.ResponseWriter, r *http.Reque
 func (this *Controller) handleEnableTarget(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
 	if err := this.EnableTarget(name); err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeError(w, http.StatusNotFound, err.Error())
-		} else {
-			writeError(w, http.StatusBadRequest, err.Error())
-		}
+		writeTargetError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]string{"status": "enabled"})
@@ -153,32 +181,13 @@ func (this *Controller) handleGetLogs(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusNotFound, "target not found")
 		return
 	}
-
-	stage := r.URL.Query().Get("stage")
-	if stage == "" {
-		stage = "run"
-	}
-	if stage != "build" && stage != "test" && stage != "run" {
-		writeError(w, http.StatusBadRequest, "stage must be build, test, or run")
+	if t.remote != nil {
+		t.remote.proxy(w, r, "logs")
 		return
 	}
 
-	if t.tcfg.Logs == nil {
-		writeError(w, http.StatusBadRequest, "no logs configured for this target")
-		return
-	}
-
-	var path string
-	switch stage {
-	case "build":
-		path = t.tcfg.Logs.Build
-	case "test":
-		path = t.tcfg.Logs.Test
-	case "run":
-		path = t.tcfg.Logs.Run
-	}
-	if path == "" {
-		writeError(w, http.StatusBadRequest, "no "+stage+" log configured for this target")
+	path, ok := stageLogPath(w, r, t)
+	if !ok {
 		return
 	}
 
@@ -235,7 +244,7 @@ func (this *Controller) handleGetLogs(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func (this *Controller) handleInsertLogMarker(w http.ResponseWriter, r *http.Request) {
+func (this *Controller) handleDownloadLogs(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
 
 	this.mu.RLock()
@@ -245,32 +254,110 @@ func (this *Controller) handleInsertLogMarker(w http.ResponseWriter, r *http.Req
 		writeError(w, http.StatusNotFound, "target not found")
 		return
 	}
+	if t.remote != nil {
+		t.remote.proxy(w, r, "logs/download")
+		return
+	}
+
+	path, ok := stageLogPath(w, r, t)
+	if !ok {
+		return
+	}
+
+	// Rotated segments, oldest first, then the live file. Each is cut at
+	// its size when opened, so the length holds while the target writes.
+	var (
+		readers []io.Reader
+		size    int64
+		modTime time.Time
+	)
+	for _, seg := range logSegments(path) {
+		f, err := os.Open(seg)
+		if errors.Is(err, os.ErrNotExist) && seg != path {
+			continue // rotated away since it was listed
+		}
+		if errors.Is(err, os.ErrNotExist) {
+			writeError(w, http.StatusNotFound, "log file does not exist yet")
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		readers = append(readers, io.NewSectionReader(f, 0, info.Size()))
+		size += info.Size()
+		modTime = info.ModTime()
+	}
+	body := io.MultiReader(readers...)
+
+	filename := filepath.Base(path)
+	if gz, _ := strconv.ParseBool(r.URL.Query().Get("gzip")); gz {
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename + ".gz"}))
+		zw := gzip.NewWriter(w)
+		zw.Name = filename
+		zw.ModTime = modTime
+		io.Copy(zw, body)
+		zw.Close()
+		return
+	}
 
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	w.Header().Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	io.Copy(w, body)
+}
+
+// stageLogPath returns the log file for the request's stage param (build,
+// test, or run; default run). On a bad stage or a target without that log it
+// writes a 400 and returns false.
+func stageLogPath(w http.ResponseWriter, r *http.Request, t *target) (string, bool) {
 	stage := r.URL.Query().Get("stage")
 	if stage == "" {
 		stage = "run"
 	}
 	if stage != "build" && stage != "test" && stage != "run" {
 		writeError(w, http.StatusBadRequest, "stage must be build, test, or run")
-		return
+		return "", false
 	}
 
 	if t.tcfg.Logs == nil {
 		writeError(w, http.StatusBadRequest, "no logs configured for this target")
-		return
+		return "", false
 	}
 
-	var path string
-	switch stage {
-	case "build":
-		path = t.tcfg.Logs.Build
-	case "test":
-		path = t.tcfg.Logs.Test
-	case "run":
-		path = t.tcfg.Logs.Run
-	}
+	path := t.tcfg.Logs.Path(stage)
 	if path == "" {
 		writeError(w, http.StatusBadRequest, "no "+stage+" log configured for this target")
+		return "", false
+	}
+	return path, true
+}
+
+func (this *Controller) handleInsertLogMarker(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	this.mu.RLock()
+	t, ok := this.targets[name]
+	this.mu.RUnlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "target not found")
+		return
+	}
+	if t.remote != nil {
+		t.remote.proxy(w, r, "logs/marker")
+		return
+	}
+
+	path, ok := stageLogPath(w, r, t)
+	if !ok {
 		return
 	}
 
@@ -297,6 +384,67 @@ func (this *Controller) handleServeFile(w http.ResponseWriter, r *http.Request)
 	http.ServeFile(w, r, filePath)
 }
 
+// eventsKeepalive is how often an idle event stream sends a comment line so
+// proxies don't close it.
+const eventsKeepalive = 15 * time.Second
+
+// handleEvents streams target events as Server-Sent Events. The stream starts
+// with the recent history (after Last-Event-ID, if the client sends one) and
+// then follows live events. ?target=name limits it to one target.
+func (this *Controller) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+	target := r.URL.Query().Get("target")
+	lastID, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+
+	replay, events, cancel := this.events.subscribe()
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(e Event) error {
+		if e.ID <= lastID || (target != "" && e.Target != target) {
+			return nil
+		}
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Event, data)
+		return err
+	}
+
+	for _, e := range replay {
+		if err := send(e); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepalive := time.NewTicker(eventsKeepalive)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-events:
+			if err := send(e); err != nil {
+				return
+			}
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
 func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -307,6 +455,40 @@ func writeError(w http.ResponseWriter, status int, msg string) {
 	writeJSON(w, status, map[string]string{"error": msg})
 }
 
+// RequireToken returns middleware that rejects requests without
+// "Authorization: Bearer <token>" with 401. runctl agent serves its whole
+// API behind it, since the controlling runctl reaches it over the network.
+func RequireToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !hasBearerToken(r, token) {
+				writeError(w, http.StatusUnauthorized, "requests need \"Authorization: Bearer <api.token>\"")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// hasBearerToken reports whether r carries token as its bearer token.
+func hasBearerToken(r *http.Request, token string) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && token != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// writeTargetError writes a target action's error: 404 for an unknown
+// target, 409 for one that is already running, else 400.
+func writeTargetError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrTargetNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrAlreadyRunning):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
 func (this *Controller) handleBackofficeProxy(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
 
@@ -317,7 +499,12 @@ func (this *Controller) handleBackofficeProxy(w http.ResponseWriter, r *http.Req
 		writeError(w, http.StatusNotFound, "target not found")
 		return
 	}
+	if t.remote != nil {
+		t.remote.proxy(w, r, "backoffice/"+chi.URLParam(r, "*"))
+		return
+	}
 
+	t.touch()
 	boClient := t.BackofficeClient()
 	if boClient == nil {
 		writeError(w, http.StatusServiceUnavailable, "backoffice not available")
@@ -0,0 +1,75 @@
+package runctl
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Badge colors, matching shields.io.
+const (
+	badgeGreen  = "#4c1"
+	badgeRed    = "#e05d44"
+	badgeYellow = "#dfb317"
+	badgeGrey   = "#9f9f9f"
+)
+
+// badgeStatus maps a target's status to a badge message and color.
+func badgeStatus(t TargetStatus) (string, string) {
+	switch {
+	case t.State == StateError || t.State == StateExited || t.State == StateCrashLoop,
+		t.Build.Result == "failed", t.Test.Result == "failed":
+		return "failing", badgeRed
+	case t.State == StateStarting:
+		return "building", badgeYellow
+	case t.State == StateRunning, t.Build.Result == "success":
+		return "passing", badgeGreen
+	case t.State == StateStopped, t.State == StateSuspended:
+		return "stopped", badgeGrey
+	default:
+		return "unknown", badgeGrey
+	}
+}
+
+// badgeTextWidth approximates the rendered width of s in 11px Verdana.
+func badgeTextWidth(s string) int {
+	return len(s)*7 + 10
+}
+
+// renderBadge returns a flat shields-style SVG badge.
+func renderBadge(label, message, color string) []byte {
+	lw, mw := badgeTextWidth(label), badgeTextWidth(message)
+	label, message = html.EscapeString(label), html.EscapeString(message)
+	return fmt.Appendf(nil, `<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">
+<title>%[4]s: %[5]s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text><text x="%[7]d" y="14">%[4]s</text>
+<text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[5]s</text><text x="%[8]d" y="14">%[5]s</text>
+</g>
+</svg>
+`, lw+mw, lw, mw, label, message, color, lw/2, lw+mw/2)
+}
+
+func (this *Controller) handleBadge(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	st, err := this.TargetStatus(name)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	label := r.URL.Query().Get("label")
+	if label == "" {
+		label = name
+	}
+	message, color := badgeStatus(*st)
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Write(renderBadge(label, message, color))
+}
@@ -0,0 +1,73 @@
+package runctl_test
+
+import (
+	"io"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("Badge", func() {
+	getBadge := func(ctrl *runctl.Controller, path string) (int, string, string) {
+		server := serveAPI(ctrl)
+		defer server.Close()
+		resp, err := http.Get(server.URL + path)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp.StatusCode, resp.Header.Get("Content-Type"), string(body)
+	}
+
+	newController := func(cmd string) *runctl.Controller {
+		ctrl, err := runctl.New(runctl.Config{
+			API: runctl.APIConfig{Port: 9100},
+			Targets: map[string]runctl.TargetConfig{
+				"app": {Type: runctl.TargetTypeCommand, Cmd: cmd},
+			},
+		}, GinkgoT().TempDir(), false)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(ctrl.KillTargets)
+		return ctrl
+	}
+
+	It("renders an SVG with the target name and status", func() {
+		ctrl := newController("sleep 60")
+		code, contentType, body := getBadge(ctrl, "/api/targets/app/badge.svg")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(contentType).To(Equal("image/svg+xml"))
+		Expect(body).To(HavePrefix("<svg"))
+		Expect(body).To(ContainSubstring("app: unknown"))
+
+		ctrl.StartTargets()
+		Eventually(func() string {
+			_, _, body := getBadge(ctrl, "/api/targets/app/badge.svg?label=dev+api")
+			return body
+		}, "5s", "20ms").Should(ContainSubstring("dev api: passing"))
+	})
+
+	It("shows failing when the process crashes", func() {
+		ctrl := newController("false")
+		ctrl.StartTargets()
+		Eventually(func() string {
+			_, _, body := getBadge(ctrl, "/api/targets/app/badge.svg")
+			return body
+		}, "5s", "20ms").Should(ContainSubstring("app: failing"))
+	})
+
+	It("escapes the label", func() {
+		ctrl := newController("sleep 60")
+		_, _, body := getBadge(ctrl, "/api/targets/app/badge.svg?label=%3Cb%3E")
+		Expect(body).To(ContainSubstring("&lt;b&gt;: unknown"))
+		Expect(body).NotTo(ContainSubstring("<b>"))
+	})
+
+	It("returns 404 for an unknown target", func() {
+		ctrl := newController("sleep 60")
+		code, _, _ := getBadge(ctrl, "/api/targets/nope/badge.svg")
+		Expect(code).To(Equal(http.StatusNotFound))
+	})
+})
@@ -0,0 +1,49 @@
+package runctl_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("Base path", func() {
+	load := func(basePath string) *runctl.Config {
+		dir := GinkgoT().TempDir()
+		path := filepath.Join(dir, "runctl.yaml")
+		Expect(os.WriteFile(path, []byte(`
+api:
+  base_path: "`+basePath+`"
+targets:
+  app:
+    type: command
+    cmd: sleep 60
+    links:
+      - name: readme
+        file: README.md
+`), 0644)).To(Succeed())
+		cfg, err := runctl.LoadConfig(path)
+		Expect(err).NotTo(HaveOccurred())
+		return cfg
+	}
+
+	It("normalizes the prefix", func() {
+		Expect(load("dev-dashboard/").API.BasePath).To(Equal("/dev-dashboard"))
+		Expect(load("/").API.BasePath).To(BeEmpty())
+		Expect(load("").API.APIPrefix()).To(Equal("/api"))
+	})
+
+	It("puts file links under the prefixed API", func() {
+		cfg := load("/dev-dashboard")
+		Expect(cfg.API.APIPrefix()).To(Equal("/dev-dashboard/api"))
+
+		ctrl, err := runctl.New(*cfg, GinkgoT().TempDir(), false)
+		Expect(err).NotTo(HaveOccurred())
+		status, err := ctrl.TargetStatus("app")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Links[0].ResolvedURL).To(HavePrefix("/dev-dashboard/api/file?path="))
+	})
+})
@@ -0,0 +1,103 @@
+package runctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// batchActions maps a batch action to the controller method behind the
+// matching /targets/{name}/<action> endpoint.
+var batchActions = map[string]func(*Controller, string) error{
+	"build":   (*Controller).BuildTarget,
+	"test":    (*Controller).TestTarget,
+	"start":   (*Controller).StartExec,
+	"stop":    (*Controller).StopExec,
+	"restart": (*Controller).BuildTarget,
+	"enable":  (*Controller).EnableTarget,
+	"disable": (*Controller).DisableTarget,
+}
+
+// BatchRequest is the body of POST /api/targets/batch. Targets is a list of
+// names or the string "all".
+type BatchRequest struct {
+	Action  string          `json:"action"`
+	Targets json.RawMessage `json:"targets"`
+}
+
+// BatchResult reports the outcome per target: "ok" or the error message.
+type BatchResult struct {
+	Action  string            `json:"action"`
+	Results map[string]string `json:"results"`
+}
+
+// Batch applies action to the named targets, or to all targets if names is
+// nil. Every name is checked before any target is touched, so an unknown
+// name or action changes nothing.
+func (this *Controller) Batch(action string, names []string) (*BatchResult, error) {
+	fn, ok := batchActions[action]
+	if !ok {
+		return nil, fmt.Errorf("unknown action %q (use %s)", action, strings.Join(slices.Sorted(maps.Keys(batchActions)), ", "))
+	}
+
+	this.mu.RLock()
+	if names == nil {
+		names = slices.Sorted(maps.Keys(this.targets))
+	}
+	for _, name := range names {
+		if _, ok := this.targets[name]; !ok {
+			this.mu.RUnlock()
+			return nil, fmt.Errorf("target %q %w", name, ErrTargetNotFound)
+		}
+	}
+	this.mu.RUnlock()
+
+	res := &BatchResult{Action: action, Results: make(map[string]string, len(names))}
+	for _, name := range names {
+		if err := fn(this, name); err != nil {
+			res.Results[name] = err.Error()
+		} else {
+			res.Results[name] = "ok"
+		}
+	}
+	return res, nil
+}
+
+// parseBatchTargets decodes the targets field: a list of names or "all"
+// (returned as nil).
+func parseBatchTargets(raw json.RawMessage) ([]string, error) {
+	var all string
+	if err := json.Unmarshal(raw, &all); err == nil {
+		if all != "all" {
+			return nil, fmt.Errorf(`targets must be a list of names or "all"`)
+		}
+		return nil, nil
+	}
+	var names []string
+	if err := json.Unmarshal(raw, &names); err != nil || len(names) == 0 {
+		return nil, fmt.Errorf(`targets must be a list of names or "all"`)
+	}
+	return names, nil
+}
+
+func (this *Controller) handleBatch(w http.ResponseWriter, r *http.Request) {
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	names, err := parseBatchTargets(req.Targets)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	res, err := this.Batch(req.Action, names)
+	if err != nil {
+		writeTargetError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
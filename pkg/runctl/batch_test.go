@@ -0,0 +1,91 @@
+package runctl_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("Batch operations", func() {
+	var (
+		ctrl *runctl.Controller
+		url  string
+	)
+
+	BeforeEach(func() {
+		var err error
+		ctrl, err = runctl.New(runctl.Config{
+			API: runctl.APIConfig{Port: 9100},
+			Targets: map[string]runctl.TargetConfig{
+				"api":    {Type: runctl.TargetTypeCommand, Cmd: "sleep 60"},
+				"web":    {Type: runctl.TargetTypeCommand, Cmd: "sleep 60"},
+				"worker": {Type: runctl.TargetTypeCommand, Cmd: "sleep 60"},
+			},
+		}, GinkgoT().TempDir(), false)
+		Expect(err).NotTo(HaveOccurred())
+		ctrl.StartTargets()
+		DeferCleanup(ctrl.KillTargets)
+
+		server := serveAPI(ctrl)
+		DeferCleanup(server.Close)
+		url = server.URL + "/api/targets/batch"
+	})
+
+	post := func(body string) (int, map[string]any) {
+		resp, err := http.Post(url, "application/json", strings.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		var out map[string]any
+		Expect(json.NewDecoder(resp.Body).Decode(&out)).To(Succeed())
+		return resp.StatusCode, out
+	}
+
+	enabled := func(name string) bool {
+		st, err := ctrl.TargetStatus(name)
+		Expect(err).NotTo(HaveOccurred())
+		return st.Enabled
+	}
+
+	It("applies an action to the listed targets", func() {
+		code, out := post(`{"action": "disable", "targets": ["api", "web"]}`)
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(out["results"]).To(Equal(map[string]any{"api": "ok", "web": "ok"}))
+		Expect(enabled("api")).To(BeFalse())
+		Expect(enabled("web")).To(BeFalse())
+		Expect(enabled("worker")).To(BeTrue())
+	})
+
+	It("applies an action to all targets", func() {
+		code, out := post(`{"action": "restart", "targets": "all"}`)
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(out["results"]).To(HaveLen(3))
+	})
+
+	It("changes nothing when a target is unknown", func() {
+		code, out := post(`{"action": "disable", "targets": ["api", "nope"]}`)
+		Expect(code).To(Equal(http.StatusNotFound))
+		Expect(out["error"]).To(ContainSubstring(`"nope" not found`))
+		Expect(enabled("api")).To(BeTrue())
+	})
+
+	It("returns sentinel errors for unknown and running targets", func() {
+		_, err := ctrl.Batch("build", []string{"nope"})
+		Expect(err).To(MatchError(runctl.ErrTargetNotFound))
+		Expect(ctrl.StartTarget("api")).To(MatchError(runctl.ErrAlreadyRunning))
+	})
+
+	It("rejects unknown actions and malformed targets", func() {
+		code, out := post(`{"action": "explode", "targets": "all"}`)
+		Expect(code).To(Equal(http.StatusBadRequest))
+		Expect(out["error"]).To(ContainSubstring(`unknown action "explode"`))
+
+		code, out = post(`{"action": "build", "targets": "some"}`)
+		Expect(code).To(Equal(http.StatusBadRequest))
+		Expect(out["error"]).To(ContainSubstring(`"all"`))
+	})
+})
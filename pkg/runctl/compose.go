@@ -0,0 +1,327 @@
+package runctl
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/shlex"
+	"gopkg.in/yaml.v3"
+)
+
+// ComposeImport is the result of converting a docker-compose file.
+type ComposeImport struct {
+	Targets  map[string]TargetConfig
+	Warnings []string // compose settings with no runctl equivalent, dropped
+}
+
+// composeFile is the subset of the compose spec the importer understands.
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	Image       string       `yaml:"image"`
+	Build       composeBuild `yaml:"build"`
+	Command     stringOrList `yaml:"command"`
+	Entrypoint  stringOrList `yaml:"entrypoint"`
+	Environment mapOrList    `yaml:"environment"`
+	EnvFile     stringOrList `yaml:"env_file"`
+	Ports       shortList    `yaml:"ports"`
+	Volumes     shortList    `yaml:"volumes"`
+	WorkingDir  string       `yaml:"working_dir"`
+	User        string       `yaml:"user"`
+	Profiles    []string     `yaml:"profiles"`
+
+	// Everything else, reported as dropped.
+	Rest map[string]yaml.Node `yaml:",inline"`
+}
+
+// composeBuild is `build:` in either its short (context path) or long form.
+type composeBuild struct {
+	Context    string            `yaml:"context"`
+	Dockerfile string            `yaml:"dockerfile"`
+	Args       map[string]string `yaml:"args"`
+}
+
+func (this *composeBuild) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		this.Context = node.Value
+		return nil
+	}
+	type plain composeBuild
+	return node.Decode((*plain)(this))
+}
+
+// stringOrList holds a compose value that is either a string or a list.
+// Split is how compose treats the string form of command and entrypoint.
+type stringOrList struct {
+	str  string
+	list []string
+	set  bool
+}
+
+func (this *stringOrList) UnmarshalYAML(node *yaml.Node) error {
+	this.set = true
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&this.str)
+	}
+	return node.Decode(&this.list)
+}
+
+func (this stringOrList) Split() ([]string, error) {
+	if this.list != nil || this.str == "" {
+		return this.list, nil
+	}
+	return shlex.Split(this.str)
+}
+
+func (this stringOrList) Values() []string {
+	if this.list != nil {
+		return this.list
+	}
+	if this.str != "" {
+		return []string{this.str}
+	}
+	return nil
+}
+
+// shortList holds the short-syntax (string) entries of ports: or volumes:
+// and counts the long-syntax (mapping) entries it skipped.
+type shortList struct {
+	values  []string
+	skipped int
+}
+
+func (this *shortList) UnmarshalYAML(node *yaml.Node) error {
+	var nodes []yaml.Node
+	if err := node.Decode(&nodes); err != nil {
+		return err
+	}
+	for _, n := range nodes {
+		if n.Kind != yaml.ScalarNode {
+			this.skipped++
+			continue
+		}
+		this.values = append(this.values, n.Value)
+	}
+	return nil
+}
+
+// mapOrList holds `environment:` as a map or a list of KEY=VALUE entries,
+// normalized to the list form. A key without a value passes the variable
+// through from runctl's environment.
+type mapOrList []string
+
+func (this *mapOrList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		return node.Decode((*[]string)(this))
+	}
+	var m map[string]*string
+	if err := node.Decode(&m); err != nil {
+		return err
+	}
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		if m[k] == nil {
+			*this = append(*this, k)
+		} else {
+			*this = append(*this, k+"="+*m[k])
+		}
+	}
+	return nil
+}
+
+// composeVar matches compose's ${VAR}, ${VAR:-default}, ${VAR-default}, and
+// the $$ escape.
+var composeVar = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(:?-([^}]*))?\}`)
+
+// interpolate turns compose variable references into runctl template calls,
+// so they are still resolved from the environment when runctl.yaml loads.
+func interpolate(s string) string {
+	return composeVar.ReplaceAllStringFunc(s, func(m string) string {
+		if m == "$$" {
+			return "$"
+		}
+		sub := composeVar.FindStringSubmatch(m)
+		if sub[2] != "" {
+			return fmt.Sprintf(`{{ env %q | default %q }}`, sub[1], sub[3])
+		}
+		return fmt.Sprintf(`{{ env %q }}`, sub[1])
+	})
+}
+
+// ImportCompose converts the services of the docker-compose file at path into
+// runctl targets for a runctl.yaml in baseDir. Services with a build: section
+// become docker targets; image-only services become command targets running
+// `docker run`. Relative paths are rebased onto each target's directory.
+func ImportCompose(path, baseDir string) (*ComposeImport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cf composeFile
+	if err := yaml.Unmarshal(data, &cf); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(cf.Services) == 0 {
+		return nil, fmt.Errorf("%s: no services", path)
+	}
+	composeDir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	baseDir, err = filepath.Abs(baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ComposeImport{Targets: make(map[string]TargetConfig)}
+	for _, name := range slices.Sorted(maps.Keys(cf.Services)) {
+		tcfg, warnings, err := importService(name, cf.Services[name], composeDir, baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("service %q: %w", name, err)
+		}
+		result.Targets[name] = tcfg
+		for _, w := range warnings {
+			result.Warnings = append(result.Warnings, name+": "+w)
+		}
+	}
+	return result, nil
+}
+
+func importService(name string, svc composeService, composeDir, baseDir string) (TargetConfig, []string, error) {
+	var warnings []string
+	for _, key := range slices.Sorted(maps.Keys(svc.Rest)) {
+		switch key {
+		case "container_name", "restart":
+			// runctl names and restarts containers itself
+		default:
+			warnings = append(warnings, key+" is not supported, dropped")
+		}
+	}
+	if len(svc.Build.Args) > 0 {
+		warnings = append(warnings, "build.args is not supported, dropped")
+	}
+	if svc.Ports.skipped > 0 {
+		warnings = append(warnings, "long-syntax ports are not supported, dropped")
+	}
+	if svc.Volumes.skipped > 0 {
+		warnings = append(warnings, "long-syntax volumes are not supported, dropped")
+	}
+	if svc.Image == "" && svc.Build.Context == "" {
+		return TargetConfig{}, nil, fmt.Errorf("needs image or build")
+	}
+
+	// The directory the target's docker commands run in, which relative
+	// host paths must be rebased onto.
+	workDir := baseDir
+	var context string
+	if svc.Build.Context != "" {
+		workDir = resolveFrom(composeDir, svc.Build.Context)
+		context = relPath(baseDir, workDir)
+	}
+
+	var runArgs []string
+	for _, f := range svc.EnvFile.Values() {
+		runArgs = append(runArgs, "--env-file", relPath(workDir, resolveFrom(composeDir, interpolate(f))))
+	}
+	for _, e := range svc.Environment {
+		runArgs = append(runArgs, "-e", interpolate(e))
+	}
+	for _, p := range svc.Ports.values {
+		runArgs = append(runArgs, "-p", interpolate(p))
+	}
+	for _, v := range svc.Volumes.values {
+		runArgs = append(runArgs, "-v", rebaseVolume(interpolate(v), composeDir, workDir))
+	}
+	if svc.WorkingDir != "" {
+		runArgs = append(runArgs, "-w", interpolate(svc.WorkingDir))
+	}
+	if svc.User != "" {
+		runArgs = append(runArgs, "-u", interpolate(svc.User))
+	}
+
+	// docker run --entrypoint takes only the executable; the rest of a
+	// compose entrypoint goes in front of the command.
+	entrypoint, err := svc.Entrypoint.Split()
+	if err != nil {
+		return TargetConfig{}, nil, fmt.Errorf("entrypoint: %w", err)
+	}
+	var args []string
+	if svc.Entrypoint.set {
+		first := ""
+		if len(entrypoint) > 0 {
+			first, args = entrypoint[0], slices.Clone(entrypoint[1:])
+		}
+		runArgs = append(runArgs, "--entrypoint", interpolate(first))
+	}
+	command, err := svc.Command.Split()
+	if err != nil {
+		return TargetConfig{}, nil, fmt.Errorf("command: %w", err)
+	}
+	args = append(args, command...)
+	for i, a := range args {
+		args[i] = interpolate(a)
+	}
+
+	tcfg := TargetConfig{}
+	if len(svc.Profiles) > 0 {
+		// Compose only starts profiled services on request.
+		disabled := false
+		tcfg.Enabled = &disabled
+	}
+	if context != "" {
+		tcfg.Type = TargetTypeDocker
+		tcfg.Docker = &DockerConfig{
+			Context:    context,
+			Dockerfile: svc.Build.Dockerfile,
+			Image:      interpolate(svc.Image),
+			RunArgs:    runArgs,
+			Args:       args,
+		}
+		return tcfg, warnings, nil
+	}
+
+	run := []string{"docker", "run", "--rm", "--name", ContainerName("", name)}
+	run = append(run, runArgs...)
+	run = append(run, interpolate(svc.Image))
+	run = append(run, args...)
+	tcfg.Type = TargetTypeCommand
+	tcfg.Cmd = quoteArgs(run...)
+	return tcfg, warnings, nil
+}
+
+// resolveFrom returns path made absolute against dir.
+func resolveFrom(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// relPath returns target relative to dir in the ./ form docker expects for
+// bind mounts, or target itself if it can't be made relative.
+func relPath(dir, target string) string {
+	rel, err := filepath.Rel(dir, target)
+	if err != nil {
+		return target
+	}
+	if rel == "." || strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return "./" + rel
+}
+
+// rebaseVolume rewrites the host side of a short-syntax volume when it is a
+// relative bind mount. Named volumes and absolute paths are kept as is.
+func rebaseVolume(v, composeDir, workDir string) string {
+	host, rest, ok := strings.Cut(v, ":")
+	if !ok || !strings.HasPrefix(host, ".") {
+		return v
+	}
+	return relPath(workDir, filepath.Join(composeDir, host)) + ":" + rest
+}
@@ -0,0 +1,117 @@
+package runctl_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("ImportCompose", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	importFile := func(content string) *runctl.ComposeImport {
+		path := filepath.Join(dir, "compose", "docker-compose.yml")
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		result, err := runctl.ImportCompose(path, dir)
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	It("turns image-only services into docker run commands", func() {
+		result := importFile(`
+services:
+  db:
+    image: postgres:16
+    restart: always
+    environment:
+      POSTGRES_PASSWORD: dev
+      PGUSER:
+    ports: ["5432:5432"]
+    volumes:
+      - ./data:/var/lib/postgresql/data
+      - cache:/cache
+`)
+		Expect(result.Warnings).To(BeEmpty())
+		Expect(result.Targets["db"]).To(Equal(runctl.TargetConfig{
+			Type: runctl.TargetTypeCommand,
+			Cmd: "docker run --rm --name runctl-db -e PGUSER -e POSTGRES_PASSWORD=dev -p 5432:5432 " +
+				"-v ./compose/data:/var/lib/postgresql/data -v cache:/cache postgres:16",
+		}))
+	})
+
+	It("turns services with a build into docker targets", func() {
+		result := importFile(`
+services:
+  api:
+    build:
+      context: ./api
+      dockerfile: Dockerfile.dev
+    command: serve --addr ":${PORT:-8080}"
+    env_file: .env
+    volumes: ["../config:/config:ro"]
+`)
+		Expect(result.Targets["api"]).To(Equal(runctl.TargetConfig{
+			Type: runctl.TargetTypeDocker,
+			Docker: &runctl.DockerConfig{
+				Context:    "./compose/api",
+				Dockerfile: "Dockerfile.dev",
+				RunArgs:    []string{"--env-file", "../.env", "-v", "../../config:/config:ro"},
+				Args:       []string{"serve", "--addr", `:{{ env "PORT" | default "8080" }}`},
+			},
+		}))
+	})
+
+	It("splits the entrypoint and keeps profiled services disabled", func() {
+		result := importFile(`
+services:
+  worker:
+    image: app
+    entrypoint: ["/bin/sh", "-c"]
+    command: ["echo $$HOME"]
+    profiles: [jobs]
+`)
+		tcfg := result.Targets["worker"]
+		Expect(tcfg.Cmd).To(Equal("docker run --rm --name runctl-worker --entrypoint /bin/sh app -c 'echo $HOME'"))
+		Expect(tcfg.Enabled).NotTo(BeNil())
+		Expect(*tcfg.Enabled).To(BeFalse())
+	})
+
+	It("warns about settings it drops", func() {
+		result := importFile(`
+services:
+  web:
+    build:
+      context: .
+      args: {VERSION: "1"}
+    depends_on: [db]
+    healthcheck: {test: ["CMD", "true"]}
+    ports:
+      - "8080:80"
+      - {target: 443, published: 8443}
+  db:
+    image: redis
+`)
+		Expect(result.Warnings).To(Equal([]string{
+			"web: depends_on is not supported, dropped",
+			"web: healthcheck is not supported, dropped",
+			"web: build.args is not supported, dropped",
+			"web: long-syntax ports are not supported, dropped",
+		}))
+	})
+
+	It("rejects services with neither image nor build", func() {
+		path := filepath.Join(dir, "docker-compose.yml")
+		Expect(os.WriteFile(path, []byte("services:\n  x:\n    command: true\n"), 0644)).To(Succeed())
+		_, err := runctl.ImportCompose(path, dir)
+		Expect(err).To(MatchError(ContainSubstring(`service "x": needs image or build`)))
+	})
+})
@@ -1,47 +1,170 @@
 package runctl
 
 import (
+	"cmp"
 	_ "embed"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/gur-shatz/go-run/internal/configutil"
+	"github.com/gur-shatz/go-run/internal/diskspace"
+	"github.com/gur-shatz/go-run/internal/procprio"
+	"github.com/gur-shatz/go-run/internal/schema"
 	"github.com/gur-shatz/go-run/pkg/config"
+	"github.com/gur-shatz/go-run/pkg/execrun"
 )
 
 // Config is the top-level runctl.yaml configuration.
 type Config struct {
 	Title             string                  `yaml:"title,omitempty"`
+	InstanceName      string                  `yaml:"instance_name,omitempty"` // namespaces containers, scratch dirs, and the default port
 	Description       string                  `yaml:"description,omitempty"`
 	Vars              map[string]string       `yaml:"vars,omitempty"`
+	Secrets           map[string]string       `yaml:"secrets,omitempty"`  // env vars for every target, redacted wherever shown
+	EnvFile           config.EnvFiles         `yaml:"env_file,omitempty"` // .env files exported to every target
+	Strict            bool                    `yaml:"strict,omitempty"`   // reject unknown fields and loose types, here and in target configs
 	API               APIConfig               `yaml:"api"`
 	LogsDir           string                  `yaml:"logs_dir,omitempty"`             // directory for auto-generated log files
 	LogsRotateOnStart *bool                   `yaml:"logs_rotate_on_start,omitempty"` // rename existing log files to *.<timestamp>.log on startup (default: true)
+	LogsRetention     string                  `yaml:"logs_retention,omitempty"`       // delete rotated log files older than this, e.g. 168h (default: keep)
+	LogsMaxSize       string                  `yaml:"logs_max_size,omitempty"`        // rotate a target log file once it reaches this size, e.g. 10MB (default: unbounded)
+	LogsMaxFiles      int                     `yaml:"logs_max_files,omitempty"`       // rotated segments kept per log file with logs_max_size (default: 3)
+	Heartbeat         execrun.Heartbeat       `yaml:"heartbeat,omitempty"`            // console heartbeat: false, or an interval like 30s (default: 10s)
+	Mask              []string                `yaml:"mask,omitempty"`                 // env var names/regexes whose values are redacted from output
+	Notifications     NotificationsConfig     `yaml:"notifications,omitempty"`        // webhooks called on target state changes
+	EventHistory      int                     `yaml:"event_history,omitempty"`        // events kept per target for replay (default: 20)
+	EventsFile        string                  `yaml:"events_file,omitempty"`          // append every event as a JSON line to this file, e.g. events.ndjson
+	MinFreeSpace      string                  `yaml:"min_free_space,omitempty"`       // default min_free_space for targets, e.g. 1GB
+	IdleTimeout       string                  `yaml:"idle_timeout,omitempty"`         // default idle_timeout for targets, e.g. 30m
+	Output            string                  `yaml:"output,omitempty"`               // default output for targets: prefixed or raw (default: raw)
+	OutputBuffer      string                  `yaml:"output_buffer,omitempty"`        // default output_buffer for targets, e.g. 1MB
+	Stats             *bool                   `yaml:"stats,omitempty"`                // record local usage stats for `runctl report` (default: true)
+	Ready             []string                `yaml:"ready,omitempty"`                // targets GET /api/ready checks (default: all enabled)
+	OnStart           []string                `yaml:"on_start,omitempty"`             // commands run in order before any target starts; a failure aborts startup
+	OnStop            []string                `yaml:"on_stop,omitempty"`              // commands run after all targets stop
+	CacheDir          string                  `yaml:"cache_dir,omitempty"`            // scratch root for sum files, build outputs and state (default: under the user cache dir)
 	Targets           map[string]TargetConfig `yaml:"targets"`
 
 	// ResolvedVars holds all resolved template variables (vars section + env).
 	// Populated by LoadConfig, not from YAML.
 	ResolvedVars map[string]string `yaml:"-"`
+
+	// ScratchDir is this checkout's directory under the user cache dir, or
+	// CacheDir, for sum files, build outputs and other intermediate files.
+	// Populated by LoadConfig/New.
+	ScratchDir string `yaml:"-"`
 }
 
 // APIConfig controls the HTTP API server.
 type APIConfig struct {
-	Port int `yaml:"port"`
+	Port      int    `yaml:"port"`
+	Host      string `yaml:"host,omitempty"`       // interface the HTTP and gRPC APIs listen on (default: 127.0.0.1)
+	GRPCPort  int    `yaml:"grpc_port,omitempty"`  // serve the gRPC API on this port (0: off)
+	BasePath  string `yaml:"base_path,omitempty"`  // path prefix when served behind a reverse proxy, e.g. /dev-dashboard
+	AllowExec bool   `yaml:"allow_exec,omitempty"` // serve POST /targets/{name}/exec (needs Token)
+	Token     string `yaml:"token,omitempty"`      // bearer token that exec requests, and all requests to an agent, must send
+}
+
+// DefaultAPIHost is the interface the APIs listen on when api.host is
+// unset, so only local processes can reach them.
+const DefaultAPIHost = "127.0.0.1"
+
+// APIPrefix returns the path the API is mounted at: BasePath + "/api".
+func (this APIConfig) APIPrefix() string {
+	return this.BasePath + "/api"
+}
+
+// ListenAddr returns the address to serve port on: Host, or
+// DefaultAPIHost, and port.
+func (this APIConfig) ListenAddr(port int) string {
+	return net.JoinHostPort(cmp.Or(this.Host, DefaultAPIHost), strconv.Itoa(port))
 }
 
+// NotificationsConfig lists the webhooks called when targets fail or recover.
+type NotificationsConfig struct {
+	Webhooks []WebhookConfig `yaml:"webhooks,omitempty"`
+	Retries  *int            `yaml:"retries,omitempty"` // extra delivery attempts on failure (default: 3)
+}
+
+// WebhookConfig is a single webhook endpoint.
+type WebhookConfig struct {
+	URL    string   `yaml:"url"`
+	Secret string   `yaml:"secret,omitempty"` // HMAC-SHA256 key; signs the body in X-Runctl-Signature
+	Events []string `yaml:"events,omitempty"` // events to send (default: failures and recoveries)
+}
+
+// Inline target types, defined entirely in runctl.yaml instead of in a
+// separate execrun config file.
+const (
+	TargetTypeCommand = "command" // a single managed process (cmd)
+	TargetTypeDocker  = "docker"  // a docker image built and run as a container
+)
+
 // TargetConfig describes a single managed target.
 type TargetConfig struct {
-	Config  string            `yaml:"config"` // path to config file (relative to runctl.yaml dir)
-	Enabled *bool             `yaml:"enabled,omitempty"`
-	Links   []Link            `yaml:"links,omitempty"`
-	Vars    map[string]string `yaml:"vars,omitempty"` // per-target template vars (override global vars)
+	Type           string            `yaml:"type,omitempty"`            // "" (execrun config file), "command", or "docker"
+	Config         string            `yaml:"config,omitempty"`          // path to config file (relative to runctl.yaml dir)
+	Cmd            string            `yaml:"cmd,omitempty"`             // managed process for type: command
+	Docker         *DockerConfig     `yaml:"docker,omitempty"`          // image and container settings for type: docker
+	Watch          execrun.WatchList `yaml:"watch,omitempty"`           // watch patterns for inline targets (optional)
+	Host           string            `yaml:"host,omitempty"`            // base URL of a `runctl agent` that runs this target
+	HostToken      string            `yaml:"host_token,omitempty"`      // the agent's api.token, sent with every request to host
+	WaitFor        []string          `yaml:"wait_for,omitempty"`        // tcp://host:port or http(s) URLs that must respond before starting
+	WaitTimeout    string            `yaml:"wait_timeout,omitempty"`    // how long to wait for wait_for endpoints (default: 60s)
+	IdleTimeout    string            `yaml:"idle_timeout,omitempty"`    // stop the process after this long without activity (default: never)
+	Lazy           *LazyConfig       `yaml:"lazy,omitempty"`            // start on the first connection to a port runctl holds
+	CrashLoop      *CrashLoopConfig  `yaml:"crash_loop,omitempty"`      // disable the target after repeated crashes
+	MinFreeSpace   string            `yaml:"min_free_space,omitempty"`  // fail builds when the temp or target volume has less free space
+	Output         string            `yaml:"output,omitempty"`          // prefixed tags each line of child output with the target and stage; raw for interactive apps
+	OutputBuffer   string            `yaml:"output_buffer,omitempty"`   // buffer output in memory, dropping the oldest lines, so slow log writes can't stall the target
+	BuildNice      int               `yaml:"build_nice,omitempty"`      // CPU priority of build and test steps, 1-19
+	BuildIonice    string            `yaml:"build_ionice,omitempty"`    // I/O priority of build and test steps (Linux)
+	RestartWatch   []string          `yaml:"restart_watch,omitempty"`   // files whose changes restart the process without rebuilding
+	ReloadWatch    []string          `yaml:"reload_watch,omitempty"`    // config-only files: changes send reload_signal instead of restarting
+	ReloadSignal   string            `yaml:"reload_signal,omitempty"`   // signal for reload_watch changes (default: SIGHUP)
+	FollowSymlinks bool              `yaml:"follow_symlinks,omitempty"` // descend into symlinked directories when expanding watch patterns
+	Enabled        *bool             `yaml:"enabled,omitempty"`
+	Links          []Link            `yaml:"links,omitempty"`
+	Vars           map[string]string `yaml:"vars,omitempty"` // per-target template vars (override global vars)
 
 	// Logs is populated internally from Config.LogsDir — not user-configurable.
 	Logs *LogsConfig `yaml:"-"`
+	// Instance is Config.InstanceName. Populated internally.
+	Instance string `yaml:"-"`
+	// APIPrefix is Config.API.APIPrefix(), used to build link URLs.
+	// Populated internally.
+	APIPrefix string `yaml:"-"`
+	// ScratchDir is the target's directory under Config.ScratchDir, passed to
+	// its config as {{ .SCRATCH_DIR }}. Populated internally.
+	ScratchDir string `yaml:"-"`
+	// Strict is Config.Strict, applied to the target's execrun config.
+	// Populated internally.
+	Strict bool `yaml:"-"`
+}
+
+// CrashLoopConfig sets when repeated crashes put a target in the crash_loop
+// state. Crashes: 0 turns detection off.
+type CrashLoopConfig struct {
+	Crashes int    `yaml:"crashes"`          // crashes that trigger the crash loop (default: 5)
+	Window  string `yaml:"window,omitempty"` // period the crashes must fall within (default: 60s)
+}
+
+// DockerConfig describes how a docker target's image is built and run.
+type DockerConfig struct {
+	Context    string   `yaml:"context,omitempty"`    // build context, relative to runctl.yaml dir (default: ".")
+	Dockerfile string   `yaml:"dockerfile,omitempty"` // relative to context (default: Dockerfile)
+	Image      string   `yaml:"image,omitempty"`      // image tag (default: runctl-<target>)
+	RunArgs    []string `yaml:"run_args,omitempty"`   // extra `docker run` args, e.g. ["-p", "8080:80"]
+	Args       []string `yaml:"args,omitempty"`       // command and args passed to the container
 }
 
 // Link is a named URL or file path associated with a target.
@@ -60,6 +183,26 @@ type LogsConfig struct {
 	Build string `json:"build,omitempty"` // build stage log file
 	Test  string `json:"test,omitempty"`  // test stage log file
 	Run   string `json:"run,omitempty"`   // run stage log file
+
+	MaxSize  int64 `json:"-"` // Config.LogsMaxBytes
+	MaxFiles int   `json:"-"` // Config.LogsKeepFiles
+}
+
+// Path returns the log file for a stage (build, test, or run), or "" if
+// none is configured. Safe to call on a nil LogsConfig.
+func (this *LogsConfig) Path(stage string) string {
+	if this == nil {
+		return ""
+	}
+	switch stage {
+	case "build":
+		return this.Build
+	case "test":
+		return this.Test
+	case "run":
+		return this.Run
+	}
+	return ""
 }
 
 // IsEnabled returns whether the target should start on launch (default: true).
@@ -70,6 +213,158 @@ func (this TargetConfig) IsEnabled() bool {
 	return *this.Enabled
 }
 
+// IsCommand returns true for inline command targets (type: command).
+func (this TargetConfig) IsCommand() bool {
+	return this.Type == TargetTypeCommand
+}
+
+// IsDocker returns true for docker targets (type: docker).
+func (this TargetConfig) IsDocker() bool {
+	return this.Type == TargetTypeDocker
+}
+
+// WaitTimeoutDuration returns the wait_for timeout (default: 60s).
+// Validate rejects unparsable values.
+func (this TargetConfig) WaitTimeoutDuration() time.Duration {
+	if d, err := time.ParseDuration(this.WaitTimeout); err == nil && d > 0 {
+		return d
+	}
+	return DefaultWaitTimeout
+}
+
+// IdleTimeoutDuration returns how long the process may go without activity
+// before it is suspended, or 0 if it never is.
+func (this TargetConfig) IdleTimeoutDuration() time.Duration {
+	d, _ := time.ParseDuration(this.IdleTimeout)
+	return d
+}
+
+// CrashLoopLimits returns how many crashes within which window put the target
+// in a crash loop. A zero count means detection is off.
+func (this TargetConfig) CrashLoopLimits() (int, time.Duration) {
+	if this.CrashLoop == nil {
+		return DefaultCrashLoopCrashes, DefaultCrashLoopWindow
+	}
+	window := DefaultCrashLoopWindow
+	if d, err := time.ParseDuration(this.CrashLoop.Window); err == nil && d > 0 {
+		window = d
+	}
+	return this.CrashLoop.Crashes, window
+}
+
+// IsRemote returns true for targets run by a remote agent (host: set).
+func (this TargetConfig) IsRemote() bool {
+	return this.Host != ""
+}
+
+// IsInline returns true for targets defined entirely in runctl.yaml.
+func (this TargetConfig) IsInline() bool {
+	return this.IsCommand() || this.IsDocker()
+}
+
+// Dir returns the target's absolute working directory. Config-file targets
+// run in their config file's directory, docker targets in their build
+// context, and command targets in baseDir.
+func (this TargetConfig) Dir(baseDir string) string {
+	dir := filepath.Dir(this.Config)
+	if this.IsDocker() && this.Docker != nil && this.Docker.Context != "" {
+		dir = this.Docker.Context
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(baseDir, dir)
+	}
+	return dir
+}
+
+// SumFileName returns the target's sum file name, relative to its Dir.
+func (this TargetConfig) SumFileName(name string) string {
+	if this.IsInline() {
+		return normalizeTargetName(name) + ".sum"
+	}
+	configFile := filepath.Base(this.Config)
+	return strings.TrimSuffix(configFile, filepath.Ext(configFile)) + ".sum"
+}
+
+// LoadExecConfig returns the execrun config for the target, along with the
+// vars resolved from the child config's vars: section. Config-file targets
+// are loaded from disk with parentVars as template data; inline targets are
+// built from their runctl.yaml fields.
+func (this TargetConfig) LoadExecConfig(name, baseDir string, parentVars map[string]string) (*execrun.Config, map[string]string, error) {
+	if this.IsDocker() {
+		ecfg := this.dockerExecConfig(name)
+		if err := ecfg.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("invalid config: %w", err)
+		}
+		return &ecfg, map[string]string{}, nil
+	}
+
+	if this.IsCommand() {
+		watch := this.Watch
+		if len(watch) == 0 && len(this.RestartWatch) == 0 && len(this.ReloadWatch) == 0 {
+			// A lone exclusion matches nothing: the process is only
+			// restarted on demand.
+			watch = execrun.Watches("!**")
+		}
+		ecfg := execrun.Config{
+			Watch:          watch,
+			Exec:           []string{this.Cmd},
+			RestartWatch:   this.RestartWatch,
+			ReloadWatch:    this.ReloadWatch,
+			ReloadSignal:   this.ReloadSignal,
+			FollowSymlinks: this.FollowSymlinks,
+		}
+		if err := ecfg.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("invalid config: %w", err)
+		}
+		return &ecfg, map[string]string{}, nil
+	}
+
+	configPath := configutil.ResolveConfigPath(filepath.Join(this.Dir(baseDir), filepath.Base(this.Config)))
+	var configOpts []config.Option
+	if len(parentVars) > 0 {
+		configOpts = append(configOpts, config.WithVars(parentVars))
+	}
+	if this.Strict {
+		configOpts = append(configOpts, config.WithStrict())
+	}
+	ecfg, vars, err := execrun.LoadConfig(configPath, configOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ecfg.MinFreeSpace == "" {
+		ecfg.MinFreeSpace = this.MinFreeSpace
+	}
+	if ecfg.Output == "" {
+		ecfg.Output = this.Output
+	}
+	if ecfg.OutputBuffer == "" {
+		ecfg.OutputBuffer = this.OutputBuffer
+	}
+	if ecfg.BuildNice == 0 {
+		ecfg.BuildNice = this.BuildNice
+	}
+	if ecfg.BuildIonice == "" {
+		ecfg.BuildIonice = this.BuildIonice
+	}
+	if this.FollowSymlinks {
+		ecfg.FollowSymlinks = true
+	}
+	if len(ecfg.RestartWatch) == 0 && len(this.RestartWatch) > 0 {
+		ecfg.RestartWatch = this.RestartWatch
+		if err := ecfg.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("invalid config: %w", err)
+		}
+	}
+	if len(ecfg.ReloadWatch) == 0 && len(this.ReloadWatch) > 0 {
+		ecfg.ReloadWatch = this.ReloadWatch
+		ecfg.ReloadSignal = this.ReloadSignal
+		if err := ecfg.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("invalid config: %w", err)
+		}
+	}
+	return ecfg, vars, nil
+}
+
 // RotatesLogsOnStart returns whether existing log files should be renamed to a
 // timestamped backup when runctl starts (default: true).
 func (this Config) RotatesLogsOnStart() bool {
@@ -79,27 +374,104 @@ func (this Config) RotatesLogsOnStart() bool {
 	return *this.LogsRotateOnStart
 }
 
+// LogsRetentionDuration returns how long rotated log files are kept, or 0 to
+// keep them forever.
+func (this Config) LogsRetentionDuration() time.Duration {
+	d, err := time.ParseDuration(this.LogsRetention)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
+}
+
+// SetDefaultOutput sets the output of targets that set none, e.g. from a
+// command-line flag, unless the config sets a default itself.
+func (this *Config) SetDefaultOutput(output string) {
+	if this.Output != "" {
+		return
+	}
+	this.Output = output
+	for name, t := range this.Targets {
+		if t.Output == "" {
+			t.Output = output
+			this.Targets[name] = t
+		}
+	}
+}
+
+// LogsMaxBytes returns the size at which target log files are rotated, or 0
+// to let them grow.
+func (this Config) LogsMaxBytes() int64 {
+	n, err := diskspace.ParseSize(this.LogsMaxSize)
+	if err != nil {
+		return 0
+	}
+	return int64(n)
+}
+
+// LogsKeepFiles returns how many rotated segments are kept per log file.
+func (this Config) LogsKeepFiles() int {
+	if this.LogsMaxFiles <= 0 {
+		return defaultLogsMaxFiles
+	}
+	return this.LogsMaxFiles
+}
+
+// Deprecations lists the renamed keys of Config and, under targets.*, of
+// TargetConfig, e.g. {Old: "targets.*.wait_for", New: "depends_on",
+// Since: "v1.2"}. Configs using an old key still load, with a warning.
+var Deprecations = config.Deprecations{}
+
 // LoadConfig reads and parses a runctl.yaml file.
 // Template variables from the vars: section are resolved using Go templates,
 // then set in the process environment (if not already present) so child
-// configs can access them.
-func LoadConfig(path string) (*Config, error) {
-	data, resolvedVars, err := config.ProcessFile(path)
+// configs can access them. opts are passed to config.ProcessFile; with
+// config.WithStrict the targets' configs are loaded strictly too.
+func LoadConfig(path string, opts ...config.Option) (*Config, error) {
+	data, resolvedVars, err := config.ProcessFile(path, opts...)
 	if err != nil {
 		return nil, err
 	}
+	if data, err = Deprecations.Apply(data, path, opts...); err != nil {
+		return nil, err
+	}
+
+	strict := config.IsStrict(data, opts...)
+	validate, unmarshal := configSchema.Validate, yaml.Unmarshal
+	if strict {
+		validate, unmarshal = configSchema.ValidateStrict, config.UnmarshalStrict
+	}
+	if err := validate(data); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
 
 	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	if err := unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("parse config %s: %w", path, err)
 	}
+	cfg.Strict = strict
 
 	cfg.ResolvedVars = resolvedVars
+	cfg.EnvFile = cfg.EnvFile.Abs(filepath.Dir(path))
 
-	// Set resolved vars in environment so child processes can access them.
+	// Export .env pairs the environment doesn't set, then resolved vars, so
+	// child processes can access them.
+	dotEnv, err := config.ReadDotEnv(".", cfg.EnvFile...)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range dotEnv {
+		if _, ok := os.LookupEnv(k); !ok {
+			os.Setenv(k, v)
+		}
+	}
 	for k, v := range resolvedVars {
 		os.Setenv(k, v)
 	}
+	for k, v := range cfg.Secrets {
+		os.Setenv(k, v)
+	}
+	cfg.registerSecrets()
 
 	// Resolve per-target vars using global vars as template data.
 	envMap := environMap()
@@ -150,6 +522,14 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 
+	absDir, err := filepath.Abs(configDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve config dir: %w", err)
+	}
+	if err := cfg.resolveScratchDirs(absDir); err != nil {
+		return nil, err
+	}
+
 	return &cfg, nil
 }
 
@@ -164,17 +544,188 @@ func normalizeTargetName(name string) string {
 
 // Validate checks the config for required fields and sets defaults.
 func (this *Config) Validate() error {
+	if this.InstanceName != "" && !reInstanceName.MatchString(this.InstanceName) {
+		return fmt.Errorf("instance_name must be lowercase letters, digits, '-' or '_', got %q", this.InstanceName)
+	}
 	if this.API.Port == 0 {
-		this.API.Port = 9100
+		this.API.Port = defaultAPIPort(this.InstanceName)
+	}
+	if this.API.BasePath != "" {
+		bp := "/" + strings.Trim(this.API.BasePath, "/")
+		if bp == "/" {
+			bp = ""
+		}
+		if strings.ContainsAny(bp, "?#") {
+			return fmt.Errorf("api.base_path must be a plain URL path, got %q", this.API.BasePath)
+		}
+		this.API.BasePath = bp
+	}
+	if this.API.AllowExec && this.API.Token == "" {
+		return fmt.Errorf("api.allow_exec needs api.token, which exec requests send as \"Authorization: Bearer <token>\"")
 	}
 	if len(this.Targets) == 0 {
 		return fmt.Errorf("at least one target is required")
 	}
+	if this.MinFreeSpace != "" {
+		if _, err := diskspace.ParseSize(this.MinFreeSpace); err != nil {
+			return fmt.Errorf("min_free_space: %w", err)
+		}
+	}
+	if this.LogsRetention != "" {
+		if d, err := time.ParseDuration(this.LogsRetention); err != nil || d <= 0 {
+			return fmt.Errorf("logs_retention must be a positive duration like 168h, got %q", this.LogsRetention)
+		}
+	}
+	if this.LogsMaxSize != "" {
+		if n, err := diskspace.ParseSize(this.LogsMaxSize); err != nil {
+			return fmt.Errorf("logs_max_size: %w", err)
+		} else if n == 0 {
+			return fmt.Errorf("logs_max_size must be positive, got %q", this.LogsMaxSize)
+		}
+	}
+	if err := this.Heartbeat.Validate(); err != nil {
+		return err
+	}
+	if this.LogsMaxFiles < 0 {
+		return fmt.Errorf("logs_max_files must not be negative, got %d", this.LogsMaxFiles)
+	}
+	for k := range this.Secrets {
+		if _, ok := this.ResolvedVars[k]; ok {
+			return fmt.Errorf("secret %q is also defined in vars", k)
+		}
+	}
+	for i, wh := range this.Notifications.Webhooks {
+		u, err := url.Parse(wh.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("notifications: webhook %d: url must be an http(s) URL, got %q", i, wh.URL)
+		}
+		for _, e := range wh.Events {
+			if !isEventName(e) {
+				return fmt.Errorf("notifications: webhook %d: unknown event %q", i, e)
+			}
+		}
+	}
+	for _, hook := range []struct {
+		name string
+		cmds []string
+	}{{"on_start", this.OnStart}, {"on_stop", this.OnStop}} {
+		for i := range hook.cmds {
+			hook.cmds[i] = strings.TrimSpace(hook.cmds[i])
+			if _, err := parseHook(hook.cmds[i]); err != nil {
+				return fmt.Errorf("%s: command %d: %w", hook.name, i, err)
+			}
+		}
+	}
+	for _, name := range this.Ready {
+		if _, ok := this.Targets[name]; !ok {
+			return fmt.Errorf("ready: unknown target %q", name)
+		}
+	}
 	for name, t := range this.Targets {
-		if t.Config == "" {
+		if t.IsRemote() {
+			u, err := url.Parse(t.Host)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("target %q: host must be an http(s) URL, got %q", name, t.Host)
+			}
+			if t.HostToken == "" {
+				return fmt.Errorf("target %q: host_token is required; set it to the agent's api.token", name)
+			}
+			if t.Config != "" || t.Cmd != "" || t.Docker != nil {
+				return fmt.Errorf("target %q: remote targets are configured on the agent (drop config, cmd, and docker)", name)
+			}
+		} else if t.IsCommand() {
+			if t.Cmd == "" {
+				return fmt.Errorf("target %q: cmd is required for type %s", name, TargetTypeCommand)
+			}
+			if t.Config != "" {
+				return fmt.Errorf("target %q: cannot specify both config and cmd", name)
+			}
+		} else if t.IsDocker() {
+			if t.Config != "" || t.Cmd != "" {
+				return fmt.Errorf("target %q: config and cmd are not used with type %s (use docker.args)", name, TargetTypeDocker)
+			}
+		} else if t.Config == "" {
 			return fmt.Errorf("target %q: config is required", name)
 		}
 
+		for _, endpoint := range t.WaitFor {
+			if _, err := parseWaitEndpoint(endpoint); err != nil {
+				return fmt.Errorf("target %q: wait_for %q: %w", name, endpoint, err)
+			}
+		}
+		if t.WaitTimeout != "" {
+			if d, err := time.ParseDuration(t.WaitTimeout); err != nil || d <= 0 {
+				return fmt.Errorf("target %q: wait_timeout must be a positive duration like 30s, got %q", name, t.WaitTimeout)
+			}
+		}
+		if t.Lazy != nil {
+			if t.IsRemote() {
+				return fmt.Errorf("target %q: lazy is configured on the agent for remote targets", name)
+			}
+			if err := t.Lazy.validate(); err != nil {
+				return fmt.Errorf("target %q: %w", name, err)
+			}
+		}
+		if t.IdleTimeout == "" {
+			t.IdleTimeout = this.IdleTimeout
+		}
+		if t.Output == "" {
+			t.Output = this.Output
+		}
+		if t.OutputBuffer == "" {
+			t.OutputBuffer = this.OutputBuffer
+		}
+		if t.OutputBuffer != "" {
+			if _, err := diskspace.ParseSize(t.OutputBuffer); err != nil {
+				return fmt.Errorf("target %q: output_buffer: %w", name, err)
+			}
+		}
+		if t.IdleTimeout != "" {
+			if d, err := time.ParseDuration(t.IdleTimeout); err != nil || d < 0 {
+				return fmt.Errorf("target %q: idle_timeout must be a duration like 30m (0 turns it off), got %q", name, t.IdleTimeout)
+			}
+		}
+
+		t.Instance = this.InstanceName
+		t.APIPrefix = this.API.APIPrefix()
+		t.Strict = this.Strict
+		this.Targets[name] = t
+
+		if t.MinFreeSpace == "" {
+			t.MinFreeSpace = this.MinFreeSpace
+			this.Targets[name] = t
+		} else if _, err := diskspace.ParseSize(t.MinFreeSpace); err != nil {
+			return fmt.Errorf("target %q: min_free_space: %w", name, err)
+		}
+		if t.BuildNice < 0 || t.BuildNice > procprio.MaxNice {
+			return fmt.Errorf("target %q: build_nice must be 0-%d, got %d", name, procprio.MaxNice, t.BuildNice)
+		}
+		if t.BuildIonice != "" {
+			if _, err := procprio.ParseIOPriority(t.BuildIonice); err != nil {
+				return fmt.Errorf("target %q: build_ionice: %w", name, err)
+			}
+		}
+
+		if t.ReloadSignal != "" {
+			if len(t.ReloadWatch) == 0 {
+				return fmt.Errorf("target %q: reload_signal needs reload_watch patterns", name)
+			}
+			if _, err := execrun.ParseSignal(t.ReloadSignal); err != nil {
+				return fmt.Errorf("target %q: reload_signal: %w", name, err)
+			}
+		}
+
+		if c := t.CrashLoop; c != nil {
+			if c.Crashes < 0 {
+				return fmt.Errorf("target %q: crash_loop.crashes must not be negative, got %d", name, c.Crashes)
+			}
+			if c.Window != "" {
+				if d, err := time.ParseDuration(c.Window); err != nil || d <= 0 {
+					return fmt.Errorf("target %q: crash_loop.window must be a positive duration like 60s, got %q", name, c.Window)
+				}
+			}
+		}
+
 		// Validate links: each must have exactly one of url or file
 		for i, link := range t.Links {
 			hasURL := link.URL != ""
@@ -194,6 +745,9 @@ func (this *Config) Validate() error {
 				Build: filepath.Join(this.LogsDir, norm+".build.log"),
 				Test:  filepath.Join(this.LogsDir, norm+".test.log"),
 				Run:   filepath.Join(this.LogsDir, norm+".run.log"),
+
+				MaxSize:  this.LogsMaxBytes(),
+				MaxFiles: this.LogsKeepFiles(),
 			}
 			this.Targets[name] = t
 		}
@@ -217,3 +771,11 @@ func environMap() map[string]string {
 //
 //go:embed runctl.default.yaml
 var DefaultConfigYAML string
+
+// ConfigSchema is the JSON Schema for runctl.yaml. LoadConfig checks configs
+// against it after template processing; editors can use it for completion.
+//
+//go:embed runctl.schema.json
+var ConfigSchema []byte
+
+var configSchema = schema.MustCompile(ConfigSchema)
@@ -0,0 +1,48 @@
+package runctl
+
+import (
+	"fmt"
+	"time"
+)
+
+// Crash loop defaults: a target whose process crashes this many times within
+// the window is stopped and disabled.
+const (
+	DefaultCrashLoopCrashes = 5
+	DefaultCrashLoopWindow  = 60 * time.Second
+)
+
+// recordCrash notes a crash and reports whether the target is now crash
+// looping. Must hold mu.
+func (this *target) recordCrash(at time.Time) bool {
+	limit, window := this.tcfg.CrashLoopLimits()
+	if limit <= 0 {
+		return false
+	}
+	crashes := this.crashes[:0]
+	for _, t := range this.crashes {
+		if at.Sub(t) < window {
+			crashes = append(crashes, t)
+		}
+	}
+	this.crashes = append(crashes, at)
+	return len(this.crashes) >= limit
+}
+
+// enterCrashLoop stops the run loop and disables the target so a broken
+// process is not restarted until someone enables it again. Must hold mu.
+func (this *target) enterCrashLoop(old TargetState) {
+	limit, window := this.tcfg.CrashLoopLimits()
+	this.crashes = nil
+	this.state = StateCrashLoop
+	this.enabled = false
+	if this.cancel != nil {
+		this.cancel()
+		this.cancel = nil
+	}
+	this.emit(Event{
+		Event:    EventCrashLoop,
+		OldState: old,
+		Error:    fmt.Sprintf("crashed %d times within %s; target disabled", limit, window),
+	})
+}
@@ -0,0 +1,120 @@
+package runctl_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/clock"
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("Crash loop", func() {
+	var (
+		ctrl *runctl.Controller
+		clk  *clock.Fake
+	)
+
+	BeforeEach(func() {
+		clk = clock.NewFake(time.Now())
+	})
+
+	startCrashing := func(crashLoop *runctl.CrashLoopConfig) {
+		var err error
+		ctrl, err = runctl.New(runctl.Config{
+			API: runctl.APIConfig{Port: 9100},
+			Targets: map[string]runctl.TargetConfig{
+				"app": {Type: runctl.TargetTypeCommand, Cmd: "false", CrashLoop: crashLoop},
+			},
+		}, GinkgoT().TempDir(), false)
+		Expect(err).NotTo(HaveOccurred())
+		ctrl.SetClock(clk)
+		ctrl.StartTargets()
+		DeferCleanup(ctrl.KillTargets)
+	}
+
+	state := func() runctl.TargetState {
+		st, err := ctrl.TargetStatus("app")
+		Expect(err).NotTo(HaveOccurred())
+		return st.State
+	}
+
+	crashCount := func() int {
+		n := 0
+		for _, e := range ctrl.RecentEvents() {
+			if e.Event == runctl.EventCrashed {
+				n++
+			}
+		}
+		return n
+	}
+
+	// crashAgain restarts the process once the previous crash is recorded.
+	crashAgain := func(crashes int) {
+		Eventually(crashCount, "5s", "20ms").Should(Equal(crashes))
+		Expect(ctrl.StartExec("app")).To(Succeed())
+	}
+
+	It("rejects a bad window", func() {
+		cfgPath := filepath.Join(GinkgoT().TempDir(), "runctl.yaml")
+		Expect(os.WriteFile(cfgPath, []byte(`
+targets:
+  app:
+    type: command
+    cmd: ./server
+    crash_loop:
+      crashes: 3
+      window: often
+`), 0644)).To(Succeed())
+		_, err := runctl.LoadConfig(cfgPath)
+		Expect(err).To(MatchError(ContainSubstring("crash_loop.window must be a positive duration")))
+	})
+
+	It("disables a target that keeps crashing", func() {
+		startCrashing(&runctl.CrashLoopConfig{Crashes: 3, Window: "1m"})
+		crashAgain(1)
+		crashAgain(2)
+
+		Eventually(state, "5s", "20ms").Should(Equal(runctl.StateCrashLoop))
+		st, err := ctrl.TargetStatus("app")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(st.Enabled).To(BeFalse())
+
+		events := ctrl.RecentEvents()
+		last := events[len(events)-1]
+		Expect(last.Event).To(Equal(runctl.EventCrashLoop))
+		Expect(last.NewState).To(Equal(runctl.StateCrashLoop))
+		Expect(last.Error).To(ContainSubstring("crashed 3 times within 1m0s"))
+
+		Consistently(state, "300ms", "20ms").Should(Equal(runctl.StateCrashLoop))
+	})
+
+	It("forgets crashes older than the window", func() {
+		startCrashing(&runctl.CrashLoopConfig{Crashes: 2, Window: "1m"})
+		Eventually(crashCount, "5s", "20ms").Should(Equal(1))
+		clk.Advance(2 * time.Minute)
+		crashAgain(1)
+		Eventually(crashCount, "5s", "20ms").Should(Equal(2))
+		Consistently(state, "300ms", "20ms").ShouldNot(Equal(runctl.StateCrashLoop))
+	})
+
+	It("starts again once enabled", func() {
+		startCrashing(&runctl.CrashLoopConfig{Crashes: 1})
+		Eventually(state, "5s", "20ms").Should(Equal(runctl.StateCrashLoop))
+
+		Expect(ctrl.EnableTarget("app")).To(Succeed())
+		st, err := ctrl.TargetStatus("app")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(st.Enabled).To(BeTrue())
+	})
+
+	It("can be turned off", func() {
+		startCrashing(&runctl.CrashLoopConfig{Crashes: 0})
+		crashAgain(1)
+		crashAgain(2)
+		Consistently(state, "300ms", "20ms").ShouldNot(Equal(runctl.StateCrashLoop))
+	})
+})
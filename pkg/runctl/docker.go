@@ -0,0 +1,121 @@
+package runctl
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gur-shatz/go-run/pkg/execrun"
+)
+
+// Docker targets are driven through the docker CLI: the image is built as
+// the target's build step and the container runs attached (`docker run
+// --rm`) as the managed process, so its output lands in the run log and
+// SIGTERM is proxied to the container on stop/restart.
+
+// ContainerName returns the container name used for a docker target. The
+// instance name, if any, keeps instances on one machine apart.
+func ContainerName(instance, target string) string {
+	if instance != "" {
+		return "runctl-" + instance + "-" + normalizeTargetName(target)
+	}
+	return "runctl-" + normalizeTargetName(target)
+}
+
+// dockerImage returns the image tag for a docker target.
+func (this TargetConfig) dockerImage(name string) string {
+	if this.Docker != nil && this.Docker.Image != "" {
+		return this.Docker.Image
+	}
+	return ContainerName(this.Instance, name)
+}
+
+// dockerCIDFile returns the path docker writes the container ID to, in the
+// target's scratch dir so checkouts don't share it.
+func (this TargetConfig) dockerCIDFile(name string) string {
+	dir := this.ScratchDir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, ContainerName(this.Instance, name)+".cid")
+}
+
+// dockerExecConfig translates a docker target into an execrun config.
+func (this TargetConfig) dockerExecConfig(name string) execrun.Config {
+	dc := DockerConfig{}
+	if this.Docker != nil {
+		dc = *this.Docker
+	}
+	dockerfile := dc.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	image := this.dockerImage(name)
+	cidFile := this.dockerCIDFile(name)
+
+	watch := this.Watch
+	if len(watch) == 0 {
+		// Rebuild on any change in the build context, except our own sum file.
+		watch = execrun.Watches("**/*", "!"+this.SumFileName(name))
+	}
+
+	run := []string{"docker", "run", "--rm", "--name", ContainerName(this.Instance, name), "--cidfile", cidFile}
+	run = append(run, dc.RunArgs...)
+	run = append(run, image)
+	run = append(run, dc.Args...)
+
+	return execrun.Config{
+		Title:        name,
+		Watch:        watch,
+		MinFreeSpace: this.MinFreeSpace,
+		RestartWatch: this.RestartWatch,
+		ReloadWatch:  this.ReloadWatch,
+		ReloadSignal: this.ReloadSignal,
+		Build:        []string{quoteArgs("docker", "build", "-t", image, "-f", dockerfile, ".")},
+		Exec: []string{
+			// docker refuses to overwrite an existing cidfile
+			quoteArgs("rm", "-f", cidFile),
+			quoteArgs(run...),
+		},
+		FollowSymlinks: this.FollowSymlinks,
+	}
+}
+
+// readContainerID returns the short ID of a docker target's running
+// container, or "" if it isn't known yet.
+func (this TargetConfig) readContainerID(name string) string {
+	data, err := os.ReadFile(this.dockerCIDFile(name))
+	if err != nil {
+		return ""
+	}
+	id := strings.TrimSpace(string(data))
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	return id
+}
+
+// removeContainer force-removes a docker target's container. Used when the
+// managed `docker run` client is killed and can't clean up after itself.
+// A container that is already gone, e.g. removed by --rm, is not an error.
+func (this TargetConfig) removeContainer(name string) {
+	container := ContainerName(this.Instance, name)
+	if out, err := exec.Command("docker", "rm", "-f", container).CombinedOutput(); err != nil && !bytes.Contains(out, []byte("No such container")) {
+		warnf("remove container %s: %v: %s", container, err, bytes.TrimSpace(out))
+	}
+	os.Remove(this.dockerCIDFile(name))
+}
+
+// quoteArgs joins args into a command string that survives shlex splitting.
+func quoteArgs(args ...string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t\n'\"\\") {
+			a = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+		}
+		quoted[i] = a
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,54 @@
+package runctl
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// eventLog appends every event to a file as newline-delimited JSON, one
+// Event object per line, for an audit trail of the session. A nil log
+// writes nothing.
+type eventLog struct {
+	mu     sync.Mutex
+	path   string
+	warned bool
+}
+
+// newEventLog resolves path against baseDir. It returns nil for "".
+func newEventLog(path, baseDir string) *eventLog {
+	if path == "" {
+		return nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	return &eventLog{path: path}
+}
+
+// write appends e. The file is opened per event, so it can be rotated or
+// deleted while runctl runs. Failures are reported once.
+func (this *eventLog) write(e Event) {
+	if this == nil {
+		return
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	f, err := os.OpenFile(this.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err == nil {
+		_, err = f.Write(append(line, '\n'))
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil && !this.warned {
+		this.warned = true
+		warnf("events_file: %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package runctl
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Target events, published on the controller's event bus.
+const (
+	EventBuildSucceeded = "build_succeeded"
+	EventBuildFailed    = "build_failed" // a build step failed
+	EventTestSucceeded  = "test_succeeded"
+	EventTestFailed     = "test_failed" // a test step failed
+	EventStarted        = "started"     // the managed process started
+	EventExited         = "exited"      // the managed process exited with code 0
+	EventCrashed        = "crashed"     // the managed process exited with a non-zero code
+	EventCrashLoop      = "crash_loop"  // the process crashed too often and the target was disabled
+	EventRecovered      = "recovered"   // a failing target is healthy again
+	EventSuspended      = "suspended"   // the process was stopped by idle_timeout
+)
+
+var eventNames = []string{
+	EventBuildSucceeded, EventBuildFailed, EventTestSucceeded, EventTestFailed,
+	EventStarted, EventExited, EventCrashed, EventCrashLoop, EventRecovered,
+	EventSuspended,
+}
+
+func isEventName(name string) bool {
+	return slices.Contains(eventNames, name)
+}
+
+// Event is a target state change. It is streamed by /api/events and is the
+// JSON body POSTed to webhooks.
+type Event struct {
+	ID       uint64      `json:"id"`
+	Event    string      `json:"event"`
+	Target   string      `json:"target"`
+	OldState TargetState `json:"old_state"`
+	NewState TargetState `json:"new_state"`
+	Error    string      `json:"error,omitempty"`     // failure message
+	Output   []string    `json:"output,omitempty"`    // last lines of the failed stage's log
+	ExitCode int         `json:"exit_code,omitempty"` // crashed only
+	Time     time.Time   `json:"time"`
+}
+
+// eventOutputLines is how much of a failed stage's log goes into an event.
+const eventOutputLines = 20
+
+// DefaultEventHistory is how many events per target are kept for replay.
+const DefaultEventHistory = 20
+
+// eventBus fans events out to subscribers and keeps the last few per target
+// so late subscribers start with recent history.
+type eventBus struct {
+	mu      sync.Mutex
+	size    int
+	nextID  uint64
+	history map[string][]Event
+	subs    map[chan Event]struct{}
+}
+
+func newEventBus(size int) *eventBus {
+	if size <= 0 {
+		size = DefaultEventHistory
+	}
+	return &eventBus{
+		size:    size,
+		history: make(map[string][]Event),
+		subs:    make(map[chan Event]struct{}),
+	}
+}
+
+// publish assigns e an ID, records it, and delivers it to subscribers.
+// Slow subscribers miss events rather than block targets.
+func (this *eventBus) publish(e Event) Event {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	this.nextID++
+	e.ID = this.nextID
+
+	h := append(this.history[e.Target], e)
+	if len(h) > this.size {
+		h = slices.Clone(h[len(h)-this.size:])
+	}
+	this.history[e.Target] = h
+
+	for ch := range this.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+	return e
+}
+
+// recent returns the recorded events of all targets, oldest first.
+func (this *eventBus) recent() []Event {
+	var events []Event
+	for _, h := range this.history {
+		events = append(events, h...)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events
+}
+
+// subscribe returns the recent history and a channel of events published
+// after it. Call cancel to unsubscribe.
+func (this *eventBus) subscribe() (replay []Event, ch <-chan Event, cancel func()) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	c := make(chan Event, 64)
+	this.subs[c] = struct{}{}
+	return this.recent(), c, func() {
+		this.mu.Lock()
+		delete(this.subs, c)
+		this.mu.Unlock()
+	}
+}
+
+// Subscribe returns a channel of the events published from now on, e.g. to
+// report build results. A slow reader misses events. Call cancel to stop.
+func (this *Controller) Subscribe() (events <-chan Event, cancel func()) {
+	_, events, cancel = this.events.subscribe()
+	return events, cancel
+}
+
+// RecentEvents returns the recorded history of all targets, oldest first.
+func (this *Controller) RecentEvents() []Event {
+	this.events.mu.Lock()
+	defer this.events.mu.Unlock()
+	return this.events.recent()
+}
+
+// publish records an event, appends it to the events file, and forwards
+// it to the webhooks.
+func (this *Controller) publish(e Event) {
+	e = this.events.publish(e)
+	this.eventLog.write(e)
+	this.notifier.Notify(e)
+}
@@ -0,0 +1,142 @@
+package runctl_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("Events", func() {
+	var ctrl *runctl.Controller
+
+	// eventNames returns the event field of each recorded event.
+	eventNames := func() []string {
+		var names []string
+		for _, e := range ctrl.RecentEvents() {
+			names = append(names, e.Event)
+		}
+		return names
+	}
+
+	startTarget := func(history int) {
+		var err error
+		ctrl, err = runctl.New(runctl.Config{
+			API:          runctl.APIConfig{Port: 9100},
+			EventHistory: history,
+			Targets: map[string]runctl.TargetConfig{
+				"app": {Type: runctl.TargetTypeCommand, Cmd: "true"},
+			},
+		}, GinkgoT().TempDir(), false)
+		Expect(err).NotTo(HaveOccurred())
+		ctrl.StartTargets()
+		DeferCleanup(ctrl.KillTargets)
+	}
+
+	It("records recent events per target", func() {
+		startTarget(0)
+		Eventually(eventNames, "5s", "20ms").Should(Equal([]string{runctl.EventStarted, runctl.EventExited}))
+
+		events := ctrl.RecentEvents()
+		Expect(events[0].Target).To(Equal("app"))
+		Expect(events[0].NewState).To(Equal(runctl.StateRunning))
+		Expect(events[1].ID).To(BeNumerically(">", events[0].ID))
+	})
+
+	It("delivers events published after Subscribe", func() {
+		var err error
+		ctrl, err = runctl.New(runctl.Config{
+			API:     runctl.APIConfig{Port: 9100},
+			Targets: map[string]runctl.TargetConfig{"app": {Type: runctl.TargetTypeCommand, Cmd: "true"}},
+		}, GinkgoT().TempDir(), false)
+		Expect(err).NotTo(HaveOccurred())
+		events, cancel := ctrl.Subscribe()
+		defer cancel()
+
+		ctrl.StartTargets()
+		DeferCleanup(ctrl.KillTargets)
+		var e runctl.Event
+		Eventually(events, "5s").Should(Receive(&e))
+		Expect(e.Event).To(Equal(runctl.EventStarted))
+		Expect(e.Target).To(Equal("app"))
+	})
+
+	It("keeps only the configured number of events", func() {
+		startTarget(1)
+		Eventually(eventNames, "5s", "20ms").Should(Equal([]string{runctl.EventExited}))
+	})
+
+	It("appends every event to events_file as JSON lines", func() {
+		base := GinkgoT().TempDir()
+		var err error
+		ctrl, err = runctl.New(runctl.Config{
+			API:        runctl.APIConfig{Port: 9100},
+			EventsFile: "events.ndjson",
+			Targets:    map[string]runctl.TargetConfig{"app": {Type: runctl.TargetTypeCommand, Cmd: "true"}},
+		}, base, false)
+		Expect(err).NotTo(HaveOccurred())
+		ctrl.StartTargets()
+		DeferCleanup(ctrl.KillTargets)
+
+		lines := func() []string {
+			data, _ := os.ReadFile(filepath.Join(base, "events.ndjson"))
+			return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		}
+		Eventually(lines, "5s", "20ms").Should(HaveLen(2))
+		var names []string
+		for _, line := range lines() {
+			var e runctl.Event
+			Expect(json.Unmarshal([]byte(line), &e)).To(Succeed())
+			Expect(e.Target).To(Equal("app"))
+			Expect(e.Time).NotTo(BeZero())
+			names = append(names, e.Event)
+		}
+		Expect(names).To(Equal([]string{runctl.EventStarted, runctl.EventExited}))
+	})
+
+	It("replays history to late SSE subscribers", func() {
+		startTarget(0)
+		Eventually(eventNames, "5s", "20ms").Should(HaveLen(2))
+
+		server := serveAPI(ctrl)
+		defer server.Close()
+
+		readEvents := func(lastID string) []string {
+			req, err := http.NewRequest(http.MethodGet, server.URL+"/api/events?target=app", nil)
+			Expect(err).NotTo(HaveOccurred())
+			if lastID != "" {
+				req.Header.Set("Last-Event-ID", lastID)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+
+			// The replay ends with the exited event; stop reading there.
+			var names []string
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				if name, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+					names = append(names, name)
+					if name == runctl.EventExited {
+						break
+					}
+				}
+			}
+			return names
+		}
+
+		Expect(readEvents("")).To(Equal([]string{runctl.EventStarted, runctl.EventExited}))
+
+		first := ctrl.RecentEvents()[0].ID
+		Expect(readEvents(strconv.FormatUint(first, 10))).To(Equal([]string{runctl.EventExited}))
+	})
+})
@@ -0,0 +1,168 @@
+package runctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"mime"
+	"net/http"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DefaultExecTimeout bounds an ad-hoc command when the request sets none.
+const DefaultExecTimeout = 5 * time.Minute
+
+// ExecRequest is the body of POST /api/targets/{name}/exec. Cmd is the
+// argv of the command; it is not run through a shell.
+type ExecRequest struct {
+	Cmd     []string `json:"cmd"`
+	Timeout string   `json:"timeout,omitempty"` // e.g. "10m" (default: 5m)
+}
+
+// ExecResult is the outcome of an ad-hoc command. A non-zero exit is a
+// result, not an error.
+type ExecResult struct {
+	ExitCode int     `json:"exit_code"`
+	Output   string  `json:"output"` // combined stdout and stderr, masked
+	Duration float64 `json:"duration_secs"`
+	TimedOut bool    `json:"timed_out,omitempty"`
+}
+
+// Exec runs argv in the target's directory with the target's resolved vars
+// in its environment, and returns the captured output.
+func (this *Controller) Exec(ctx context.Context, name string, argv []string, timeout time.Duration) (*ExecResult, error) {
+	this.mu.RLock()
+	t, ok := this.targets[name]
+	this.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("target %q %w", name, ErrTargetNotFound)
+	}
+	if t.remote != nil {
+		return nil, fmt.Errorf("target %q runs on %s; exec there", name, t.tcfg.Host)
+	}
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("cmd is required")
+	}
+	if timeout <= 0 {
+		timeout = DefaultExecTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	c := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	c.Dir = t.rootDir
+	c.Env = t.execEnv()
+	c.Stdout = &out
+	c.Stderr = &out
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	c.Cancel = func() error {
+		return syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+	}
+	c.WaitDelay = 5 * time.Second
+
+	start := time.Now()
+	err := c.Run()
+	res := &ExecResult{
+		Output:   this.Redact(out.String()),
+		Duration: time.Since(start).Seconds(),
+		TimedOut: errors.Is(ctx.Err(), context.DeadlineExceeded),
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		res.ExitCode = exitErr.ExitCode()
+	case res.TimedOut:
+		res.ExitCode = -1
+	default:
+		return nil, fmt.Errorf("exec %s: %w", argv[0], err)
+	}
+	return res, nil
+}
+
+// execEnv returns the environment of an ad-hoc command: the target's vars
+// with the same precedence as `runctl vars` (environment, then target,
+// global, built-in, and child config vars).
+func (this *target) execEnv() []string {
+	vars := make(map[string]string)
+	if _, childVars, err := this.tcfg.LoadExecConfig(this.name, this.baseDir, this.parentVars); err == nil {
+		maps.Copy(vars, childVars)
+	}
+	maps.Copy(vars, this.parentVars)
+	maps.Copy(vars, environMap())
+
+	env := make([]string, 0, len(vars))
+	for k, v := range vars {
+		env = append(env, k+"="+v)
+	}
+	return env
+}
+
+// authorizeExec rejects exec requests unless api.allow_exec is set and the
+// request is JSON carrying api.token. The content type keeps browsers from
+// sending it cross-site without a CORS preflight.
+func (this *Controller) authorizeExec(w http.ResponseWriter, r *http.Request) bool {
+	if !this.cfg.API.AllowExec {
+		writeError(w, http.StatusForbidden, "exec is disabled; set api.allow_exec and api.token to enable it")
+		return false
+	}
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "exec requests must be application/json")
+		return false
+	}
+	if !hasBearerToken(r, this.cfg.API.Token) {
+		writeError(w, http.StatusUnauthorized, "exec requests need \"Authorization: Bearer <api.token>\"")
+		return false
+	}
+	return true
+}
+
+func (this *Controller) handleExec(w http.ResponseWriter, r *http.Request) {
+	if !this.authorizeExec(w, r) {
+		return
+	}
+	name := chi.URLParam(r, "name")
+
+	this.mu.RLock()
+	t, ok := this.targets[name]
+	this.mu.RUnlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "target not found")
+		return
+	}
+	if t.remote != nil {
+		t.remote.proxy(w, r, "exec")
+		return
+	}
+
+	var req ExecRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	var timeout time.Duration
+	if req.Timeout != "" {
+		d, err := time.ParseDuration(req.Timeout)
+		if err != nil || d <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid timeout %q", req.Timeout))
+			return
+		}
+		timeout = d
+	}
+
+	res, err := this.Exec(r.Context(), name, req.Cmd, timeout)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,123 @@
+package runctl_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("Ad-hoc exec", func() {
+	var (
+		ctrl *runctl.Controller
+		dir  string
+		url  string
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "marker.txt"), []byte("here"), 0644)).To(Succeed())
+		var err error
+		ctrl, err = runctl.New(runctl.Config{
+			API:          runctl.APIConfig{Port: 9100, AllowExec: true, Token: "s3cret"},
+			Mask:         []string{"EXEC_SECRET"},
+			ResolvedVars: map[string]string{"EXEC_GREETING": "global", "EXEC_SECRET": "hunter2"},
+			Targets: map[string]runctl.TargetConfig{
+				"api": {Type: runctl.TargetTypeCommand, Cmd: "sleep 60", Vars: map[string]string{"EXEC_GREETING": "hello"}},
+			},
+		}, dir, false)
+		Expect(err).NotTo(HaveOccurred())
+
+		server := serveAPI(ctrl)
+		DeferCleanup(server.Close)
+		url = server.URL + "/api/targets/api/exec"
+	})
+
+	send := func(body, contentType, token string) (int, map[string]any) {
+		req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Content-Type", contentType)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		var out map[string]any
+		Expect(json.NewDecoder(resp.Body).Decode(&out)).To(Succeed())
+		return resp.StatusCode, out
+	}
+
+	post := func(body string) (int, map[string]any) {
+		return send(body, "application/json", "s3cret")
+	}
+
+	It("runs in the target's dir with its vars", func() {
+		code, out := post(`{"cmd": ["sh", "-c", "cat marker.txt; echo \" $EXEC_GREETING $EXEC_SECRET\""]}`)
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(out["output"]).To(Equal("here hello ***\n"))
+		Expect(out["exit_code"]).To(BeEquivalentTo(0))
+	})
+
+	It("reports a non-zero exit as a result", func() {
+		code, out := post(`{"cmd": ["sh", "-c", "echo oops >&2; exit 3"]}`)
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(out["output"]).To(Equal("oops\n"))
+		Expect(out["exit_code"]).To(BeEquivalentTo(3))
+	})
+
+	It("kills commands that outlive the timeout", func() {
+		code, out := post(`{"cmd": ["sleep", "10"], "timeout": "100ms"}`)
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(out["timed_out"]).To(BeTrue())
+	})
+
+	It("rejects empty commands and unknown targets", func() {
+		code, out := post(`{"cmd": []}`)
+		Expect(code).To(Equal(http.StatusBadRequest))
+		Expect(out["error"]).To(Equal("cmd is required"))
+
+		_, err := ctrl.Exec(GinkgoT().Context(), "nope", []string{"true"}, 0)
+		Expect(err).To(MatchError(ContainSubstring(`"nope" not found`)))
+	})
+
+	It("rejects requests without the token or as a form", func() {
+		code, _ := send(`{"cmd": ["true"]}`, "application/json", "")
+		Expect(code).To(Equal(http.StatusUnauthorized))
+
+		code, _ = send(`{"cmd": ["true"]}`, "application/json", "wrong")
+		Expect(code).To(Equal(http.StatusUnauthorized))
+
+		code, _ = send(`{"cmd": ["true"]}`, "text/plain", "s3cret")
+		Expect(code).To(Equal(http.StatusUnsupportedMediaType))
+	})
+
+	It("is disabled without allow_exec", func() {
+		ctrl, err := runctl.New(runctl.Config{
+			API:     runctl.APIConfig{Port: 9100, Token: "s3cret"},
+			Targets: map[string]runctl.TargetConfig{"api": {Type: runctl.TargetTypeCommand, Cmd: "sleep 60"}},
+		}, dir, false)
+		Expect(err).NotTo(HaveOccurred())
+		server := serveAPI(ctrl)
+		DeferCleanup(server.Close)
+		url = server.URL + "/api/targets/api/exec"
+
+		code, out := post(`{"cmd": ["true"]}`)
+		Expect(code).To(Equal(http.StatusForbidden))
+		Expect(out["error"]).To(ContainSubstring("api.allow_exec"))
+	})
+
+	It("requires a token to enable exec", func() {
+		cfg := runctl.Config{
+			API:     runctl.APIConfig{AllowExec: true},
+			Targets: map[string]runctl.TargetConfig{"api": {Type: runctl.TargetTypeCommand, Cmd: "sleep 60"}},
+		}
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("api.token")))
+	})
+})
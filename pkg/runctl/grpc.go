@@ -0,0 +1,252 @@
+package runctl
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/gur-shatz/go-run/pkg/runctl/runctlpb"
+)
+
+// GRPCServer returns a gRPC server exposing the controller as the
+// runctl.v1.Runctl service (see runctlpb).
+func (this *Controller) GRPCServer() *grpc.Server {
+	s := grpc.NewServer()
+	runctlpb.RegisterRunctlServer(s, &grpcService{ctrl: this})
+	return s
+}
+
+// grpcService implements runctlpb.RunctlServer on top of a Controller.
+type grpcService struct {
+	runctlpb.UnimplementedRunctlServer
+	ctrl *Controller
+}
+
+func (this *grpcService) target(name string) (*target, error) {
+	this.ctrl.mu.RLock()
+	t, ok := this.ctrl.targets[name]
+	this.ctrl.mu.RUnlock()
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "target %q not found", name)
+	}
+	return t, nil
+}
+
+func (this *grpcService) ListTargets(ctx context.Context, req *runctlpb.ListTargetsRequest) (*runctlpb.ListTargetsResponse, error) {
+	resp := &runctlpb.ListTargetsResponse{}
+	for _, s := range this.ctrl.Status() {
+		resp.Targets = append(resp.Targets, targetStatusPB(s))
+	}
+	return resp, nil
+}
+
+func (this *grpcService) GetTarget(ctx context.Context, req *runctlpb.TargetRequest) (*runctlpb.TargetStatus, error) {
+	t, err := this.target(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return targetStatusPB(t.Status()), nil
+}
+
+// action runs one of the Controller's per-target operations.
+func (this *grpcService) action(name string, do func(string) error, result string) (*runctlpb.ActionResponse, error) {
+	if _, err := this.target(name); err != nil {
+		return nil, err
+	}
+	if err := do(name); err != nil {
+		return nil, grpcError(err)
+	}
+	return &runctlpb.ActionResponse{Status: result}, nil
+}
+
+// grpcError converts a Controller error to a status with a matching code.
+func grpcError(err error) error {
+	var urlErr *url.Error
+	switch {
+	case errors.Is(err, ErrTargetNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrAlreadyRunning):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.As(err, &urlErr):
+		// A remote target's agent couldn't be reached.
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func (this *grpcService) BuildTarget(ctx context.Context, req *runctlpb.TargetRequest) (*runctlpb.ActionResponse, error) {
+	return this.action(req.GetName(), this.ctrl.BuildTarget, "building")
+}
+
+func (this *grpcService) TestTarget(ctx context.Context, req *runctlpb.TargetRequest) (*runctlpb.ActionResponse, error) {
+	return this.action(req.GetName(), this.ctrl.TestTarget, "testing")
+}
+
+func (this *grpcService) StartTarget(ctx context.Context, req *runctlpb.TargetRequest) (*runctlpb.ActionResponse, error) {
+	return this.action(req.GetName(), this.ctrl.StartExec, "starting")
+}
+
+func (this *grpcService) StopTarget(ctx context.Context, req *runctlpb.TargetRequest) (*runctlpb.ActionResponse, error) {
+	return this.action(req.GetName(), this.ctrl.StopExec, "stopped")
+}
+
+func (this *grpcService) RestartTarget(ctx context.Context, req *runctlpb.TargetRequest) (*runctlpb.ActionResponse, error) {
+	return this.action(req.GetName(), this.ctrl.RestartTarget, "restarting")
+}
+
+func (this *grpcService) StreamLogs(req *runctlpb.StreamLogsRequest, stream grpc.ServerStreamingServer[runctlpb.LogLine]) error {
+	t, err := this.target(req.GetName())
+	if err != nil {
+		return err
+	}
+	if t.remote != nil {
+		return status.Errorf(codes.Unimplemented, "logs of remote target %q are served by its agent", t.name)
+	}
+
+	stage := req.GetStage()
+	if stage == "" {
+		stage = "run"
+	}
+	if stage != "build" && stage != "test" && stage != "run" {
+		return status.Error(codes.InvalidArgument, "stage must be build, test, or run")
+	}
+	path := t.tcfg.Logs.Path(stage)
+	if path == "" {
+		return status.Errorf(codes.FailedPrecondition, "no %s log configured for this target", stage)
+	}
+	lines := int(req.GetLines())
+	if lines <= 0 {
+		lines = 200
+	}
+
+	send := func(line string) error {
+		return stream.Send(&runctlpb.LogLine{Line: line})
+	}
+
+	// Note the size before reading the tail so following can't skip
+	// output written in between.
+	var offset int64
+	if info, err := os.Stat(path); err == nil {
+		offset = info.Size()
+		tail, err := tailFile(path, lines)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		for _, line := range tail {
+			if err := send(line); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !req.GetFollow() {
+		return nil
+	}
+	if err := followLog(stream.Context(), path, offset, send); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+func (this *grpcService) StreamEvents(req *runctlpb.StreamEventsRequest, stream grpc.ServerStreamingServer[runctlpb.Event]) error {
+	replay, events, cancel := this.ctrl.events.subscribe()
+	defer cancel()
+
+	send := func(e Event) error {
+		if e.ID <= req.GetAfterId() || (req.GetTarget() != "" && e.Target != req.GetTarget()) {
+			return nil
+		}
+		return stream.Send(eventPB(e))
+	}
+
+	for _, e := range replay {
+		if err := send(e); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case e := <-events:
+			if err := send(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func targetStatusPB(s TargetStatus) *runctlpb.TargetStatus {
+	pb := &runctlpb.TargetStatus{
+		Name:               s.Name,
+		Title:              s.Title,
+		Description:        s.Description,
+		HasBuild:           s.HasBuild,
+		HasTest:            s.HasTest,
+		HasRun:             s.HasRun,
+		State:              string(s.State),
+		CurrentStage:       s.CurrentStage,
+		Enabled:            s.Enabled,
+		Pid:                int32(s.PID),
+		ContainerId:        s.ContainerID,
+		WaitError:          s.WaitError,
+		Host:               s.Host,
+		Build:              phasePB(s.Build),
+		Test:               phasePB(s.Test),
+		LastStartTime:      timestampPB(s.LastStartTime),
+		LastFileChangeTime: timestampPB(s.LastFileChangeTime),
+		RestartCount:       int32(s.RestartCount),
+		BackofficeReady:    s.BackofficeReady,
+	}
+	for _, l := range s.Links {
+		pb.Links = append(pb.Links, &runctlpb.Link{
+			Name:        l.Name,
+			Description: l.Description,
+			Url:         l.URL,
+			File:        l.File,
+			ResolvedUrl: l.ResolvedURL,
+		})
+	}
+	return pb
+}
+
+func phasePB(p PhaseStatus) *runctlpb.Phase {
+	pb := &runctlpb.Phase{
+		Time:   timestampPB(p.Time),
+		Result: p.Result,
+		Error:  p.Error,
+		Count:  int32(p.Count),
+	}
+	if p.Duration != nil {
+		pb.DurationSecs = *p.Duration
+	}
+	return pb
+}
+
+func eventPB(e Event) *runctlpb.Event {
+	return &runctlpb.Event{
+		Id:       e.ID,
+		Event:    e.Event,
+		Target:   e.Target,
+		OldState: string(e.OldState),
+		NewState: string(e.NewState),
+		Error:    e.Error,
+		Output:   e.Output,
+		ExitCode: int32(e.ExitCode),
+		Time:     timestamppb.New(e.Time),
+	}
+}
+
+func timestampPB(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
@@ -0,0 +1,29 @@
+package runctl
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCError(t *testing.T) {
+	cases := []struct {
+		err  error
+		code codes.Code
+	}{
+		{fmt.Errorf("target %q %w", "api", ErrTargetNotFound), codes.NotFound},
+		{fmt.Errorf("target %q is %w", "api", ErrAlreadyRunning), codes.FailedPrecondition},
+		{&agentError{msg: "agent http://box:9100: conflict", kind: ErrAlreadyRunning}, codes.FailedPrecondition},
+		{fmt.Errorf("agent %s: %w", "http://box:9100", &url.Error{Op: "Post", URL: "http://box:9100", Err: errors.New("refused")}), codes.Unavailable},
+		{errors.New("load config: boom"), codes.Internal},
+	}
+	for _, c := range cases {
+		if got := status.Code(grpcError(c.err)); got != c.code {
+			t.Errorf("grpcError(%v) = %v, want %v", c.err, got, c.code)
+		}
+	}
+}
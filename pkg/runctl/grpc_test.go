@@ -0,0 +1,116 @@
+package runctl_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+	"github.com/gur-shatz/go-run/pkg/runctl/runctlpb"
+)
+
+var _ = Describe("gRPC API", func() {
+	var (
+		ctrl    *runctl.Controller
+		client  runctlpb.RunctlClient
+		logsDir string
+	)
+
+	BeforeEach(func() {
+		logsDir = GinkgoT().TempDir()
+		cfg := runctl.Config{
+			API:     runctl.APIConfig{Port: 9100},
+			LogsDir: logsDir,
+			Targets: map[string]runctl.TargetConfig{
+				"api": {Type: runctl.TargetTypeCommand, Cmd: `sh -c "echo one; echo two; sleep 60"`},
+			},
+		}
+		Expect(cfg.Validate()).To(Succeed())
+		var err error
+		ctrl, err = runctl.New(cfg, GinkgoT().TempDir(), false)
+		Expect(err).NotTo(HaveOccurred())
+		ctrl.StartTargets()
+		DeferCleanup(ctrl.KillTargets)
+
+		lis := bufconn.Listen(1 << 20)
+		server := ctrl.GRPCServer()
+		go server.Serve(lis)
+		DeferCleanup(server.Stop)
+
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()))
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		client = runctlpb.NewRunctlClient(conn)
+	})
+
+	It("lists and gets targets", func(ctx SpecContext) {
+		list, err := client.ListTargets(ctx, &runctlpb.ListTargetsRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Targets).To(HaveLen(1))
+		Expect(list.Targets[0].Name).To(Equal("api"))
+
+		Eventually(func() string {
+			st, err := client.GetTarget(ctx, &runctlpb.TargetRequest{Name: "api"})
+			Expect(err).NotTo(HaveOccurred())
+			return st.State
+		}).Should(Equal(string(runctl.StateRunning)))
+	})
+
+	It("returns NotFound for unknown targets", func(ctx SpecContext) {
+		_, err := client.StopTarget(ctx, &runctlpb.TargetRequest{Name: "nope"})
+		Expect(status.Code(err)).To(Equal(codes.NotFound))
+	})
+
+	It("stops and starts a target", func(ctx SpecContext) {
+		resp, err := client.StopTarget(ctx, &runctlpb.TargetRequest{Name: "api"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Status).To(Equal("stopped"))
+		_, err = client.StartTarget(ctx, &runctlpb.TargetRequest{Name: "api"})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("streams events", func(ctx SpecContext) {
+		stream, err := client.StreamEvents(ctx, &runctlpb.StreamEventsRequest{Target: "api"})
+		Expect(err).NotTo(HaveOccurred())
+		e, err := stream.Recv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.Event).To(Equal(runctl.EventStarted))
+		Expect(e.Target).To(Equal("api"))
+	})
+
+	It("streams the log tail and follows new lines", func(ctx SpecContext) {
+		logPath := filepath.Join(logsDir, "api.run.log")
+		Eventually(func() string {
+			data, _ := os.ReadFile(logPath)
+			return string(data)
+		}).Should(ContainSubstring("two"))
+
+		stream, err := client.StreamLogs(ctx, &runctlpb.StreamLogsRequest{Name: "api", Lines: 3, Follow: true})
+		Expect(err).NotTo(HaveOccurred())
+		recv := func() string {
+			line, err := stream.Recv()
+			Expect(err).NotTo(HaveOccurred())
+			return line.Line
+		}
+		Expect([]string{recv(), recv(), recv()}).To(ContainElement("two"))
+
+		f, err := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND, 0644)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = f.WriteString("three\n")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Close()).To(Succeed())
+
+		Eventually(recv).Should(Equal("three"))
+	})
+})
@@ -0,0 +1,37 @@
+package runctl_test
+
+import (
+	"encoding/json"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/internal/buildinfo"
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("Health", func() {
+	It("reports the version runctl was built from", func() {
+		ctrl, err := runctl.New(runctl.Config{
+			API: runctl.APIConfig{Port: 9100},
+			Targets: map[string]runctl.TargetConfig{
+				"app": {Type: runctl.TargetTypeCommand, Cmd: "sleep 60"},
+			},
+		}, GinkgoT().TempDir(), false)
+		Expect(err).NotTo(HaveOccurred())
+
+		server := serveAPI(ctrl)
+		defer server.Close()
+		resp, err := http.Get(server.URL + "/api/health")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		var health map[string]string
+		Expect(json.NewDecoder(resp.Body).Decode(&health)).To(Succeed())
+		Expect(health).To(HaveKeyWithValue("status", "ok"))
+		Expect(health).To(HaveKeyWithValue("version", buildinfo.Version))
+		Expect(health).To(HaveKeyWithValue("commit", buildinfo.Commit))
+		Expect(health).To(HaveKey("date"))
+	})
+})
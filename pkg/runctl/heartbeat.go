@@ -114,3 +114,16 @@ func (s HeartbeatSummary) HasFailures() bool {
 func (s HeartbeatSummary) FailureTuple() string {
 	return fmt.Sprintf("(%d %d %d)", s.BuildFailures, s.RunFailures, s.TestFailures)
 }
+
+// PlainMarker spells out the heartbeat for plain (colorless) output:
+// "[OK]", "[PENDING]", or "[FAIL] build=1 run=0 test=0".
+func (s HeartbeatSummary) PlainMarker() string {
+	switch {
+	case s.AllHealthy:
+		return "[OK]"
+	case s.HasFailures():
+		return fmt.Sprintf("[FAIL] build=%d run=%d test=%d", s.BuildFailures, s.RunFailures, s.TestFailures)
+	default:
+		return "[PENDING]"
+	}
+}
@@ -0,0 +1,22 @@
+package runctl_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("HeartbeatSummary", func() {
+	It("spells out the heartbeat in plain mode", func() {
+		statuses := []runctl.TargetStatus{
+			{Name: "api", Enabled: true, HasRun: true, State: runctl.StateRunning},
+			{Name: "web", Enabled: true, HasRun: true, State: runctl.StateExited},
+			{Name: "worker", Enabled: true, HasRun: true, State: runctl.StateStarting},
+		}
+
+		Expect(runctl.SummarizeHeartbeat(statuses[:1], nil).PlainMarker()).To(Equal("[OK]"))
+		Expect(runctl.SummarizeHeartbeat(statuses[2:], nil).PlainMarker()).To(Equal("[PENDING]"))
+		Expect(runctl.SummarizeHeartbeat(statuses, nil).PlainMarker()).To(Equal("[FAIL] build=0 run=1 test=0"))
+	})
+})
@@ -0,0 +1,107 @@
+package runctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/google/shlex"
+
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/pkg/messages"
+)
+
+// HookError is a failed on_start or on_stop command.
+type HookError struct {
+	Hook string // "on_start" or "on_stop"
+	Cmd  string
+	Err  error
+}
+
+func (this *HookError) Error() string {
+	return fmt.Sprintf("%s hook %q failed: %v", this.Hook, this.Cmd, this.Err)
+}
+
+func (this *HookError) Unwrap() error {
+	return this.Err
+}
+
+// RunStartHooks runs the on_start commands in order and stops at the first
+// one that fails. Targets should not be started after a failure.
+func (this *Controller) RunStartHooks(ctx context.Context) error {
+	for _, cmd := range this.cfg.OnStart {
+		if err := this.runHook(ctx, "on_start", cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunStopHooks runs every on_stop command, even after one fails, and returns
+// the failures joined. Call it once the targets have stopped.
+func (this *Controller) RunStopHooks(ctx context.Context) error {
+	var errs []error
+	for _, cmd := range this.cfg.OnStop {
+		if err := this.runHook(ctx, "on_stop", cmd); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runHook runs a hook command in the runctl.yaml directory, without a shell,
+// with its output masked on stdout and stderr.
+func (this *Controller) runHook(ctx context.Context, hook, cmdStr string) error {
+	argv, err := parseHook(cmdStr)
+	if err != nil {
+		return &HookError{Hook: hook, Cmd: this.Redact(cmdStr), Err: err}
+	}
+	if log.Structured() {
+		log.Record("runctl", log.EventStatus, hook+": "+this.Redact(cmdStr))
+	} else if log.Enabled(log.LevelInfo) && !log.Quiet() {
+		fmt.Fprintf(os.Stdout, "%s%s %s: %s\n", log.Timestamp(), messages.Get(messages.RunctlPrefix), hook, this.Redact(cmdStr))
+	}
+
+	stdout, stderr := this.masker.Writer(os.Stdout), this.masker.Writer(os.Stderr)
+	defer flushMasked(stdout, os.Stdout)
+	defer flushMasked(stderr, os.Stderr)
+
+	c := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	c.Dir = this.baseDir
+	c.Stdout = stdout
+	c.Stderr = stderr
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	c.Cancel = func() error {
+		return syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+	}
+	c.WaitDelay = 5 * time.Second
+	if err := c.Run(); err != nil {
+		return &HookError{Hook: hook, Cmd: this.Redact(cmdStr), Err: err}
+	}
+	return nil
+}
+
+// parseHook splits a hook command into words the way execrun splits its
+// commands: shell quoting rules, but no shell.
+func parseHook(cmdStr string) ([]string, error) {
+	argv, err := shlex.Split(cmdStr)
+	if err != nil {
+		return nil, fmt.Errorf("parse command: %w", err)
+	}
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	return argv, nil
+}
+
+// flushMasked flushes mw, a masking wrapper of w. w itself stays open.
+func flushMasked(mw, w io.Writer) {
+	if c, ok := mw.(io.Closer); ok && mw != w {
+		c.Close()
+	}
+}
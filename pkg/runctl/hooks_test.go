@@ -0,0 +1,79 @@
+package runctl_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("Hooks", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	newController := func(onStart, onStop []string) *runctl.Controller {
+		ctrl, err := runctl.New(runctl.Config{
+			API:     runctl.APIConfig{Port: 9100},
+			OnStart: onStart,
+			OnStop:  onStop,
+			Targets: map[string]runctl.TargetConfig{
+				"api": {Type: runctl.TargetTypeCommand, Cmd: "sleep 60"},
+			},
+		}, dir, false)
+		Expect(err).NotTo(HaveOccurred())
+		return ctrl
+	}
+
+	readLog := func() string {
+		data, _ := os.ReadFile(filepath.Join(dir, "hooks.log"))
+		return string(data)
+	}
+
+	It("runs on_start commands in order in the config dir", func() {
+		ctrl := newController([]string{`sh -c "echo one >> hooks.log"`, `sh -c "echo two >> hooks.log"`}, nil)
+		Expect(ctrl.RunStartHooks(context.Background())).To(Succeed())
+		Expect(readLog()).To(Equal("one\ntwo\n"))
+	})
+
+	It("stops at the first failing on_start command", func() {
+		ctrl := newController([]string{"false", `sh -c "echo never >> hooks.log"`}, nil)
+		err := ctrl.RunStartHooks(context.Background())
+
+		var hookErr *runctl.HookError
+		Expect(errors.As(err, &hookErr)).To(BeTrue())
+		Expect(hookErr.Hook).To(Equal("on_start"))
+		Expect(hookErr.Cmd).To(Equal("false"))
+		Expect(err).To(MatchError(`on_start hook "false" failed: exit status 1`))
+		Expect(readLog()).To(BeEmpty())
+	})
+
+	It("runs every on_stop command and reports each failure", func() {
+		ctrl := newController(nil, []string{"false", `sh -c "echo down >> hooks.log"`, "no-such-hook-command"})
+		err := ctrl.RunStopHooks(context.Background())
+		Expect(err).To(MatchError(ContainSubstring(`on_stop hook "false" failed`)))
+		Expect(err).To(MatchError(ContainSubstring(`on_stop hook "no-such-hook-command" failed`)))
+		Expect(readLog()).To(Equal("down\n"))
+	})
+
+	It("rejects hook commands that don't parse", func() {
+		cfgPath := filepath.Join(dir, "runctl.yaml")
+		Expect(os.WriteFile(cfgPath, []byte(`
+on_stop:
+  - echo "unterminated
+targets:
+  api:
+    type: command
+    cmd: ./api
+`), 0644)).To(Succeed())
+		_, err := runctl.LoadConfig(cfgPath)
+		Expect(err).To(MatchError(ContainSubstring("on_stop: command 0: parse command")))
+	})
+})
@@ -0,0 +1,64 @@
+package runctl
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// touch records activity, which postpones the idle timeout.
+func (this *target) touch() {
+	this.lastActive.Store(this.clock.Now().UnixNano())
+}
+
+// activityWriter records activity on every write to the process output.
+type activityWriter struct {
+	t *target
+	w io.Writer
+}
+
+func (this activityWriter) Write(p []byte) (int, error) {
+	this.t.touch()
+	return this.w.Write(p)
+}
+
+// watchIdle suspends the target's process whenever it has been idle for
+// timeout, until ctx is cancelled.
+func (this *target) watchIdle(ctx context.Context, timeout time.Duration) {
+	wait := timeout
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-this.clock.After(wait):
+		}
+		idle := this.clock.Now().Sub(time.Unix(0, this.lastActive.Load()))
+		if idle < timeout {
+			wait = timeout - idle
+			continue
+		}
+		this.suspend()
+		wait = timeout
+	}
+}
+
+// suspend stops a running process but keeps the run loop and its watcher,
+// so the next file change or start brings the process back.
+func (this *target) suspend() {
+	this.mu.Lock()
+	defer this.flush()
+	defer this.mu.Unlock()
+	if this.state != StateRunning {
+		return
+	}
+	select {
+	case this.execStop <- struct{}{}:
+	default:
+		return
+	}
+	old := this.state
+	this.state = StateSuspended
+	this.currentStage = ""
+	this.pid = 0
+	this.emit(Event{Event: EventSuspended, OldState: old})
+}
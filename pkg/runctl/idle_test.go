@@ -0,0 +1,112 @@
+package runctl_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/clock"
+	"github.com/gur-shatz/go-run/pkg/execrun"
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("Idle shutdown", func() {
+	var (
+		ctrl *runctl.Controller
+		clk  *clock.Fake
+		dir  string
+	)
+
+	BeforeEach(func() {
+		clk = clock.NewFake(time.Now())
+		dir = GinkgoT().TempDir()
+		var err error
+		ctrl, err = runctl.New(runctl.Config{
+			API:   runctl.APIConfig{Port: 9100},
+			Stats: new(bool), // killing the process records a crash
+			Targets: map[string]runctl.TargetConfig{
+				"app": {Type: runctl.TargetTypeCommand, Cmd: "sleep 60", Watch: execrun.Watches("trigger.txt"), IdleTimeout: "10m"},
+			},
+		}, dir, false)
+		Expect(err).NotTo(HaveOccurred())
+		ctrl.SetClock(clk)
+		ctrl.StartTargets()
+		DeferCleanup(ctrl.KillTargets)
+	})
+
+	status := func() *runctl.TargetStatus {
+		st, err := ctrl.TargetStatus("app")
+		Expect(err).NotTo(HaveOccurred())
+		return st
+	}
+
+	// advanceUntil moves the clock a minute at a time until the target
+	// reaches state.
+	advanceUntil := func(state runctl.TargetState) {
+		Eventually(func() runctl.TargetState {
+			clk.Advance(time.Minute)
+			return status().State
+		}, "5s", "20ms").Should(Equal(state))
+	}
+
+	suspend := func() int {
+		Eventually(func() runctl.TargetState { return status().State }, "5s", "20ms").Should(Equal(runctl.StateRunning))
+		pid := status().PID
+		advanceUntil(runctl.StateSuspended)
+		Expect(status().PID).To(BeZero())
+		Expect(ctrl.RecentEvents()).To(ContainElement(HaveField("Event", runctl.EventSuspended)))
+		return pid
+	}
+
+	It("suspends an idle process and starts it again on a file change", func() {
+		pid := suspend()
+		Expect(os.WriteFile(filepath.Join(dir, "trigger.txt"), []byte("go\n"), 0644)).To(Succeed())
+		advanceUntil(runctl.StateRunning)
+		Expect(status().PID).NotTo(Equal(pid))
+	})
+
+	It("starts a suspended target on an explicit start", func() {
+		suspend()
+		Expect(ctrl.StartTarget("app")).To(Succeed())
+		Eventually(func() runctl.TargetState { return status().State }, "5s", "20ms").Should(Equal(runctl.StateRunning))
+	})
+})
+
+var _ = Describe("idle_timeout", func() {
+	load := func(content string) (*runctl.Config, error) {
+		cfgPath := filepath.Join(GinkgoT().TempDir(), "runctl.yaml")
+		Expect(os.WriteFile(cfgPath, []byte(content), 0644)).To(Succeed())
+		return runctl.LoadConfig(cfgPath)
+	}
+
+	It("defaults to the top-level value, which 0 turns off", func() {
+		cfg, err := load(`
+idle_timeout: 30m
+targets:
+  api:
+    type: command
+    cmd: ./api
+  db:
+    type: command
+    cmd: ./db
+    idle_timeout: 0
+`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Targets["api"].IdleTimeoutDuration()).To(Equal(30 * time.Minute))
+		Expect(cfg.Targets["db"].IdleTimeoutDuration()).To(BeZero())
+	})
+
+	It("rejects a bad duration", func() {
+		_, err := load(`
+targets:
+  api:
+    type: command
+    cmd: ./api
+    idle_timeout: soon
+`)
+		Expect(err).To(MatchError(ContainSubstring(`target "api": idle_timeout must be a duration like 30m (0 turns it off), got "soon"`)))
+	})
+})
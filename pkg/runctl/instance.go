@@ -0,0 +1,28 @@
+package runctl
+
+import (
+	"hash/fnv"
+	"regexp"
+)
+
+// DefaultAPIPort is the API port when neither api.port nor instance_name is
+// set.
+const DefaultAPIPort = 9100
+
+// instancePortRange is how many ports above DefaultAPIPort named instances
+// are spread over.
+const instancePortRange = 1000
+
+var reInstanceName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// defaultAPIPort returns the API port for an instance without api.port.
+// Named instances get a stable port derived from the name, so two checkouts
+// with different instance names don't fight over 9100.
+func defaultAPIPort(instance string) int {
+	if instance == "" {
+		return DefaultAPIPort
+	}
+	h := fnv.New32a()
+	h.Write([]byte(instance))
+	return DefaultAPIPort + 1 + int(h.Sum32()%instancePortRange)
+}
@@ -0,0 +1,68 @@
+package runctl_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("Instances", func() {
+	load := func(dir, header string) (*runctl.Config, error) {
+		path := filepath.Join(dir, "runctl.yaml")
+		Expect(os.WriteFile(path, []byte(header+`
+targets:
+  api:
+    type: docker
+`), 0644)).To(Succeed())
+		return runctl.LoadConfig(path)
+	}
+
+	It("derives a stable default port from the instance name", func() {
+		dir := GinkgoT().TempDir()
+		a, err := load(dir, "instance_name: alice")
+		Expect(err).NotTo(HaveOccurred())
+		again, err := load(dir, "instance_name: alice")
+		Expect(err).NotTo(HaveOccurred())
+		b, err := load(dir, "instance_name: bob")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(a.API.Port).To(BeNumerically(">", runctl.DefaultAPIPort))
+		Expect(a.API.Port).To(Equal(again.API.Port))
+		Expect(a.API.Port).NotTo(Equal(b.API.Port))
+	})
+
+	It("keeps an explicit api.port", func() {
+		cfg, err := load(GinkgoT().TempDir(), "instance_name: alice\napi:\n  port: 9200")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.API.Port).To(Equal(9200))
+	})
+
+	It("uses the plain defaults without an instance name", func() {
+		cfg, err := load(GinkgoT().TempDir(), "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.API.Port).To(Equal(runctl.DefaultAPIPort))
+		Expect(runctl.ContainerName("", "api")).To(Equal("runctl-api"))
+	})
+
+	It("namespaces scratch dirs and container names", func() {
+		dir := GinkgoT().TempDir()
+		a, err := load(dir, "instance_name: alice")
+		Expect(err).NotTo(HaveOccurred())
+		b, err := load(dir, "instance_name: bob")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filepath.Base(a.ScratchDir)).To(HavePrefix("alice-"))
+		Expect(a.ScratchDir).NotTo(Equal(b.ScratchDir))
+		Expect(a.Targets["api"].Instance).To(Equal("alice"))
+		Expect(runctl.ContainerName("alice", "api")).To(Equal("runctl-alice-api"))
+	})
+
+	It("rejects unsafe instance names", func() {
+		_, err := load(GinkgoT().TempDir(), "instance_name: ../evil")
+		Expect(err).To(MatchError(ContainSubstring("instance_name must be")))
+	})
+})
@@ -0,0 +1,92 @@
+package runctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/gur-shatz/go-run/internal/log"
+)
+
+// rotateSuffixLayout is the timestamp appended to rotated log files.
+const rotateSuffixLayout = "20060102-150405"
+
+// janitorInterval is how often the janitor looks for expired files.
+const janitorInterval = time.Hour
+
+// reRotatedLog matches log files renamed by rotateLogFile, e.g.
+// api.run.20250102-150405.log or api.run.20250102-150405.1.log. Live logs
+// and their current segments never match.
+var reRotatedLog = regexp.MustCompile(`\.\d{8}-\d{6}(\.\d+)?\.log$`)
+
+// RunJanitor deletes rotated log files older than logs_retention and scratch
+// dirs nothing owns anymore, once at start and then hourly, until ctx is
+// cancelled.
+func (this *Controller) RunJanitor(ctx context.Context) {
+	ticker := time.NewTicker(janitorInterval)
+	defer ticker.Stop()
+
+	for {
+		this.cleanup()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// cleanup runs one janitor pass. Failures are logged and retried next pass.
+func (this *Controller) cleanup() {
+	removed := 0
+	if retention := this.cfg.LogsRetentionDuration(); this.cfg.LogsDir != "" && retention > 0 {
+		n, err := pruneRotatedLogs(this.cfg.LogsDir, time.Now().Add(-retention))
+		if err != nil {
+			warnf("log cleanup: %v", err)
+		}
+		removed += n
+	}
+	if cacheDir, err := scratchCacheDir(); err == nil && this.cfg.ScratchDir != "" {
+		n, err := pruneScratch(cacheDir, this.cfg.ScratchDir, this.cfg.Targets)
+		if err != nil {
+			warnf("scratch cleanup: %v", err)
+		}
+		removed += n
+	}
+	if removed > 0 && log.VerboseEnabled(this.verbose) {
+		msg := fmt.Sprintf("Removed %d expired file(s) and dir(s)", removed)
+		if log.Structured() {
+			log.Record("runctl", log.EventVerbose, msg)
+		} else {
+			fmt.Fprintln(os.Stderr, log.Timestamp()+"[runctl] "+msg)
+		}
+	}
+}
+
+// pruneRotatedLogs deletes rotated log files in dir last modified before
+// cutoff and returns how many were removed.
+func pruneRotatedLogs(dir string, cutoff time.Time) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, e := range entries {
+		if e.IsDir() || !reRotatedLog.MatchString(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+			return removed, fmt.Errorf("remove %s: %w", e.Name(), err)
+		}
+		removed++
+	}
+	return removed, nil
+}
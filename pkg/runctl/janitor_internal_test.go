@@ -0,0 +1,46 @@
+package runctl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPruneRotatedLogs(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-48 * time.Hour)
+	files := map[string]time.Time{
+		"api.run.log":                   old, // live log, never pruned
+		"api.run.20250101-120000.log":   old,
+		"api.build.20250101-120000.log": old,
+		"api.run.20250102-120000.log":   time.Now(),
+		"notes.txt":                     old,
+	}
+	for name, mtime := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := pruneRotatedLogs(dir, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("pruneRotatedLogs: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("removed = %d, want 2", removed)
+	}
+
+	for name := range files {
+		_, err := os.Stat(filepath.Join(dir, name))
+		gone := os.IsNotExist(err)
+		wantGone := name == "api.run.20250101-120000.log" || name == "api.build.20250101-120000.log"
+		if gone != wantGone {
+			t.Errorf("%s: removed = %v, want %v", name, gone, wantGone)
+		}
+	}
+}
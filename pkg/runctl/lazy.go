@@ -0,0 +1,211 @@
+package runctl
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultLazyTimeout bounds how long a connection to a lazy target is held
+// while the target builds and starts.
+const DefaultLazyTimeout = 2 * time.Minute
+
+// LazyConfig makes a target start on demand. runctl holds Listen and, on
+// the first connection, builds and starts the target, then forwards every
+// connection to Upstream once the process accepts them.
+type LazyConfig struct {
+	Listen   string `yaml:"listen"`            // address clients connect to, e.g. :8080
+	Upstream string `yaml:"upstream"`          // address the process listens on, e.g. localhost:18080
+	Timeout  string `yaml:"timeout,omitempty"` // how long a connection waits for the process (default: 2m)
+}
+
+// TimeoutDuration returns how long a connection waits for the process
+// (default: 2m). Validate rejects unparsable values.
+func (this LazyConfig) TimeoutDuration() time.Duration {
+	if d, err := time.ParseDuration(this.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return DefaultLazyTimeout
+}
+
+func (this LazyConfig) validate() error {
+	for _, addr := range []struct{ key, value string }{{"listen", this.Listen}, {"upstream", this.Upstream}} {
+		if _, _, err := net.SplitHostPort(addr.value); err != nil {
+			return fmt.Errorf("lazy.%s must be host:port or :port, got %q", addr.key, addr.value)
+		}
+	}
+	if this.Listen == this.Upstream {
+		return fmt.Errorf("lazy.listen and lazy.upstream must differ")
+	}
+	if this.Timeout != "" {
+		if d, err := time.ParseDuration(this.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("lazy.timeout must be a positive duration like 2m, got %q", this.Timeout)
+		}
+	}
+	return nil
+}
+
+// lazyProxy holds a lazy target's port and forwards connections to it,
+// starting it first when it isn't running.
+type lazyProxy struct {
+	t   *target
+	cfg LazyConfig
+	ln  net.Listener
+
+	mu       sync.Mutex
+	conns    map[net.Conn]struct{}
+	starting bool // a start was triggered and no connection got through yet
+}
+
+// launch starts the target, or for a lazy target only holds its port until
+// the first connection.
+func (this *target) launch() error {
+	if this.tcfg.Lazy != nil {
+		return this.listenLazy()
+	}
+	return this.Start()
+}
+
+// listenLazy starts holding the target's lazy.listen port. It does nothing
+// if the port is already held.
+func (this *target) listenLazy() error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if this.lazy != nil {
+		return nil
+	}
+	ln, err := net.Listen("tcp", this.tcfg.Lazy.Listen)
+	if err != nil {
+		return fmt.Errorf("lazy: %w", err)
+	}
+	this.lazy = &lazyProxy{t: this, cfg: *this.tcfg.Lazy, ln: ln, conns: make(map[net.Conn]struct{})}
+	go this.lazy.serve()
+	return nil
+}
+
+// closeLazy releases the lazy port and drops the connections through it.
+func (this *target) closeLazy() {
+	this.mu.Lock()
+	p := this.lazy
+	this.lazy = nil
+	this.mu.Unlock()
+	if p == nil {
+		return
+	}
+	p.ln.Close()
+	p.mu.Lock()
+	for c := range p.conns {
+		c.Close()
+	}
+	p.mu.Unlock()
+}
+
+func (this *lazyProxy) serve() {
+	for {
+		c, err := this.ln.Accept()
+		if err != nil {
+			return
+		}
+		go this.handle(c)
+	}
+}
+
+// handle starts the target if needed and pipes c to the process.
+func (this *lazyProxy) handle(c net.Conn) {
+	this.track(c, true)
+	defer this.track(c, false)
+	defer c.Close()
+
+	this.t.touch()
+	if err := this.start(); err != nil {
+		warnf("%s: start on connection: %v", this.t.name, err)
+		return
+	}
+	up, err := this.dial()
+	this.mu.Lock()
+	this.starting = false
+	this.mu.Unlock()
+	if err != nil {
+		warnf("%s: %v", this.t.name, err)
+		return
+	}
+	this.track(up, true)
+	defer this.track(up, false)
+	defer up.Close()
+
+	// Traffic both ways counts as activity for idle_timeout.
+	done := make(chan struct{})
+	go func() {
+		io.Copy(activityWriter{t: this.t, w: c}, up)
+		c.Close()
+		close(done)
+	}()
+	io.Copy(activityWriter{t: this.t, w: up}, c)
+	up.Close()
+	<-done
+}
+
+// dial connects to the upstream, retrying until the process accepts
+// connections or the lazy timeout elapses.
+func (this *lazyProxy) dial() (net.Conn, error) {
+	timeout := this.cfg.TimeoutDuration()
+	deadline := time.Now().Add(timeout)
+	for {
+		up, err := net.DialTimeout("tcp", this.cfg.Upstream, time.Second)
+		if err == nil {
+			return up, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out after %s waiting for %s: %w", timeout, this.cfg.Upstream, err)
+		}
+		time.Sleep(waitPollInterval)
+	}
+}
+
+// track records open connections, so closeLazy can drop them.
+func (this *lazyProxy) track(c net.Conn, open bool) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if open {
+		this.conns[c] = struct{}{}
+	} else {
+		delete(this.conns, c)
+	}
+}
+
+// start starts the target for an incoming connection unless a start is
+// already under way: a suspended process is started again, a run loop whose
+// process is down rebuilds, and a stopped target is started. A running or
+// starting target is left alone.
+func (this *lazyProxy) start() error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if this.starting {
+		return nil
+	}
+
+	t := this.t
+	t.mu.Lock()
+	state, looping := t.state, t.cancel != nil
+	t.mu.Unlock()
+
+	switch {
+	case state == StateRunning || state == StateStarting:
+		return nil
+	case state == StateSuspended && looping:
+		t.StartExec()
+	case looping:
+		t.Build()
+	default:
+		if err := t.Start(); err != nil {
+			return err
+		}
+	}
+	this.starting = true
+	if err := t.appendRunLogMarker(fmt.Sprintf("Starting on demand: connection to %s", this.cfg.Listen)); err != nil {
+		warnf("failed to write %s run log: %v", t.name, err)
+	}
+	return nil
+}
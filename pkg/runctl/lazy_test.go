@@ -0,0 +1,94 @@
+package runctl_test
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+// freeAddr returns a localhost address nothing listens on.
+func freeAddr() string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+var _ = Describe("Lazy targets", func() {
+	It("starts the target on the first connection and forwards it", func() {
+		dir := GinkgoT().TempDir()
+		listen, upstream := freeAddr(), freeAddr()
+		ctrl, err := runctl.New(runctl.Config{
+			API:   runctl.APIConfig{Port: 9100},
+			Stats: new(bool), // killing the process records a crash
+			Targets: map[string]runctl.TargetConfig{
+				"app": {
+					Type: runctl.TargetTypeCommand,
+					Cmd:  `sh -c "touch started; sleep 60"`,
+					Lazy: &runctl.LazyConfig{Listen: listen, Upstream: upstream},
+				},
+			},
+		}, dir, false)
+		Expect(err).NotTo(HaveOccurred())
+		ctrl.StartTargets()
+		DeferCleanup(ctrl.KillTargets)
+
+		Consistently(func() error {
+			_, err := os.Stat(filepath.Join(dir, "started"))
+			return err
+		}, "300ms", "50ms").Should(MatchError(os.ErrNotExist))
+		st, err := ctrl.TargetStatus("app")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(st.State).To(Equal(runctl.StateIdle))
+
+		c, err := net.Dial("tcp", listen)
+		Expect(err).NotTo(HaveOccurred())
+		defer c.Close()
+		Eventually(filepath.Join(dir, "started"), "5s", "20ms").Should(BeAnExistingFile())
+
+		// Stand in for the process's server: echo one line back.
+		ln, err := net.Listen("tcp", upstream)
+		Expect(err).NotTo(HaveOccurred())
+		defer ln.Close()
+		go func() {
+			defer GinkgoRecover()
+			up, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer up.Close()
+			line, _ := bufio.NewReader(up).ReadString('\n')
+			up.Write([]byte(line))
+		}()
+
+		_, err = c.Write([]byte("ping\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bufio.NewReader(c).ReadString('\n')).To(Equal("ping\n"))
+		Eventually(func() runctl.TargetState {
+			st, err := ctrl.TargetStatus("app")
+			Expect(err).NotTo(HaveOccurred())
+			return st.State
+		}, "5s", "20ms").Should(Equal(runctl.StateRunning))
+	})
+
+	It("rejects a lazy address without a port", func() {
+		cfgPath := filepath.Join(GinkgoT().TempDir(), "runctl.yaml")
+		Expect(os.WriteFile(cfgPath, []byte(`
+targets:
+  api:
+    type: command
+    cmd: ./api
+    lazy:
+      listen: ":8080"
+      upstream: localhost
+`), 0644)).To(Succeed())
+		_, err := runctl.LoadConfig(cfgPath)
+		Expect(err).To(MatchError(ContainSubstring(`target "api": lazy.upstream must be host:port or :port, got "localhost"`)))
+	})
+})
@@ -0,0 +1,99 @@
+package runctl_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("Log download", func() {
+	var (
+		baseURL string
+		logPath string
+	)
+
+	BeforeEach(func() {
+		dir := GinkgoT().TempDir()
+		logPath = filepath.Join(dir, "app.run.log")
+		ctrl, err := runctl.New(runctl.Config{
+			API: runctl.APIConfig{Port: 9100},
+			Targets: map[string]runctl.TargetConfig{
+				"app": {
+					Type: runctl.TargetTypeCommand,
+					Cmd:  "sleep 60",
+					Logs: &runctl.LogsConfig{Run: logPath},
+				},
+			},
+		}, dir, false)
+		Expect(err).NotTo(HaveOccurred())
+		server := serveAPI(ctrl)
+		DeferCleanup(server.Close)
+		baseURL = server.URL + "/api/targets/app/logs/download"
+	})
+
+	get := func(query string) *http.Response {
+		resp, err := http.Get(baseURL + query)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(resp.Body.Close)
+		return resp
+	}
+
+	It("streams the whole log as an attachment", func() {
+		Expect(os.WriteFile(logPath, []byte("line 1\nline 2\n"), 0644)).To(Succeed())
+
+		resp := get("?stage=run")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Disposition")).To(Equal(`attachment; filename=app.run.log`))
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("line 1\nline 2\n"))
+	})
+
+	It("compresses the log with gzip=true", func() {
+		Expect(os.WriteFile(logPath, []byte("line 1\n"), 0644)).To(Succeed())
+
+		resp := get("?gzip=true")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Type")).To(Equal("application/gzip"))
+		Expect(resp.Header.Get("Content-Disposition")).To(Equal(`attachment; filename=app.run.log.gz`))
+		zr, err := gzip.NewReader(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		body, err := io.ReadAll(zr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("line 1\n"))
+	})
+
+	It("rejects stages without a log", func() {
+		Expect(get("?stage=build").StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(get("?stage=nope").StatusCode).To(Equal(http.StatusBadRequest))
+	})
+
+	It("returns 404 before the log is written", func() {
+		Expect(get("").StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("includes rotated segments, oldest first", func() {
+		Expect(os.WriteFile(filepath.Join(filepath.Dir(logPath), "app.run.2.log"), []byte("line 1\n"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(filepath.Dir(logPath), "app.run.1.log"), []byte("line 2\n"), 0644)).To(Succeed())
+		Expect(os.WriteFile(logPath, []byte("line 3\n"), 0644)).To(Succeed())
+
+		resp := get("")
+		Expect(resp.Header.Get("Content-Length")).To(Equal("21"))
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("line 1\nline 2\nline 3\n"))
+
+		zr, err := gzip.NewReader(get("?gzip=true").Body)
+		Expect(err).NotTo(HaveOccurred())
+		body, err = io.ReadAll(zr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("line 1\nline 2\nline 3\n"))
+	})
+})
@@ -0,0 +1,120 @@
+package runctl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// defaultLogsMaxFiles is how many rotated segments are kept per log file
+// when logs_max_size is set and logs_max_files is not.
+const defaultLogsMaxFiles = 3
+
+// segmentPath returns the k-th rotated segment of the log file at path,
+// "<base>.<k><ext>"; segment 1 is the newest.
+func segmentPath(path string, k int) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s.%d%s", strings.TrimSuffix(path, ext), k, ext)
+}
+
+// logSegments returns the existing rotated segments of the log file at
+// path, oldest first, followed by path itself.
+func logSegments(path string) []string {
+	var segs []string
+	for k := 1; ; k++ {
+		p := segmentPath(path, k)
+		if _, err := os.Stat(p); err != nil {
+			break
+		}
+		segs = append([]string{p}, segs...)
+	}
+	return append(segs, path)
+}
+
+// rotatingFile is a log file that is renamed to segment 1 once it reaches
+// maxSize, shifting older segments up and deleting those past maxFiles.
+type rotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	f        *os.File
+	size     int64
+	maxSize  int64
+	maxFiles int
+}
+
+// openRotatingFile opens the log file at path for append, rotating it
+// before a write would take it past maxSize.
+func openRotatingFile(path string, maxSize int64, maxFiles int) (*rotatingFile, error) {
+	this := &rotatingFile{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := this.open(); err != nil {
+		return nil, err
+	}
+	return this, nil
+}
+
+func (this *rotatingFile) open() error {
+	f, err := os.OpenFile(this.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	this.f, this.size = f, info.Size()
+	return nil
+}
+
+func (this *rotatingFile) Write(p []byte) (int, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if this.f == nil {
+		return 0, os.ErrClosed
+	}
+	if this.size > 0 && this.size+int64(len(p)) > this.maxSize {
+		if err := this.rotate(); err != nil {
+			if this.f == nil {
+				this.open()
+			}
+			return 0, fmt.Errorf("rotate log %s: %w", this.path, err)
+		}
+	}
+	n, err := this.f.Write(p)
+	this.size += int64(n)
+	return n, err
+}
+
+// rotate moves the file to segment 1 and opens a fresh one.
+func (this *rotatingFile) rotate() error {
+	if err := this.f.Close(); err != nil {
+		return err
+	}
+	this.f = nil
+	if err := os.Remove(segmentPath(this.path, this.maxFiles)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for k := this.maxFiles - 1; k >= 1; k-- {
+		if err := os.Rename(segmentPath(this.path, k), segmentPath(this.path, k+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Rename(this.path, segmentPath(this.path, 1)); err != nil {
+		return err
+	}
+	return this.open()
+}
+
+func (this *rotatingFile) Close() error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if this.f == nil {
+		return nil
+	}
+	err := this.f.Close()
+	this.f = nil
+	return err
+}
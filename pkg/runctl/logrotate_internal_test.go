@@ -0,0 +1,149 @@
+package runctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.run.log")
+	f, err := openRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 4; i++ {
+		if _, err := fmt.Fprintf(f, "line %d\n", i); err != nil { // 7 bytes: one line per file
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for p, want := range map[string]string{
+		path:                 "line 4\n",
+		segmentPath(path, 1): "line 3\n",
+		segmentPath(path, 2): "line 2\n",
+	} {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(p), data, want)
+		}
+	}
+	if _, err := os.Stat(segmentPath(path, 3)); !os.IsNotExist(err) {
+		t.Errorf("segment past logs_max_files kept: %v", err)
+	}
+}
+
+// writeSegments writes a log and two rotated segments holding lines 1-6.
+func writeSegments(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "api.run.log")
+	for p, data := range map[string]string{
+		segmentPath(path, 2): "1\n2\n",
+		segmentPath(path, 1): "3\n4\n",
+		path:                 "5\n6\n",
+	} {
+		if err := os.WriteFile(p, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func TestTailFileAcrossSegments(t *testing.T) {
+	path := writeSegments(t)
+	lines, err := tailFile(path, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"2", "3", "4", "5", "6"}; !slices.Equal(lines, want) {
+		t.Errorf("tailFile = %q, want %q", lines, want)
+	}
+}
+
+func TestReadLineRangeAcrossSegments(t *testing.T) {
+	path := writeSegments(t)
+	lines, total, err := readLineRange(path, 1, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 6 {
+		t.Errorf("totalLines = %d, want 6", total)
+	}
+	if want := []string{"2", "3", "4", "5"}; !slices.Equal(lines, want) {
+		t.Errorf("readLineRange = %q, want %q", lines, want)
+	}
+}
+
+func TestRotateLogFileRenamesSegments(t *testing.T) {
+	path := writeSegments(t)
+	if err := rotateLogFile(path, "20250102-150405"); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+		if !reRotatedLog.MatchString(e.Name()) {
+			t.Errorf("%s does not look rotated", e.Name())
+		}
+	}
+	want := []string{"api.run.20250102-150405.1.log", "api.run.20250102-150405.2.log", "api.run.20250102-150405.log"}
+	if !slices.Equal(names, want) {
+		t.Errorf("files = %q, want %q", names, want)
+	}
+}
+
+func TestFollowLogAcrossRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.run.log")
+	f, err := openRotatingFile(path, 8, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	lines := make(chan string, 10)
+	go followLog(ctx, path, 0, func(line string) error {
+		lines <- line
+		return nil
+	})
+
+	fmt.Fprint(f, "first\n")
+	if got := <-lines; got != "first" {
+		t.Fatalf("got %q, want first", got)
+	}
+	// Both writes land between polls, rotating twice: the rest of the
+	// followed file is segment 2 and "second" is in segment 1.
+	fmt.Fprint(f, "second\n")
+	fmt.Fprint(f, "third\n")
+	var got []string
+	for len(got) < 2 {
+		select {
+		case line := <-lines:
+			got = append(got, line)
+		case <-ctx.Done():
+			t.Fatalf("followed %q, want second and third", got)
+		}
+	}
+	if want := []string{"second", "third"}; !slices.Equal(got, want) {
+		t.Errorf("followed %q, want %q", got, want)
+	}
+	if data, _ := os.ReadFile(segmentPath(path, 1)); !strings.Contains(string(data), "second") {
+		t.Errorf("segment 1 = %q, want the second line", data)
+	}
+}
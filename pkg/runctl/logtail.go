@@ -3,6 +3,7 @@ package runctl
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -12,9 +13,22 @@ import (
 	"time"
 )
 
-// rotateLogFile renames a non-empty log file at path to "<base>.<suffix><ext>".
-// Returns nil if the file is missing or empty (nothing to rotate).
+// rotateLogFile renames a non-empty log file at path to "<base>.<suffix><ext>",
+// and its rotated segments "<base>.<k><ext>" to "<base>.<suffix>.<k><ext>".
+// Missing or empty files are left alone (nothing to rotate).
 func rotateLogFile(path, suffix string) error {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for k := 1; ; k++ {
+		seg := segmentPath(path, k)
+		if _, err := os.Stat(seg); err != nil {
+			break
+		}
+		if err := os.Rename(seg, fmt.Sprintf("%s.%s.%d%s", base, suffix, k, ext)); err != nil {
+			return err
+		}
+	}
+
 	info, err := os.Stat(path)
 	if errors.Is(err, os.ErrNotExist) {
 		return nil
@@ -25,8 +39,6 @@ func rotateLogFile(path, suffix string) error {
 	if info.Size() == 0 {
 		return nil
 	}
-	ext := filepath.Ext(path)
-	base := strings.TrimSuffix(path, ext)
 	rotated := fmt.Sprintf("%s.%s%s", base, suffix, ext)
 	return os.Rename(path, rotated)
 }
@@ -50,8 +62,26 @@ func writeMarker(path string) error {
 	return nil
 }
 
-// tailFile reads the last n lines from a file. Returns the lines and any error.
+// tailFile reads the last n lines from a log file, continuing into its
+// rotated segments if the file has fewer. Returns the lines and any error.
 func tailFile(path string, n int) ([]string, error) {
+	segs := logSegments(path)
+	lines, err := tailOne(segs[len(segs)-1], n)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(segs) - 2; i >= 0 && len(lines) < n; i-- {
+		older, err := tailOne(segs[i], n-len(lines))
+		if err != nil {
+			return nil, err
+		}
+		lines = append(older, lines...)
+	}
+	return lines, nil
+}
+
+// tailOne reads the last n lines from a single file.
+func tailOne(path string, n int) ([]string, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("open log file: %w", err)
@@ -91,29 +121,32 @@ func readAllLines(r io.Reader, n int) ([]string, error) {
 	return lines, nil
 }
 
-// readLineRange reads lines from offset to offset+limit from a file.
+// readLineRange reads lines from offset to offset+limit from a log file and
+// its rotated segments, numbered from the start of the oldest segment.
 // If limit is 0, no lines are returned (useful for getting just totalLines).
-// Returns the lines, total line count in the file, and any error.
+// Returns the lines, total line count in the files, and any error.
 func readLineRange(path string, offset, limit int) ([]string, int, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, 0, fmt.Errorf("open log file: %w", err)
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	scanner.Buffer(make([]byte, 1024*1024), 1024*1024) // 1MB max line length
-
 	var lines []string
 	lineNum := 0
-	for scanner.Scan() {
-		if limit > 0 && lineNum >= offset && lineNum < offset+limit {
-			lines = append(lines, scanner.Text())
+	for _, seg := range logSegments(path) {
+		f, err := os.Open(seg)
+		if err != nil {
+			return nil, 0, fmt.Errorf("open log file: %w", err)
+		}
+
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 1024*1024), 1024*1024) // 1MB max line length
+		for scanner.Scan() {
+			if limit > 0 && lineNum >= offset && lineNum < offset+limit {
+				lines = append(lines, scanner.Text())
+			}
+			lineNum++
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, 0, err
 		}
-		lineNum++
-	}
-	if err := scanner.Err(); err != nil {
-		return nil, 0, err
 	}
 	return lines, lineNum, nil
 }
@@ -157,3 +190,113 @@ func seekTail(f *os.File, size int64, n int) ([]string, error) {
 	}
 	return lines, nil
 }
+
+// logFollowInterval is how often followLog polls for new output.
+const logFollowInterval = 250 * time.Millisecond
+
+// followLog calls emit for each complete line appended to the file at path
+// after offset, until ctx is done or emit fails. A file that shrinks or is
+// replaced (log rotation on restart) is read again from the start; when it
+// was rotated by size, the rest of the old file and any newer segments are
+// read first.
+func followLog(ctx context.Context, path string, offset int64, emit func(string) error) error {
+	ticker := time.NewTicker(logFollowInterval)
+	defer ticker.Stop()
+
+	var prev os.FileInfo
+	var partial []byte
+	emitLines := func() error {
+		for {
+			i := bytes.IndexByte(partial, '\n')
+			if i < 0 {
+				return nil
+			}
+			if err := emit(string(partial[:i])); err != nil {
+				return err
+			}
+			partial = partial[i+1:]
+		}
+	}
+	for {
+		info, err := os.Stat(path)
+		if err == nil {
+			if prev != nil && !os.SameFile(prev, info) {
+				if err := followSegments(path, prev, offset, func(data []byte) error {
+					partial = append(partial, data...)
+					return emitLines()
+				}); err != nil {
+					return err
+				}
+				offset, partial = 0, nil
+			} else if info.Size() < offset {
+				offset, partial = 0, nil
+			}
+			prev = info
+			if info.Size() > offset {
+				data, err := readRange(path, offset, info.Size())
+				if err != nil {
+					return err
+				}
+				offset += int64(len(data))
+				partial = append(partial, data...)
+				if err := emitLines(); err != nil {
+					return err
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// followSegments finds the followed file prev among the rotated segments of
+// path and passes on the rest of it, after offset, and all of each newer
+// segment. Does nothing if prev is not a segment (e.g. it was deleted).
+func followSegments(path string, prev os.FileInfo, offset int64, read func([]byte) error) error {
+	segs := logSegments(path)
+	segs = segs[:len(segs)-1]
+	for i, seg := range segs {
+		info, err := os.Stat(seg)
+		if err != nil || !os.SameFile(prev, info) {
+			continue
+		}
+		for _, seg := range segs[i:] {
+			info, err := os.Stat(seg)
+			if err != nil {
+				return nil
+			}
+			if info.Size() > offset {
+				data, err := readRange(seg, offset, info.Size())
+				if err != nil {
+					return err
+				}
+				if err := read(data); err != nil {
+					return err
+				}
+			}
+			offset = 0
+		}
+		return nil
+	}
+	return nil
+}
+
+// readRange returns the bytes of the file at path in [from, to).
+func readRange(path string, from, to int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open log file: %w", err)
+	}
+	defer f.Close()
+
+	buf := make([]byte, to-from)
+	n, err := f.ReadAt(buf, from)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("read log file: %w", err)
+	}
+	return buf[:n], nil
+}
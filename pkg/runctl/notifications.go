@@ -0,0 +1,115 @@
+package runctl
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// notifyEvents are sent to webhooks that don't list events of their own.
+var notifyEvents = []string{EventBuildFailed, EventTestFailed, EventCrashed, EventCrashLoop, EventRecovered}
+
+// SignatureHeader carries the hex HMAC-SHA256 of the body, as "sha256=<hex>",
+// for webhooks with a secret.
+const SignatureHeader = "X-Runctl-Signature"
+
+// notifier delivers notifications to webhooks in the background.
+type notifier struct {
+	webhooks   []WebhookConfig
+	retries    int
+	backoff    time.Duration // delay before the first retry; doubles each attempt
+	httpClient *http.Client
+}
+
+// newNotifier returns nil when no webhooks are configured.
+func newNotifier(cfg NotificationsConfig) *notifier {
+	if len(cfg.Webhooks) == 0 {
+		return nil
+	}
+	retries := 3
+	if cfg.Retries != nil {
+		retries = *cfg.Retries
+	}
+	return &notifier{
+		webhooks:   cfg.Webhooks,
+		retries:    retries,
+		backoff:    time.Second,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify sends e to every webhook subscribed to its event without blocking.
+// Safe to call on a nil notifier.
+func (this *notifier) Notify(e Event) {
+	if this == nil {
+		return
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	for _, wh := range this.webhooks {
+		events := wh.Events
+		if len(events) == 0 {
+			events = notifyEvents
+		}
+		if !slices.Contains(events, e.Event) {
+			continue
+		}
+		go func() {
+			if err := this.deliver(wh, body); err != nil {
+				warnf("webhook %s: %v", wh.URL, err)
+			}
+		}()
+	}
+}
+
+// deliver POSTs body to the webhook, retrying with exponential backoff on
+// connection errors and non-2xx responses.
+func (this *notifier) deliver(wh WebhookConfig, body []byte) error {
+	delay := this.backoff
+	var err error
+	for attempt := 0; attempt <= this.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+		if err = this.post(wh, body); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", this.retries+1, err)
+}
+
+func (this *notifier) post(wh WebhookConfig, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if wh.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+sign(wh.Secret, body))
+	}
+	resp, err := this.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// sign returns the hex HMAC-SHA256 of body keyed with secret.
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,84 @@
+package runctl
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNotifierRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	n := newNotifier(NotificationsConfig{Webhooks: []WebhookConfig{{URL: server.URL}}})
+	n.backoff = time.Millisecond
+
+	if err := n.deliver(n.webhooks[0], []byte(`{}`)); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestNotifierGivesUp(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	retries := 1
+	n := newNotifier(NotificationsConfig{Webhooks: []WebhookConfig{{URL: server.URL}}, Retries: &retries})
+	n.backoff = time.Millisecond
+
+	if err := n.deliver(n.webhooks[0], []byte(`{}`)); err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected 2 attempts, got %d", got)
+	}
+}
+
+func TestEmitRecoveredAfterFailure(t *testing.T) {
+	var events []string
+	tgt := newTarget("app", TargetConfig{}, t.TempDir(), nil, false, nil, func(e Event) {
+		events = append(events, e.Event)
+	})
+	tgt.emitRecovered(StateError)
+	tgt.emitFailure(EventBuildFailed, "build", StateStarting, nil, 0)
+	tgt.emitRecovered(StateError)
+	tgt.emitRecovered(StateError)
+	tgt.flush()
+
+	want := []string{EventBuildFailed, EventRecovered}
+	if strings.Join(events, ",") != strings.Join(want, ",") {
+		t.Errorf("expected events %v, got %v", want, events)
+	}
+}
+
+func TestPublishAfterUnlock(t *testing.T) {
+	var tgt *target
+	held := false
+	tgt = newTarget("app", TargetConfig{}, t.TempDir(), nil, false, nil, func(e Event) {
+		if !tgt.mu.TryLock() {
+			held = true
+			return
+		}
+		tgt.mu.Unlock()
+	})
+	tgt.onBuildDone(time.Second, errors.New("boom"))
+	if held {
+		t.Error("event published while holding the target lock")
+	}
+}
@@ -0,0 +1,89 @@
+package runctl_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("Notifications", func() {
+	It("rejects a webhook with an unknown event", func() {
+		dir := GinkgoT().TempDir()
+		cfgPath := filepath.Join(dir, "runctl.yaml")
+		Expect(os.WriteFile(cfgPath, []byte(`
+notifications:
+  webhooks:
+    - url: http://hooks.example.com/runctl
+      events: [exploded]
+targets:
+  app:
+    config: app/execrun.yaml
+`), 0644)).To(Succeed())
+
+		_, err := runctl.LoadConfig(cfgPath)
+		Expect(err).To(MatchError(ContainSubstring(`unknown event "exploded"`)))
+	})
+
+	It("posts a signed crashed event when the process exits non-zero", func() {
+		var (
+			mu        sync.Mutex
+			bodies    [][]byte
+			signature string
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			bodies = append(bodies, body)
+			signature = r.Header.Get(runctl.SignatureHeader)
+			mu.Unlock()
+		}))
+		defer server.Close()
+
+		ctrl, err := runctl.New(runctl.Config{
+			API: runctl.APIConfig{Port: 9100},
+			Notifications: runctl.NotificationsConfig{
+				Webhooks: []runctl.WebhookConfig{{URL: server.URL, Secret: "s3cret"}},
+			},
+			Targets: map[string]runctl.TargetConfig{
+				"app": {Type: runctl.TargetTypeCommand, Cmd: "false"},
+			},
+		}, GinkgoT().TempDir(), false)
+		Expect(err).NotTo(HaveOccurred())
+		ctrl.StartTargets()
+		defer ctrl.KillTargets()
+
+		Eventually(func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(bodies)
+		}, "5s", "20ms").Should(BeNumerically(">=", 1))
+
+		mu.Lock()
+		body, sig := bodies[0], signature
+		mu.Unlock()
+
+		var n runctl.Event
+		Expect(json.Unmarshal(body, &n)).To(Succeed())
+		Expect(n.Event).To(Equal(runctl.EventCrashed))
+		Expect(n.Target).To(Equal("app"))
+		Expect(n.OldState).To(Equal(runctl.StateRunning))
+		Expect(n.NewState).To(Equal(runctl.StateError))
+		Expect(n.ExitCode).To(Equal(1))
+
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write(body)
+		Expect(sig).To(Equal("sha256=" + hex.EncodeToString(mac.Sum(nil))))
+	})
+})
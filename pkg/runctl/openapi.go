@@ -0,0 +1,59 @@
+package runctl
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIYAML is the OpenAPI 3 document describing the runctl API.
+//
+//go:embed openapi.yaml
+var OpenAPIYAML []byte
+
+var openAPIJSON = sync.OnceValues(func() ([]byte, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(OpenAPIYAML, &doc); err != nil {
+		return nil, fmt.Errorf("parse openapi.yaml: %w", err)
+	}
+	return json.Marshal(doc)
+})
+
+// redocVersion is the Redoc release the docs page loads. It is pinned so an
+// upstream release can't change what runs on the page; bump it deliberately.
+const redocVersion = "v2.1.5"
+
+// docsPage renders openapi.json with Redoc. The spec URL is relative so the
+// page works wherever the API is mounted.
+const docsPage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>runctl API</title>
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body>
+  <redoc spec-url="openapi.json"></redoc>
+  <script src="https://cdn.redoc.ly/redoc/` + redocVersion + `/bundles/redoc.standalone.js" crossorigin="anonymous" referrerpolicy="no-referrer"></script>
+</body>
+</html>
+`
+
+func (this *Controller) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	data, err := openAPIJSON()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (this *Controller) handleDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(docsPage))
+}
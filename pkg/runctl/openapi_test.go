@@ -0,0 +1,76 @@
+package runctl_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("OpenAPI", func() {
+	var ctrl *runctl.Controller
+
+	BeforeEach(func() {
+		var err error
+		ctrl, err = runctl.New(runctl.Config{
+			API: runctl.APIConfig{Port: 9100},
+			Targets: map[string]runctl.TargetConfig{
+				"app": {Type: runctl.TargetTypeCommand, Cmd: "sleep 60"},
+			},
+		}, GinkgoT().TempDir(), false)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	fetchSpec := func() map[string]any {
+		server := serveAPI(ctrl)
+		defer server.Close()
+		resp, err := http.Get(server.URL + "/api/openapi.json")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
+
+		var spec map[string]any
+		Expect(json.NewDecoder(resp.Body).Decode(&spec)).To(Succeed())
+		return spec
+	}
+
+	It("serves the spec as JSON", func() {
+		spec := fetchSpec()
+		Expect(spec["openapi"]).To(HavePrefix("3."))
+		Expect(spec["paths"]).To(HaveKey("/targets/{name}"))
+	})
+
+	It("documents every route", func() {
+		paths := fetchSpec()["paths"].(map[string]any)
+
+		err := chi.Walk(ctrl.Routes(), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+			proxy := strings.HasSuffix(route, "/*")
+			route = strings.Replace(route, "/*", "/{path}", 1)
+			Expect(paths).To(HaveKey(route), "route %s %s", method, route)
+			if proxy {
+				return nil // the backoffice proxy accepts any method
+			}
+			Expect(paths[route]).To(HaveKey(strings.ToLower(method)), "route %s %s", method, route)
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("serves a docs page", func() {
+		server := serveAPI(ctrl)
+		defer server.Close()
+		resp, err := http.Get(server.URL + "/api/docs")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Header.Get("Content-Type")).To(HavePrefix("text/html"))
+		Expect(string(body)).To(ContainSubstring(`spec-url="openapi.json"`))
+	})
+})
@@ -0,0 +1,54 @@
+package runctl_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/execrun"
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("output", func() {
+	load := func(content string) (*runctl.Config, error) {
+		cfgPath := filepath.Join(GinkgoT().TempDir(), "runctl.yaml")
+		Expect(os.WriteFile(cfgPath, []byte(content), 0644)).To(Succeed())
+		return runctl.LoadConfig(cfgPath)
+	}
+
+	It("defaults targets to the top-level value or the flag's", func() {
+		content := `
+targets:
+  api:
+    type: command
+    cmd: ./api
+  shell:
+    type: command
+    cmd: ./repl
+    output: raw
+`
+		cfg, err := load("output: prefixed\n" + content)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Targets["api"].Output).To(Equal(execrun.OutputPrefixed))
+		Expect(cfg.Targets["shell"].Output).To(Equal(execrun.OutputRaw))
+
+		cfg, err = load(content)
+		Expect(err).NotTo(HaveOccurred())
+		cfg.SetDefaultOutput(execrun.OutputPrefixed)
+		Expect(cfg.Targets["api"].Output).To(Equal(execrun.OutputPrefixed))
+		Expect(cfg.Targets["shell"].Output).To(Equal(execrun.OutputRaw))
+	})
+
+	It("rejects an unknown value", func() {
+		_, err := load(`
+targets:
+  api:
+    type: command
+    cmd: ./api
+    output: fancy
+`)
+		Expect(err).To(MatchError(ContainSubstring(`targets.api.output must be one of raw, prefixed, got "fancy"`)))
+	})
+})
@@ -0,0 +1,117 @@
+package runctl
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ProcfileExport is a Procfile and .env rendered from a config, for running
+// the targets with foreman, overmind, or honcho instead of runctl.
+type ProcfileExport struct {
+	Procfile []byte   // one process per target; disabled targets are commented out
+	Env      []byte   // global vars as .env lines
+	Skipped  []string // targets that can't be expressed as a process, with the reason
+}
+
+// ExportProcfile renders the local targets as Procfile processes. Each
+// process changes to the target's directory, runs its build steps, and
+// execs its managed process; test steps are left out. Vars specific to a
+// target are exported on its line, global vars go to Env. baseDir is the
+// directory of runctl.yaml, where the Procfile is meant to live.
+func (this *Config) ExportProcfile(baseDir string) (*ProcfileExport, error) {
+	baseDir, err := filepath.Abs(baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ProcfileExport{}
+	var procfile bytes.Buffer
+	for _, name := range slices.Sorted(maps.Keys(this.Targets)) {
+		tcfg := this.Targets[name]
+		if tcfg.IsRemote() {
+			out.Skipped = append(out.Skipped, fmt.Sprintf("%s: runs on remote agent %s", name, tcfg.Host))
+			continue
+		}
+		parentVars := this.ParentVars(name)
+		ecfg, childVars, err := tcfg.LoadExecConfig(name, baseDir, parentVars)
+		if err != nil {
+			return nil, fmt.Errorf("target %q: load config: %w", name, err)
+		}
+		if ecfg.IsBuildOnly() {
+			out.Skipped = append(out.Skipped, name+": build-only, no long-running process")
+			continue
+		}
+
+		var parts []string
+		if exports := targetExports(this.ResolvedVars, parentVars, childVars); len(exports) > 0 {
+			parts = append(parts, "export "+strings.Join(exports, " "))
+		}
+		if rel, err := filepath.Rel(baseDir, tcfg.Dir(baseDir)); err == nil && rel != "." {
+			parts = append(parts, "cd "+shellQuote(rel))
+		} else if err != nil {
+			parts = append(parts, "cd "+shellQuote(tcfg.Dir(baseDir)))
+		}
+		parts = append(parts, ecfg.Build...)
+		parts = append(parts, ecfg.Exec[:len(ecfg.Exec)-1]...)
+		parts = append(parts, "exec "+ecfg.Exec[len(ecfg.Exec)-1])
+
+		line := normalizeTargetName(name) + ": " + strings.Join(parts, " && ")
+		if !tcfg.IsEnabled() {
+			line = "# " + line
+		}
+		procfile.WriteString(line + "\n")
+	}
+	out.Procfile = procfile.Bytes()
+
+	var env bytes.Buffer
+	for _, k := range slices.Sorted(maps.Keys(this.ResolvedVars)) {
+		env.WriteString(k + "=" + dotenvQuote(this.ResolvedVars[k]) + "\n")
+	}
+	out.Env = env.Bytes()
+	return out, nil
+}
+
+// targetExports returns KEY=value assignments for the vars a target sees
+// beyond the global ones: its own vars, built-ins, and child config vars.
+func targetExports(global, parentVars, childVars map[string]string) []string {
+	vars := make(map[string]string, len(childVars)+len(parentVars))
+	maps.Copy(vars, childVars)
+	maps.Copy(vars, parentVars)
+	var exports []string
+	for _, k := range slices.Sorted(maps.Keys(vars)) {
+		if g, ok := global[k]; ok && g == vars[k] {
+			continue
+		}
+		exports = append(exports, k+"="+shellQuote(vars[k]))
+	}
+	return exports
+}
+
+// reShellSafe matches values sh takes literally without quoting.
+var reShellSafe = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,=-]+$`)
+
+// shellQuote single-quotes v for sh unless it is safe as is.
+func shellQuote(v string) string {
+	if reShellSafe.MatchString(v) {
+		return v
+	}
+	return "'" + strings.ReplaceAll(v, "'", `'\''`) + "'"
+}
+
+// dotenvQuote quotes a .env value when it needs it: single quotes keep $
+// literal; values with quotes or newlines fall back to escaped double quotes.
+func dotenvQuote(v string) string {
+	switch {
+	case reShellSafe.MatchString(v):
+		return v
+	case !strings.ContainsAny(v, "'\n"):
+		return "'" + v + "'"
+	}
+	return strconv.Quote(v)
+}
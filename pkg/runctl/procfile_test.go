@@ -0,0 +1,55 @@
+package runctl_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("ExportProcfile", func() {
+	It("renders one process per local target and the global vars", func() {
+		dir := GinkgoT().TempDir()
+		Expect(os.MkdirAll(filepath.Join(dir, "api"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "api", "execrun.yaml"), []byte(`
+watch: ["*.go"]
+build: ["go build -o ./bin/api ."]
+test: ["go test ./..."]
+exec: ["./bin/api -port {{ .PORT }}"]
+`), 0644)).To(Succeed())
+		cfgPath := filepath.Join(dir, "runctl.yaml")
+		Expect(os.WriteFile(cfgPath, []byte(`
+vars:
+  PORT: "8080"
+  GREETING: "hello world"
+targets:
+  api:
+    config: api/execrun.yaml
+    vars:
+      MODE: "it's dev"
+  Web.UI:
+    type: command
+    cmd: npm run dev
+    enabled: false
+  gpu:
+    host: http://gpu:9100
+    host_token: s3cret
+`), 0644)).To(Succeed())
+
+		cfg, err := runctl.LoadConfig(cfgPath)
+		Expect(err).NotTo(HaveOccurred())
+		export, err := cfg.ExportProcfile(dir)
+		Expect(err).NotTo(HaveOccurred())
+
+		scratch := cfg.Targets["api"].ScratchDir
+		Expect(string(export.Procfile)).To(Equal(
+			"# web_ui: export SCRATCH_DIR=" + cfg.Targets["Web.UI"].ScratchDir + " && exec npm run dev\n" +
+				"api: export MODE='it'\\''s dev' SCRATCH_DIR=" + scratch +
+				" && cd api && go build -o ./bin/api . && exec ./bin/api -port 8080\n"))
+		Expect(string(export.Env)).To(Equal("GREETING='hello world'\nPORT=8080\n"))
+		Expect(export.Skipped).To(Equal([]string{"gpu: runs on remote agent http://gpu:9100"}))
+	})
+})
@@ -0,0 +1,110 @@
+package runctl
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// fallbackLogsDir is where logs go, under the scratch dir, when logs_dir
+// can't be written.
+const fallbackLogsDir = "logs"
+
+// dirWritable reports whether files can be created in dir. It probes with a
+// real file rather than checking mode bits, so read-only mounts and sandboxes
+// are caught too.
+func dirWritable(dir string) bool {
+	f, err := os.CreateTemp(dir, ".runctl-probe-*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
+
+// SumFilePath returns where the target's sum file is written: in its
+// scratch dir, or next to its config in Dir when it has none.
+func (this TargetConfig) SumFilePath(name, baseDir string) string {
+	dir := this.ScratchDir
+	if dir == "" {
+		dir = this.Dir(baseDir)
+	}
+	return filepath.Join(dir, this.SumFileName(name))
+}
+
+// resolveLogsDir creates logs_dir, resolving it against baseDir. When it
+// can't be created or written, logs go to the scratch dir instead and every
+// target's log paths follow. It returns the read-only dir it fell back
+// from, or "".
+func (this *Config) resolveLogsDir(baseDir string) (string, error) {
+	if this.LogsDir == "" {
+		return "", nil
+	}
+	if !filepath.IsAbs(this.LogsDir) {
+		this.LogsDir = filepath.Join(baseDir, this.LogsDir)
+	}
+	if err := os.MkdirAll(this.LogsDir, 0755); err == nil && dirWritable(this.LogsDir) {
+		return "", nil
+	}
+	if this.ScratchDir == "" {
+		return "", fmt.Errorf("logs_dir %s is not writable", this.LogsDir)
+	}
+
+	fallback := filepath.Join(this.ScratchDir, fallbackLogsDir)
+	if err := os.MkdirAll(fallback, 0755); err != nil {
+		return "", fmt.Errorf("create logs dir %s: %w", fallback, err)
+	}
+	readOnly := this.LogsDir
+	warnf("logs_dir %s is read-only; writing logs to %s", readOnly, fallback)
+	this.LogsDir = fallback
+	for name, t := range this.Targets {
+		if t.Logs == nil {
+			continue
+		}
+		norm := normalizeTargetName(name)
+		t.Logs = &LogsConfig{
+			Build: filepath.Join(fallback, norm+".build.log"),
+			Test:  filepath.Join(fallback, norm+".test.log"),
+			Run:   filepath.Join(fallback, norm+".run.log"),
+		}
+		this.Targets[name] = t
+	}
+	return readOnly, nil
+}
+
+// StateInfo is the /api/info payload: where runctl actually keeps its
+// on-disk state, after any read-only fallbacks.
+type StateInfo struct {
+	ScratchDir string            `json:"scratch_dir"`
+	LogsDir    string            `json:"logs_dir,omitempty"`
+	StatsFile  string            `json:"stats_file,omitempty"`
+	SumFiles   map[string]string `json:"sum_files"`           // target name → sum file path
+	ReadOnly   []string          `json:"read_only,omitempty"` // dirs found read-only, whose state moved to scratch_dir
+}
+
+// StateInfo reports where logs, sum files, and stats are written.
+func (this *Controller) StateInfo() StateInfo {
+	this.mu.RLock()
+	defer this.mu.RUnlock()
+
+	info := StateInfo{
+		ScratchDir: this.cfg.ScratchDir,
+		LogsDir:    this.cfg.LogsDir,
+		StatsFile:  this.cfg.StatsPath(),
+		SumFiles:   make(map[string]string, len(this.targets)),
+	}
+	if this.readOnlyLogsDir != "" {
+		info.ReadOnly = append(info.ReadOnly, this.readOnlyLogsDir)
+	}
+	for _, name := range slices.Sorted(maps.Keys(this.targets)) {
+		t := this.targets[name]
+		if t.remote != nil {
+			continue
+		}
+		info.SumFiles[name] = t.tcfg.SumFilePath(name, t.baseDir)
+	}
+	return info
+}
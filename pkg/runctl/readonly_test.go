@@ -0,0 +1,104 @@
+package runctl_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("Read-only checkouts", func() {
+	It("moves logs to the scratch dir and reports it via /api/info", func() {
+		base := GinkgoT().TempDir()
+		scratch := GinkgoT().TempDir()
+		// A logs_dir below a regular file can never be created.
+		blocker := filepath.Join(base, "blocker")
+		Expect(os.WriteFile(blocker, nil, 0644)).To(Succeed())
+		logsDir := filepath.Join(blocker, "logs")
+
+		ctrl, err := runctl.New(runctl.Config{
+			API:        runctl.APIConfig{Port: 9100},
+			LogsDir:    logsDir,
+			ScratchDir: scratch,
+			Targets: map[string]runctl.TargetConfig{
+				"app": {
+					Type:   runctl.TargetTypeCommand,
+					Cmd:    "sleep 60",
+					Config: "missing/app.yaml", // its dir does not exist, so it can't be written
+					Logs:   &runctl.LogsConfig{Run: filepath.Join(logsDir, "app.run.log")},
+				},
+			},
+		}, base, false)
+		Expect(err).NotTo(HaveOccurred())
+
+		server := serveAPI(ctrl)
+		defer server.Close()
+		resp, err := http.Get(server.URL + "/api/info")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		var info runctl.StateInfo
+		Expect(json.NewDecoder(resp.Body).Decode(&info)).To(Succeed())
+		Expect(info.ScratchDir).To(Equal(scratch))
+		Expect(info.LogsDir).To(Equal(filepath.Join(scratch, "logs")))
+		Expect(info.LogsDir).To(BeADirectory())
+		Expect(info.SumFiles).To(HaveKeyWithValue("app", filepath.Join(scratch, "targets", "app", "app.sum")))
+		Expect(info.ReadOnly).To(ConsistOf(logsDir))
+	})
+
+	It("keeps logs in the checkout when it is writable", func() {
+		base := GinkgoT().TempDir()
+		scratch := GinkgoT().TempDir()
+		ctrl, err := runctl.New(runctl.Config{
+			API:        runctl.APIConfig{Port: 9100},
+			LogsDir:    "logs",
+			ScratchDir: scratch,
+			Targets: map[string]runctl.TargetConfig{
+				"app": {Type: runctl.TargetTypeCommand, Cmd: "sleep 60"},
+			},
+		}, base, false)
+		Expect(err).NotTo(HaveOccurred())
+
+		info := ctrl.StateInfo()
+		Expect(info.LogsDir).To(Equal(filepath.Join(base, "logs")))
+		Expect(info.SumFiles).To(HaveKeyWithValue("app", filepath.Join(scratch, "targets", "app", "app.sum")))
+		Expect(info.ReadOnly).To(BeEmpty())
+	})
+
+	It("uses cache_dir as the scratch dir, ignored by git", func() {
+		base := GinkgoT().TempDir()
+		ctrl, err := runctl.New(runctl.Config{
+			API:      runctl.APIConfig{Port: 9100},
+			CacheDir: ".gorun",
+			Targets: map[string]runctl.TargetConfig{
+				"app": {Type: runctl.TargetTypeCommand, Cmd: "sleep 60"},
+			},
+		}, base, false)
+		Expect(err).NotTo(HaveOccurred())
+
+		info := ctrl.StateInfo()
+		Expect(info.ScratchDir).To(Equal(filepath.Join(base, ".gorun")))
+		Expect(info.SumFiles).To(HaveKeyWithValue("app", filepath.Join(base, ".gorun", "targets", "app", "app.sum")))
+		Expect(os.ReadFile(filepath.Join(base, ".gorun", ".gitignore"))).To(Equal([]byte("*\n")))
+	})
+
+	It("rejects a cache_dir that holds the checkout", func() {
+		base := GinkgoT().TempDir()
+		for _, dir := range []string{".", ".."} {
+			_, err := runctl.New(runctl.Config{
+				API:      runctl.APIConfig{Port: 9100},
+				CacheDir: dir,
+				Targets: map[string]runctl.TargetConfig{
+					"app": {Type: runctl.TargetTypeCommand, Cmd: "sleep 60"},
+				},
+			}, base, false)
+			Expect(err).To(MatchError(ContainSubstring("cache_dir")))
+		}
+		Expect(filepath.Join(base, ".gitignore")).NotTo(BeAnExistingFile())
+	})
+})
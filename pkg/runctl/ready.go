@@ -0,0 +1,67 @@
+package runctl
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Readiness is the body of GET /api/ready: whether every checked target is
+// built, running, and passing its tests, and each target's phase.
+type Readiness struct {
+	Ready   bool              `json:"ready"`
+	Targets map[string]string `json:"targets"` // "ready", "pending", or "failed"
+}
+
+// Ready reports the readiness of the named targets; with no names, of the
+// config's ready: targets, or else of all enabled targets.
+func (this *Controller) Ready(names []string) (*Readiness, error) {
+	if len(names) == 0 {
+		names = this.cfg.Ready
+	}
+	statuses := this.Status()
+	for _, name := range names {
+		if !slices.ContainsFunc(statuses, func(st TargetStatus) bool { return st.Name == name }) {
+			return nil, fmt.Errorf("target %q %w", name, ErrTargetNotFound)
+		}
+	}
+
+	res := &Readiness{Ready: true, Targets: map[string]string{}}
+	for _, st := range statuses {
+		if (len(names) > 0 && !slices.Contains(names, st.Name)) || (len(names) == 0 && !st.Enabled) {
+			continue
+		}
+		summary := SummarizeHeartbeat([]TargetStatus{st}, map[string]bool{st.Name: true})
+		switch {
+		case summary.AllHealthy:
+			res.Targets[st.Name] = "ready"
+		case summary.HasFailures():
+			res.Targets[st.Name] = "failed"
+			res.Ready = false
+		default:
+			res.Targets[st.Name] = "pending"
+			res.Ready = false
+		}
+	}
+	return res, nil
+}
+
+// handleReady answers 200 when the targets are ready and 503 otherwise, so
+// scripts can wait with curl --fail.
+func (this *Controller) handleReady(w http.ResponseWriter, r *http.Request) {
+	var names []string
+	if q := r.URL.Query().Get("targets"); q != "" {
+		names = strings.Split(q, ",")
+	}
+	res, err := this.Ready(names)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	status := http.StatusOK
+	if !res.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, res)
+}
@@ -0,0 +1,88 @@
+package runctl_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("Ready", func() {
+	var ctrl *runctl.Controller
+
+	BeforeEach(func() {
+		var err error
+		ctrl, err = runctl.New(runctl.Config{
+			API:   runctl.APIConfig{Port: 9100},
+			Stats: new(bool), // killing the process records a crash
+			Targets: map[string]runctl.TargetConfig{
+				"api":    {Type: runctl.TargetTypeCommand, Cmd: "sleep 60"},
+				"broken": {Type: runctl.TargetTypeCommand, Cmd: "false"},
+			},
+		}, GinkgoT().TempDir(), false)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(ctrl.KillTargets)
+	})
+
+	getReady := func(query string) (int, runctl.Readiness) {
+		server := serveAPI(ctrl)
+		defer server.Close()
+		resp, err := http.Get(server.URL + "/api/ready" + query)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		var res runctl.Readiness
+		Expect(json.NewDecoder(resp.Body).Decode(&res)).To(Succeed())
+		return resp.StatusCode, res
+	}
+
+	It("answers 503 until the targets run", func() {
+		code, res := getReady("")
+		Expect(code).To(Equal(http.StatusServiceUnavailable))
+		Expect(res.Targets).To(Equal(map[string]string{"api": "pending", "broken": "pending"}))
+
+		ctrl.StartTargets()
+		Eventually(func() map[string]string {
+			_, res := getReady("")
+			return res.Targets
+		}, "5s", "20ms").Should(Equal(map[string]string{"api": "ready", "broken": "failed"}))
+		code, _ = getReady("")
+		Expect(code).To(Equal(http.StatusServiceUnavailable))
+	})
+
+	It("checks only the requested targets", func() {
+		ctrl.StartTargets()
+		Eventually(func() int {
+			code, _ := getReady("?targets=api")
+			return code
+		}, "5s", "20ms").Should(Equal(http.StatusOK))
+		_, res := getReady("?targets=api")
+		Expect(res).To(Equal(runctl.Readiness{Ready: true, Targets: map[string]string{"api": "ready"}}))
+	})
+
+	It("returns 404 for an unknown target", func() {
+		server := serveAPI(ctrl)
+		defer server.Close()
+		resp, err := http.Get(server.URL + "/api/ready?targets=api,nope")
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("rejects unknown targets under ready:", func() {
+		cfgPath := filepath.Join(GinkgoT().TempDir(), "runctl.yaml")
+		Expect(os.WriteFile(cfgPath, []byte(`
+ready: [api, web]
+targets:
+  api:
+    type: command
+    cmd: ./api
+`), 0644)).To(Succeed())
+		_, err := runctl.LoadConfig(cfgPath)
+		Expect(err).To(MatchError(ContainSubstring(`ready: unknown target "web"`)))
+	})
+})
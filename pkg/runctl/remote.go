@@ -0,0 +1,163 @@
+package runctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// remoteStatusTimeout bounds a status request. Status is polled for every
+// remote target, so a hung agent must not hold up the overview for long.
+const remoteStatusTimeout = 5 * time.Second
+
+// remoteControlTimeout bounds a control action that the agent answers at
+// once, such as build or start; building happens in the background.
+const remoteControlTimeout = 15 * time.Second
+
+// remoteStopTimeout bounds stop and disable, which the agent answers once
+// the process has exited: up to 5s after SIGTERM before SIGKILL, plus the
+// time to remove a docker container.
+const remoteStopTimeout = time.Minute
+
+// remoteClient talks to a `runctl agent` that runs a target on another
+// machine. The target has the same name on the agent as locally.
+type remoteClient struct {
+	host       string // agent base URL, e.g. http://buildbox:9100
+	token      string // the agent's api.token
+	name       string
+	httpClient *http.Client
+}
+
+func newRemoteClient(host, token, name string) *remoteClient {
+	return &remoteClient{
+		host:       strings.TrimSuffix(host, "/"),
+		token:      token,
+		name:       name,
+		httpClient: &http.Client{}, // each request has a timeout that fits it
+	}
+}
+
+// url returns the agent API URL for the target, with an optional action
+// path appended (e.g. "build", "logs").
+func (this *remoteClient) url(action string) string {
+	u := this.host + "/api/targets/" + url.PathEscape(this.name)
+	if action != "" {
+		u += "/" + action
+	}
+	return u
+}
+
+// do sends a request for the target to the agent with its token.
+func (this *remoteClient) do(ctx context.Context, method, action string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, this.url(action), nil)
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+this.token)
+	return this.httpClient.Do(req)
+}
+
+// post sends a control action (build, test, start, stop, enable, disable).
+func (this *remoteClient) post(action string) error {
+	timeout := remoteControlTimeout
+	if action == "stop" || action == "disable" {
+		timeout = remoteStopTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	resp, err := this.do(ctx, http.MethodPost, action)
+	if err != nil {
+		return fmt.Errorf("agent %s: %w", this.host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		err := &agentError{msg: fmt.Sprintf("agent %s: %s", this.host, readAPIError(resp))}
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			err.kind = ErrTargetNotFound
+		case http.StatusConflict:
+			err.kind = ErrAlreadyRunning
+		}
+		return err
+	}
+	return nil
+}
+
+// agentError is an error response from an agent. It wraps the sentinel
+// matching the response status, if any, so callers can use errors.Is.
+type agentError struct {
+	msg  string
+	kind error
+}
+
+func (this *agentError) Error() string { return this.msg }
+
+func (this *agentError) Unwrap() error { return this.kind }
+
+// status fetches the target's status from the agent.
+func (this *remoteClient) status() (TargetStatus, error) {
+	var ts TargetStatus
+	ctx, cancel := context.WithTimeout(context.Background(), remoteStatusTimeout)
+	defer cancel()
+	resp, err := this.do(ctx, http.MethodGet, "")
+	if err != nil {
+		return ts, fmt.Errorf("agent %s: %w", this.host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ts, fmt.Errorf("agent %s: %s", this.host, readAPIError(resp))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&ts); err != nil {
+		return ts, fmt.Errorf("agent %s: decode status: %w", this.host, err)
+	}
+	return ts, nil
+}
+
+// proxy forwards r to the agent's endpoint for the target at path
+// (e.g. "logs", "backoffice/index.json"), preserving the query string.
+// The caller's credentials are for this runctl, so they are replaced with
+// the agent's token. It has no timeout of its own: the request ends with
+// the caller's, so log downloads and long execs aren't cut off.
+func (this *remoteClient) proxy(w http.ResponseWriter, r *http.Request, path string) {
+	target, err := url.Parse(this.url(path))
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	proxy := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = target.Scheme
+			req.URL.Host = target.Host
+			req.URL.Path = target.Path
+			req.URL.RawPath = target.RawPath
+			req.URL.RawQuery = r.URL.RawQuery
+			req.Host = target.Host
+			req.Header.Set("Authorization", "Bearer "+this.token)
+		},
+		ErrorHandler: func(w http.ResponseWriter, _ *http.Request, err error) {
+			writeError(w, http.StatusBadGateway, fmt.Sprintf("agent %s: %v", this.host, err))
+		},
+	}
+	proxy.ServeHTTP(w, r)
+}
+
+// readAPIError extracts the error message from a runctl API error response.
+func readAPIError(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var e struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &e) == nil && e.Error != "" {
+		return e.Error
+	}
+	return resp.Status
+}
@@ -0,0 +1,189 @@
+package runctl_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"github.com/go-chi/chi/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+// serveAPI mounts a controller's API under /api, as runctl does.
+func serveAPI(ctrl *runctl.Controller) *httptest.Server {
+	r := chi.NewRouter()
+	r.Mount("/api", ctrl.Routes())
+	return httptest.NewServer(r)
+}
+
+// serveAgentAPI mounts a controller's API under /api behind token, as
+// runctl agent does.
+func serveAgentAPI(ctrl *runctl.Controller, token string) *httptest.Server {
+	r := chi.NewRouter()
+	r.Mount("/api", runctl.RequireToken(token)(ctrl.Routes()))
+	return httptest.NewServer(r)
+}
+
+var _ = Describe("Remote targets", func() {
+	writeConfig := func(dir, yaml string) string {
+		cfgPath := filepath.Join(dir, "runctl.yaml")
+		Expect(os.WriteFile(cfgPath, []byte(yaml), 0644)).To(Succeed())
+		return cfgPath
+	}
+
+	It("rejects a host that is not an http URL", func() {
+		cfgPath := writeConfig(GinkgoT().TempDir(), `
+targets:
+  app:
+    host: buildbox:9100
+`)
+		_, err := runctl.LoadConfig(cfgPath)
+		Expect(err).To(MatchError(ContainSubstring("host must be an http(s) URL")))
+	})
+
+	It("rejects local run settings on a remote target", func() {
+		cfgPath := writeConfig(GinkgoT().TempDir(), `
+targets:
+  app:
+    host: http://buildbox:9100
+    host_token: s3cret
+    cmd: sleep 60
+`)
+		_, err := runctl.LoadConfig(cfgPath)
+		Expect(err).To(MatchError(ContainSubstring("configured on the agent")))
+	})
+
+	It("requires the agent's token", func() {
+		cfgPath := writeConfig(GinkgoT().TempDir(), `
+targets:
+  app:
+    host: http://buildbox:9100
+`)
+		_, err := runctl.LoadConfig(cfgPath)
+		Expect(err).To(MatchError(ContainSubstring(`target "app": host_token is required`)))
+	})
+
+	Describe("proxying to an agent", func() {
+		var (
+			agentDir string
+			agent    *runctl.Controller
+			server   *httptest.Server
+			local    *runctl.Controller
+		)
+
+		BeforeEach(func() {
+			agentDir = GinkgoT().TempDir()
+			agentCfg, err := runctl.LoadConfig(writeConfig(agentDir, `
+logs_dir: logs
+targets:
+  app:
+    type: command
+    cmd: sleep 60
+    enabled: false
+`))
+			Expect(err).NotTo(HaveOccurred())
+			agent, err = runctl.New(*agentCfg, agentDir, false)
+			Expect(err).NotTo(HaveOccurred())
+			server = serveAgentAPI(agent, "agent-token")
+
+			local, err = runctl.New(runctl.Config{
+				API: runctl.APIConfig{Port: 9100},
+				Targets: map[string]runctl.TargetConfig{
+					"app": {Host: server.URL, HostToken: "agent-token"},
+				},
+			}, ".", false)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			agent.KillTargets()
+			server.Close()
+		})
+
+		It("reports the agent's status with the host", func() {
+			st, err := local.TargetStatus("app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(st.Name).To(Equal("app"))
+			Expect(st.Host).To(Equal(server.URL))
+			Expect(st.State).To(Equal(runctl.StateIdle))
+			Expect(st.Enabled).To(BeFalse())
+		})
+
+		It("starts and stops the target on the agent", func() {
+			Expect(local.StartTarget("app")).To(Succeed())
+			Eventually(func() runctl.TargetState {
+				st, _ := agent.TargetStatus("app")
+				return st.State
+			}, "5s", "50ms").Should(Equal(runctl.StateRunning))
+
+			// Starting again is not an error.
+			Expect(local.StartTarget("app")).To(Succeed())
+
+			Expect(local.StopTarget("app")).To(Succeed())
+			st, err := agent.TargetStatus("app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(st.State).To(Equal(runctl.StateStopped))
+			Expect(st.Enabled).To(BeFalse())
+		})
+
+		It("proxies log requests to the agent", func() {
+			Expect(os.MkdirAll(filepath.Join(agentDir, "logs"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(agentDir, "logs", "app.run.log"), []byte("hello from the agent\n"), 0644)).To(Succeed())
+
+			localServer := serveAPI(local)
+			defer localServer.Close()
+
+			req, err := http.NewRequest(http.MethodGet, localServer.URL+"/api/targets/app/logs?stage=run", nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Authorization", "Bearer local-token") // not the agent's
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(string(body)).To(ContainSubstring("hello from the agent"))
+		})
+
+		It("rejects requests to the agent without its token", func() {
+			for _, token := range []string{"", "wrong"} {
+				req, err := http.NewRequest(http.MethodPost, server.URL+"/api/targets/app/start", nil)
+				Expect(err).NotTo(HaveOccurred())
+				if token != "" {
+					req.Header.Set("Authorization", "Bearer "+token)
+				}
+				resp, err := http.DefaultClient.Do(req)
+				Expect(err).NotTo(HaveOccurred())
+				resp.Body.Close()
+				Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+			}
+			st, err := agent.TargetStatus("app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(st.Enabled).To(BeFalse())
+		})
+	})
+
+	It("reports an unreachable agent as an error state", func() {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		ctrl, err := runctl.New(runctl.Config{
+			API: runctl.APIConfig{Port: 9100},
+			Targets: map[string]runctl.TargetConfig{
+				"app": {Host: server.URL},
+			},
+		}, ".", false)
+		Expect(err).NotTo(HaveOccurred())
+
+		st, err := ctrl.TargetStatus("app")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(st.State).To(Equal(runctl.StateError))
+		Expect(st.Build.Error).To(ContainSubstring(server.URL))
+		Expect(ctrl.BuildTarget("app")).To(MatchError(ContainSubstring(server.URL)))
+	})
+})
@@ -0,0 +1,51 @@
+package runctl
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/gur-shatz/go-run/internal/procstat"
+)
+
+// ResourceUsage is one target's entry in /api/resources: the combined
+// usage of its managed process and everything that process started.
+// CPU time is cumulative, so clients derive CPU% from two samples.
+type ResourceUsage struct {
+	Name       string  `json:"name"`
+	PID        int     `json:"pid,omitempty"`
+	Processes  int     `json:"processes"`          // 0 when not running or not sampled
+	CPUSeconds float64 `json:"cpu_secs,omitempty"` // user + system
+	RSSBytes   uint64  `json:"rss_bytes,omitempty"`
+}
+
+// Resources samples the process group of every local target, sorted by
+// name. Remote targets are listed without usage; sampling needs Linux.
+func (this *Controller) Resources() []ResourceUsage {
+	this.mu.RLock()
+	defer this.mu.RUnlock()
+
+	out := make([]ResourceUsage, 0, len(this.targets))
+	for _, name := range slices.Sorted(maps.Keys(this.targets)) {
+		t := this.targets[name]
+		r := ResourceUsage{Name: name}
+		if t.remote == nil {
+			t.mu.Lock()
+			r.PID = t.pid
+			t.mu.Unlock()
+		}
+		if r.PID > 0 {
+			if u, err := procstat.Sample(r.PID); err == nil {
+				r.Processes = u.Processes
+				r.CPUSeconds = u.CPUSeconds
+				r.RSSBytes = u.RSSBytes
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func (this *Controller) handleResources(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, this.Resources())
+}
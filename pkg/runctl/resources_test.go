@@ -0,0 +1,50 @@
+package runctl_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("Resources", func() {
+	It("reports the usage of running targets", func() {
+		if runtime.GOOS != "linux" {
+			Skip("sampling reads /proc")
+		}
+		f := false
+		ctrl, err := runctl.New(runctl.Config{
+			API: runctl.APIConfig{Port: 9100},
+			Targets: map[string]runctl.TargetConfig{
+				"app":  {Type: runctl.TargetTypeCommand, Cmd: "sleep 60"},
+				"idle": {Type: runctl.TargetTypeCommand, Cmd: "sleep 60", Enabled: &f},
+			},
+		}, GinkgoT().TempDir(), false)
+		Expect(err).NotTo(HaveOccurred())
+		ctrl.StartTargets()
+		DeferCleanup(ctrl.KillTargets)
+		server := serveAPI(ctrl)
+		DeferCleanup(server.Close)
+
+		get := func() []runctl.ResourceUsage {
+			resp, err := http.Get(server.URL + "/api/resources")
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+			var out []runctl.ResourceUsage
+			Expect(json.NewDecoder(resp.Body).Decode(&out)).To(Succeed())
+			return out
+		}
+
+		Eventually(func() int { return get()[0].Processes }, "5s", "20ms").Should(Equal(1))
+		usage := get()
+		Expect(usage).To(HaveLen(2))
+		Expect(usage[0].Name).To(Equal("app"))
+		Expect(usage[0].PID).To(BeNumerically(">", 0))
+		Expect(usage[0].RSSBytes).To(BeNumerically(">", 0))
+		Expect(usage[1]).To(Equal(runctl.ResourceUsage{Name: "idle"}))
+	})
+})
@@ -1,20 +1,45 @@
 package runctl
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"time"
+
+	"github.com/gur-shatz/go-run/internal/log"
+	"github.com/gur-shatz/go-run/internal/mask"
+	"github.com/gur-shatz/go-run/pkg/clock"
+	"github.com/gur-shatz/go-run/pkg/messages"
+)
+
+var (
+	// ErrTargetNotFound is returned for a target name that isn't in the
+	// config.
+	ErrTargetNotFound = errors.New("not found")
+	// ErrAlreadyRunning is returned when starting a target that is already
+	// running or starting.
+	ErrAlreadyRunning = errors.New("already running")
 )
 
 // Controller manages multiple targets and exposes an HTTP API.
 type Controller struct {
-	cfg     Config
-	baseDir string
-	verbose bool
-	targets map[string]*target
-	mu      sync.RWMutex
+	cfg      Config
+	baseDir  string
+	verbose  bool
+	targets  map[string]*target
+	masker   *mask.Masker
+	events   *eventBus
+	notifier *notifier
+	eventLog *eventLog
+	stats    *statsRecorder
+	overview overviewHub
+	mu       sync.RWMutex
+
+	readOnlyLogsDir string // configured logs_dir, if it was read-only
 }
 
 // Overview is the dashboard/API payload for project-level metadata and targets.
@@ -22,6 +47,7 @@ type Overview struct {
 	Title       string         `json:"title,omitempty"`
 	Description string         `json:"description,omitempty"`
 	Targets     []TargetStatus `json:"targets"`
+	Warnings    []string       `json:"warnings,omitempty"` // e.g. low disk space
 }
 
 // New creates a Controller from the given config.
@@ -32,26 +58,39 @@ func New(cfg Config, baseDir string, verbose bool) (*Controller, error) {
 		return nil, fmt.Errorf("resolve base dir: %w", err)
 	}
 
-	// Ensure logs_dir exists if configured
-	if cfg.LogsDir != "" {
-		logsDir := cfg.LogsDir
-		if !filepath.IsAbs(logsDir) {
-			logsDir = filepath.Join(absBase, logsDir)
-		}
-		if err := os.MkdirAll(logsDir, 0755); err != nil {
-			return nil, fmt.Errorf("create logs_dir %s: %w", logsDir, err)
-		}
+	if err := cfg.resolveScratchDirs(absBase); err != nil {
+		return nil, err
+	}
+
+	// Ensure logs_dir exists if configured, falling back to the scratch dir
+	// on read-only checkouts.
+	readOnlyLogsDir, err := cfg.resolveLogsDir(absBase)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.registerSecrets()
+	masker, err := cfg.newMasker()
+	if err != nil {
+		return nil, err
 	}
 
 	ctrl := &Controller{
-		cfg:     cfg,
-		baseDir: absBase,
-		verbose: verbose,
-		targets: make(map[string]*target, len(cfg.Targets)),
+		cfg:      cfg,
+		baseDir:  absBase,
+		verbose:  verbose,
+		targets:  make(map[string]*target, len(cfg.Targets)),
+		masker:   masker,
+		events:   newEventBus(cfg.EventHistory),
+		notifier: newNotifier(cfg.Notifications),
+		eventLog: newEventLog(cfg.EventsFile, absBase),
+		stats:    newStatsRecorder(cfg.StatsPath()),
+
+		readOnlyLogsDir: readOnlyLogsDir,
 	}
 
 	if cfg.RotatesLogsOnStart() {
-		suffix := time.Now().Format("20060102-150405")
+		suffix := time.Now().Format(rotateSuffixLayout)
 		for name, tcfg := range cfg.Targets {
 			if tcfg.Logs == nil {
 				continue
@@ -68,18 +107,12 @@ func New(cfg Config, baseDir string, verbose bool) (*Controller, error) {
 	}
 
 	for name, tcfg := range cfg.Targets {
-		// Merge global vars with per-target vars (target wins on conflict)
-		parentVars := cfg.ResolvedVars
-		if len(tcfg.Vars) > 0 {
-			parentVars = make(map[string]string, len(cfg.ResolvedVars)+len(tcfg.Vars))
-			for k, v := range cfg.ResolvedVars {
-				parentVars[k] = v
-			}
-			for k, v := range tcfg.Vars {
-				parentVars[k] = v
-			}
-		}
-		ctrl.targets[name] = newTarget(name, tcfg, absBase, parentVars, verbose)
+		tcfg.Instance = cfg.InstanceName
+		tcfg.APIPrefix = cfg.API.APIPrefix()
+		tcfg.Strict = cfg.Strict
+		t := newTarget(name, tcfg, absBase, cfg.ParentVars(name), verbose, masker, ctrl.publish)
+		t.stats = ctrl.stats
+		ctrl.targets[name] = t
 	}
 
 	return ctrl, nil
@@ -92,7 +125,7 @@ func (this *Controller) StartTargets() {
 
 	for name, t := range this.targets {
 		if t.enabled {
-			if err := t.Start(); err != nil {
+			if err := t.launch(); err != nil {
 				this.logStartFailure(name, t, err)
 			}
 		}
@@ -117,7 +150,7 @@ func (this *Controller) StartTargetsFiltered(names []string) {
 
 	for name, t := range this.targets {
 		if filter[name] {
-			if err := t.Start(); err != nil {
+			if err := t.launch(); err != nil {
 				this.logStartFailure(name, t, err)
 			}
 		}
@@ -125,11 +158,33 @@ func (this *Controller) StartTargetsFiltered(names []string) {
 }
 
 func (this *Controller) logStartFailure(name string, t *target, err error) {
-	msg := fmt.Sprintf("[runctl] Warning: failed to start %s: %v", name, err)
+	msg := this.Redact(warning("failed to start %s: %v", name, err))
 	if logErr := t.appendRunLogMarker(msg); logErr != nil {
-		fmt.Fprintf(os.Stderr, "[runctl] Warning: failed to write %s run log: %v\n", name, logErr)
+		warnf("failed to write %s run log: %v", name, logErr)
+	}
+	if log.Structured() {
+		log.Record(name, log.EventWarn, this.Redact(fmt.Sprintf("failed to start: %v", err)))
+		return
+	}
+	if log.Enabled(log.LevelWarn) {
+		fmt.Fprintln(os.Stderr, log.Timestamp()+msg)
+	}
+}
+
+// warning formats a runctl warning line.
+func warning(format string, args ...any) string {
+	return messages.Get(messages.RunctlPrefix) + " " + messages.Get(messages.WarningLabel) + " " + fmt.Sprintf(format, args...)
+}
+
+// warnf prints a runctl warning to stderr, or a porcelain record.
+func warnf(format string, args ...any) {
+	if log.Structured() {
+		log.Record("runctl", log.EventWarn, fmt.Sprintf(format, args...))
+		return
+	}
+	if log.Enabled(log.LevelWarn) {
+		fmt.Fprintln(os.Stderr, log.Timestamp()+warning(format, args...))
 	}
-	fmt.Fprintln(os.Stderr, msg)
 }
 
 // StopTargets gracefully stops all targets (SIGTERM → 5s → SIGKILL).
@@ -138,6 +193,7 @@ func (this *Controller) StopTargets() {
 	defer this.mu.RUnlock()
 
 	for _, t := range this.targets {
+		t.closeLazy()
 		t.Stop()
 	}
 }
@@ -148,6 +204,7 @@ func (this *Controller) KillTargets() {
 	defer this.mu.RUnlock()
 
 	for _, t := range this.targets {
+		t.closeLazy()
 		t.Kill()
 	}
 }
@@ -158,7 +215,7 @@ func (this *Controller) StartTarget(name string) error {
 	t, ok := this.targets[name]
 	this.mu.RUnlock()
 	if !ok {
-		return fmt.Errorf("target %q not found", name)
+		return fmt.Errorf("target %q %w", name, ErrTargetNotFound)
 	}
 	return t.Start()
 }
@@ -169,7 +226,7 @@ func (this *Controller) StopTarget(name string) error {
 	t, ok := this.targets[name]
 	this.mu.RUnlock()
 	if !ok {
-		return fmt.Errorf("target %q not found", name)
+		return fmt.Errorf("target %q %w", name, ErrTargetNotFound)
 	}
 	t.Stop()
 	return nil
@@ -181,7 +238,10 @@ func (this *Controller) BuildTarget(name string) error {
 	t, ok := this.targets[name]
 	this.mu.RUnlock()
 	if !ok {
-		return fmt.Errorf("target %q not found", name)
+		return fmt.Errorf("target %q %w", name, ErrTargetNotFound)
+	}
+	if t.remote != nil {
+		return t.remote.post("build")
 	}
 	t.Build()
 	return nil
@@ -193,7 +253,10 @@ func (this *Controller) TestTarget(name string) error {
 	t, ok := this.targets[name]
 	this.mu.RUnlock()
 	if !ok {
-		return fmt.Errorf("target %q not found", name)
+		return fmt.Errorf("target %q %w", name, ErrTargetNotFound)
+	}
+	if t.remote != nil {
+		return t.remote.post("test")
 	}
 	t.Test()
 	return nil
@@ -205,7 +268,10 @@ func (this *Controller) StartExec(name string) error {
 	t, ok := this.targets[name]
 	this.mu.RUnlock()
 	if !ok {
-		return fmt.Errorf("target %q not found", name)
+		return fmt.Errorf("target %q %w", name, ErrTargetNotFound)
+	}
+	if t.remote != nil {
+		return t.remote.post("start")
 	}
 	t.StartExec()
 	return nil
@@ -217,36 +283,75 @@ func (this *Controller) StopExec(name string) error {
 	t, ok := this.targets[name]
 	this.mu.RUnlock()
 	if !ok {
-		return fmt.Errorf("target %q not found", name)
+		return fmt.Errorf("target %q %w", name, ErrTargetNotFound)
+	}
+	if t.remote != nil {
+		return t.remote.post("stop")
 	}
 	t.StopExec()
 	return nil
 }
 
+// PauseWatch stops file changes from rebuilding a target, e.g. during a
+// rebase. The pause lasts until ResumeWatch, across restarts.
+func (this *Controller) PauseWatch(name string) error {
+	this.mu.RLock()
+	t, ok := this.targets[name]
+	this.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("target %q %w", name, ErrTargetNotFound)
+	}
+	if t.remote != nil {
+		return t.remote.post("watch/pause")
+	}
+	t.PauseWatch()
+	return nil
+}
+
+// ResumeWatch undoes PauseWatch. Changes made while paused trigger a
+// single rebuild.
+func (this *Controller) ResumeWatch(name string) error {
+	this.mu.RLock()
+	t, ok := this.targets[name]
+	this.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("target %q %w", name, ErrTargetNotFound)
+	}
+	if t.remote != nil {
+		return t.remote.post("watch/resume")
+	}
+	t.ResumeWatch()
+	return nil
+}
+
 // RestartTarget stops and re-starts a target.
 func (this *Controller) RestartTarget(name string) error {
 	this.mu.RLock()
 	t, ok := this.targets[name]
 	this.mu.RUnlock()
 	if !ok {
-		return fmt.Errorf("target %q not found", name)
+		return fmt.Errorf("target %q %w", name, ErrTargetNotFound)
+	}
+	if t.remote != nil {
+		return t.remote.post("restart")
 	}
 	t.Stop()
 	return t.Start()
 }
 
-// EnableTarget enables a target and starts it.
+// EnableTarget enables a target and starts it, or for a lazy target holds
+// its port again.
 func (this *Controller) EnableTarget(name string) error {
 	this.mu.RLock()
 	t, ok := this.targets[name]
 	this.mu.RUnlock()
 	if !ok {
-		return fmt.Errorf("target %q not found", name)
+		return fmt.Errorf("target %q %w", name, ErrTargetNotFound)
 	}
 	t.mu.Lock()
 	t.enabled = true
 	t.mu.Unlock()
-	return t.Start()
+	return t.launch()
 }
 
 // DisableTarget stops a target and disables it.
@@ -255,8 +360,9 @@ func (this *Controller) DisableTarget(name string) error {
 	t, ok := this.targets[name]
 	this.mu.RUnlock()
 	if !ok {
-		return fmt.Errorf("target %q not found", name)
+		return fmt.Errorf("target %q %w", name, ErrTargetNotFound)
 	}
+	t.closeLazy()
 	t.Stop()
 	t.mu.Lock()
 	t.enabled = false
@@ -264,6 +370,22 @@ func (this *Controller) DisableTarget(name string) error {
 	return nil
 }
 
+// SetClock replaces the wall clock used for target timestamps, crash loop
+// windows, and the targets' watchers. Call it before starting targets.
+func (this *Controller) SetClock(c clock.Clock) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	for _, t := range this.targets {
+		t.clock = clock.Or(c)
+	}
+}
+
+// Redact masks the values of the vars listed in the config's mask: section
+// and of registered secrets.
+func (this *Controller) Redact(s string) string {
+	return this.masker.String(s)
+}
+
 // AllowedFilePaths returns the set of absolute file paths from link configs.
 // Used to restrict which files the /api/file endpoint can serve.
 func (this *Controller) AllowedFilePaths() map[string]bool {
@@ -299,7 +421,23 @@ func (this *Controller) Overview() Overview {
 		Title:       this.cfg.Title,
 		Description: this.cfg.Description,
 		Targets:     this.Status(),
+		Warnings:    this.Warnings(),
+	}
+}
+
+// Warnings returns problems worth showing next to the target list, such as
+// build volumes running low on space.
+func (this *Controller) Warnings() []string {
+	this.mu.RLock()
+	defer this.mu.RUnlock()
+
+	var warnings []string
+	for _, name := range slices.Sorted(maps.Keys(this.targets)) {
+		if w := this.targets[name].diskWarning(); w != "" {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", name, w))
+		}
 	}
+	return warnings
 }
 
 // TargetStatus returns the status of a single target.
@@ -308,7 +446,7 @@ func (this *Controller) TargetStatus(name string) (*TargetStatus, error) {
 	t, ok := this.targets[name]
 	this.mu.RUnlock()
 	if !ok {
-		return nil, fmt.Errorf("target %q not found", name)
+		return nil, fmt.Errorf("target %q %w", name, ErrTargetNotFound)
 	}
 	s := t.Status()
 	return &s, nil
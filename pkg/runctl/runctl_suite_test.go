@@ -8,6 +8,8 @@ import (
 )
 
 func TestRunctl(t *testing.T) {
+	// Keep scratch dirs out of the real user cache.
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Runctl Suite")
 }
@@ -7,6 +7,8 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/gur-shatz/go-run/pkg/config"
+	"github.com/gur-shatz/go-run/pkg/execrun"
 	"github.com/gur-shatz/go-run/pkg/runctl"
 )
 
@@ -32,6 +34,20 @@ targets:
 			Expect(cfg.Description).To(Equal("API and workers for local development"))
 		})
 
+		It("applies strict: true to the targets' configs", func() {
+			dir := GinkgoT().TempDir()
+			cfgPath := filepath.Join(dir, "runctl.yaml")
+			Expect(os.MkdirAll(filepath.Join(dir, "api"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "api", "execrun.yaml"), []byte("watch: [\"*.go\"]\nexec: [./api]\ntitle: 42\n"), 0644)).To(Succeed())
+			Expect(os.WriteFile(cfgPath, []byte("strict: true\ntargets:\n  api:\n    config: api/execrun.yaml\n"), 0644)).To(Succeed())
+
+			cfg, err := runctl.LoadConfig(cfgPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Targets["api"].Strict).To(BeTrue())
+			_, _, err = cfg.Targets["api"].LoadExecConfig("api", dir, nil)
+			Expect(err).To(MatchError(ContainSubstring("title must be a string, got integer 42")))
+		})
+
 		It("loads a valid execrun config file", func() {
 			dir := GinkgoT().TempDir()
 			cfgPath := filepath.Join(dir, "runctl.yaml")
@@ -121,6 +137,25 @@ targets:
 			Expect(err).To(HaveOccurred())
 		})
 
+		It("rejects misspelled fields with a suggestion", func() {
+			dir := GinkgoT().TempDir()
+			cfgPath := filepath.Join(dir, "runctl.yaml")
+
+			yaml := `
+api:
+  port: nine
+targets:
+  api:
+    config: api/execrun.yaml
+    enabld: false
+`
+			Expect(os.WriteFile(cfgPath, []byte(yaml), 0644)).To(Succeed())
+
+			_, err := runctl.LoadConfig(cfgPath)
+			Expect(err).To(MatchError(ContainSubstring("targets.api.enabld is not a known field, did you mean enabled?")))
+			Expect(err).To(MatchError(ContainSubstring(`api.port must be an integer, got "nine"`)))
+		})
+
 		It("ignores unknown type field from old configs", func() {
 			dir := GinkgoT().TempDir()
 			cfgPath := filepath.Join(dir, "runctl.yaml")
@@ -138,6 +173,23 @@ targets:
 			Expect(err).NotTo(HaveOccurred())
 			Expect(cfg.Targets["my-app"].Config).To(Equal("execrun.yaml"))
 		})
+
+		It("loads renamed keys under their old names, with a warning", func() {
+			old := runctl.Deprecations
+			runctl.Deprecations = config.Deprecations{{Old: "targets.*.startup_wait", New: "wait_timeout"}}
+			DeferCleanup(func() { runctl.Deprecations = old })
+
+			cfgPath := filepath.Join(GinkgoT().TempDir(), "runctl.yaml")
+			Expect(os.WriteFile(cfgPath, []byte("targets:\n  api:\n    type: command\n    cmd: ./api\n    startup_wait: 5s\n"), 0644)).To(Succeed())
+
+			var warnings []config.Warning
+			cfg, err := runctl.LoadConfig(cfgPath, config.WithDeprecationHandler(func(w config.Warning) {
+				warnings = append(warnings, w)
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Targets["api"].WaitTimeout).To(Equal("5s"))
+			Expect(warnings).To(ConsistOf(config.Warning{File: cfgPath, Line: 5, Key: "targets.api.startup_wait", Replacement: "targets.api.wait_timeout"}))
+		})
 	})
 
 	Describe("Per-target vars", func() {
@@ -216,6 +268,33 @@ targets:
 			Expect(err).NotTo(HaveOccurred())
 			Expect(cfg.Targets["my-app"].Vars).To(HaveKeyWithValue("DERIVED", "hello-world"))
 		})
+
+		It("exports env_file pairs the environment doesn't set", func() {
+			dir := GinkgoT().TempDir()
+			cfgPath := filepath.Join(dir, "runctl.yaml")
+
+			const fileKey, envKey = "RUNCTL_TEST_DOTENV_FILE", "RUNCTL_TEST_DOTENV_ENV"
+			os.Unsetenv(fileKey)
+			DeferCleanup(os.Unsetenv, fileKey)
+			GinkgoT().Setenv(envKey, "from-env")
+
+			Expect(os.WriteFile(filepath.Join(dir, ".env"), []byte(fileKey+"=from-file\n"+envKey+"=from-file\n"), 0644)).To(Succeed())
+			yaml := `
+env_file: .env
+targets:
+  my-app:
+    config: "my-app/execrun.yaml"
+    vars:
+      DERIVED: "{{ .` + fileKey + ` }}"
+`
+			Expect(os.WriteFile(cfgPath, []byte(yaml), 0644)).To(Succeed())
+
+			cfg, err := runctl.LoadConfig(cfgPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Targets["my-app"].Vars).To(HaveKeyWithValue("DERIVED", "from-file"))
+			Expect(os.Getenv(fileKey)).To(Equal("from-file"))
+			Expect(os.Getenv(envKey)).To(Equal("from-env"))
+		})
 	})
 
 	Describe("Link validation", func() {
@@ -323,6 +402,225 @@ targets:
 		})
 	})
 
+	Describe("Command targets", func() {
+		It("loads an inline command target", func() {
+			dir := GinkgoT().TempDir()
+			cfgPath := filepath.Join(dir, "runctl.yaml")
+
+			yaml := `
+targets:
+  redis:
+    type: command
+    cmd: "redis-server --port 6380"
+  web:
+    type: command
+    cmd: "npm run dev"
+    watch:
+      - "package.json"
+`
+			Expect(os.WriteFile(cfgPath, []byte(yaml), 0644)).To(Succeed())
+
+			cfg, err := runctl.LoadConfig(cfgPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Targets["redis"].IsCommand()).To(BeTrue())
+			Expect(cfg.Targets["redis"].Cmd).To(Equal("redis-server --port 6380"))
+			Expect(cfg.Targets["web"].Watch).To(Equal(execrun.Watches("package.json")))
+		})
+
+		It("rejects a command target without cmd", func() {
+			dir := GinkgoT().TempDir()
+			cfgPath := filepath.Join(dir, "runctl.yaml")
+
+			yaml := `
+targets:
+  redis:
+    type: command
+`
+			Expect(os.WriteFile(cfgPath, []byte(yaml), 0644)).To(Succeed())
+
+			_, err := runctl.LoadConfig(cfgPath)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("cmd is required"))
+		})
+
+		It("rejects a command target that also sets config", func() {
+			dir := GinkgoT().TempDir()
+			cfgPath := filepath.Join(dir, "runctl.yaml")
+
+			yaml := `
+targets:
+  redis:
+    type: command
+    cmd: "redis-server"
+    config: "redis/execrun.yaml"
+`
+			Expect(os.WriteFile(cfgPath, []byte(yaml), 0644)).To(Succeed())
+
+			_, err := runctl.LoadConfig(cfgPath)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("cannot specify both config and cmd"))
+		})
+
+		It("builds an execrun config from the inline fields", func() {
+			tc := runctl.TargetConfig{Type: runctl.TargetTypeCommand, Cmd: "npm run dev", Watch: execrun.Watches("src/**/*.ts")}
+			ecfg, _, err := tc.LoadExecConfig("web", "/project", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ecfg.Exec).To(Equal([]string{"npm run dev"}))
+			Expect(ecfg.Watch).To(Equal(execrun.Watches("src/**/*.ts")))
+			Expect(tc.Dir("/project")).To(Equal("/project"))
+			Expect(tc.SumFileName("Web App")).To(Equal("web_app.sum"))
+		})
+
+		It("watches nothing when watch is omitted", func() {
+			dir := GinkgoT().TempDir()
+			Expect(os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644)).To(Succeed())
+
+			tc := runctl.TargetConfig{Type: runctl.TargetTypeCommand, Cmd: "redis-server"}
+			ecfg, _, err := tc.LoadExecConfig("redis", dir, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			sums, err := execrun.ScanFiles(ecfg, dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sums).To(BeEmpty())
+		})
+	})
+
+	Describe("Build priority", func() {
+		It("passes build_nice and build_ionice down unless the execrun config sets them", func() {
+			dir := GinkgoT().TempDir()
+			Expect(os.MkdirAll(filepath.Join(dir, "app"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "app", "execrun.yaml"), []byte(`
+watch: ["**/*.go"]
+build: ["go build ./..."]
+build_nice: 5
+`), 0644)).To(Succeed())
+
+			tc := runctl.TargetConfig{Config: "app/execrun.yaml", BuildNice: 15, BuildIonice: "idle"}
+			ecfg, _, err := tc.LoadExecConfig("app", dir, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ecfg.BuildNice).To(Equal(5))
+			Expect(ecfg.BuildIonice).To(Equal("idle"))
+		})
+
+		It("rejects bad values", func() {
+			cfgPath := filepath.Join(GinkgoT().TempDir(), "runctl.yaml")
+			Expect(os.WriteFile(cfgPath, []byte(`
+targets:
+  app:
+    config: app/execrun.yaml
+    build_ionice: fast
+`), 0644)).To(Succeed())
+			_, err := runctl.LoadConfig(cfgPath)
+			Expect(err).To(MatchError(ContainSubstring(`target "app": build_ionice`)))
+		})
+	})
+
+	Describe("Reload signal", func() {
+		It("passes reload_watch and reload_signal down to command and config targets", func() {
+			tc := runctl.TargetConfig{Type: runctl.TargetTypeCommand, Cmd: "nginx -g 'daemon off;'", ReloadWatch: []string{"nginx.conf"}}
+			ecfg, _, err := tc.LoadExecConfig("proxy", "/project", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ecfg.Watch).To(BeEmpty())
+			Expect(ecfg.ReloadWatch).To(Equal([]string{"nginx.conf"}))
+			Expect(ecfg.WatchPatterns()).To(Equal([]string{"nginx.conf"}))
+
+			dir := GinkgoT().TempDir()
+			Expect(os.MkdirAll(filepath.Join(dir, "app"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "app", "execrun.yaml"), []byte(`
+watch: ["**/*.go"]
+exec: ["./bin/app"]
+`), 0644)).To(Succeed())
+			tc = runctl.TargetConfig{Config: "app/execrun.yaml", ReloadWatch: []string{"config/*.yaml"}, ReloadSignal: "SIGUSR1"}
+			ecfg, _, err = tc.LoadExecConfig("app", dir, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ecfg.ReloadWatch).To(Equal([]string{"config/*.yaml"}))
+			Expect(ecfg.ReloadSignal).To(Equal("SIGUSR1"))
+		})
+
+		It("passes restart_watch down to config targets that don't set it", func() {
+			dir := GinkgoT().TempDir()
+			Expect(os.MkdirAll(filepath.Join(dir, "app"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "app", "execrun.yaml"), []byte(`
+watch: ["**/*.go"]
+build: ["go build -o bin/app ."]
+exec: ["./bin/app"]
+`), 0644)).To(Succeed())
+			tc := runctl.TargetConfig{Config: "app/execrun.yaml", RestartWatch: []string{"templates/**"}}
+			ecfg, _, err := tc.LoadExecConfig("app", dir, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ecfg.WatchPatterns()).To(Equal([]string{"**/*.go", "templates/**"}))
+		})
+
+		It("rejects an unknown signal", func() {
+			cfgPath := filepath.Join(GinkgoT().TempDir(), "runctl.yaml")
+			Expect(os.WriteFile(cfgPath, []byte(`
+targets:
+  app:
+    config: app/execrun.yaml
+    reload_watch: [app.yaml]
+    reload_signal: SIGRELOAD
+`), 0644)).To(Succeed())
+			_, err := runctl.LoadConfig(cfgPath)
+			Expect(err).To(MatchError(ContainSubstring(`target "app": reload_signal: unknown signal "SIGRELOAD"`)))
+		})
+	})
+
+	Describe("Docker targets", func() {
+		It("loads a docker target", func() {
+			dir := GinkgoT().TempDir()
+			cfgPath := filepath.Join(dir, "runctl.yaml")
+
+			yaml := `
+targets:
+  api:
+    type: docker
+    docker:
+      context: services/api
+      image: api:dev
+      run_args: ["-p", "8080:80"]
+`
+			Expect(os.WriteFile(cfgPath, []byte(yaml), 0644)).To(Succeed())
+
+			cfg, err := runctl.LoadConfig(cfgPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Targets["api"].IsDocker()).To(BeTrue())
+			Expect(cfg.Targets["api"].Dir(dir)).To(Equal(filepath.Join(dir, "services/api")))
+		})
+
+		It("rejects cmd on a docker target", func() {
+			dir := GinkgoT().TempDir()
+			cfgPath := filepath.Join(dir, "runctl.yaml")
+
+			yaml := `
+targets:
+  api:
+    type: docker
+    cmd: "./server"
+`
+			Expect(os.WriteFile(cfgPath, []byte(yaml), 0644)).To(Succeed())
+
+			_, err := runctl.LoadConfig(cfgPath)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("docker.args"))
+		})
+
+		It("builds the image and runs the container", func() {
+			tc := runctl.TargetConfig{
+				Type: runctl.TargetTypeDocker,
+				Docker: &runctl.DockerConfig{
+					RunArgs: []string{"-p", "8080:80"},
+					Args:    []string{"serve", "--verbose"},
+				},
+			}
+			ecfg, _, err := tc.LoadExecConfig("api", "/project", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ecfg.Build).To(Equal([]string{"docker build -t runctl-api -f Dockerfile ."}))
+			Expect(ecfg.RunCmd()).To(HavePrefix("docker run --rm --name runctl-api --cidfile "))
+			Expect(ecfg.RunCmd()).To(HaveSuffix("-p 8080:80 runctl-api serve --verbose"))
+			Expect(ecfg.Watch).To(Equal(execrun.Watches("**/*", "!api.sum")))
+		})
+	})
+
 	Describe("TargetConfig.IsEnabled", func() {
 		It("defaults to true when Enabled is nil", func() {
 			tc := runctl.TargetConfig{Config: "execrun.yaml"}
@@ -383,6 +681,20 @@ targets:
 			Expect(string(data)).To(ContainSubstring("parse config"))
 		})
 
+		It("redacts the values of masked vars", func() {
+			cfg := runctl.Config{
+				API:          runctl.APIConfig{Port: 9100},
+				Mask:         []string{"API_.*"},
+				ResolvedVars: map[string]string{"API_TOKEN": "global-secret"},
+				Targets: map[string]runctl.TargetConfig{
+					"app": {Config: "app/execrun.yaml", Vars: map[string]string{"API_KEY": "target-secret"}},
+				},
+			}
+			ctrl, err := runctl.New(cfg, ".", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ctrl.Redact("a=global-secret b=target-secret")).To(Equal("a=*** b=***"))
+		})
+
 		It("returns status for all targets", func() {
 			cfg := runctl.Config{
 				API: runctl.APIConfig{Port: 9100},
@@ -418,6 +730,34 @@ targets:
 			Expect(overview.Targets[0].Test.Count).To(Equal(0))
 		})
 
+		It("warns about low disk space in the overview", func() {
+			dir := GinkgoT().TempDir()
+			Expect(os.MkdirAll(filepath.Join(dir, "app"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "app", "execrun.yaml"), []byte(`
+watch: ["*.go"]
+build: ["true"]
+exec: ["sleep 60"]
+`), 0644)).To(Succeed())
+
+			cfg := runctl.Config{
+				API: runctl.APIConfig{Port: 9100},
+				Targets: map[string]runctl.TargetConfig{
+					"app": {Config: "app/execrun.yaml", MinFreeSpace: "1000000TB"},
+				},
+			}
+			ctrl, err := runctl.New(cfg, dir, false)
+			Expect(err).NotTo(HaveOccurred())
+			ctrl.StartTargets()
+			DeferCleanup(ctrl.KillTargets)
+
+			Eventually(func() []string { return ctrl.Overview().Warnings }, "5s", "20ms").Should(
+				ConsistOf(HavePrefix("app: low disk space:")))
+			Eventually(func() string {
+				st, _ := ctrl.TargetStatus("app")
+				return st.Build.Error
+			}, "5s", "20ms").Should(ContainSubstring("low disk space"))
+		})
+
 		It("returns error for unknown target", func() {
 			cfg := runctl.Config{
 				API: runctl.APIConfig{Port: 9100},
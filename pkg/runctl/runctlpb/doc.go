@@ -0,0 +1,6 @@
+// Package runctlpb is the generated gRPC client and server code for the
+// runctl control API. Serve it with (*runctl.Controller).GRPCServer and dial
+// it with NewRunctlClient.
+package runctlpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative runctl.proto
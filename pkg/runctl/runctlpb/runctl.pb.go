@@ -0,0 +1,977 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: runctl.proto
+
+package runctlpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListTargetsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTargetsRequest) Reset() {
+	*x = ListTargetsRequest{}
+	mi := &file_runctl_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTargetsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTargetsRequest) ProtoMessage() {}
+
+func (x *ListTargetsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_runctl_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTargetsRequest.ProtoReflect.Descriptor instead.
+func (*ListTargetsRequest) Descriptor() ([]byte, []int) {
+	return file_runctl_proto_rawDescGZIP(), []int{0}
+}
+
+type ListTargetsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Targets       []*TargetStatus        `protobuf:"bytes,1,rep,name=targets,proto3" json:"targets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTargetsResponse) Reset() {
+	*x = ListTargetsResponse{}
+	mi := &file_runctl_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTargetsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTargetsResponse) ProtoMessage() {}
+
+func (x *ListTargetsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_runctl_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTargetsResponse.ProtoReflect.Descriptor instead.
+func (*ListTargetsResponse) Descriptor() ([]byte, []int) {
+	return file_runctl_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListTargetsResponse) GetTargets() []*TargetStatus {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+type TargetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TargetRequest) Reset() {
+	*x = TargetRequest{}
+	mi := &file_runctl_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TargetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TargetRequest) ProtoMessage() {}
+
+func (x *TargetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_runctl_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TargetRequest.ProtoReflect.Descriptor instead.
+func (*TargetRequest) Descriptor() ([]byte, []int) {
+	return file_runctl_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TargetRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ActionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ActionResponse) Reset() {
+	*x = ActionResponse{}
+	mi := &file_runctl_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ActionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActionResponse) ProtoMessage() {}
+
+func (x *ActionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_runctl_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActionResponse.ProtoReflect.Descriptor instead.
+func (*ActionResponse) Descriptor() ([]byte, []int) {
+	return file_runctl_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ActionResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type Phase struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	DurationSecs  float64                `protobuf:"fixed64,2,opt,name=duration_secs,json=durationSecs,proto3" json:"duration_secs,omitempty"`
+	Result        string                 `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Count         int32                  `protobuf:"varint,5,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Phase) Reset() {
+	*x = Phase{}
+	mi := &file_runctl_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Phase) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Phase) ProtoMessage() {}
+
+func (x *Phase) ProtoReflect() protoreflect.Message {
+	mi := &file_runctl_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Phase.ProtoReflect.Descriptor instead.
+func (*Phase) Descriptor() ([]byte, []int) {
+	return file_runctl_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Phase) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Phase) GetDurationSecs() float64 {
+	if x != nil {
+		return x.DurationSecs
+	}
+	return 0
+}
+
+func (x *Phase) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *Phase) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Phase) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type Link struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Url           string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	File          string                 `protobuf:"bytes,4,opt,name=file,proto3" json:"file,omitempty"`
+	ResolvedUrl   string                 `protobuf:"bytes,5,opt,name=resolved_url,json=resolvedUrl,proto3" json:"resolved_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Link) Reset() {
+	*x = Link{}
+	mi := &file_runctl_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Link) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Link) ProtoMessage() {}
+
+func (x *Link) ProtoReflect() protoreflect.Message {
+	mi := &file_runctl_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Link.ProtoReflect.Descriptor instead.
+func (*Link) Descriptor() ([]byte, []int) {
+	return file_runctl_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Link) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Link) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Link) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Link) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *Link) GetResolvedUrl() string {
+	if x != nil {
+		return x.ResolvedUrl
+	}
+	return ""
+}
+
+type TargetStatus struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Name               string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Title              string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description        string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	HasBuild           bool                   `protobuf:"varint,4,opt,name=has_build,json=hasBuild,proto3" json:"has_build,omitempty"`
+	HasTest            bool                   `protobuf:"varint,5,opt,name=has_test,json=hasTest,proto3" json:"has_test,omitempty"`
+	HasRun             bool                   `protobuf:"varint,6,opt,name=has_run,json=hasRun,proto3" json:"has_run,omitempty"`
+	State              string                 `protobuf:"bytes,7,opt,name=state,proto3" json:"state,omitempty"`
+	CurrentStage       string                 `protobuf:"bytes,8,opt,name=current_stage,json=currentStage,proto3" json:"current_stage,omitempty"`
+	Enabled            bool                   `protobuf:"varint,9,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Pid                int32                  `protobuf:"varint,10,opt,name=pid,proto3" json:"pid,omitempty"`
+	ContainerId        string                 `protobuf:"bytes,11,opt,name=container_id,json=containerId,proto3" json:"container_id,omitempty"`
+	WaitError          string                 `protobuf:"bytes,12,opt,name=wait_error,json=waitError,proto3" json:"wait_error,omitempty"`
+	Host               string                 `protobuf:"bytes,13,opt,name=host,proto3" json:"host,omitempty"`
+	Build              *Phase                 `protobuf:"bytes,14,opt,name=build,proto3" json:"build,omitempty"`
+	Test               *Phase                 `protobuf:"bytes,15,opt,name=test,proto3" json:"test,omitempty"`
+	LastStartTime      *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=last_start_time,json=lastStartTime,proto3" json:"last_start_time,omitempty"`
+	LastFileChangeTime *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=last_file_change_time,json=lastFileChangeTime,proto3" json:"last_file_change_time,omitempty"`
+	RestartCount       int32                  `protobuf:"varint,18,opt,name=restart_count,json=restartCount,proto3" json:"restart_count,omitempty"`
+	Links              []*Link                `protobuf:"bytes,19,rep,name=links,proto3" json:"links,omitempty"`
+	BackofficeReady    bool                   `protobuf:"varint,20,opt,name=backoffice_ready,json=backofficeReady,proto3" json:"backoffice_ready,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *TargetStatus) Reset() {
+	*x = TargetStatus{}
+	mi := &file_runctl_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TargetStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TargetStatus) ProtoMessage() {}
+
+func (x *TargetStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_runctl_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TargetStatus.ProtoReflect.Descriptor instead.
+func (*TargetStatus) Descriptor() ([]byte, []int) {
+	return file_runctl_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TargetStatus) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TargetStatus) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *TargetStatus) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *TargetStatus) GetHasBuild() bool {
+	if x != nil {
+		return x.HasBuild
+	}
+	return false
+}
+
+func (x *TargetStatus) GetHasTest() bool {
+	if x != nil {
+		return x.HasTest
+	}
+	return false
+}
+
+func (x *TargetStatus) GetHasRun() bool {
+	if x != nil {
+		return x.HasRun
+	}
+	return false
+}
+
+func (x *TargetStatus) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *TargetStatus) GetCurrentStage() string {
+	if x != nil {
+		return x.CurrentStage
+	}
+	return ""
+}
+
+func (x *TargetStatus) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *TargetStatus) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *TargetStatus) GetContainerId() string {
+	if x != nil {
+		return x.ContainerId
+	}
+	return ""
+}
+
+func (x *TargetStatus) GetWaitError() string {
+	if x != nil {
+		return x.WaitError
+	}
+	return ""
+}
+
+func (x *TargetStatus) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *TargetStatus) GetBuild() *Phase {
+	if x != nil {
+		return x.Build
+	}
+	return nil
+}
+
+func (x *TargetStatus) GetTest() *Phase {
+	if x != nil {
+		return x.Test
+	}
+	return nil
+}
+
+func (x *TargetStatus) GetLastStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastStartTime
+	}
+	return nil
+}
+
+func (x *TargetStatus) GetLastFileChangeTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastFileChangeTime
+	}
+	return nil
+}
+
+func (x *TargetStatus) GetRestartCount() int32 {
+	if x != nil {
+		return x.RestartCount
+	}
+	return 0
+}
+
+func (x *TargetStatus) GetLinks() []*Link {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+func (x *TargetStatus) GetBackofficeReady() bool {
+	if x != nil {
+		return x.BackofficeReady
+	}
+	return false
+}
+
+type StreamLogsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Stage         string                 `protobuf:"bytes,2,opt,name=stage,proto3" json:"stage,omitempty"`
+	Lines         int32                  `protobuf:"varint,3,opt,name=lines,proto3" json:"lines,omitempty"`
+	Follow        bool                   `protobuf:"varint,4,opt,name=follow,proto3" json:"follow,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamLogsRequest) Reset() {
+	*x = StreamLogsRequest{}
+	mi := &file_runctl_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamLogsRequest) ProtoMessage() {}
+
+func (x *StreamLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_runctl_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamLogsRequest.ProtoReflect.Descriptor instead.
+func (*StreamLogsRequest) Descriptor() ([]byte, []int) {
+	return file_runctl_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *StreamLogsRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *StreamLogsRequest) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+func (x *StreamLogsRequest) GetLines() int32 {
+	if x != nil {
+		return x.Lines
+	}
+	return 0
+}
+
+func (x *StreamLogsRequest) GetFollow() bool {
+	if x != nil {
+		return x.Follow
+	}
+	return false
+}
+
+type LogLine struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Line          string                 `protobuf:"bytes,1,opt,name=line,proto3" json:"line,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogLine) Reset() {
+	*x = LogLine{}
+	mi := &file_runctl_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogLine) ProtoMessage() {}
+
+func (x *LogLine) ProtoReflect() protoreflect.Message {
+	mi := &file_runctl_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogLine.ProtoReflect.Descriptor instead.
+func (*LogLine) Descriptor() ([]byte, []int) {
+	return file_runctl_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *LogLine) GetLine() string {
+	if x != nil {
+		return x.Line
+	}
+	return ""
+}
+
+type StreamEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Target        string                 `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	AfterId       uint64                 `protobuf:"varint,2,opt,name=after_id,json=afterId,proto3" json:"after_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamEventsRequest) Reset() {
+	*x = StreamEventsRequest{}
+	mi := &file_runctl_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamEventsRequest) ProtoMessage() {}
+
+func (x *StreamEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_runctl_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamEventsRequest.ProtoReflect.Descriptor instead.
+func (*StreamEventsRequest) Descriptor() ([]byte, []int) {
+	return file_runctl_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StreamEventsRequest) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *StreamEventsRequest) GetAfterId() uint64 {
+	if x != nil {
+		return x.AfterId
+	}
+	return 0
+}
+
+type Event struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Event         string                 `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`
+	Target        string                 `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`
+	OldState      string                 `protobuf:"bytes,4,opt,name=old_state,json=oldState,proto3" json:"old_state,omitempty"`
+	NewState      string                 `protobuf:"bytes,5,opt,name=new_state,json=newState,proto3" json:"new_state,omitempty"`
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	Output        []string               `protobuf:"bytes,7,rep,name=output,proto3" json:"output,omitempty"`
+	ExitCode      int32                  `protobuf:"varint,8,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_runctl_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_runctl_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_runctl_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Event) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Event) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *Event) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *Event) GetOldState() string {
+	if x != nil {
+		return x.OldState
+	}
+	return ""
+}
+
+func (x *Event) GetNewState() string {
+	if x != nil {
+		return x.NewState
+	}
+	return ""
+}
+
+func (x *Event) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Event) GetOutput() []string {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *Event) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *Event) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+var File_runctl_proto protoreflect.FileDescriptor
+
+const file_runctl_proto_rawDesc = "" +
+	"\n" +
+	"\frunctl.proto\x12\trunctl.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x14\n" +
+	"\x12ListTargetsRequest\"H\n" +
+	"\x13ListTargetsResponse\x121\n" +
+	"\atargets\x18\x01 \x03(\v2\x17.runctl.v1.TargetStatusR\atargets\"#\n" +
+	"\rTargetRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"(\n" +
+	"\x0eActionResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"\xa0\x01\n" +
+	"\x05Phase\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12#\n" +
+	"\rduration_secs\x18\x02 \x01(\x01R\fdurationSecs\x12\x16\n" +
+	"\x06result\x18\x03 \x01(\tR\x06result\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12\x14\n" +
+	"\x05count\x18\x05 \x01(\x05R\x05count\"\x85\x01\n" +
+	"\x04Link\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\x12\x12\n" +
+	"\x04file\x18\x04 \x01(\tR\x04file\x12!\n" +
+	"\fresolved_url\x18\x05 \x01(\tR\vresolvedUrl\"\xc0\x05\n" +
+	"\fTargetStatus\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1b\n" +
+	"\thas_build\x18\x04 \x01(\bR\bhasBuild\x12\x19\n" +
+	"\bhas_test\x18\x05 \x01(\bR\ahasTest\x12\x17\n" +
+	"\ahas_run\x18\x06 \x01(\bR\x06hasRun\x12\x14\n" +
+	"\x05state\x18\a \x01(\tR\x05state\x12#\n" +
+	"\rcurrent_stage\x18\b \x01(\tR\fcurrentStage\x12\x18\n" +
+	"\aenabled\x18\t \x01(\bR\aenabled\x12\x10\n" +
+	"\x03pid\x18\n" +
+	" \x01(\x05R\x03pid\x12!\n" +
+	"\fcontainer_id\x18\v \x01(\tR\vcontainerId\x12\x1d\n" +
+	"\n" +
+	"wait_error\x18\f \x01(\tR\twaitError\x12\x12\n" +
+	"\x04host\x18\r \x01(\tR\x04host\x12&\n" +
+	"\x05build\x18\x0e \x01(\v2\x10.runctl.v1.PhaseR\x05build\x12$\n" +
+	"\x04test\x18\x0f \x01(\v2\x10.runctl.v1.PhaseR\x04test\x12B\n" +
+	"\x0flast_start_time\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\rlastStartTime\x12M\n" +
+	"\x15last_file_change_time\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\x12lastFileChangeTime\x12#\n" +
+	"\rrestart_count\x18\x12 \x01(\x05R\frestartCount\x12%\n" +
+	"\x05links\x18\x13 \x03(\v2\x0f.runctl.v1.LinkR\x05links\x12)\n" +
+	"\x10backoffice_ready\x18\x14 \x01(\bR\x0fbackofficeReady\"k\n" +
+	"\x11StreamLogsRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05stage\x18\x02 \x01(\tR\x05stage\x12\x14\n" +
+	"\x05lines\x18\x03 \x01(\x05R\x05lines\x12\x16\n" +
+	"\x06follow\x18\x04 \x01(\bR\x06follow\"\x1d\n" +
+	"\aLogLine\x12\x12\n" +
+	"\x04line\x18\x01 \x01(\tR\x04line\"H\n" +
+	"\x13StreamEventsRequest\x12\x16\n" +
+	"\x06target\x18\x01 \x01(\tR\x06target\x12\x19\n" +
+	"\bafter_id\x18\x02 \x01(\x04R\aafterId\"\xfa\x01\n" +
+	"\x05Event\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x14\n" +
+	"\x05event\x18\x02 \x01(\tR\x05event\x12\x16\n" +
+	"\x06target\x18\x03 \x01(\tR\x06target\x12\x1b\n" +
+	"\told_state\x18\x04 \x01(\tR\boldState\x12\x1b\n" +
+	"\tnew_state\x18\x05 \x01(\tR\bnewState\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\x12\x16\n" +
+	"\x06output\x18\a \x03(\tR\x06output\x12\x1b\n" +
+	"\texit_code\x18\b \x01(\x05R\bexitCode\x12.\n" +
+	"\x04time\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\x04time2\xf0\x04\n" +
+	"\x06Runctl\x12L\n" +
+	"\vListTargets\x12\x1d.runctl.v1.ListTargetsRequest\x1a\x1e.runctl.v1.ListTargetsResponse\x12>\n" +
+	"\tGetTarget\x12\x18.runctl.v1.TargetRequest\x1a\x17.runctl.v1.TargetStatus\x12B\n" +
+	"\vBuildTarget\x12\x18.runctl.v1.TargetRequest\x1a\x19.runctl.v1.ActionResponse\x12A\n" +
+	"\n" +
+	"TestTarget\x12\x18.runctl.v1.TargetRequest\x1a\x19.runctl.v1.ActionResponse\x12B\n" +
+	"\vStartTarget\x12\x18.runctl.v1.TargetRequest\x1a\x19.runctl.v1.ActionResponse\x12A\n" +
+	"\n" +
+	"StopTarget\x12\x18.runctl.v1.TargetRequest\x1a\x19.runctl.v1.ActionResponse\x12D\n" +
+	"\rRestartTarget\x12\x18.runctl.v1.TargetRequest\x1a\x19.runctl.v1.ActionResponse\x12@\n" +
+	"\n" +
+	"StreamLogs\x12\x1c.runctl.v1.StreamLogsRequest\x1a\x12.runctl.v1.LogLine0\x01\x12B\n" +
+	"\fStreamEvents\x12\x1e.runctl.v1.StreamEventsRequest\x1a\x10.runctl.v1.Event0\x01B1Z/github.com/gur-shatz/go-run/pkg/runctl/runctlpbb\x06proto3"
+
+var (
+	file_runctl_proto_rawDescOnce sync.Once
+	file_runctl_proto_rawDescData []byte
+)
+
+func file_runctl_proto_rawDescGZIP() []byte {
+	file_runctl_proto_rawDescOnce.Do(func() {
+		file_runctl_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_runctl_proto_rawDesc), len(file_runctl_proto_rawDesc)))
+	})
+	return file_runctl_proto_rawDescData
+}
+
+var file_runctl_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_runctl_proto_goTypes = []any{
+	(*ListTargetsRequest)(nil),    // 0: runctl.v1.ListTargetsRequest
+	(*ListTargetsResponse)(nil),   // 1: runctl.v1.ListTargetsResponse
+	(*TargetRequest)(nil),         // 2: runctl.v1.TargetRequest
+	(*ActionResponse)(nil),        // 3: runctl.v1.ActionResponse
+	(*Phase)(nil),                 // 4: runctl.v1.Phase
+	(*Link)(nil),                  // 5: runctl.v1.Link
+	(*TargetStatus)(nil),          // 6: runctl.v1.TargetStatus
+	(*StreamLogsRequest)(nil),     // 7: runctl.v1.StreamLogsRequest
+	(*LogLine)(nil),               // 8: runctl.v1.LogLine
+	(*StreamEventsRequest)(nil),   // 9: runctl.v1.StreamEventsRequest
+	(*Event)(nil),                 // 10: runctl.v1.Event
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
+}
+var file_runctl_proto_depIdxs = []int32{
+	6,  // 0: runctl.v1.ListTargetsResponse.targets:type_name -> runctl.v1.TargetStatus
+	11, // 1: runctl.v1.Phase.time:type_name -> google.protobuf.Timestamp
+	4,  // 2: runctl.v1.TargetStatus.build:type_name -> runctl.v1.Phase
+	4,  // 3: runctl.v1.TargetStatus.test:type_name -> runctl.v1.Phase
+	11, // 4: runctl.v1.TargetStatus.last_start_time:type_name -> google.protobuf.Timestamp
+	11, // 5: runctl.v1.TargetStatus.last_file_change_time:type_name -> google.protobuf.Timestamp
+	5,  // 6: runctl.v1.TargetStatus.links:type_name -> runctl.v1.Link
+	11, // 7: runctl.v1.Event.time:type_name -> google.protobuf.Timestamp
+	0,  // 8: runctl.v1.Runctl.ListTargets:input_type -> runctl.v1.ListTargetsRequest
+	2,  // 9: runctl.v1.Runctl.GetTarget:input_type -> runctl.v1.TargetRequest
+	2,  // 10: runctl.v1.Runctl.BuildTarget:input_type -> runctl.v1.TargetRequest
+	2,  // 11: runctl.v1.Runctl.TestTarget:input_type -> runctl.v1.TargetRequest
+	2,  // 12: runctl.v1.Runctl.StartTarget:input_type -> runctl.v1.TargetRequest
+	2,  // 13: runctl.v1.Runctl.StopTarget:input_type -> runctl.v1.TargetRequest
+	2,  // 14: runctl.v1.Runctl.RestartTarget:input_type -> runctl.v1.TargetRequest
+	7,  // 15: runctl.v1.Runctl.StreamLogs:input_type -> runctl.v1.StreamLogsRequest
+	9,  // 16: runctl.v1.Runctl.StreamEvents:input_type -> runctl.v1.StreamEventsRequest
+	1,  // 17: runctl.v1.Runctl.ListTargets:output_type -> runctl.v1.ListTargetsResponse
+	6,  // 18: runctl.v1.Runctl.GetTarget:output_type -> runctl.v1.TargetStatus
+	3,  // 19: runctl.v1.Runctl.BuildTarget:output_type -> runctl.v1.ActionResponse
+	3,  // 20: runctl.v1.Runctl.TestTarget:output_type -> runctl.v1.ActionResponse
+	3,  // 21: runctl.v1.Runctl.StartTarget:output_type -> runctl.v1.ActionResponse
+	3,  // 22: runctl.v1.Runctl.StopTarget:output_type -> runctl.v1.ActionResponse
+	3,  // 23: runctl.v1.Runctl.RestartTarget:output_type -> runctl.v1.ActionResponse
+	8,  // 24: runctl.v1.Runctl.StreamLogs:output_type -> runctl.v1.LogLine
+	10, // 25: runctl.v1.Runctl.StreamEvents:output_type -> runctl.v1.Event
+	17, // [17:26] is the sub-list for method output_type
+	8,  // [8:17] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_runctl_proto_init() }
+func file_runctl_proto_init() {
+	if File_runctl_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_runctl_proto_rawDesc), len(file_runctl_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_runctl_proto_goTypes,
+		DependencyIndexes: file_runctl_proto_depIdxs,
+		MessageInfos:      file_runctl_proto_msgTypes,
+	}.Build()
+	File_runctl_proto = out.File
+	file_runctl_proto_goTypes = nil
+	file_runctl_proto_depIdxs = nil
+}
@@ -0,0 +1,432 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: runctl.proto
+
+package runctlpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Runctl_ListTargets_FullMethodName   = "/runctl.v1.Runctl/ListTargets"
+	Runctl_GetTarget_FullMethodName     = "/runctl.v1.Runctl/GetTarget"
+	Runctl_BuildTarget_FullMethodName   = "/runctl.v1.Runctl/BuildTarget"
+	Runctl_TestTarget_FullMethodName    = "/runctl.v1.Runctl/TestTarget"
+	Runctl_StartTarget_FullMethodName   = "/runctl.v1.Runctl/StartTarget"
+	Runctl_StopTarget_FullMethodName    = "/runctl.v1.Runctl/StopTarget"
+	Runctl_RestartTarget_FullMethodName = "/runctl.v1.Runctl/RestartTarget"
+	Runctl_StreamLogs_FullMethodName    = "/runctl.v1.Runctl/StreamLogs"
+	Runctl_StreamEvents_FullMethodName  = "/runctl.v1.Runctl/StreamEvents"
+)
+
+// RunctlClient is the client API for Runctl service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RunctlClient interface {
+	ListTargets(ctx context.Context, in *ListTargetsRequest, opts ...grpc.CallOption) (*ListTargetsResponse, error)
+	GetTarget(ctx context.Context, in *TargetRequest, opts ...grpc.CallOption) (*TargetStatus, error)
+	BuildTarget(ctx context.Context, in *TargetRequest, opts ...grpc.CallOption) (*ActionResponse, error)
+	TestTarget(ctx context.Context, in *TargetRequest, opts ...grpc.CallOption) (*ActionResponse, error)
+	StartTarget(ctx context.Context, in *TargetRequest, opts ...grpc.CallOption) (*ActionResponse, error)
+	StopTarget(ctx context.Context, in *TargetRequest, opts ...grpc.CallOption) (*ActionResponse, error)
+	RestartTarget(ctx context.Context, in *TargetRequest, opts ...grpc.CallOption) (*ActionResponse, error)
+	StreamLogs(ctx context.Context, in *StreamLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogLine], error)
+	StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type runctlClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRunctlClient(cc grpc.ClientConnInterface) RunctlClient {
+	return &runctlClient{cc}
+}
+
+func (c *runctlClient) ListTargets(ctx context.Context, in *ListTargetsRequest, opts ...grpc.CallOption) (*ListTargetsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTargetsResponse)
+	err := c.cc.Invoke(ctx, Runctl_ListTargets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *runctlClient) GetTarget(ctx context.Context, in *TargetRequest, opts ...grpc.CallOption) (*TargetStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TargetStatus)
+	err := c.cc.Invoke(ctx, Runctl_GetTarget_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *runctlClient) BuildTarget(ctx context.Context, in *TargetRequest, opts ...grpc.CallOption) (*ActionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ActionResponse)
+	err := c.cc.Invoke(ctx, Runctl_BuildTarget_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *runctlClient) TestTarget(ctx context.Context, in *TargetRequest, opts ...grpc.CallOption) (*ActionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ActionResponse)
+	err := c.cc.Invoke(ctx, Runctl_TestTarget_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *runctlClient) StartTarget(ctx context.Context, in *TargetRequest, opts ...grpc.CallOption) (*ActionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ActionResponse)
+	err := c.cc.Invoke(ctx, Runctl_StartTarget_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *runctlClient) StopTarget(ctx context.Context, in *TargetRequest, opts ...grpc.CallOption) (*ActionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ActionResponse)
+	err := c.cc.Invoke(ctx, Runctl_StopTarget_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *runctlClient) RestartTarget(ctx context.Context, in *TargetRequest, opts ...grpc.CallOption) (*ActionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ActionResponse)
+	err := c.cc.Invoke(ctx, Runctl_RestartTarget_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *runctlClient) StreamLogs(ctx context.Context, in *StreamLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogLine], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Runctl_ServiceDesc.Streams[0], Runctl_StreamLogs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamLogsRequest, LogLine]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Runctl_StreamLogsClient = grpc.ServerStreamingClient[LogLine]
+
+func (c *runctlClient) StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Runctl_ServiceDesc.Streams[1], Runctl_StreamEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamEventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Runctl_StreamEventsClient = grpc.ServerStreamingClient[Event]
+
+// RunctlServer is the server API for Runctl service.
+// All implementations must embed UnimplementedRunctlServer
+// for forward compatibility.
+type RunctlServer interface {
+	ListTargets(context.Context, *ListTargetsRequest) (*ListTargetsResponse, error)
+	GetTarget(context.Context, *TargetRequest) (*TargetStatus, error)
+	BuildTarget(context.Context, *TargetRequest) (*ActionResponse, error)
+	TestTarget(context.Context, *TargetRequest) (*ActionResponse, error)
+	StartTarget(context.Context, *TargetRequest) (*ActionResponse, error)
+	StopTarget(context.Context, *TargetRequest) (*ActionResponse, error)
+	RestartTarget(context.Context, *TargetRequest) (*ActionResponse, error)
+	StreamLogs(*StreamLogsRequest, grpc.ServerStreamingServer[LogLine]) error
+	StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedRunctlServer()
+}
+
+// UnimplementedRunctlServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRunctlServer struct{}
+
+func (UnimplementedRunctlServer) ListTargets(context.Context, *ListTargetsRequest) (*ListTargetsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTargets not implemented")
+}
+func (UnimplementedRunctlServer) GetTarget(context.Context, *TargetRequest) (*TargetStatus, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTarget not implemented")
+}
+func (UnimplementedRunctlServer) BuildTarget(context.Context, *TargetRequest) (*ActionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BuildTarget not implemented")
+}
+func (UnimplementedRunctlServer) TestTarget(context.Context, *TargetRequest) (*ActionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method TestTarget not implemented")
+}
+func (UnimplementedRunctlServer) StartTarget(context.Context, *TargetRequest) (*ActionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method StartTarget not implemented")
+}
+func (UnimplementedRunctlServer) StopTarget(context.Context, *TargetRequest) (*ActionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method StopTarget not implemented")
+}
+func (UnimplementedRunctlServer) RestartTarget(context.Context, *TargetRequest) (*ActionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RestartTarget not implemented")
+}
+func (UnimplementedRunctlServer) StreamLogs(*StreamLogsRequest, grpc.ServerStreamingServer[LogLine]) error {
+	return status.Error(codes.Unimplemented, "method StreamLogs not implemented")
+}
+func (UnimplementedRunctlServer) StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Error(codes.Unimplemented, "method StreamEvents not implemented")
+}
+func (UnimplementedRunctlServer) mustEmbedUnimplementedRunctlServer() {}
+func (UnimplementedRunctlServer) testEmbeddedByValue()                {}
+
+// UnsafeRunctlServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RunctlServer will
+// result in compilation errors.
+type UnsafeRunctlServer interface {
+	mustEmbedUnimplementedRunctlServer()
+}
+
+func RegisterRunctlServer(s grpc.ServiceRegistrar, srv RunctlServer) {
+	// If the following call panics, it indicates UnimplementedRunctlServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Runctl_ServiceDesc, srv)
+}
+
+func _Runctl_ListTargets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTargetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RunctlServer).ListTargets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Runctl_ListTargets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RunctlServer).ListTargets(ctx, req.(*ListTargetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Runctl_GetTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TargetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RunctlServer).GetTarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Runctl_GetTarget_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RunctlServer).GetTarget(ctx, req.(*TargetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Runctl_BuildTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TargetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RunctlServer).BuildTarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Runctl_BuildTarget_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RunctlServer).BuildTarget(ctx, req.(*TargetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Runctl_TestTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TargetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RunctlServer).TestTarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Runctl_TestTarget_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RunctlServer).TestTarget(ctx, req.(*TargetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Runctl_StartTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TargetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RunctlServer).StartTarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Runctl_StartTarget_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RunctlServer).StartTarget(ctx, req.(*TargetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Runctl_StopTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TargetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RunctlServer).StopTarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Runctl_StopTarget_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RunctlServer).StopTarget(ctx, req.(*TargetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Runctl_RestartTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TargetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RunctlServer).RestartTarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Runctl_RestartTarget_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RunctlServer).RestartTarget(ctx, req.(*TargetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Runctl_StreamLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RunctlServer).StreamLogs(m, &grpc.GenericServerStream[StreamLogsRequest, LogLine]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Runctl_StreamLogsServer = grpc.ServerStreamingServer[LogLine]
+
+func _Runctl_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RunctlServer).StreamEvents(m, &grpc.GenericServerStream[StreamEventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Runctl_StreamEventsServer = grpc.ServerStreamingServer[Event]
+
+// Runctl_ServiceDesc is the grpc.ServiceDesc for Runctl service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Runctl_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "runctl.v1.Runctl",
+	HandlerType: (*RunctlServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListTargets",
+			Handler:    _Runctl_ListTargets_Handler,
+		},
+		{
+			MethodName: "GetTarget",
+			Handler:    _Runctl_GetTarget_Handler,
+		},
+		{
+			MethodName: "BuildTarget",
+			Handler:    _Runctl_BuildTarget_Handler,
+		},
+		{
+			MethodName: "TestTarget",
+			Handler:    _Runctl_TestTarget_Handler,
+		},
+		{
+			MethodName: "StartTarget",
+			Handler:    _Runctl_StartTarget_Handler,
+		},
+		{
+			MethodName: "StopTarget",
+			Handler:    _Runctl_StopTarget_Handler,
+		},
+		{
+			MethodName: "RestartTarget",
+			Handler:    _Runctl_RestartTarget_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamLogs",
+			Handler:       _Runctl_StreamLogs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamEvents",
+			Handler:       _Runctl_StreamEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "runctl.proto",
+}
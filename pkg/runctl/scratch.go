@@ -0,0 +1,149 @@
+package runctl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gur-shatz/go-run/pkg/execrun"
+)
+
+// ScratchVar is the template var that holds a target's scratch directory,
+// e.g. build: ["go build -o {{ .SCRATCH_DIR }}/app ."].
+const ScratchVar = "SCRATCH_DIR"
+
+// scratchSourceFile records, inside each scratch root, the config directory
+// it belongs to, so the janitor can tell when a checkout is gone.
+const scratchSourceFile = ".source"
+
+// scratchTargetsDir holds the per-target dirs inside a scratch root.
+const scratchTargetsDir = "targets"
+
+// scratchTargetFile marks a per-target dir as created by runctl; the
+// janitor only removes dirs that carry it.
+const scratchTargetFile = ".runctl-target"
+
+// scratchCacheDir is where all scratch roots live: the user cache dir
+// ($XDG_CACHE_HOME or ~/.cache on Linux) under runctl/.
+func scratchCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locate cache dir: %w", err)
+	}
+	return filepath.Join(dir, "runctl"), nil
+}
+
+// scratchRoot returns the scratch directory for the runctl.yaml in baseDir.
+// It is keyed by a hash of the absolute path and instance name, so two
+// checkouts of the same project, or two instances of one, never share one.
+func scratchRoot(baseDir, instance string) (string, error) {
+	cache, err := scratchCacheDir()
+	if err != nil {
+		return "", err
+	}
+	prefix := instance
+	if prefix == "" {
+		prefix = filepath.Base(baseDir)
+	}
+	sum := sha256.Sum256([]byte(baseDir + "\x00" + instance))
+	return filepath.Join(cache, prefix+"-"+hex.EncodeToString(sum[:6])), nil
+}
+
+// resolveScratchDirs sets ScratchDir on the config and every target and
+// creates the directories. baseDir must be absolute.
+func (this *Config) resolveScratchDirs(baseDir string) error {
+	if this.ScratchDir == "" && this.CacheDir != "" {
+		this.ScratchDir = this.CacheDir
+		if !filepath.IsAbs(this.ScratchDir) {
+			this.ScratchDir = filepath.Join(baseDir, this.ScratchDir)
+		}
+	}
+	if this.ScratchDir == "" {
+		root, err := scratchRoot(baseDir, this.InstanceName)
+		if err != nil {
+			return err
+		}
+		this.ScratchDir = root
+	}
+	if err := execrun.PrepareCacheDir(this.ScratchDir, baseDir); err != nil {
+		if this.CacheDir != "" {
+			return fmt.Errorf("cache_dir: %w", err)
+		}
+		return fmt.Errorf("create scratch dir: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(this.ScratchDir, scratchSourceFile), []byte(baseDir+"\n"), 0644); err != nil {
+		return fmt.Errorf("create scratch dir: %w", err)
+	}
+	for name, t := range this.Targets {
+		t.ScratchDir = filepath.Join(this.ScratchDir, scratchTargetsDir, normalizeTargetName(name))
+		if err := os.MkdirAll(t.ScratchDir, 0755); err != nil {
+			return fmt.Errorf("create scratch dir for target %q: %w", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(t.ScratchDir, scratchTargetFile), nil, 0644); err != nil {
+			return fmt.Errorf("create scratch dir for target %q: %w", name, err)
+		}
+		this.Targets[name] = t
+	}
+	return nil
+}
+
+// pruneScratch removes scratch roots under cacheDir whose checkout no
+// longer exists, and target dirs in root that no config target owns.
+// Only dirs runctl created, marked by scratchSourceFile or
+// scratchTargetFile, are removed.
+// It returns how many directories were removed.
+func pruneScratch(cacheDir, root string, targets map[string]TargetConfig) (int, error) {
+	entries, err := os.ReadDir(cacheDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, e := range entries {
+		dir := filepath.Join(cacheDir, e.Name())
+		if !e.IsDir() || dir == root {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, scratchSourceFile))
+		if err != nil {
+			continue // not ours
+		}
+		if _, err := os.Stat(strings.TrimSpace(string(data))); !errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return removed, fmt.Errorf("remove %s: %w", dir, err)
+		}
+		removed++
+	}
+
+	owned := make(map[string]bool, len(targets))
+	for name := range targets {
+		owned[normalizeTargetName(name)] = true
+	}
+	targetsDir := filepath.Join(root, scratchTargetsDir)
+	entries, err = os.ReadDir(targetsDir)
+	if err != nil {
+		return removed, nil
+	}
+	for _, e := range entries {
+		if !e.IsDir() || owned[e.Name()] {
+			continue
+		}
+		dir := filepath.Join(targetsDir, e.Name())
+		if _, err := os.Stat(filepath.Join(dir, scratchTargetFile)); err != nil {
+			continue // not ours
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return removed, fmt.Errorf("remove %s: %w", e.Name(), err)
+		}
+		removed++
+	}
+	return removed, nil
+}
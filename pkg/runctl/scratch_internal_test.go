@@ -0,0 +1,65 @@
+package runctl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPruneScratch(t *testing.T) {
+	cache := t.TempDir()
+	checkout := t.TempDir()
+
+	mkScratch := func(name, source string) string {
+		dir := filepath.Join(cache, name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, scratchSourceFile), []byte(source+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	root := mkScratch("current", checkout)
+	live := mkScratch("live", checkout)
+	gone := mkScratch("gone", filepath.Join(checkout, "deleted"))
+	foreign := filepath.Join(cache, "foreign") // no source file: not ours
+	targets := filepath.Join(root, scratchTargetsDir)
+	for _, dir := range []string{foreign, filepath.Join(root, "src"), filepath.Join(targets, "user_dir")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, dir := range []string{filepath.Join(targets, "api"), filepath.Join(targets, "old_target")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, scratchTargetFile), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := pruneScratch(cache, root, map[string]TargetConfig{"API": {}})
+	if err != nil {
+		t.Fatalf("pruneScratch: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("removed = %d, want 2", removed)
+	}
+
+	for dir, wantGone := range map[string]bool{
+		root:                                 false,
+		live:                                 false,
+		gone:                                 true,
+		foreign:                              false,
+		filepath.Join(root, "src"):           false, // not a target dir
+		filepath.Join(targets, "user_dir"):   false, // not created by runctl
+		filepath.Join(targets, "api"):        false,
+		filepath.Join(targets, "old_target"): true,
+	} {
+		_, err := os.Stat(dir)
+		if os.IsNotExist(err) != wantGone {
+			t.Errorf("%s: removed = %v, want %v", dir, os.IsNotExist(err), wantGone)
+		}
+	}
+}
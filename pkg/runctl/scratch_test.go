@@ -0,0 +1,53 @@
+package runctl_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("Scratch dirs", func() {
+	load := func(dir string) *runctl.Config {
+		Expect(os.WriteFile(filepath.Join(dir, "runctl.yaml"), []byte(`
+targets:
+  api:
+    config: api/execrun.yaml
+`), 0644)).To(Succeed())
+		cfg, err := runctl.LoadConfig(filepath.Join(dir, "runctl.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		return cfg
+	}
+
+	It("gives each target a scratch dir under the user cache", func() {
+		cfg := load(GinkgoT().TempDir())
+
+		dir := cfg.Targets["api"].ScratchDir
+		Expect(dir).To(HavePrefix(filepath.Join(os.Getenv("XDG_CACHE_HOME"), "runctl")))
+		Expect(dir).To(BeADirectory())
+		Expect(cfg.ParentVars("api")).To(HaveKeyWithValue(runctl.ScratchVar, dir))
+	})
+
+	It("keeps checkouts of the same project apart", func() {
+		a := filepath.Join(GinkgoT().TempDir(), "project")
+		b := filepath.Join(GinkgoT().TempDir(), "project")
+		Expect(os.Mkdir(a, 0755)).To(Succeed())
+		Expect(os.Mkdir(b, 0755)).To(Succeed())
+
+		Expect(load(a).ScratchDir).NotTo(Equal(load(b).ScratchDir))
+	})
+
+	It("lets target vars override SCRATCH_DIR", func() {
+		cfg := load(GinkgoT().TempDir())
+		tcfg := cfg.Targets["api"]
+		tcfg.Vars = map[string]string{runctl.ScratchVar: "/elsewhere"}
+		cfg.Targets["api"] = tcfg
+
+		Expect(cfg.ParentVars("api")).To(HaveKeyWithValue(runctl.ScratchVar, "/elsewhere"))
+		Expect(strings.HasPrefix(tcfg.ScratchDir, cfg.ScratchDir)).To(BeTrue())
+	})
+})
@@ -0,0 +1,35 @@
+package runctl
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/gur-shatz/go-run/internal/mask"
+)
+
+// registerSecrets marks the values of the secrets: section for masking
+// everywhere in this process, including execrun's output.
+func (this *Config) registerSecrets() {
+	mask.Register(slices.Collect(maps.Values(this.Secrets))...)
+}
+
+// newMasker returns the masker for the mask: patterns. Secret values come
+// from the environment, global vars, or any target's vars; values from
+// secrets: and secretFile are masked through the registry.
+func (this *Config) newMasker() (*mask.Masker, error) {
+	envs := []map[string]string{environMap(), this.ResolvedVars}
+	for _, tcfg := range this.Targets {
+		envs = append(envs, tcfg.Vars)
+	}
+	return mask.New(this.Mask, envs...)
+}
+
+// Redact masks secret values in s the way the controller masks logs and
+// API responses.
+func (this *Config) Redact(s string) string {
+	m, err := this.newMasker()
+	if err != nil {
+		return mask.String(s, []string{})
+	}
+	return m.String(s)
+}
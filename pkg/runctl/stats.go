@@ -0,0 +1,243 @@
+package runctl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// statsFile is the usage stats file in the checkout's scratch dir. It never
+// leaves the machine; `runctl report` reads it.
+const statsFile = "stats.json"
+
+// statsRetention is how many days of usage stats are kept.
+const statsRetention = 90
+
+// statsDayLayout keys the stats by local calendar day.
+const statsDayLayout = "2006-01-02"
+
+// Usage counts a target's builds, tests, and crashes.
+type Usage struct {
+	Builds        int     `json:"builds"`
+	BuildFailures int     `json:"build_failures"`
+	BuildSecs     float64 `json:"build_secs"`
+	Tests         int     `json:"tests"`
+	TestFailures  int     `json:"test_failures"`
+	TestSecs      float64 `json:"test_secs"`
+	Crashes       int     `json:"crashes"`
+}
+
+// AvgBuild returns the mean build duration.
+func (this Usage) AvgBuild() time.Duration {
+	if this.Builds == 0 {
+		return 0
+	}
+	return time.Duration(this.BuildSecs / float64(this.Builds) * float64(time.Second))
+}
+
+// Failures returns failed builds, failed tests, and crashes combined.
+func (this Usage) Failures() int {
+	return this.BuildFailures + this.TestFailures + this.Crashes
+}
+
+func (this *Usage) add(o Usage) {
+	this.Builds += o.Builds
+	this.BuildFailures += o.BuildFailures
+	this.BuildSecs += o.BuildSecs
+	this.Tests += o.Tests
+	this.TestFailures += o.TestFailures
+	this.TestSecs += o.TestSecs
+	this.Crashes += o.Crashes
+}
+
+// UsageStats is the content of the stats file: usage per day, per target.
+type UsageStats struct {
+	Days map[string]map[string]*Usage `json:"days"`
+}
+
+// StatsPath returns the usage stats file of this checkout, or "" if stats
+// are off. Requires ScratchDir (set by LoadConfig/New).
+func (this Config) StatsPath() string {
+	if this.ScratchDir == "" || (this.Stats != nil && !*this.Stats) {
+		return ""
+	}
+	return filepath.Join(this.ScratchDir, statsFile)
+}
+
+// LoadUsageStats reads a stats file. A missing file is empty stats.
+func LoadUsageStats(path string) (*UsageStats, error) {
+	stats := &UsageStats{Days: make(map[string]map[string]*Usage)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read stats: %w", err)
+	}
+	if err := json.Unmarshal(data, stats); err != nil {
+		return nil, fmt.Errorf("parse stats %s: %w", path, err)
+	}
+	if stats.Days == nil {
+		stats.Days = make(map[string]map[string]*Usage)
+	}
+	return stats, nil
+}
+
+// statsRecorder updates the stats file as targets build, test, and crash.
+// A nil recorder records nothing.
+type statsRecorder struct {
+	mu    sync.Mutex
+	path  string
+	stats *UsageStats
+	now   func() time.Time
+}
+
+func newStatsRecorder(path string) *statsRecorder {
+	if path == "" {
+		return nil
+	}
+	stats, err := LoadUsageStats(path)
+	if err != nil {
+		warnf("%v; starting fresh stats", err)
+		stats = &UsageStats{Days: make(map[string]map[string]*Usage)}
+	}
+	return &statsRecorder{path: path, stats: stats, now: time.Now}
+}
+
+func (this *statsRecorder) build(target string, d time.Duration, err error) {
+	this.record(target, func(u *Usage) {
+		u.Builds++
+		u.BuildSecs += d.Seconds()
+		if err != nil {
+			u.BuildFailures++
+		}
+	})
+}
+
+func (this *statsRecorder) test(target string, d time.Duration, err error) {
+	this.record(target, func(u *Usage) {
+		u.Tests++
+		u.TestSecs += d.Seconds()
+		if err != nil {
+			u.TestFailures++
+		}
+	})
+}
+
+func (this *statsRecorder) crash(target string) {
+	this.record(target, func(u *Usage) { u.Crashes++ })
+}
+
+// record applies update to today's usage of target, drops days past the
+// retention, and saves the file.
+func (this *statsRecorder) record(target string, update func(*Usage)) {
+	if this == nil {
+		return
+	}
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	now := this.now()
+	day := now.Format(statsDayLayout)
+	targets := this.stats.Days[day]
+	if targets == nil {
+		targets = make(map[string]*Usage)
+		this.stats.Days[day] = targets
+	}
+	u := targets[target]
+	if u == nil {
+		u = &Usage{}
+		targets[target] = u
+	}
+	update(u)
+
+	cutoff := now.AddDate(0, 0, -statsRetention).Format(statsDayLayout)
+	for d := range this.stats.Days {
+		if d < cutoff {
+			delete(this.stats.Days, d)
+		}
+	}
+
+	if err := this.save(); err != nil {
+		warnf("save stats: %v", err)
+	}
+}
+
+// save writes the stats atomically. Must hold mu.
+func (this *statsRecorder) save() error {
+	data, err := json.MarshalIndent(this.stats, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := this.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, this.path)
+}
+
+// UsageReport summarizes usage stats over a period, for `runctl report`.
+type UsageReport struct {
+	Since       string        `json:"since"` // first day included
+	Total       Usage         `json:"total"`
+	Days        []DayUsage    `json:"days"`                   // days with activity, oldest first
+	Targets     []TargetUsage `json:"targets"`                // most failures first
+	MostFailing string        `json:"most_failing,omitempty"` // target with the most failures, if any failed
+}
+
+// DayUsage is the usage of all targets on one day.
+type DayUsage struct {
+	Day string `json:"day"`
+	Usage
+}
+
+// TargetUsage is one target's usage over the report period.
+type TargetUsage struct {
+	Name string `json:"name"`
+	Usage
+}
+
+// Report summarizes the stats of the given number of days up to and
+// including the day of now.
+func (this *UsageStats) Report(now time.Time, days int) UsageReport {
+	since := now.AddDate(0, 0, 1-days).Format(statsDayLayout)
+	report := UsageReport{Since: since, Days: []DayUsage{}, Targets: []TargetUsage{}}
+
+	byTarget := make(map[string]*Usage)
+	for day, targets := range this.Days {
+		if day < since {
+			continue
+		}
+		du := DayUsage{Day: day}
+		for name, u := range targets {
+			du.add(*u)
+			if byTarget[name] == nil {
+				byTarget[name] = &Usage{}
+			}
+			byTarget[name].add(*u)
+		}
+		report.Total.add(du.Usage)
+		report.Days = append(report.Days, du)
+	}
+	sort.Slice(report.Days, func(i, j int) bool { return report.Days[i].Day < report.Days[j].Day })
+
+	for name, u := range byTarget {
+		report.Targets = append(report.Targets, TargetUsage{Name: name, Usage: *u})
+	}
+	sort.Slice(report.Targets, func(i, j int) bool {
+		a, b := report.Targets[i], report.Targets[j]
+		if a.Failures() != b.Failures() {
+			return a.Failures() > b.Failures()
+		}
+		return a.Name < b.Name
+	})
+	if len(report.Targets) > 0 && report.Targets[0].Failures() > 0 {
+		report.MostFailing = report.Targets[0].Name
+	}
+	return report
+}
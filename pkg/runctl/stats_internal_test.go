@@ -0,0 +1,74 @@
+package runctl
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStatsRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), statsFile)
+	rec := newStatsRecorder(path)
+	day := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	rec.now = func() time.Time { return day }
+
+	rec.build("api", 2*time.Second, nil)
+	rec.build("api", 4*time.Second, errors.New("boom"))
+	rec.test("api", time.Second, nil)
+	rec.crash("web")
+	rec.crash("web")
+
+	// An old day is pruned by the next write.
+	rec.stats.Days["2025-01-01"] = map[string]*Usage{"api": {Builds: 1}}
+	day = day.Add(24 * time.Hour)
+	rec.build("web", time.Second, nil)
+
+	stats, err := LoadUsageStats(path)
+	if err != nil {
+		t.Fatalf("LoadUsageStats: %v", err)
+	}
+	if _, ok := stats.Days["2025-01-01"]; ok {
+		t.Error("day past retention was kept")
+	}
+	api := stats.Days["2026-03-10"]["api"]
+	if api == nil || api.Builds != 2 || api.BuildFailures != 1 || api.Tests != 1 {
+		t.Errorf("api usage = %+v", api)
+	}
+
+	report := stats.Report(day, 7)
+	if report.Since != "2026-03-05" {
+		t.Errorf("Since = %q, want 2026-03-05", report.Since)
+	}
+	if len(report.Days) != 2 || report.Days[0].Day != "2026-03-10" {
+		t.Errorf("Days = %+v", report.Days)
+	}
+	if report.Total.Builds != 3 || report.Total.Crashes != 2 {
+		t.Errorf("Total = %+v", report.Total)
+	}
+	if got := report.Targets[0].AvgBuild(); report.Targets[0].Name != "web" || got != time.Second {
+		t.Errorf("first target = %s (avg build %s), want web (1s)", report.Targets[0].Name, got)
+	}
+	if report.MostFailing != "web" {
+		t.Errorf("MostFailing = %q, want web", report.MostFailing)
+	}
+	if got := stats.Report(day, 1); len(got.Days) != 1 || got.Total.Builds != 1 {
+		t.Errorf("1-day report = %+v", got)
+	}
+}
+
+func TestStatsPath(t *testing.T) {
+	off := false
+	for _, tc := range []struct {
+		cfg  Config
+		want string
+	}{
+		{Config{ScratchDir: "/cache/x"}, "/cache/x/stats.json"},
+		{Config{ScratchDir: "/cache/x", Stats: &off}, ""},
+		{Config{}, ""},
+	} {
+		if got := tc.cfg.StatsPath(); got != tc.want {
+			t.Errorf("StatsPath() = %q, want %q", got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package runctl_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("Usage stats", func() {
+	var dir string
+
+	load := func(yaml string) *runctl.Config {
+		dir = GinkgoT().TempDir()
+		path := filepath.Join(dir, "runctl.yaml")
+		Expect(os.WriteFile(path, []byte(yaml), 0644)).To(Succeed())
+		cfg, err := runctl.LoadConfig(path)
+		Expect(err).NotTo(HaveOccurred())
+		return cfg
+	}
+
+	It("records crashes in the checkout's stats file", func() {
+		cfg := load(`
+targets:
+  app:
+    type: command
+    cmd: "false"
+`)
+		ctrl, err := runctl.New(*cfg, dir, false)
+		Expect(err).NotTo(HaveOccurred())
+		ctrl.StartTargets()
+		DeferCleanup(ctrl.KillTargets)
+
+		Expect(cfg.StatsPath()).NotTo(BeEmpty())
+		Eventually(func() int {
+			stats, err := runctl.LoadUsageStats(cfg.StatsPath())
+			Expect(err).NotTo(HaveOccurred())
+			return stats.Report(time.Now(), 1).Total.Crashes
+		}).Should(Equal(1))
+	})
+
+	It("can be turned off", func() {
+		cfg := load(`
+stats: false
+targets:
+  app:
+    type: command
+    cmd: "false"
+`)
+		Expect(cfg.StatsPath()).To(BeEmpty())
+	})
+})
@@ -2,33 +2,38 @@ package runctl
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/url"
 	"os"
-	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
-	"github.com/gur-shatz/go-run/internal/configutil"
+	"github.com/gur-shatz/go-run/internal/diskspace"
+	"github.com/gur-shatz/go-run/internal/mask"
 	"github.com/gur-shatz/go-run/internal/sumfile"
 	boclient "github.com/gur-shatz/go-run/pkg/backoffice/client"
-	"github.com/gur-shatz/go-run/pkg/config"
+	"github.com/gur-shatz/go-run/pkg/clock"
 	"github.com/gur-shatz/go-run/pkg/execrun"
+	"github.com/gur-shatz/go-run/pkg/messages"
 )
 
 // TargetState represents the current state of a target.
 type TargetState string
 
 const (
-	StateIdle     TargetState = "idle"
-	StateStarting TargetState = "starting"
-	StateRunning  TargetState = "running"
-	StateStopped  TargetState = "stopped"
-	StateError    TargetState = "error"
-	StateExited   TargetState = "exited"
+	StateIdle      TargetState = "idle"
+	StateStarting  TargetState = "starting"
+	StateRunning   TargetState = "running"
+	StateStopped   TargetState = "stopped"
+	StateError     TargetState = "error"
+	StateExited    TargetState = "exited"
+	StateCrashLoop TargetState = "crash_loop" // disabled after repeated crashes
+	StateSuspended TargetState = "suspended"  // stopped by idle_timeout until the next change or start
 )
 
 // PhaseStatus is the structured status for a build/test phase.
@@ -51,7 +56,11 @@ type TargetStatus struct {
 	State        TargetState `json:"state"`
 	CurrentStage string      `json:"current_stage,omitempty"`
 	Enabled      bool        `json:"enabled"`
+	WatchPaused  bool        `json:"watch_paused,omitempty"` // file changes don't trigger rebuilds
 	PID          int         `json:"pid,omitempty"`
+	ContainerID  string      `json:"container_id,omitempty"` // docker targets only
+	WaitError    string      `json:"wait_error,omitempty"`   // wait_for endpoints never became ready
+	Host         string      `json:"host,omitempty"`         // remote targets only
 
 	Build PhaseStatus `json:"build"`
 	Test  PhaseStatus `json:"test"`
@@ -87,9 +96,15 @@ type TargetStatus struct {
 type target struct {
 	name        string
 	tcfg        TargetConfig
+	baseDir     string            // absolute path to the runctl.yaml directory
 	rootDir     string            // absolute path to target working directory
 	parentVars  map[string]string // resolved vars from parent (runctl) config
 	verbose     bool
+	masker      *mask.Masker   // redacts secrets from logs and status; may be nil
+	remote      *remoteClient  // set for targets run by a remote agent
+	publish     func(Event)    // sends events to the controller's bus; may be nil
+	stats       *statsRecorder // local usage stats; may be nil
+	clock       clock.Clock
 	title       string
 	description string
 	hasBuild    bool
@@ -102,6 +117,7 @@ type target struct {
 	enabled      bool
 	cancel       context.CancelFunc
 	pid          int
+	containerID  string // docker targets: the running container, once its cidfile appears
 
 	lastBuildTime      *time.Time
 	lastBuildDuration  *float64
@@ -116,28 +132,46 @@ type target struct {
 	restartCount       int
 	buildCount         int
 	testCount          int
+	failing            bool        // a failure was reported and no recovery yet
+	crashes            []time.Time // recent crash times, for crash loop detection
+	waitError          string
+	minFreeSpace       uint64 // bytes the build volumes need free; 0 when unchecked
+
+	lastActive atomic.Int64 // unix nanos of the last activity, for idle_timeout
+
+	outbox  []func()   // events and stats writes queued under mu; see later
+	flushMu sync.Mutex // runs flushes, and so the outbox, one at a time
 
 	buildTrigger chan struct{}
 	testTrigger  chan struct{}
 	execStop     chan struct{}
 	execStart    chan struct{}
+	watchPause   chan struct{}
+	watchResume  chan struct{}
+	watchPaused  bool // survives run loop restarts
 
 	backofficeClient *boclient.Client
 	backofficeReady  bool
+
+	lazy *lazyProxy // holds the lazy.listen port; nil until listenLazy
 }
 
-func newTarget(name string, tcfg TargetConfig, baseDir string, parentVars map[string]string, verbose bool) *target {
-	dir := filepath.Dir(tcfg.Config)
-	if !filepath.IsAbs(dir) {
-		dir = filepath.Join(baseDir, dir)
+func newTarget(name string, tcfg TargetConfig, baseDir string, parentVars map[string]string, verbose bool, masker *mask.Masker, publish func(Event)) *target {
+	var remote *remoteClient
+	if tcfg.IsRemote() {
+		remote = newRemoteClient(tcfg.Host, tcfg.HostToken, name)
 	}
-
 	return &target{
 		name:         name,
+		remote:       remote,
 		tcfg:         tcfg,
-		rootDir:      dir,
+		baseDir:      baseDir,
+		rootDir:      tcfg.Dir(baseDir),
 		parentVars:   parentVars,
 		verbose:      verbose,
+		masker:       masker,
+		publish:      publish,
+		clock:        clock.Real,
 		hasBuild:     false,
 		hasTest:      false,
 		hasRun:       true,
@@ -147,30 +181,42 @@ func newTarget(name string, tcfg TargetConfig, baseDir string, parentVars map[st
 		testTrigger:  make(chan struct{}, 1),
 		execStop:     make(chan struct{}, 1),
 		execStart:    make(chan struct{}, 1),
+		watchPause:   make(chan struct{}, 1),
+		watchResume:  make(chan struct{}, 1),
 	}
 }
 
 // Start launches the target's run loop in a goroutine.
 func (this *target) Start() error {
+	if this.remote != nil {
+		// The agent owns the run loop; enabling it there starts it.
+		err := this.remote.post("enable")
+		if errors.Is(err, ErrAlreadyRunning) {
+			return nil
+		}
+		return err
+	}
+
 	this.mu.Lock()
 	if this.state == StateRunning || this.state == StateStarting {
 		this.mu.Unlock()
-		return fmt.Errorf("target %q is already running", this.name)
+		return fmt.Errorf("target %q is %w", this.name, ErrAlreadyRunning)
+	}
+	if this.state == StateSuspended && this.cancel != nil {
+		// The run loop is still watching; only the process is down.
+		this.mu.Unlock()
+		this.StartExec()
+		return nil
 	}
 	this.state = StateStarting
+	this.crashes = nil
 	this.mu.Unlock()
 
 	return this.start()
 }
 
 func (this *target) start() error {
-	configFile := filepath.Base(this.tcfg.Config)
-	configPath := configutil.ResolveYAMLPath(filepath.Join(this.rootDir, configFile))
-	var configOpts []config.Option
-	if len(this.parentVars) > 0 {
-		configOpts = append(configOpts, config.WithVars(this.parentVars))
-	}
-	ecfg, _, err := execrun.LoadConfig(configPath, configOpts...)
+	ecfg, _, err := this.tcfg.LoadExecConfig(this.name, this.baseDir, this.parentVars)
 	if err != nil {
 		this.mu.Lock()
 		this.state = StateError
@@ -185,6 +231,12 @@ func (this *target) start() error {
 	this.hasRun = !ecfg.IsBuildOnly()
 	this.title = ecfg.Title
 	this.description = ecfg.Description
+	this.mu.Lock()
+	this.minFreeSpace = 0
+	if this.hasBuild {
+		this.minFreeSpace = ecfg.MinFreeBytes()
+	}
+	this.mu.Unlock()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	this.mu.Lock()
@@ -195,12 +247,12 @@ func (this *target) start() error {
 	var buildLog, testLog, runLog io.Writer = os.Stdout, os.Stdout, os.Stdout
 	if this.tcfg.Logs != nil {
 		var err error
-		buildLog, err = openLogFile(this.tcfg.Logs.Build, os.Stdout, &closers)
+		buildLog, err = openLogFile(this.tcfg.Logs.Build, this.tcfg.Logs, os.Stdout, &closers)
 		if err != nil {
 			cancel()
 			return fmt.Errorf("target %q: %w", this.name, err)
 		}
-		testLog, err = openLogFile(this.tcfg.Logs.Test, os.Stdout, &closers)
+		testLog, err = openLogFile(this.tcfg.Logs.Test, this.tcfg.Logs, os.Stdout, &closers)
 		if err != nil {
 			for _, c := range closers {
 				c.Close()
@@ -208,7 +260,7 @@ func (this *target) start() error {
 			cancel()
 			return fmt.Errorf("target %q: %w", this.name, err)
 		}
-		runLog, err = openLogFile(this.tcfg.Logs.Run, os.Stdout, &closers)
+		runLog, err = openLogFile(this.tcfg.Logs.Run, this.tcfg.Logs, os.Stdout, &closers)
 		if err != nil {
 			for _, c := range closers {
 				c.Close()
@@ -218,17 +270,23 @@ func (this *target) start() error {
 		}
 	}
 
-	execSumFile := strings.TrimSuffix(configFile, filepath.Ext(configFile)) + ".sum"
+	// Redact secrets from everything the target writes. The writers are
+	// closed (flushed) before the log files they wrap.
+	buildLog = this.maskWriter(buildLog, &closers)
+	testLog = this.maskWriter(testLog, &closers)
+	runLog = this.maskWriter(runLog, &closers)
+	runLog = activityWriter{t: this, w: runLog}
 
 	opts := execrun.Options{
 		RootDir:          this.rootDir,
-		LogPrefix:        fmt.Sprintf("[%s]", this.name),
+		LogPrefix:        messages.Sprintf(messages.TargetPrefix, this.name),
 		Verbose:          this.verbose,
 		ContinueOnError:  true,
 		DisableHeartbeat: true,
 		Stdout:           runLog,
 		Stderr:           runLog,
-		SumFile:          execSumFile,
+		SumFile:          this.tcfg.SumFilePath(this.name, this.baseDir),
+		Clock:            this.clock,
 
 		ExecStdout: buildLog,
 		ExecStderr: buildLog,
@@ -248,15 +306,31 @@ func (this *target) start() error {
 		TestTrigger:  this.testTrigger,
 		ExecStop:     this.execStop,
 		ExecStart:    this.execStart,
+		WatchPause:   this.watchPause,
+		WatchResume:  this.watchResume,
+	}
+	this.mu.Lock()
+	if this.watchPaused {
+		sendLatest(this.watchPause, this.watchResume) // keep a pause across restarts
+	}
+	this.mu.Unlock()
+
+	this.touch()
+	if timeout := this.tcfg.IdleTimeoutDuration(); timeout > 0 && this.hasRun {
+		go this.watchIdle(ctx, timeout)
 	}
 
 	go func() {
 		defer func() {
-			for _, c := range closers {
-				c.Close()
+			for i := len(closers) - 1; i >= 0; i-- {
+				closers[i].Close()
 			}
 		}()
 
+		if err := this.waitReady(ctx, runLog); err != nil {
+			this.handleRunComplete(ctx, nil)
+			return
+		}
 		err := execrun.Run(ctx, *ecfg, opts)
 		this.handleRunComplete(ctx, err)
 	}()
@@ -264,12 +338,49 @@ func (this *target) start() error {
 	return nil
 }
 
-// openLogFile opens a log file for append. Returns the file as an io.Writer
-// (or the fallback if path is empty) and appends the file to closers.
-func openLogFile(path string, fallback io.Writer, closers *[]io.Closer) (io.Writer, error) {
+// waitReady blocks until the target's wait_for endpoints respond. On timeout
+// the target is left in the error state with the "wait" stage.
+func (this *target) waitReady(ctx context.Context, runLog io.Writer) error {
+	if len(this.tcfg.WaitFor) == 0 {
+		return nil
+	}
+	this.mu.Lock()
+	this.currentStage = "wait"
+	this.waitError = ""
+	this.mu.Unlock()
+
+	fmt.Fprintf(runLog, "[%s] Waiting for %s\n", this.name, strings.Join(this.tcfg.WaitFor, ", "))
+	err := waitFor(ctx, this.tcfg.WaitFor, this.tcfg.WaitTimeoutDuration())
+
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if err != nil {
+		if ctx.Err() == nil {
+			this.state = StateError
+			this.waitError = this.masker.String(err.Error())
+			fmt.Fprintf(runLog, "[%s] %s\n", this.name, this.waitError)
+		}
+		return err
+	}
+	this.currentStage = ""
+	return nil
+}
+
+// openLogFile opens a log file for append, rotating it by size if logs sets
+// a MaxSize. Returns the file as an io.Writer (or the fallback if path is
+// empty) and appends the file to closers.
+func openLogFile(path string, logs *LogsConfig, fallback io.Writer, closers *[]io.Closer) (io.Writer, error) {
 	if path == "" {
 		return fallback, nil
 	}
+	if logs.MaxSize > 0 {
+		f, err := openRotatingFile(path, logs.MaxSize, logs.MaxFiles)
+		if err != nil {
+			return nil, fmt.Errorf("open log %s: %w", path, err)
+		}
+		*closers = append(*closers, f)
+		return f, nil
+	}
 	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
 	if err != nil {
 		return nil, fmt.Errorf("open log %s: %w", path, err)
@@ -278,6 +389,16 @@ func openLogFile(path string, fallback io.Writer, closers *[]io.Closer) (io.Writ
 	return f, nil
 }
 
+// maskWriter wraps w with the target's secret masker, registering the
+// wrapper in closers so held-back bytes are flushed on shutdown.
+func (this *target) maskWriter(w io.Writer, closers *[]io.Closer) io.Writer {
+	mw := this.masker.Writer(w)
+	if c, ok := mw.(io.Closer); ok && mw != w {
+		*closers = append(*closers, c)
+	}
+	return mw
+}
+
 func (this *target) appendRunLogMarker(msg string) error {
 	if this.tcfg.Logs == nil || this.tcfg.Logs.Run == "" {
 		return nil
@@ -289,7 +410,7 @@ func (this *target) appendRunLogMarker(msg string) error {
 	defer f.Close()
 
 	ts := time.Now().Format("2006-01-02 15:04:05")
-	if _, err := fmt.Fprintf(f, "======== %s : %s\n", ts, msg); err != nil {
+	if _, err := fmt.Fprintf(f, "======== %s : %s\n", ts, this.masker.String(msg)); err != nil {
 		return fmt.Errorf("write log %s: %w", this.tcfg.Logs.Run, err)
 	}
 	return nil
@@ -407,6 +528,7 @@ func (this *target) clearRuntimeState() {
 func (this *target) markRunStart(pid int, at time.Time) {
 	hadStartedBefore := this.lastStartTime != nil
 	this.pid = pid
+	this.containerID = ""
 	this.lastStartTime = &at
 	this.currentStage = "run"
 	this.state = StateRunning
@@ -428,11 +550,15 @@ func (this *target) markRunExit(exitCode int) {
 }
 
 func (this *target) handleRunComplete(ctx context.Context, err error) {
+	if this.tcfg.IsDocker() {
+		this.tcfg.removeContainer(this.name)
+	}
+
 	this.mu.Lock()
 	defer this.mu.Unlock()
 	if ctx.Err() != nil {
-		// Context was cancelled — intentional stop
-		if this.state != StateStopped {
+		// Context was cancelled — intentional stop or crash loop
+		if this.state != StateStopped && this.state != StateCrashLoop {
 			this.state = StateStopped
 		}
 		this.currentStage = ""
@@ -445,44 +571,159 @@ func (this *target) handleRunComplete(ctx context.Context, err error) {
 func (this *target) onBuildStart() {
 	this.mu.Lock()
 	defer this.mu.Unlock()
-	this.markPhaseStart("build", time.Now())
+	this.markPhaseStart("build", this.clock.Now())
 }
 
 func (this *target) onBuildDone(duration time.Duration, err error) {
 	this.mu.Lock()
+	defer this.flush()
 	defer this.mu.Unlock()
+	old := this.state
 	this.markPhaseDone("build", duration, err, this.hasBuild)
+	if this.hasBuild {
+		this.later(func() { this.stats.build(this.name, duration, err) })
+	}
+	if err != nil {
+		this.emitFailure(EventBuildFailed, "build", old, err, 0)
+		return
+	}
+	this.emit(Event{Event: EventBuildSucceeded, OldState: old})
+	if !this.hasTest && !this.hasRun {
+		this.emitRecovered(old)
+	}
 }
 
 func (this *target) onTestStart() {
 	this.mu.Lock()
 	defer this.mu.Unlock()
-	this.markPhaseStart("test", time.Now())
+	this.markPhaseStart("test", this.clock.Now())
 }
 
 func (this *target) onTestDone(duration time.Duration, err error) {
 	this.mu.Lock()
+	defer this.flush()
 	defer this.mu.Unlock()
+	old := this.state
 	this.markPhaseDone("test", duration, err, this.hasTest)
+	if this.hasTest {
+		this.later(func() { this.stats.test(this.name, duration, err) })
+	}
+	if err != nil {
+		this.emitFailure(EventTestFailed, "test", old, err, 0)
+		return
+	}
+	this.emit(Event{Event: EventTestSucceeded, OldState: old})
+	if !this.hasRun {
+		this.emitRecovered(old)
+	}
 }
 
 func (this *target) onFilesChanged(at time.Time, _ sumfile.ChangeSet) {
+	this.touch()
 	this.mu.Lock()
 	defer this.mu.Unlock()
 	this.lastFileChangeTime = &at
 }
 
 func (this *target) onProcessStart(pid int) {
+	this.touch()
 	this.mu.Lock()
+	defer this.flush()
 	defer this.mu.Unlock()
-	this.markRunStart(pid, time.Now())
+	old := this.state
+	this.markRunStart(pid, this.clock.Now())
+	this.emit(Event{Event: EventStarted, OldState: old})
+	this.emitRecovered(old)
 }
 
 func (this *target) onProcessExit(exitCode int, err error) {
 	this.mu.Lock()
+	defer this.flush()
 	defer this.mu.Unlock()
-	_ = err
+	old := this.state
 	this.markRunExit(exitCode)
+	if exitCode != 0 {
+		this.emitFailure(EventCrashed, "run", old, err, exitCode)
+		this.later(func() { this.stats.crash(this.name) })
+		if this.recordCrash(this.clock.Now()) {
+			this.enterCrashLoop(StateError)
+		}
+		return
+	}
+	this.emit(Event{Event: EventExited, OldState: old})
+}
+
+// later queues fn, such as publishing an event or saving stats, to run
+// after mu is released, in the order queued. Must hold mu, and the caller
+// must flush after unlocking.
+func (this *target) later(fn func()) {
+	this.outbox = append(this.outbox, fn)
+}
+
+// flush runs the work queued by later. Call it after releasing mu, so file
+// I/O and event subscribers never hold the target's lock.
+func (this *target) flush() {
+	this.flushMu.Lock()
+	defer this.flushMu.Unlock()
+	this.mu.Lock()
+	work := this.outbox
+	this.outbox = nil
+	this.mu.Unlock()
+	for _, fn := range work {
+		fn()
+	}
+}
+
+// emit fills in the target, new state, and time and queues e to be
+// published on flush. Must hold mu.
+func (this *target) emit(e Event) {
+	this.emitWith(e, "")
+}
+
+// emitWith is emit, adding the tail of the stage's log, if any, as the
+// event's output. The log is read on flush. Must hold mu.
+func (this *target) emitWith(e Event, stage string) {
+	if this.publish == nil {
+		return
+	}
+	e.Target = this.name
+	e.NewState = this.state
+	e.Time = this.clock.Now()
+	path := ""
+	if stage != "" {
+		path = this.tcfg.Logs.Path(stage)
+	}
+	this.later(func() {
+		if path != "" {
+			if lines, err := tailFile(path, eventOutputLines); err == nil {
+				for i, line := range lines {
+					lines[i] = this.masker.String(line)
+				}
+				e.Output = lines
+			}
+		}
+		this.publish(e)
+	})
+}
+
+// emitFailure queues a failed stage with its error and log excerpt. Must
+// hold mu.
+func (this *target) emitFailure(event, stage string, old TargetState, err error, exitCode int) {
+	this.failing = true
+	e := Event{Event: event, OldState: old, ExitCode: exitCode}
+	if err != nil {
+		e.Error = this.masker.String(err.Error())
+	}
+	this.emitWith(e, stage)
+}
+
+// emitRecovered queues a recovery if the target was failing. Must hold mu.
+func (this *target) emitRecovered(old TargetState) {
+	if !this.failing {
+		return
+	}
+	this.failing = false
+	this.emit(Event{Event: EventRecovered, OldState: old})
 }
 
 func (this *target) onBackofficeReady(sockPath string) {
@@ -501,6 +742,7 @@ func (this *target) BackofficeClient() *boclient.Client {
 
 // Build sends a build trigger (rebuild + restart).
 func (this *target) Build() {
+	this.touch()
 	select {
 	case this.buildTrigger <- struct{}{}:
 	default:
@@ -509,6 +751,7 @@ func (this *target) Build() {
 
 // Test sends a test trigger (tests only).
 func (this *target) Test() {
+	this.touch()
 	select {
 	case this.testTrigger <- struct{}{}:
 	default:
@@ -517,6 +760,7 @@ func (this *target) Test() {
 
 // StartExec sends an exec start signal (start process without rebuilding).
 func (this *target) StartExec() {
+	this.touch()
 	select {
 	case this.execStart <- struct{}{}:
 	default:
@@ -535,9 +779,43 @@ func (this *target) StopExec() {
 	}
 }
 
+// PauseWatch stops file changes from triggering rebuilds until ResumeWatch.
+func (this *target) PauseWatch() {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.watchPaused = true
+	sendLatest(this.watchPause, this.watchResume)
+}
+
+// ResumeWatch undoes PauseWatch; changes made meanwhile trigger one rebuild.
+func (this *target) ResumeWatch() {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.watchPaused = false
+	sendLatest(this.watchResume, this.watchPause)
+}
+
+// sendLatest sends on ch without blocking, first dropping an unread signal on
+// opposite so only the latest of a pair is seen.
+func sendLatest(ch, opposite chan struct{}) {
+	select {
+	case <-opposite:
+	default:
+	}
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
 // Stop cancels the target's run loop and lets the runner shut down gracefully
 // (SIGTERM → 5s timeout → SIGKILL).
 func (this *target) Stop() {
+	if this.remote != nil {
+		this.stopRemote()
+		return
+	}
+
 	this.mu.Lock()
 	cancel := this.cancel
 	this.cancel = nil
@@ -551,6 +829,11 @@ func (this *target) Stop() {
 
 // Kill cancels the target's run loop and immediately kills the process group.
 func (this *target) Kill() {
+	if this.remote != nil {
+		this.stopRemote()
+		return
+	}
+
 	this.mu.Lock()
 	cancel := this.cancel
 	this.cancel = nil
@@ -567,22 +850,80 @@ func (this *target) Kill() {
 			syscall.Kill(-pgid, syscall.SIGKILL)
 		}
 	}
+
+	// Killing the docker client leaves the container running.
+	if this.tcfg.IsDocker() {
+		this.tcfg.removeContainer(this.name)
+	}
+}
+
+// stopRemote disables the target on its agent, so it stays stopped until
+// this controller starts it again.
+func (this *target) stopRemote() {
+	if err := this.remote.post("disable"); err != nil {
+		warnf("failed to stop %s: %v", this.name, err)
+	}
+}
+
+// diskWarning reports low disk space on the volumes the target builds on,
+// or "" if there is enough (or no threshold).
+func (this *target) diskWarning() string {
+	this.mu.Lock()
+	minFree := this.minFreeSpace
+	this.mu.Unlock()
+	if minFree == 0 {
+		return ""
+	}
+	if err := diskspace.Check(minFree, os.TempDir(), this.rootDir); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// cacheContainerID reads a docker target's container ID from its cidfile,
+// outside the lock, until the file appears; the ID then holds until the
+// process restarts.
+func (this *target) cacheContainerID() {
+	if !this.tcfg.IsDocker() {
+		return
+	}
+	this.mu.Lock()
+	pid, known := this.pid, this.containerID != ""
+	this.mu.Unlock()
+	if pid == 0 || known {
+		return
+	}
+	id := this.tcfg.readContainerID(this.name)
+	if id == "" {
+		return
+	}
+	this.mu.Lock()
+	if this.pid == pid {
+		this.containerID = id
+	}
+	this.mu.Unlock()
 }
 
 // Status returns the current status snapshot.
 func (this *target) Status() TargetStatus {
+	if this.remote != nil {
+		return this.remoteStatus()
+	}
+
+	this.cacheContainerID()
+
 	this.mu.Lock()
 	defer this.mu.Unlock()
 
-	// Populate ResolvedURL for each link
-	links := make([]Link, len(this.tcfg.Links))
-	copy(links, this.tcfg.Links)
-	for i := range links {
-		if links[i].File != "" {
-			links[i].ResolvedURL = "/api/file?path=" + url.QueryEscape(links[i].File)
-		} else {
-			links[i].ResolvedURL = links[i].URL
-		}
+	links := this.resolvedLinks()
+
+	// Errors embed command lines, which may contain secrets.
+	buildErr := this.masker.String(this.lastBuildError)
+	testErr := this.masker.String(this.lastTestError)
+
+	containerID := ""
+	if this.pid > 0 {
+		containerID = this.containerID
 	}
 
 	ts := TargetStatus{
@@ -595,21 +936,24 @@ func (this *target) Status() TargetStatus {
 		State:              this.state,
 		CurrentStage:       this.currentStage,
 		Enabled:            this.enabled,
+		WatchPaused:        this.watchPaused,
 		PID:                this.pid,
-		Build:              phaseSnapshot(this.lastBuildTime, this.lastBuildDuration, this.lastBuildResult, this.lastBuildError, this.buildCount),
-		Test:               phaseSnapshot(this.lastTestTime, this.lastTestDuration, this.lastTestResult, this.lastTestError, this.testCount),
+		ContainerID:        containerID,
+		WaitError:          this.waitError,
+		Build:              phaseSnapshot(this.lastBuildTime, this.lastBuildDuration, this.lastBuildResult, buildErr, this.buildCount),
+		Test:               phaseSnapshot(this.lastTestTime, this.lastTestDuration, this.lastTestResult, testErr, this.testCount),
 		LastBuildTime:      this.lastBuildTime,
 		LastBuildDuration:  this.lastBuildDuration,
 		LastBuildResult:    this.lastBuildResult,
-		LastBuildError:     this.lastBuildError,
+		LastBuildError:     buildErr,
 		LastTestTime:       this.lastTestTime,
 		LastTestDuration:   this.lastTestDuration,
 		LastTestResult:     this.lastTestResult,
-		LastTestError:      this.lastTestError,
+		LastTestError:      testErr,
 		LastExecTime:       this.lastBuildTime,
 		LastExecDuration:   this.lastBuildDuration,
 		LastExecResult:     this.lastBuildResult,
-		LastExecError:      this.lastBuildError,
+		LastExecError:      buildErr,
 		LastStartTime:      this.lastStartTime,
 		LastFileChangeTime: this.lastFileChangeTime,
 		RestartCount:       this.restartCount,
@@ -622,3 +966,37 @@ func (this *target) Status() TargetStatus {
 
 	return ts
 }
+
+// resolvedLinks returns the configured links with ResolvedURL populated.
+func (this *target) resolvedLinks() []Link {
+	links := make([]Link, len(this.tcfg.Links))
+	copy(links, this.tcfg.Links)
+	for i := range links {
+		if links[i].File != "" {
+			links[i].ResolvedURL = this.tcfg.APIPrefix + "/file?path=" + url.QueryEscape(links[i].File)
+		} else {
+			links[i].ResolvedURL = links[i].URL
+		}
+	}
+	return links
+}
+
+// remoteStatus fetches the status from the target's agent. An unreachable
+// agent is reported as an error state rather than failing the whole listing.
+func (this *target) remoteStatus() TargetStatus {
+	ts, err := this.remote.status()
+	if err != nil {
+		ts = TargetStatus{
+			State:          StateError,
+			Enabled:        this.tcfg.IsEnabled(),
+			HasRun:         true,
+			LastBuildError: err.Error(),
+		}
+		ts.Build.Error = err.Error()
+	}
+	ts.Name = this.name
+	ts.Host = this.tcfg.Host
+	// Links are configured on this side; file links resolve locally.
+	ts.Links = this.resolvedLinks()
+	return ts
+}
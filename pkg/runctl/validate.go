@@ -0,0 +1,25 @@
+package runctl
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ValidateTargets loads every local target's config, processing its
+// templates with the vars passed down to it, and returns all the failures
+// joined, one per target. baseDir is runctl.yaml's directory.
+func (this *Config) ValidateTargets(baseDir string) error {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(this.Targets)) {
+		t := this.Targets[name]
+		if t.IsRemote() {
+			continue
+		}
+		if _, _, err := t.LoadExecConfig(name, baseDir, this.ParentVars(name)); err != nil {
+			errs = append(errs, fmt.Errorf("target %q: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
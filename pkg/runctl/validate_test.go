@@ -0,0 +1,60 @@
+package runctl_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("ValidateTargets", func() {
+	var dir string
+
+	writeFile := func(name, content string) {
+		path := filepath.Join(dir, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+	}
+
+	load := func() *runctl.Config {
+		cfg, err := runctl.LoadConfig(filepath.Join(dir, "runctl.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		return cfg
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		writeFile("runctl.yaml", `
+cache_dir: .cache
+targets:
+  api:
+    config: api/execrun.yaml
+    vars:
+      PORT: "8080"
+  web:
+    config: web/execrun.yaml
+  worker:
+    config: worker/execrun.yaml
+`)
+		writeFile("api/execrun.yaml", "watch: [\"*.go\"]\nexec: [\"./api -port {{ .PORT }}\"]\n")
+		writeFile("web/execrun.yaml", "watch: [\"*.js\"]\nexec: [\"node {{ .MISSING\"]\n")
+		writeFile("worker/execrun.yaml", "watch: [\"*.go\"]\n")
+	})
+
+	It("lists every target whose config fails", func() {
+		err := load().ValidateTargets(dir)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(`target "web"`))
+		Expect(err.Error()).To(ContainSubstring(`target "worker"`))
+		Expect(err.Error()).NotTo(ContainSubstring(`target "api"`))
+	})
+
+	It("passes when every target's config loads", func() {
+		writeFile("web/execrun.yaml", "watch: [\"*.js\"]\nexec: [\"node server.js\"]\n")
+		writeFile("worker/execrun.yaml", "watch: [\"*.go\"]\nexec: [\"./worker\"]\n")
+		Expect(load().ValidateTargets(dir)).To(Succeed())
+	})
+})
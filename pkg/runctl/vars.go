@@ -0,0 +1,159 @@
+package runctl
+
+import (
+	"maps"
+	"slices"
+	"sort"
+
+	"github.com/gur-shatz/go-run/internal/mask"
+)
+
+// VarSource tells where a var's effective value comes from.
+type VarSource string
+
+// Sources in priority order: env wins over secret, secret over target,
+// target over global, global over builtin, and any of them over the child
+// execrun config's own vars: section.
+const (
+	VarSourceEnv     VarSource = "env"
+	VarSourceSecret  VarSource = "secret" // from secrets:; the value is always masked
+	VarSourceTarget  VarSource = "target"
+	VarSourceGlobal  VarSource = "global"
+	VarSourceBuiltin VarSource = "builtin" // defined by runctl, e.g. SCRATCH_DIR
+	VarSourceChild   VarSource = "child"
+)
+
+// Var is a resolved variable with its provenance.
+type Var struct {
+	Name    string      `json:"name"`
+	Value   string      `json:"value"`
+	Source  VarSource   `json:"source"`
+	Shadows []VarSource `json:"shadows,omitempty"` // lower-priority sources that also define it
+}
+
+// TargetVars is the merged view of the vars a target's child config sees.
+type TargetVars struct {
+	Name  string `json:"name"`
+	Vars  []Var  `json:"vars"`
+	Error string `json:"error,omitempty"` // child config failed to load
+}
+
+// VarsView is the structured output of `runctl vars`.
+type VarsView struct {
+	Global  []Var        `json:"global"`
+	Targets []TargetVars `json:"targets"`
+}
+
+// ParentVars returns the vars passed down to a target's child config:
+// built-in vars, then global vars, then the target's vars on top.
+func (this *Config) ParentVars(name string) map[string]string {
+	tcfg := this.Targets[name]
+	builtin := tcfg.builtinVars()
+	if len(tcfg.Vars) == 0 && len(builtin) == 0 {
+		return this.ResolvedVars
+	}
+	merged := make(map[string]string, len(builtin)+len(this.ResolvedVars)+len(tcfg.Vars))
+	maps.Copy(merged, builtin)
+	maps.Copy(merged, this.ResolvedVars)
+	maps.Copy(merged, tcfg.Vars)
+	return merged
+}
+
+// builtinVars returns the vars runctl defines for every target.
+func (this TargetConfig) builtinVars() map[string]string {
+	if this.ScratchDir == "" {
+		return nil
+	}
+	return map[string]string{ScratchVar: this.ScratchDir}
+}
+
+// VarsReport builds a VarsView for the named targets, loading each target's
+// child config to include its own vars. A nil env defaults to os.Environ().
+func (this *Config) VarsReport(names []string, baseDir string, env map[string]string) VarsView {
+	if env == nil {
+		env = environMap()
+	}
+
+	// Secrets are injected into the environment on load; don't report
+	// them as env overrides of themselves.
+	env = maps.Clone(env)
+	for k, v := range this.Secrets {
+		if env[k] == v {
+			delete(env, k)
+		}
+	}
+	masker, _ := this.newMasker()
+
+	report := VarsView{
+		Global: redactVars(masker, mergeVars(env, []varLayer{
+			{VarSourceSecret, this.Secrets},
+			{VarSourceGlobal, this.ResolvedVars},
+		})),
+		Targets: make([]TargetVars, 0, len(names)),
+	}
+	for _, name := range names {
+		tcfg := this.Targets[name]
+		tv := TargetVars{Name: name}
+		_, childVars, err := tcfg.LoadExecConfig(name, baseDir, this.ParentVars(name))
+		if err != nil {
+			tv.Error = masker.String(err.Error())
+		}
+		tv.Vars = redactVars(masker, mergeVars(env, []varLayer{
+			{VarSourceSecret, this.Secrets},
+			{VarSourceTarget, tcfg.Vars},
+			{VarSourceGlobal, this.ResolvedVars},
+			{VarSourceBuiltin, tcfg.builtinVars()},
+			{VarSourceChild, childVars},
+		}))
+		report.Targets = append(report.Targets, tv)
+	}
+	return report
+}
+
+// redactVars masks secret values, and the whole value of any var named in
+// secrets: however short it is.
+func redactVars(masker *mask.Masker, vars []Var) []Var {
+	for i, v := range vars {
+		if v.Source == VarSourceSecret || slices.Contains(v.Shadows, VarSourceSecret) {
+			vars[i].Value = mask.Placeholder
+		} else {
+			vars[i].Value = masker.String(v.Value)
+		}
+	}
+	return vars
+}
+
+type varLayer struct {
+	source VarSource
+	vars   map[string]string
+}
+
+// mergeVars resolves every var defined in layers (highest priority first).
+// The environment overrides any of them but does not add vars of its own.
+func mergeVars(env map[string]string, layers []varLayer) []Var {
+	byName := make(map[string]*Var)
+	var names []string
+	for _, l := range layers {
+		for k, v := range l.vars {
+			if existing, ok := byName[k]; ok {
+				existing.Shadows = append(existing.Shadows, l.source)
+				continue
+			}
+			byName[k] = &Var{Name: k, Value: v, Source: l.source}
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+
+	result := make([]Var, 0, len(names))
+	for _, k := range names {
+		v := byName[k]
+		if ev, ok := env[k]; ok {
+			v.Shadows = append([]VarSource{v.Source}, v.Shadows...)
+			v.Value = ev
+			v.Source = VarSourceEnv
+		}
+		result = append(result, *v)
+	}
+	return result
+}
@@ -0,0 +1,137 @@
+package runctl_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("VarsReport", func() {
+	var (
+		dir string
+		cfg *runctl.Config
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		Expect(os.MkdirAll(filepath.Join(dir, "app"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "app", "execrun.yaml"), []byte(`
+vars:
+  GREETING: "child default"
+  LOG_LEVEL: "info"
+watch:
+  - "*.go"
+exec:
+  - "echo {{ .GREETING }}"
+`), 0644)).To(Succeed())
+		cfgPath := filepath.Join(dir, "runctl.yaml")
+		Expect(os.WriteFile(cfgPath, []byte(`
+vars:
+  PORT: "8080"
+  GREETING: "global hello"
+targets:
+  app:
+    config: app/execrun.yaml
+    vars:
+      GREETING: "target hello"
+  broken:
+    config: missing/execrun.yaml
+`), 0644)).To(Succeed())
+
+		var err error
+		cfg, err = runctl.LoadConfig(cfgPath)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("merges global, target, and child vars with provenance", func() {
+		report := cfg.VarsReport([]string{"app"}, dir, map[string]string{})
+
+		Expect(report.Global).To(Equal([]runctl.Var{
+			{Name: "GREETING", Value: "global hello", Source: runctl.VarSourceGlobal},
+			{Name: "PORT", Value: "8080", Source: runctl.VarSourceGlobal},
+		}))
+		Expect(report.Targets).To(HaveLen(1))
+		Expect(report.Targets[0].Error).To(BeEmpty())
+		Expect(report.Targets[0].Vars).To(Equal([]runctl.Var{
+			{Name: "GREETING", Value: "target hello", Source: runctl.VarSourceTarget, Shadows: []runctl.VarSource{runctl.VarSourceGlobal, runctl.VarSourceChild}},
+			{Name: "LOG_LEVEL", Value: "info", Source: runctl.VarSourceChild},
+			{Name: "PORT", Value: "8080", Source: runctl.VarSourceGlobal},
+			{Name: runctl.ScratchVar, Value: cfg.Targets["app"].ScratchDir, Source: runctl.VarSourceBuiltin},
+		}))
+	})
+
+	It("reports env overrides of defined vars only", func() {
+		env := map[string]string{"LOG_LEVEL": "debug", "UNRELATED": "x"}
+		report := cfg.VarsReport([]string{"app"}, dir, env)
+
+		Expect(report.Targets[0].Vars).To(ContainElement(runctl.Var{
+			Name: "LOG_LEVEL", Value: "debug", Source: runctl.VarSourceEnv, Shadows: []runctl.VarSource{runctl.VarSourceChild},
+		}))
+		for _, v := range report.Targets[0].Vars {
+			Expect(v.Name).NotTo(Equal("UNRELATED"))
+		}
+	})
+
+	It("keeps parent vars when the child config fails to load", func() {
+		report := cfg.VarsReport([]string{"broken"}, dir, map[string]string{})
+
+		Expect(report.Targets[0].Error).To(ContainSubstring("missing/execrun.yaml"))
+		Expect(report.Targets[0].Vars).To(HaveLen(3)) // GREETING, PORT, SCRATCH_DIR
+	})
+})
+
+var _ = Describe("Secrets", func() {
+	var (
+		dir     string
+		cfgPath string
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		cfgPath = filepath.Join(dir, "runctl.yaml")
+		Expect(os.WriteFile(filepath.Join(dir, "api-key"), []byte("key-from-file-42\n"), 0600)).To(Succeed())
+		DeferCleanup(os.Unsetenv, "RUNCTL_TEST_DB_PASS")
+	})
+
+	It("exports secrets and masks them in reports", func() {
+		Expect(os.WriteFile(cfgPath, []byte(`
+vars:
+  API_KEY: '{{ secretFile "api-key" }}'
+secrets:
+  RUNCTL_TEST_DB_PASS: "hunter2-db"
+targets:
+  app:
+    type: command
+    cmd: "echo $RUNCTL_TEST_DB_PASS"
+`), 0644)).To(Succeed())
+		cfg, err := runctl.LoadConfig(cfgPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Getenv("RUNCTL_TEST_DB_PASS")).To(Equal("hunter2-db"))
+
+		report := cfg.VarsReport([]string{"app"}, dir, map[string]string{"RUNCTL_TEST_DB_PASS": "hunter2-db"})
+		Expect(report.Global).To(Equal([]runctl.Var{
+			{Name: "API_KEY", Value: "***", Source: runctl.VarSourceGlobal},
+			{Name: "RUNCTL_TEST_DB_PASS", Value: "***", Source: runctl.VarSourceSecret},
+		}))
+		Expect(cfg.Redact("db=hunter2-db key=key-from-file-42")).To(Equal("db=*** key=***"))
+	})
+
+	It("rejects a secret that is also a var", func() {
+		Expect(os.WriteFile(cfgPath, []byte(`
+vars:
+  RUNCTL_TEST_DB_PASS: "x"
+secrets:
+  RUNCTL_TEST_DB_PASS: "hunter2-db"
+targets:
+  app:
+    type: command
+    cmd: "true"
+`), 0644)).To(Succeed())
+		_, err := runctl.LoadConfig(cfgPath)
+		Expect(err).To(MatchError(ContainSubstring(`secret "RUNCTL_TEST_DB_PASS" is also defined in vars`)))
+	})
+})
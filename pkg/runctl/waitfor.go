@@ -0,0 +1,96 @@
+package runctl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultWaitTimeout bounds how long a target waits for its wait_for endpoints.
+const DefaultWaitTimeout = 60 * time.Second
+
+// waitPollInterval is the delay between readiness checks.
+const waitPollInterval = 250 * time.Millisecond
+
+// parseWaitEndpoint validates a wait_for entry: tcp://host:port or an
+// http(s) URL.
+func parseWaitEndpoint(endpoint string) (*url.URL, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "tcp":
+		if u.Port() == "" {
+			return nil, fmt.Errorf("tcp endpoint needs a port (tcp://host:port)")
+		}
+	case "http", "https":
+		if u.Host == "" {
+			return nil, fmt.Errorf("missing host")
+		}
+	default:
+		return nil, fmt.Errorf("scheme must be tcp, http, or https")
+	}
+	return u, nil
+}
+
+// waitFor blocks until every endpoint is ready, the timeout elapses, or ctx
+// is cancelled. A TCP endpoint is ready once it accepts a connection, an HTTP
+// endpoint once it answers with a 2xx or 3xx status.
+func waitFor(ctx context.Context, endpoints []string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for _, endpoint := range endpoints {
+		u, err := parseWaitEndpoint(endpoint)
+		if err != nil {
+			return fmt.Errorf("wait_for %q: %w", endpoint, err)
+		}
+		for {
+			err = checkEndpoint(ctx, u)
+			if err == nil {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				if ctx.Err() == context.DeadlineExceeded {
+					return fmt.Errorf("timed out after %s waiting for %s: %w", timeout, endpoint, err)
+				}
+				return ctx.Err()
+			case <-time.After(waitPollInterval):
+			}
+		}
+	}
+	return nil
+}
+
+func checkEndpoint(ctx context.Context, u *url.URL) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	if u.Scheme == "tcp" {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", u.Host)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+	return nil
+}
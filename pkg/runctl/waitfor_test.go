@@ -0,0 +1,94 @@
+package runctl_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("wait_for", func() {
+	// freeAddr returns a local address with nothing listening on it.
+	freeAddr := func() string {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		addr := l.Addr().String()
+		l.Close()
+		return addr
+	}
+
+	newController := func(waitFor []string, timeout string) *runctl.Controller {
+		ctrl, err := runctl.New(runctl.Config{
+			API: runctl.APIConfig{Port: 9100},
+			Targets: map[string]runctl.TargetConfig{
+				"app": {Type: runctl.TargetTypeCommand, Cmd: "sleep 60", WaitFor: waitFor, WaitTimeout: timeout},
+			},
+		}, GinkgoT().TempDir(), false)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(ctrl.KillTargets)
+		return ctrl
+	}
+
+	status := func(ctrl *runctl.Controller) func() runctl.TargetStatus {
+		return func() runctl.TargetStatus {
+			st, err := ctrl.TargetStatus("app")
+			Expect(err).NotTo(HaveOccurred())
+			return *st
+		}
+	}
+
+	It("rejects unsupported endpoints and bad timeouts", func() {
+		dir := GinkgoT().TempDir()
+		cfgPath := filepath.Join(dir, "runctl.yaml")
+
+		Expect(os.WriteFile(cfgPath, []byte(`
+targets:
+  app:
+    config: app/execrun.yaml
+    wait_for: ["postgres://localhost:5432"]
+`), 0644)).To(Succeed())
+		_, err := runctl.LoadConfig(cfgPath)
+		Expect(err).To(MatchError(ContainSubstring("scheme must be tcp, http, or https")))
+
+		Expect(os.WriteFile(cfgPath, []byte(`
+targets:
+  app:
+    config: app/execrun.yaml
+    wait_for: ["tcp://localhost:5432"]
+    wait_timeout: soon
+`), 0644)).To(Succeed())
+		_, err = runctl.LoadConfig(cfgPath)
+		Expect(err).To(MatchError(ContainSubstring("wait_timeout must be a positive duration")))
+	})
+
+	It("starts once the endpoint accepts connections", func() {
+		addr := freeAddr()
+		ctrl := newController([]string{"tcp://" + addr}, "10s")
+		ctrl.StartTargets()
+
+		Eventually(status(ctrl), "2s", "20ms").Should(HaveField("CurrentStage", "wait"))
+		Consistently(status(ctrl), "300ms", "50ms").ShouldNot(HaveField("State", runctl.StateRunning))
+
+		l, err := net.Listen("tcp", addr)
+		Expect(err).NotTo(HaveOccurred())
+		defer l.Close()
+
+		Eventually(status(ctrl), "5s", "20ms").Should(HaveField("State", runctl.StateRunning))
+	})
+
+	It("reports an error when the endpoint never responds", func() {
+		addr := freeAddr()
+		ctrl := newController([]string{"tcp://" + addr}, "300ms")
+		ctrl.StartTargets()
+
+		Eventually(status(ctrl), "5s", "20ms").Should(SatisfyAll(
+			HaveField("State", runctl.StateError),
+			HaveField("CurrentStage", "wait"),
+			HaveField("WaitError", ContainSubstring("timed out after 300ms waiting for tcp://"+addr)),
+		))
+	})
+})
@@ -0,0 +1,82 @@
+package runctl_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gur-shatz/go-run/pkg/clock"
+	"github.com/gur-shatz/go-run/pkg/execrun"
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("Watch pause", func() {
+	var (
+		ctrl *runctl.Controller
+		clk  *clock.Fake
+		dir  string
+	)
+
+	BeforeEach(func() {
+		clk = clock.NewFake(time.Now())
+		dir = GinkgoT().TempDir()
+		var err error
+		ctrl, err = runctl.New(runctl.Config{
+			API:   runctl.APIConfig{Port: 9100},
+			Stats: new(bool), // killing the process records a crash
+			Targets: map[string]runctl.TargetConfig{
+				"app": {Type: runctl.TargetTypeCommand, Cmd: "sleep 60", Watch: execrun.Watches("trigger.txt")},
+			},
+		}, dir, false)
+		Expect(err).NotTo(HaveOccurred())
+		ctrl.SetClock(clk)
+		ctrl.StartTargets()
+		DeferCleanup(ctrl.KillTargets)
+	})
+
+	status := func() *runctl.TargetStatus {
+		st, err := ctrl.TargetStatus("app")
+		Expect(err).NotTo(HaveOccurred())
+		return st
+	}
+
+	lastChange := func() *time.Time {
+		clk.Advance(time.Second)
+		return status().LastFileChangeTime
+	}
+
+	It("holds file changes until resumed", func() {
+		Eventually(func() runctl.TargetState { return status().State }, "5s", "20ms").Should(Equal(runctl.StateRunning))
+
+		server := serveAPI(ctrl)
+		defer server.Close()
+		resp, err := http.Post(server.URL+"/api/targets/app/watch/pause", "application/json", nil)
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(status().WatchPaused).To(BeTrue())
+
+		Expect(os.WriteFile(filepath.Join(dir, "trigger.txt"), []byte("go\n"), 0644)).To(Succeed())
+		Consistently(lastChange, "300ms", "20ms").Should(BeNil())
+
+		resp, err = http.Post(server.URL+"/api/targets/app/watch/resume", "application/json", nil)
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(status().WatchPaused).To(BeFalse())
+		Eventually(lastChange, "5s", "20ms").ShouldNot(BeNil())
+	})
+
+	It("returns 404 for an unknown target", func() {
+		server := serveAPI(ctrl)
+		defer server.Close()
+		resp, err := http.Post(server.URL+"/api/targets/nope/watch/pause", "application/json", nil)
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+	})
+})
@@ -0,0 +1,192 @@
+package runctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// overviewPollInterval is how often the overview watcher rechecks target
+// statuses for changes that don't raise an event, such as a build starting
+// or files changing. Events wake it at once.
+const overviewPollInterval = 500 * time.Millisecond
+
+// overviewWarningsInterval is how often the overview watcher refreshes the
+// disk space warnings, which stat every target's volumes, between events.
+const overviewWarningsInterval = 30 * time.Second
+
+// overviewHub runs a single overview watcher for all WatchOverview callers,
+// so the cost of polling doesn't grow with the number of dashboards open.
+type overviewHub struct {
+	mu   sync.Mutex
+	subs map[chan Overview]struct{}
+	last *Overview
+	stop context.CancelFunc
+}
+
+// WatchOverview sends the overview on the returned channel right away and
+// again whenever it changes, until ctx is cancelled. Targets are sorted by
+// name. A slow receiver only gets the latest overview.
+func (this *Controller) WatchOverview(ctx context.Context) <-chan Overview {
+	hub := &this.overview
+	ch := make(chan Overview, 1)
+
+	hub.mu.Lock()
+	if hub.subs == nil {
+		hub.subs = make(map[chan Overview]struct{})
+	}
+	hub.subs[ch] = struct{}{}
+	if hub.last != nil {
+		ch <- *hub.last
+	}
+	if hub.stop == nil {
+		watchCtx, stop := context.WithCancel(context.Background())
+		hub.stop = stop
+		go this.watchOverview(watchCtx)
+	}
+	hub.mu.Unlock()
+
+	out := make(chan Overview)
+	go func() {
+		defer close(out)
+		defer func() {
+			hub.mu.Lock()
+			delete(hub.subs, ch)
+			if len(hub.subs) == 0 {
+				hub.stop()
+				hub.stop = nil
+				hub.last = nil
+			}
+			hub.mu.Unlock()
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case overview := <-ch:
+				select {
+				case out <- overview:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// watchOverview builds the overview and hands it to every subscriber when
+// it changes, until ctx is cancelled.
+func (this *Controller) watchOverview(ctx context.Context) {
+	_, events, cancel := this.events.subscribe()
+	defer cancel()
+
+	ticker := time.NewTicker(overviewPollInterval)
+	defer ticker.Stop()
+
+	warnings := this.Warnings()
+	warnedAt := time.Now()
+	var last *Overview
+	for {
+		overview := Overview{
+			Title:       this.cfg.Title,
+			Description: this.cfg.Description,
+			Targets:     this.Status(),
+			Warnings:    warnings,
+		}
+		slices.SortFunc(overview.Targets, func(a, b TargetStatus) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+		if last == nil || !reflect.DeepEqual(overview, *last) {
+			last = &overview
+			this.overview.broadcast(ctx, last)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-events:
+			warnings, warnedAt = this.Warnings(), time.Now()
+		case <-ticker.C:
+			if time.Since(warnedAt) >= overviewWarningsInterval {
+				warnings, warnedAt = this.Warnings(), time.Now()
+			}
+		}
+	}
+}
+
+// broadcast records overview as the latest and queues it for every
+// subscriber, replacing one it hasn't received yet.
+func (this *overviewHub) broadcast(ctx context.Context, overview *Overview) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if ctx.Err() != nil {
+		return // a newer watcher owns the hub
+	}
+	this.last = overview
+	for ch := range this.subs {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- *overview
+	}
+}
+
+// checkOrigin rejects WebSocket handshakes from pages on another host.
+// x/net/websocket doesn't enforce the same-origin policy itself, and a
+// cross-site page could otherwise read the overview. Clients that send no
+// Origin, such as CLIs, are not browsers and are let through.
+func checkOrigin(config *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return fmt.Errorf("bad origin %q", origin)
+	}
+	host := r.Host
+	if fwd := r.Header.Get("X-Forwarded-Host"); fwd != "" {
+		host = fwd
+	}
+	if !strings.EqualFold(u.Host, host) {
+		return fmt.Errorf("origin %s does not match host %s", origin, host)
+	}
+	config.Origin = u
+	return nil
+}
+
+// handleWS pushes an overview snapshot as a JSON text message whenever it
+// changes. Messages from the client are ignored.
+func (this *Controller) handleWS(w http.ResponseWriter, r *http.Request) {
+	websocket.Server{
+		Handshake: checkOrigin,
+		Handler: func(ws *websocket.Conn) {
+			ctx, cancel := context.WithCancel(r.Context())
+			defer cancel()
+
+			// The request context outlives the hijacked connection, so
+			// notice the client going away by reading until it fails.
+			go func() {
+				io.Copy(io.Discard, ws)
+				cancel()
+			}()
+
+			for overview := range this.WatchOverview(ctx) {
+				if err := websocket.JSON.Send(ws, overview); err != nil {
+					return
+				}
+			}
+		},
+	}.ServeHTTP(w, r)
+}
@@ -0,0 +1,79 @@
+package runctl_test
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/websocket"
+
+	"github.com/gur-shatz/go-run/pkg/runctl"
+)
+
+var _ = Describe("WebSocket push", func() {
+	It("sends an overview on connect and again when a target changes", func() {
+		ctrl, err := runctl.New(runctl.Config{
+			API:   runctl.APIConfig{Port: 9100},
+			Title: "demo",
+			Targets: map[string]runctl.TargetConfig{
+				"app": {Type: runctl.TargetTypeCommand, Cmd: "true"},
+			},
+		}, GinkgoT().TempDir(), false)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(ctrl.KillTargets)
+
+		server := serveAPI(ctrl)
+		defer server.Close()
+		ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/ws", "", server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		defer ws.Close()
+		ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+		var overview runctl.Overview
+		Expect(websocket.JSON.Receive(ws, &overview)).To(Succeed())
+		Expect(overview.Title).To(Equal("demo"))
+		Expect(overview.Targets).To(HaveLen(1))
+		Expect(overview.Targets[0].State).To(Equal(runctl.StateIdle))
+
+		ctrl.StartTargets()
+		Eventually(func() runctl.TargetState {
+			Expect(websocket.JSON.Receive(ws, &overview)).To(Succeed())
+			return overview.Targets[0].State
+		}, "5s").Should(Equal(runctl.StateExited))
+	})
+
+	It("rejects connections from other origins", func() {
+		ctrl, err := runctl.New(runctl.Config{
+			API:     runctl.APIConfig{Port: 9100},
+			Targets: map[string]runctl.TargetConfig{"app": {Type: runctl.TargetTypeCommand, Cmd: "true"}},
+		}, GinkgoT().TempDir(), false)
+		Expect(err).NotTo(HaveOccurred())
+
+		server := serveAPI(ctrl)
+		defer server.Close()
+		_, err = websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/ws", "", "http://evil.example")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("shares one watcher between clients", func(ctx SpecContext) {
+		ctrl, err := runctl.New(runctl.Config{
+			API:     runctl.APIConfig{Port: 9100},
+			Targets: map[string]runctl.TargetConfig{"app": {Type: runctl.TargetTypeCommand, Cmd: "true"}},
+		}, GinkgoT().TempDir(), false)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(ctrl.KillTargets)
+
+		a := ctrl.WatchOverview(ctx)
+		b := ctrl.WatchOverview(ctx)
+		Expect((<-a).Targets[0].State).To(Equal(runctl.StateIdle))
+		Expect((<-b).Targets[0].State).To(Equal(runctl.StateIdle))
+
+		ctrl.StartTargets()
+		for _, ch := range []<-chan runctl.Overview{a, b} {
+			Eventually(func() runctl.TargetState {
+				return (<-ch).Targets[0].State
+			}, "5s").Should(Equal(runctl.StateExited))
+		}
+	})
+})
@@ -1,10 +1,12 @@
 package runui
 
 import (
+	"bytes"
 	"embed"
-	"io"
+	"html"
 	"io/fs"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -12,27 +14,36 @@ import (
 //go:embed static/*
 var staticFiles embed.FS
 
+// startTime stands in for index.html's modification time, which embed.FS
+// doesn't record.
+var startTime = time.Now()
+
 // Routes returns a chi.Router that serves the embedded dashboard UI.
-func Routes() chi.Router {
+// basePath is the prefix runctl is served under (api.base_path, "" for
+// none); the page calls the API at basePath + "/api".
+func Routes(basePath string) chi.Router {
 	r := chi.NewRouter()
 
 	sub, _ := fs.Sub(staticFiles, "static")
 	fileServer := http.FileServer(http.FS(sub))
 
+	index, _ := fs.ReadFile(sub, "index.html")
+	index = bytes.Replace(index,
+		[]byte(`<meta name="runui-base" content="">`),
+		[]byte(`<meta name="runui-base" content="`+html.EscapeString(basePath)+`">`), 1)
+
 	r.Get("/*", func(w http.ResponseWriter, req *http.Request) {
+		// The path below the mount point; req.URL.Path still has the prefix.
+		rest := chi.URLParam(req, "*")
+
 		// Serve index.html for the root path.
 		// Use ServeContent directly to avoid FileServer's redirect from /index.html → /
-		if req.URL.Path == "/" || req.URL.Path == "/index.html" {
-			f, err := sub.Open("index.html")
-			if err != nil {
-				http.NotFound(w, req)
-				return
-			}
-			defer f.Close()
-			stat, _ := f.Stat()
-			http.ServeContent(w, req, "index.html", stat.ModTime(), f.(io.ReadSeeker))
+		if rest == "" || rest == "index.html" {
+			http.ServeContent(w, req, "index.html", startTime, bytes.NewReader(index))
 			return
 		}
+		req = req.Clone(req.Context())
+		req.URL.Path = "/" + rest
 		fileServer.ServeHTTP(w, req)
 	})
 